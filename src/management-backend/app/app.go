@@ -1,23 +1,46 @@
 package app
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"gorm.io/gorm"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/config"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/adapter/captcha"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/adapter/mailer"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/adapter/repository"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/adapter/revocation"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/adapter/throttle"
 	user "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin"
+	adminD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/domain"
 	userP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/port"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/audit"
+	auditP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/audit/port"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/auth"
+	authD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/auth/domain"
+	authP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/auth/port"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/common"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/plan"
 	planP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/plan/port"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/security"
+	securityD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/security/domain"
+	securityP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/security/port"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/migrations"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/pkg/database"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/pkg/migrate"
 )
 
-var ErrNilLogger = errors.New("nil logger")
+var (
+	ErrNilLogger     = errors.New("nil logger")
+	ErrInvalidConfig = errors.New("invalid config")
+)
 
 type App interface {
 	Config() config.Config
@@ -25,6 +48,10 @@ type App interface {
 	DB() *gorm.DB
 	UserService() userP.Service
 	PlanService() planP.Service
+	AuditService() auditP.Service
+	AuthService() authP.Service
+	SecurityService() securityP.Service
+	CaptchaVerifier() securityP.CaptchaVerifier
 }
 
 type app struct {
@@ -33,14 +60,29 @@ type app struct {
 	db  *gorm.DB
 	cc  *grpc.ClientConn
 
-	userService userP.Service
-	planService planP.Service
+	redis   *redis.Client // nil without REDIS_URL
+	mailer  userP.Mailer
+	revoked authP.RevocationList
+	captcha securityP.CaptchaVerifier
+
+	userService  userP.Service
+	planService  planP.Service
+	auditService auditP.Service
+	authService  authP.Service
+
+	securityService securityP.Service
 }
 
 func New(cfg config.Config, log *zap.Logger) (App, error) {
 	if log == nil {
 		return nil, ErrNilLogger
 	}
+	// a misspelled role would silently not require a second factor
+	for _, role := range cfg.TwoFactor.RequiredRoles {
+		if !adminD.ValidRole(role) {
+			return nil, fmt.Errorf("%w: unknown role %q in TWO_FACTOR_REQUIRED_ROLES", ErrInvalidConfig, role)
+		}
+	}
 	db, err := initDB(cfg.DB, log)
 	if err != nil {
 		return nil, err
@@ -49,11 +91,31 @@ func New(cfg config.Config, log *zap.Logger) (App, error) {
 	if err != nil {
 		return nil, err
 	}
+	m, err := mailer.New(cfg.Mail, log)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := captcha.New(cfg.Arcaptcha, cfg.DevEnv, log)
+	if err != nil {
+		return nil, err
+	}
+	rdb, err := newRedisClient(cfg.RedisURL, log)
+	if err != nil {
+		return nil, err
+	}
+	revoked := revocation.NewMemory()
+	if rdb != nil {
+		revoked = revocation.NewRedis(rdb)
+	}
 	return &app{
-		cfg: cfg,
-		log: log,
-		db:  db,
-		cc:  cc,
+		cfg:     cfg,
+		log:     log,
+		db:      db,
+		cc:      cc,
+		redis:   rdb,
+		mailer:  m,
+		revoked: revoked,
+		captcha: verifier,
 	}, nil
 }
 
@@ -65,7 +127,18 @@ func (a *app) DB() *gorm.DB { return a.db }
 
 func (a *app) UserService() userP.Service {
 	if a.userService == nil {
-		a.userService = user.NewService(repository.NewUserRepository(a.db), a.cc)
+		opts := adminD.AccountOptions{
+			URL:       a.cfg.Account.URL,
+			InviteTTL: a.cfg.Account.InviteTTL,
+			ResetTTL:  a.cfg.Account.ResetTTL,
+			TwoFactor: adminD.TwoFactorOptions{
+				Issuer:        a.cfg.TwoFactor.Issuer,
+				RequiredRoles: a.cfg.TwoFactor.RequiredRoles,
+				ChallengeTTL:  a.cfg.TwoFactor.ChallengeTTL,
+			},
+		}
+		a.userService = user.NewService(repository.NewUserRepository(a.db), repository.NewTokenRepository(a.db),
+			repository.NewRecoveryCodeRepository(a.db), a.mailer, a.AuthService(), opts, a.cc)
 	}
 	return a.userService
 }
@@ -77,27 +150,132 @@ func (a *app) PlanService() planP.Service {
 	return a.planService
 }
 
+func (a *app) AuditService() auditP.Service {
+	if a.auditService == nil {
+		a.auditService = audit.NewService(repository.NewAuditRepository(a.db))
+	}
+	return a.auditService
+}
+
+func (a *app) AuthService() authP.Service {
+	if a.authService == nil {
+		opts := authD.Options{
+			Secret:     []byte(a.cfg.JWT.Secret),
+			AccessTTL:  a.cfg.JWT.AccessTTL,
+			RefreshTTL: a.cfg.JWT.RefreshTTL,
+		}
+		a.authService = auth.NewService(repository.NewSessionRepository(a.db), repository.NewUserRepository(a.db), a.revoked, opts)
+	}
+	return a.authService
+}
+
+func (a *app) SecurityService() securityP.Service {
+	if a.securityService == nil {
+		counter := throttle.NewMemory()
+		if a.redis != nil {
+			counter = throttle.NewRedis(a.redis)
+		}
+		c := a.cfg.Login
+		policy := securityD.Policy{
+			Account:      securityD.Limit{FreeAttempts: c.AccountFreeAttempts, LockAfter: c.AccountLockAfter},
+			IP:           securityD.Limit{FreeAttempts: c.IPFreeAttempts, LockAfter: c.IPLockAfter},
+			BaseDelay:    c.BaseDelay,
+			MaxDelay:     c.MaxDelay,
+			LockDuration: c.LockDuration,
+			CaptchaAfter: c.CaptchaAfter,
+		}
+		a.securityService = security.NewService(a.log, repository.NewLoginEventRepository(a.db), counter, policy)
+	}
+	return a.securityService
+}
+
+func (a *app) CaptchaVerifier() securityP.CaptchaVerifier { return a.captcha }
+
+// models are the admin tables, plan data is managed by the userplan service via gRPC
+var models = []any{
+	&adminD.AdminUser{},
+	&adminD.AdminToken{},
+	&adminD.AdminRecoveryCode{},
+	&authD.Session{},
+	&common.AuditLog{},
+	&securityD.LoginEvent{},
+}
+
+// initDB refuses to start on a schema that does not match the migrations of this version,
+// pending migrations are applied first when DB_MIGRATE is set
 func initDB(c config.DBConfig, log *zap.Logger) (*gorm.DB, error) {
+	db, err := openDB(c, log)
+	if err != nil {
+		return nil, err
+	}
+	m, err := newMigrator(db, log)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	if c.Migrate {
+		if _, err := m.Up(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if err := m.Check(ctx); err != nil {
+		return nil, fmt.Errorf("%w, run the migrate command", err)
+	}
+	if err := migrate.CheckModels(db, models...); err != nil {
+		return nil, err
+	}
+
+	// todo: initial admin setup
+	return db, nil
+}
+
+// NewMigrator returns the migrator of the admin database
+func NewMigrator(c config.DBConfig, log *zap.Logger) (*migrate.Migrator, error) {
+	db, err := openDB(c, log)
+	if err != nil {
+		return nil, err
+	}
+	return newMigrator(db, log)
+}
+
+func openDB(c config.DBConfig, log *zap.Logger) (*gorm.DB, error) {
 	dsn := database.PostgresDSN(
 		c.Host, c.Port, c.DBName, c.Schema, c.User, c.Password, c.AppName,
 	)
-	db, err := database.NewPostgresConnectionWithLogger(dsn, log)
+	return database.NewPostgresConnectionWithLogger(dsn, log)
+}
+
+func newMigrator(db *gorm.DB, log *zap.Logger) (*migrate.Migrator, error) {
+	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, err
 	}
-
-	// only migrate admin tables
-	// plan data will be managed by the userplan service via gRPC
-	err = db.AutoMigrate(
-	// add your admin domain models here
-	// &admin.Domain.AdminUser{},
-	)
+	all, err := migrate.Load(migrations.FS)
 	if err != nil {
 		return nil, err
 	}
+	return migrate.New(sqlDB, all, log), nil
+}
 
-	// todo: initial admin setup
-	return db, nil
+// newRedisClient connects to the redis the instances share revoked tokens and failed logins through,
+// nil without a url
+func newRedisClient(url string, log *zap.Logger) (*redis.Client, error) {
+	if url == "" {
+		log.Warn("REDIS_URL is not set, revoked tokens and failed logins are only known to this instance")
+		return nil, nil
+	}
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+	client := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	return client, nil
 }
 
 func newGRPCClientConn(cfg config.UserPlanServiceConfig) (*grpc.ClientConn, error) {
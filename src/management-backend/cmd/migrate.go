@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"go.uber.org/zap"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/app"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/config"
+)
+
+var ErrUnknownMigrate = errors.New("migrate command must be up, down or status")
+
+// Migrate runs the migrate command: up (default) applies the pending migrations,
+// down [-steps n] rolls back the last ones and status lists them
+func Migrate(cfg config.Config, log *zap.Logger, args []string) error {
+	command := "up"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+	fs := flag.NewFlagSet("migrate "+command, flag.ContinueOnError)
+	steps := fs.Int("steps", 1, "number of migrations to roll back")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	m, err := app.NewMigrator(cfg.DB, log)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	switch command {
+	case "up":
+		n, err := m.Up(ctx)
+		if err != nil {
+			return err
+		}
+		log.Info("database migrated", zap.Int("applied", n))
+	case "down":
+		n, err := m.Down(ctx, *steps)
+		if err != nil {
+			return err
+		}
+		log.Info("migrations rolled back", zap.Int("rolled_back", n))
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tSTATE\tAPPLIED_AT")
+		for _, s := range statuses {
+			state, appliedAt := "pending", ""
+			if s.AppliedAt != nil {
+				state, appliedAt = "applied", s.AppliedAt.UTC().Format(time.RFC3339)
+			}
+			if s.Modified {
+				state = "modified"
+			}
+			if !s.Known {
+				state = "unknown"
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownMigrate, command)
+	}
+	return nil
+}
@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 type Config struct {
 	// DevEnv specifies the environment the application runs in.
 	DevEnv          bool                  `json:"devEnv" env:"DEV_ENV,required,notEmpty"`
@@ -8,6 +10,13 @@ type Config struct {
 	JWT             JWTConfig             `json:"jwt" envPrefix:"JWT_"`
 	UserPlanService UserPlanServiceConfig `json:"userPlanService" envPrefix:"USER_PLAN_"`
 	Arcaptcha       ArcaptchaConfig       `json:"arcaptcha" envPrefix:"ARCAPTCHA_"`
+	Mail            MailConfig            `json:"mail" envPrefix:"MAIL_"`
+	Account         AccountConfig         `json:"account" envPrefix:"ACCOUNT_"`
+	TwoFactor       TwoFactorConfig       `json:"twoFactor" envPrefix:"TWO_FACTOR_"`
+	Login           LoginConfig           `json:"login" envPrefix:"LOGIN_"`
+	// RedisURL holds the token revocation list and failed login counters, empty keeps them in memory
+	// which only suits a single instance
+	RedisURL string `json:"redisUrl" env:"REDIS_URL"`
 }
 
 type DBConfig struct {
@@ -18,6 +27,8 @@ type DBConfig struct {
 	User     string `json:"user" env:"USER,required,notEmpty"`
 	Password string `json:"password" env:"PASSWORD,required,notEmpty"`
 	AppName  string `json:"appName" env:"APP_NAME,required,notEmpty"`
+	// apply pending migrations on startup instead of refusing to start
+	Migrate bool `json:"migrate" env:"MIGRATE" envDefault:"false"`
 }
 
 type ServerConfig struct {
@@ -29,8 +40,11 @@ type ServerConfig struct {
 }
 
 type JWTConfig struct {
-	Secret     string `json:"secret" env:"SECRET,required,notEmpty"`
-	Expiration int    `json:"expiration" env:"EXPIRATION" envDefault:"24"` // in hours
+	Secret string `json:"secret" env:"SECRET,required,notEmpty"`
+	// AccessTTL bounds how long a revoked access token stays valid when the revocation list is lost
+	AccessTTL time.Duration `json:"accessTtl" env:"ACCESS_TTL" envDefault:"15m"`
+	// RefreshTTL is extended on every refresh, an idle session ends after it
+	RefreshTTL time.Duration `json:"refreshTtl" env:"REFRESH_TTL" envDefault:"168h"`
 }
 
 type UserPlanServiceConfig struct {
@@ -39,7 +53,58 @@ type UserPlanServiceConfig struct {
 }
 
 type ArcaptchaConfig struct {
-	SiteKey   string `json:"siteKey" env:"SITE_KEY,required,notEmpty"`
-	SecretKey string `json:"secretKey" env:"SECRET_KEY,required,notEmpty"`
-	VerifyURL string `json:"verifyUrl" env:"VERIFY_URL" envDefault:"https://arcaptcha.ir/verify"`
+	// Driver verifies login captchas: arcaptcha, or stub accepting StubToken in the dev environment
+	Driver string `json:"driver" env:"DRIVER" envDefault:"arcaptcha"`
+	// SiteKey and SecretKey are required by the arcaptcha driver
+	SiteKey   string        `json:"siteKey" env:"SITE_KEY"`
+	SecretKey string        `json:"secretKey" env:"SECRET_KEY"`
+	VerifyURL string        `json:"verifyUrl" env:"VERIFY_URL" envDefault:"https://arcaptcha.co/2/siteverify"`
+	Timeout   time.Duration `json:"timeout" env:"TIMEOUT" envDefault:"5s"`
+	StubToken string        `json:"stubToken" env:"STUB_TOKEN"`
+}
+
+type MailConfig struct {
+	// Driver delivers invitation and password reset mails: log, file or smtp
+	Driver string     `json:"driver" env:"DRIVER" envDefault:"log"`
+	From   string     `json:"from" env:"FROM" envDefault:"no-reply@arcaptcha.ir"`
+	SMTP   SMTPConfig `json:"smtp" envPrefix:"SMTP_"`
+	// FilePath is appended one JSON mail per line by the file driver
+	FilePath string `json:"filePath" env:"FILE_PATH" envDefault:"mails.jsonl"`
+}
+
+type SMTPConfig struct {
+	Host     string `json:"host" env:"HOST"`
+	Port     uint   `json:"port" env:"PORT" envDefault:"587"`
+	Username string `json:"username" env:"USERNAME"`
+	Password string `json:"password" env:"PASSWORD"`
+}
+
+type AccountConfig struct {
+	// URL of the admin panel, mailed links point to its /accept-invitation and /reset-password pages
+	URL       string        `json:"url" env:"URL" envDefault:"http://localhost:3000"`
+	InviteTTL time.Duration `json:"inviteTtl" env:"INVITE_TTL" envDefault:"72h"`
+	ResetTTL  time.Duration `json:"resetTtl" env:"RESET_TTL" envDefault:"1h"`
+}
+
+type TwoFactorConfig struct {
+	// Issuer names the account in authenticator apps
+	Issuer string `json:"issuer" env:"ISSUER" envDefault:"Arcaptcha Management"`
+	// RequiredRoles have to log in with a TOTP code, other admins may enable it
+	RequiredRoles []string      `json:"requiredRoles" env:"REQUIRED_ROLES" envSeparator:","`
+	ChallengeTTL  time.Duration `json:"challengeTtl" env:"CHALLENGE_TTL" envDefault:"5m"`
+}
+
+// LoginConfig throttles failed logins per account and per IP: after the free attempts every failure
+// doubles the delay before the next attempt, starting at BaseDelay, and at the lock limit logins are
+// refused for LockDuration. CaptchaAfter failures of the account or IP require a captcha, 0 requires
+// it on every login.
+type LoginConfig struct {
+	CaptchaAfter        int           `json:"captchaAfter" env:"CAPTCHA_AFTER" envDefault:"0"`
+	AccountFreeAttempts int           `json:"accountFreeAttempts" env:"ACCOUNT_FREE_ATTEMPTS" envDefault:"3"`
+	AccountLockAfter    int           `json:"accountLockAfter" env:"ACCOUNT_LOCK_AFTER" envDefault:"10"`
+	IPFreeAttempts      int           `json:"ipFreeAttempts" env:"IP_FREE_ATTEMPTS" envDefault:"10"`
+	IPLockAfter         int           `json:"ipLockAfter" env:"IP_LOCK_AFTER" envDefault:"50"`
+	BaseDelay           time.Duration `json:"baseDelay" env:"BASE_DELAY" envDefault:"1s"`
+	MaxDelay            time.Duration `json:"maxDelay" env:"MAX_DELAY" envDefault:"1m"`
+	LockDuration        time.Duration `json:"lockDuration" env:"LOCK_DURATION" envDefault:"15m"`
 }
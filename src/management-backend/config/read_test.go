@@ -17,6 +17,11 @@ func validEnvMap() map[string]string {
 		"DB_USER":     "postgres",
 		"DB_PASSWORD": "postgres",
 		"DB_APP_NAME": "userplan-service",
+
+		"JWT_SECRET":           "secret",
+		"USER_PLAN_HOST":       "userplan",
+		"ARCAPTCHA_SITE_KEY":   "site-key",
+		"ARCAPTCHA_SECRET_KEY": "secret-key",
 	}
 }
 
@@ -0,0 +1,48 @@
+package captcha
+
+import (
+	"context"
+	"time"
+
+	"github.com/arcaptcha/arcaptcha-go"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/security/port"
+)
+
+type arcaptchaVerifier struct {
+	website *arcaptcha.Website
+}
+
+// NewArcaptcha returns a verifier asking the arcaptcha service at verifyURL, waiting at most timeout
+func NewArcaptcha(siteKey, secretKey, verifyURL string, timeout time.Duration) port.CaptchaVerifier {
+	website := arcaptcha.NewWebsite(siteKey, secretKey)
+	website.SetVerifyUrl(verifyURL)
+	website.SetTimeout(timeout)
+	return &arcaptchaVerifier{website: website}
+}
+
+type verifyResult struct {
+	res arcaptcha.VerifyResp
+	err error
+}
+
+// Verify returns when ctx is done without waiting for the request, which the timeout still bounds
+func (v *arcaptchaVerifier) Verify(ctx context.Context, token string) (bool, error) {
+	if token == "" {
+		return false, nil
+	}
+	done := make(chan verifyResult, 1)
+	go func() {
+		res, err := v.website.Verify(token)
+		done <- verifyResult{res: res, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	case r := <-done:
+		if r.err != nil {
+			return false, r.err
+		}
+		return r.res.Success, nil
+	}
+}
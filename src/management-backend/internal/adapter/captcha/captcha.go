@@ -0,0 +1,41 @@
+package captcha
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/config"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/security/port"
+)
+
+const (
+	DriverArcaptcha = "arcaptcha"
+	DriverStub      = "stub"
+)
+
+var (
+	ErrUnknownDriver = errors.New("unknown captcha driver")
+	ErrMissingKeys   = errors.New("arcaptcha site and secret keys are required")
+	ErrStubNotDev    = errors.New("the stub captcha is only allowed in the dev environment")
+)
+
+// New returns the verifier of the configured driver, the stub would let anyone skip
+// the captcha so it is refused outside the dev environment
+func New(cfg config.ArcaptchaConfig, devEnv bool, log *zap.Logger) (port.CaptchaVerifier, error) {
+	switch cfg.Driver {
+	case DriverArcaptcha:
+		if cfg.SiteKey == "" || cfg.SecretKey == "" {
+			return nil, ErrMissingKeys
+		}
+		return NewArcaptcha(cfg.SiteKey, cfg.SecretKey, cfg.VerifyURL, cfg.Timeout), nil
+	case DriverStub:
+		if !devEnv {
+			return nil, ErrStubNotDev
+		}
+		log.Warn("captchas are verified by the stub, only the configured token passes")
+		return NewStub(cfg.StubToken), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDriver, cfg.Driver)
+	}
+}
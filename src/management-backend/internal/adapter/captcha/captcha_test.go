@@ -0,0 +1,83 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/config"
+)
+
+func TestArcaptcha(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			SiteKey  string `json:"sitekey"`
+			Secret   string `json:"secret"`
+			Response string `json:"response"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		switch req.Response {
+		case "slow":
+			time.Sleep(200 * time.Millisecond)
+		case "broken":
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		ok := req.SiteKey == "site" && req.Secret == "secret" && req.Response == "solved"
+		_ = json.NewEncoder(w).Encode(map[string]any{"success": ok})
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	v := NewArcaptcha("site", "secret", srv.URL, 50*time.Millisecond)
+	ok, err := v.Verify(ctx, "solved")
+	require.NoError(t, err)
+	assert.True(t, ok, "the configured verify url is asked")
+	ok, err = v.Verify(ctx, "wrong")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = v.Verify(ctx, "")
+	require.NoError(t, err)
+	assert.False(t, ok, "an empty token is not sent")
+
+	_, err = v.Verify(ctx, "broken")
+	assert.Error(t, err)
+	_, err = v.Verify(ctx, "slow")
+	assert.Error(t, err, "the timeout bounds the request")
+
+	slow := NewArcaptcha("site", "secret", srv.URL, time.Second)
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	start := time.Now()
+	_, err = slow.Verify(canceled, "slow")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 100*time.Millisecond, "a done context does not wait for the request")
+}
+
+func TestStub(t *testing.T) {
+	ctx := context.Background()
+	ok, _ := NewStub("pass").Verify(ctx, "pass")
+	assert.True(t, ok)
+	ok, _ = NewStub("pass").Verify(ctx, "fail")
+	assert.False(t, ok)
+	ok, _ = NewStub("").Verify(ctx, "")
+	assert.False(t, ok, "an empty stub token accepts nothing")
+}
+
+func TestNew(t *testing.T) {
+	log := zap.NewNop()
+	_, err := New(config.ArcaptchaConfig{Driver: DriverArcaptcha}, true, log)
+	assert.ErrorIs(t, err, ErrMissingKeys)
+	_, err = New(config.ArcaptchaConfig{Driver: DriverStub, StubToken: "pass"}, false, log)
+	assert.ErrorIs(t, err, ErrStubNotDev)
+	_, err = New(config.ArcaptchaConfig{Driver: "recaptcha"}, true, log)
+	assert.ErrorIs(t, err, ErrUnknownDriver)
+	_, err = New(config.ArcaptchaConfig{Driver: DriverStub, StubToken: "pass"}, true, log)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,22 @@
+package captcha
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/security/port"
+)
+
+type stubVerifier struct {
+	token string
+}
+
+// NewStub returns a verifier accepting token and nothing else, for running and testing
+// logins offline
+func NewStub(token string) port.CaptchaVerifier {
+	return &stubVerifier{token: token}
+}
+
+func (v *stubVerifier) Verify(_ context.Context, token string) (bool, error) {
+	return v.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(v.token)) == 1, nil
+}
@@ -0,0 +1,40 @@
+package mailer
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/port"
+)
+
+type fileMailer struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileMailer returns a mailer appending mails to path as JSON lines, meant for tests and local runs
+func NewFileMailer(path string) port.Mailer {
+	return &fileMailer{path: path}
+}
+
+func (m *fileMailer) Send(_ context.Context, mail *domain.Mail) error {
+	line, err := json.Marshal(mail)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
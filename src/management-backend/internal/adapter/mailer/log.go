@@ -0,0 +1,24 @@
+package mailer
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/port"
+)
+
+type logMailer struct {
+	log *zap.Logger
+}
+
+// NewLogMailer returns a mailer logging mails instead of sending them, meant for local runs
+// since the logged links carry the tokens
+func NewLogMailer(log *zap.Logger) port.Mailer {
+	return &logMailer{log: log}
+}
+
+func (m *logMailer) Send(_ context.Context, mail *domain.Mail) error {
+	m.log.Info("mail", zap.String("to", mail.To), zap.String("subject", mail.Subject), zap.String("body", mail.Body))
+	return nil
+}
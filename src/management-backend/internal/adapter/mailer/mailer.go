@@ -0,0 +1,32 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/config"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/port"
+)
+
+const (
+	DriverLog  = "log"
+	DriverFile = "file"
+	DriverSMTP = "smtp"
+)
+
+var ErrUnknownDriver = errors.New("unknown mail driver")
+
+// New returns the mailer of the configured driver
+func New(cfg config.MailConfig, log *zap.Logger) (port.Mailer, error) {
+	switch cfg.Driver {
+	case DriverLog:
+		return NewLogMailer(log), nil
+	case DriverFile:
+		return NewFileMailer(cfg.FilePath), nil
+	case DriverSMTP:
+		return NewSMTPMailer(cfg.SMTP, cfg.From), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDriver, cfg.Driver)
+	}
+}
@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/config"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/port"
+)
+
+type smtpMailer struct {
+	cfg  config.SMTPConfig
+	from string
+}
+
+// NewSMTPMailer returns a mailer sending mails through the configured SMTP server
+func NewSMTPMailer(cfg config.SMTPConfig, from string) port.Mailer {
+	return &smtpMailer{cfg: cfg, from: from}
+}
+
+func (m *smtpMailer) Send(_ context.Context, mail *domain.Mail) error {
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(int(m.cfg.Port)))
+	return smtp.SendMail(addr, auth, m.from, []string{mail.To}, m.message(mail))
+}
+
+func (m *smtpMailer) message(mail *domain.Mail) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", m.from)
+	fmt.Fprintf(&b, "To: %s\r\n", mail.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mail.Subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(mail.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -16,6 +16,8 @@ type UserRepository interface {
 	Delete(ctx context.Context, id uint) error
 	List(ctx context.Context, limit, offset int, filters map[string]string) ([]*domain.AdminUser, error)
 	ToggleActive(ctx context.Context, id uint) error
+	CountByRole(ctx context.Context, role string) (int64, error)
+	UseTOTPStep(ctx context.Context, id uint, step int64) (bool, error)
 }
 
 type userRepository struct {
@@ -77,3 +79,17 @@ func (r *userRepository) ToggleActive(ctx context.Context, id uint) error {
 		Where("id = ?", id).
 		Update("is_active", gorm.Expr("NOT is_active")).Error
 }
+
+func (r *userRepository) CountByRole(ctx context.Context, role string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.AdminUser{}).Where("role = ? AND is_active", role).Count(&count).Error
+	return count, err
+}
+
+// UseTOTPStep compares and sets in one statement so a code can not be accepted twice concurrently
+func (r *userRepository) UseTOTPStep(ctx context.Context, id uint, step int64) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&domain.AdminUser{}).
+		Where("id = ? AND totp_last_step < ?", id, step).
+		Update("totp_last_step", step)
+	return res.RowsAffected == 1, res.Error
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/port"
+)
+
+type tokenRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) port.TokenRepository {
+	return &tokenRepository{db: db}
+}
+
+func (r *tokenRepository) Create(ctx context.Context, token *domain.AdminToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+// Consume uses the token in a single statement so concurrent requests can not use it twice
+func (r *tokenRepository) Consume(ctx context.Context, hash, purpose string, now time.Time) (*domain.AdminToken, error) {
+	var token domain.AdminToken
+	res := r.db.WithContext(ctx).Model(&token).
+		Clauses(clause.Returning{}).
+		Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", hash, purpose, now).
+		Update("used_at", now)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &token, nil
+}
+
+func (r *tokenRepository) Revoke(ctx context.Context, userID uint, purpose string, now time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.AdminToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", now).Error
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/audit/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/audit/port"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/common"
+)
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) port.Repository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Create(ctx context.Context, log *common.AuditLog) error {
+	return r.db.WithContext(ctx).Create(log).Error
+}
+
+func (r *auditRepository) List(ctx context.Context, filter *domain.AuditFilter, limit, offset int) ([]*common.AuditLog, int64, error) {
+	query := r.db.WithContext(ctx).Model(&common.AuditLog{})
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != 0 {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var logs []*common.AuditLog
+	err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&logs).Error
+	return logs, total, err
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/security/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/security/port"
+)
+
+type loginEventRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginEventRepository(db *gorm.DB) port.EventRepository {
+	return &loginEventRepository{db: db}
+}
+
+func (r *loginEventRepository) Create(ctx context.Context, event *domain.LoginEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+func (r *loginEventRepository) List(ctx context.Context, filter *domain.LoginEventFilter, limit, offset int) ([]*domain.LoginEvent, int64, error) {
+	query := r.db.WithContext(ctx).Model(&domain.LoginEvent{})
+	if filter.Email != "" {
+		query = query.Where("email = ?", filter.Email)
+	}
+	if filter.IPAddress != "" {
+		query = query.Where("ip_address = ?", filter.IPAddress)
+	}
+	if filter.Outcome != "" {
+		query = query.Where("outcome = ?", filter.Outcome)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var events []*domain.LoginEvent
+	err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&events).Error
+	return events, total, err
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/auth/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/auth/port"
+)
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) port.SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+// Rotate replaces the hash in a single statement so a refresh token can not be rotated twice
+func (r *sessionRepository) Rotate(ctx context.Context, oldHash, newHash string, expiresAt, now time.Time) (*domain.Session, error) {
+	var session domain.Session
+	res := r.db.WithContext(ctx).Model(&session).
+		Clauses(clause.Returning{}).
+		Where("refresh_hash = ? AND revoked_at IS NULL AND expires_at > ?", oldHash, now).
+		Updates(map[string]interface{}{
+			"refresh_hash":  newHash,
+			"previous_hash": oldHash,
+			"expires_at":    expiresAt,
+			"rotated_at":    now,
+		})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) GetByPreviousHash(ctx context.Context, hash string) (*domain.Session, error) {
+	var session domain.Session
+	err := r.db.WithContext(ctx).Where("previous_hash = ?", hash).First(&session).Error
+	return &session, err
+}
+
+func (r *sessionRepository) Revoke(ctx context.Context, id uint, now time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", now).Error
+}
+
+func (r *sessionRepository) RevokeUser(ctx context.Context, userID uint, now time.Time) (uint, error) {
+	err := r.db.WithContext(ctx).Model(&domain.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", now).Error
+	if err != nil {
+		return 0, err
+	}
+	var last uint
+	err = r.db.WithContext(ctx).Model(&domain.Session{}).
+		Where("user_id = ?", userID).
+		Select("COALESCE(MAX(id), 0)").Scan(&last).Error
+	return last, err
+}
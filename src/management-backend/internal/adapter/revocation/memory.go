@@ -0,0 +1,62 @@
+package revocation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/auth/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/auth/port"
+)
+
+type entry struct {
+	value     uint
+	expiresAt time.Time
+}
+
+type memoryList struct {
+	mu       sync.Mutex
+	sessions map[uint]time.Time
+	users    map[uint]entry
+}
+
+// NewMemory returns a revocation list of this process, meant for tests and single instance local runs
+func NewMemory() port.RevocationList {
+	return &memoryList{sessions: map[uint]time.Time{}, users: map[uint]entry{}}
+}
+
+func (l *memoryList) RevokeSession(_ context.Context, sessionID uint, ttl time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sessions[sessionID] = time.Now().Add(ttl)
+	return nil
+}
+
+func (l *memoryList) RevokeUser(_ context.Context, userID, lastSessionID uint, ttl time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e, ok := l.users[userID]; ok && e.value > lastSessionID && time.Now().Before(e.expiresAt) {
+		return nil
+	}
+	l.users[userID] = entry{value: lastSessionID, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (l *memoryList) Revoked(_ context.Context, claims *domain.Claims) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if expiresAt, ok := l.sessions[claims.SessionID]; ok {
+		if now.Before(expiresAt) {
+			return true, nil
+		}
+		delete(l.sessions, claims.SessionID)
+	}
+	if e, ok := l.users[claims.UserID]; ok {
+		if now.Before(e.expiresAt) {
+			return claims.SessionID <= e.value, nil
+		}
+		delete(l.users, claims.UserID)
+	}
+	return false, nil
+}
@@ -0,0 +1,70 @@
+package revocation
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/auth/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/auth/port"
+)
+
+const (
+	sessionKey = "auth:revoked:session:"
+	userKey    = "auth:revoked:user:"
+)
+
+type redisList struct {
+	client redis.UniversalClient
+}
+
+// NewRedis returns a revocation list shared by every instance through redis,
+// the redis must not evict keys or revoked tokens become valid again
+func NewRedis(client redis.UniversalClient) port.RevocationList {
+	return &redisList{client: client}
+}
+
+func (l *redisList) RevokeSession(ctx context.Context, sessionID uint, ttl time.Duration) error {
+	return l.client.Set(ctx, sessionKey+strconv.FormatUint(uint64(sessionID), 10), 1, ttl).Err()
+}
+
+// RevokeUser keeps the highest session ID revoked so far
+func (l *redisList) RevokeUser(ctx context.Context, userID, lastSessionID uint, ttl time.Duration) error {
+	key := userKey + strconv.FormatUint(uint64(userID), 10)
+	return l.client.Watch(ctx, func(tx *redis.Tx) error {
+		current, err := tx.Get(ctx, key).Uint64()
+		if err != nil && err != redis.Nil {
+			return err
+		}
+		if current > uint64(lastSessionID) {
+			return nil
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, uint64(lastSessionID), ttl)
+			return nil
+		})
+		return err
+	}, key)
+}
+
+func (l *redisList) Revoked(ctx context.Context, claims *domain.Claims) (bool, error) {
+	values, err := l.client.MGet(ctx,
+		sessionKey+strconv.FormatUint(uint64(claims.SessionID), 10),
+		userKey+strconv.FormatUint(uint64(claims.UserID), 10),
+	).Result()
+	if err != nil {
+		return false, err
+	}
+	if values[0] != nil {
+		return true, nil
+	}
+	if s, ok := values[1].(string); ok {
+		last, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return false, err
+		}
+		return uint64(claims.SessionID) <= last, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,63 @@
+package revocation
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/auth/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/auth/port"
+)
+
+// testRedis connects to TEST_REDIS_URL, skipping the test when it is not set
+func testRedis(t *testing.T) *redis.Client {
+	url := os.Getenv("TEST_REDIS_URL")
+	if url == "" {
+		t.Skip("TEST_REDIS_URL is not set")
+	}
+	opts, err := redis.ParseURL(url)
+	require.NoError(t, err)
+	client := redis.NewClient(opts)
+	t.Cleanup(func() { client.Close() })
+	require.NoError(t, client.FlushDB(context.Background()).Err())
+	return client
+}
+
+func testList(t *testing.T, list port.RevocationList) {
+	ctx := context.Background()
+	revoked := func(userID, sessionID uint) bool {
+		r, err := list.Revoked(ctx, &domain.Claims{UserID: userID, SessionID: sessionID})
+		require.NoError(t, err)
+		return r
+	}
+
+	assert.False(t, revoked(1, 10))
+	require.NoError(t, list.RevokeSession(ctx, 10, time.Minute))
+	assert.True(t, revoked(1, 10))
+	assert.False(t, revoked(1, 11))
+
+	require.NoError(t, list.RevokeUser(ctx, 2, 20, time.Minute))
+	assert.True(t, revoked(2, 19))
+	assert.True(t, revoked(2, 20))
+	assert.False(t, revoked(2, 21), "sessions started later stay valid")
+	assert.False(t, revoked(3, 19))
+
+	require.NoError(t, list.RevokeUser(ctx, 2, 15, time.Minute))
+	assert.True(t, revoked(2, 20), "an older revocation does not shrink a newer one")
+
+	require.NoError(t, list.RevokeSession(ctx, 30, time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	assert.False(t, revoked(4, 30), "entries expire with the access tokens")
+}
+
+func TestMemory(t *testing.T) {
+	testList(t, NewMemory())
+}
+
+func TestRedis(t *testing.T) {
+	testList(t, NewRedis(testRedis(t)))
+}
@@ -0,0 +1,61 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/security/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/security/port"
+)
+
+type entry struct {
+	failures  domain.Failures
+	expiresAt time.Time
+}
+
+type memoryCounter struct {
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+// NewMemory returns a failure counter of this process, meant for tests and single instance local runs
+func NewMemory() port.FailureCounter {
+	return &memoryCounter{entries: map[string]entry{}}
+}
+
+func (c *memoryCounter) Add(_ context.Context, key string, now time.Time, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.get(key)
+	c.entries[key] = entry{
+		failures:  domain.Failures{Count: e.Count + 1, Last: now},
+		expiresAt: now.Add(ttl),
+	}
+	return nil
+}
+
+func (c *memoryCounter) Get(_ context.Context, key string) (domain.Failures, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.get(key), nil
+}
+
+func (c *memoryCounter) Reset(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+	return nil
+}
+
+func (c *memoryCounter) get(key string) domain.Failures {
+	e, ok := c.entries[key]
+	if !ok {
+		return domain.Failures{}
+	}
+	if !time.Now().Before(e.expiresAt) {
+		delete(c.entries, key)
+		return domain.Failures{}
+	}
+	return e.failures
+}
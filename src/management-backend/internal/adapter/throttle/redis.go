@@ -0,0 +1,55 @@
+package throttle
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/security/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/security/port"
+)
+
+type redisCounter struct {
+	client redis.UniversalClient
+}
+
+// NewRedis returns a failure counter shared by every instance through redis
+func NewRedis(client redis.UniversalClient) port.FailureCounter {
+	return &redisCounter{client: client}
+}
+
+func (c *redisCounter) Add(ctx context.Context, key string, now time.Time, ttl time.Duration) error {
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HIncrBy(ctx, key, "count", 1)
+		pipe.HSet(ctx, key, "last", now.UnixNano())
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+	return err
+}
+
+func (c *redisCounter) Get(ctx context.Context, key string) (domain.Failures, error) {
+	values, err := c.client.HMGet(ctx, key, "count", "last").Result()
+	if err != nil {
+		return domain.Failures{}, err
+	}
+	count, _ := values[0].(string)
+	last, _ := values[1].(string)
+	if count == "" || last == "" {
+		return domain.Failures{}, nil
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil {
+		return domain.Failures{}, err
+	}
+	nanos, err := strconv.ParseInt(last, 10, 64)
+	if err != nil {
+		return domain.Failures{}, err
+	}
+	return domain.Failures{Count: n, Last: time.Unix(0, nanos)}, nil
+}
+
+func (c *redisCounter) Reset(ctx context.Context, key string) error {
+	return c.client.Del(ctx, key).Err()
+}
@@ -0,0 +1,61 @@
+package throttle
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/security/port"
+)
+
+// testRedis connects to TEST_REDIS_URL, skipping the test when it is not set
+func testRedis(t *testing.T) *redis.Client {
+	url := os.Getenv("TEST_REDIS_URL")
+	if url == "" {
+		t.Skip("TEST_REDIS_URL is not set")
+	}
+	opts, err := redis.ParseURL(url)
+	require.NoError(t, err)
+	client := redis.NewClient(opts)
+	t.Cleanup(func() { client.Close() })
+	require.NoError(t, client.FlushDB(context.Background()).Err())
+	return client
+}
+
+func testCounter(t *testing.T, counter port.FailureCounter) {
+	ctx := context.Background()
+	get := func(key string) int {
+		f, err := counter.Get(ctx, key)
+		require.NoError(t, err)
+		return f.Count
+	}
+
+	assert.Zero(t, get("a"))
+	now := time.Now()
+	require.NoError(t, counter.Add(ctx, "a", now.Add(-time.Second), time.Minute))
+	require.NoError(t, counter.Add(ctx, "a", now, time.Minute))
+	f, err := counter.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, 2, f.Count)
+	assert.True(t, f.Last.Equal(now), "the last failure is kept")
+	assert.Zero(t, get("b"))
+
+	require.NoError(t, counter.Reset(ctx, "a"))
+	assert.Zero(t, get("a"))
+
+	require.NoError(t, counter.Add(ctx, "c", time.Now(), 5*time.Millisecond))
+	time.Sleep(20 * time.Millisecond)
+	assert.Zero(t, get("c"), "failures are forgotten after the ttl")
+}
+
+func TestMemory(t *testing.T) {
+	testCounter(t, NewMemory())
+}
+
+func TestRedis(t *testing.T) {
+	testCounter(t, NewRedis(testRedis(t)))
+}
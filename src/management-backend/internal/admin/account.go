@@ -0,0 +1,179 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/audit"
+	auditD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/audit/domain"
+)
+
+var (
+	ErrInvalidToken       = errors.New("token is invalid, expired or already used")
+	ErrInvitationAccepted = errors.New("invitation is already accepted")
+	ErrMailNotSent        = errors.New("mail could not be sent")
+)
+
+// InviteUser creates an admin without a password and mails them a link to set one,
+// the admin can not log in before accepting the invitation
+func (s *service) InviteUser(ctx context.Context, user *domain.AdminUser) error {
+	if user.Role == "" {
+		user.Role = domain.RoleReadOnly
+	}
+	if !domain.ValidRole(user.Role) {
+		return ErrInvalidRole
+	}
+	if _, err := s.repo.GetByEmail(ctx, user.Email); err == nil {
+		return ErrUserAlreadyExists
+	}
+
+	user.PasswordHash = ""
+	user.IsActive = true
+	if err := s.repo.Create(ctx, user); err != nil {
+		return err
+	}
+	audit.SetAfter(ctx, auditD.EntityUser, user.ID, user)
+	return s.sendInvitation(ctx, user)
+}
+
+// ResendInvitation mails a new invitation, replacing the previous one
+func (s *service) ResendInvitation(ctx context.Context, id uint) error {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if user.PasswordHash != "" {
+		return ErrInvitationAccepted
+	}
+	return s.sendInvitation(ctx, user)
+}
+
+func (s *service) AcceptInvitation(ctx context.Context, token, password string) error {
+	return s.setPasswordWithToken(ctx, domain.TokenInvite, token, password)
+}
+
+// RequestPasswordReset mails a reset link to an active admin, unknown emails are ignored
+// so the response does not tell which emails are admins
+func (s *service) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.repo.GetByEmail(ctx, email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !user.IsActive {
+		return nil
+	}
+
+	token, err := s.issueToken(ctx, user.ID, domain.TokenReset, s.opts.ResetTTL)
+	if err != nil {
+		return err
+	}
+	return s.send(ctx, &domain.Mail{
+		To:      user.Email,
+		Subject: "Reset your Arcaptcha management password",
+		Body: fmt.Sprintf("Hello %s,\n\nA password reset was requested for your account. "+
+			"Choose a new password within %s at:\n\n%s\n\nIgnore this mail if you did not request it.\n",
+			displayName(user), s.opts.ResetTTL, s.link("reset-password", token)),
+	})
+}
+
+func (s *service) ResetPassword(ctx context.Context, token, password string) error {
+	return s.setPasswordWithToken(ctx, domain.TokenReset, token, password)
+}
+
+func (s *service) sendInvitation(ctx context.Context, user *domain.AdminUser) error {
+	token, err := s.issueToken(ctx, user.ID, domain.TokenInvite, s.opts.InviteTTL)
+	if err != nil {
+		return err
+	}
+	return s.send(ctx, &domain.Mail{
+		To:      user.Email,
+		Subject: "You are invited to the Arcaptcha management panel",
+		Body: fmt.Sprintf("Hello %s,\n\nAn account with the %s role was created for you. "+
+			"Set your password within %s at:\n\n%s\n",
+			displayName(user), user.Role, s.opts.InviteTTL, s.link("accept-invitation", token)),
+	})
+}
+
+// issueToken replaces the unused tokens of the user and purpose with a new one,
+// returning the token to mail while only its hash is stored
+func (s *service) issueToken(ctx context.Context, userID uint, purpose string, ttl time.Duration) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	now := time.Now()
+	if err := s.tokens.Revoke(ctx, userID, purpose, now); err != nil {
+		return "", err
+	}
+	err := s.tokens.Create(ctx, &domain.AdminToken{
+		CreatedAt: now,
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(ttl),
+	})
+	return token, err
+}
+
+func (s *service) setPasswordWithToken(ctx context.Context, purpose, token, password string) error {
+	t, err := s.tokens.Consume(ctx, hashToken(token), purpose, time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvalidToken
+	}
+	if err != nil {
+		return err
+	}
+	user, err := s.repo.GetByID(ctx, t.UserID)
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = string(hashedPassword)
+	if err := s.repo.Update(ctx, user); err != nil {
+		return err
+	}
+	return s.sessions.RevokeUser(ctx, user.ID)
+}
+
+func (s *service) send(ctx context.Context, mail *domain.Mail) error {
+	if err := s.mailer.Send(ctx, mail); err != nil {
+		return fmt.Errorf("%w: %w", ErrMailNotSent, err)
+	}
+	return nil
+}
+
+func (s *service) link(page, token string) string {
+	return strings.TrimSuffix(s.opts.URL, "/") + "/" + page + "?token=" + url.QueryEscape(token)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func displayName(user *domain.AdminUser) string {
+	if name := strings.TrimSpace(user.FirstName + " " + user.LastName); name != "" {
+		return name
+	}
+	return user.Email
+}
@@ -0,0 +1,198 @@
+package user
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/domain"
+)
+
+type memRepo struct {
+	users map[uint]*domain.AdminUser
+}
+
+func (r *memRepo) Create(_ context.Context, user *domain.AdminUser) error {
+	user.ID = uint(len(r.users) + 1)
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *memRepo) GetByID(_ context.Context, id uint) (*domain.AdminUser, error) {
+	if u, ok := r.users[id]; ok {
+		copied := *u
+		return &copied, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memRepo) GetByEmail(ctx context.Context, email string) (*domain.AdminUser, error) {
+	for id, u := range r.users {
+		if u.Email == email {
+			return r.GetByID(ctx, id)
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memRepo) Update(_ context.Context, user *domain.AdminUser) error {
+	copied := *user
+	r.users[user.ID] = &copied
+	return nil
+}
+
+func (r *memRepo) Delete(_ context.Context, id uint) error { delete(r.users, id); return nil }
+
+func (r *memRepo) List(context.Context, int, int, map[string]string) ([]*domain.AdminUser, error) {
+	return nil, nil
+}
+
+func (r *memRepo) ToggleActive(_ context.Context, id uint) error {
+	r.users[id].IsActive = !r.users[id].IsActive
+	return nil
+}
+
+func (r *memRepo) CountByRole(_ context.Context, role string) (int64, error) {
+	var n int64
+	for _, u := range r.users {
+		if u.Role == role && u.IsActive {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *memRepo) UseTOTPStep(_ context.Context, id uint, step int64) (bool, error) {
+	if r.users[id].TOTPLastStep >= step {
+		return false, nil
+	}
+	r.users[id].TOTPLastStep = step
+	return true, nil
+}
+
+type memTokens struct {
+	tokens []*domain.AdminToken
+}
+
+func (r *memTokens) Create(_ context.Context, token *domain.AdminToken) error {
+	r.tokens = append(r.tokens, token)
+	return nil
+}
+
+func (r *memTokens) Consume(_ context.Context, hash, purpose string, now time.Time) (*domain.AdminToken, error) {
+	for _, t := range r.tokens {
+		if t.TokenHash == hash && t.Purpose == purpose && t.UsedAt == nil && t.ExpiresAt.After(now) {
+			t.UsedAt = &now
+			return t, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memTokens) Revoke(_ context.Context, userID uint, purpose string, now time.Time) error {
+	for _, t := range r.tokens {
+		if t.UserID == userID && t.Purpose == purpose && t.UsedAt == nil {
+			t.UsedAt = &now
+		}
+	}
+	return nil
+}
+
+type revoker struct {
+	users []uint
+}
+
+func (r *revoker) RevokeUser(_ context.Context, userID uint) error {
+	r.users = append(r.users, userID)
+	return nil
+}
+
+type outbox struct {
+	mails []*domain.Mail
+}
+
+func (o *outbox) Send(_ context.Context, mail *domain.Mail) error {
+	o.mails = append(o.mails, mail)
+	return nil
+}
+
+// token extracts the token of the link in the last mail
+func (o *outbox) token(t *testing.T) string {
+	require.NotEmpty(t, o.mails)
+	body := o.mails[len(o.mails)-1].Body
+	i := strings.Index(body, "http")
+	require.GreaterOrEqual(t, i, 0, "mail has a link: %s", body)
+	u, err := url.Parse(strings.Fields(body[i:])[0])
+	require.NoError(t, err)
+	return u.Query().Get("token")
+}
+
+func newTestService() (*service, *memTokens, *outbox) {
+	tokens, mails := &memTokens{}, &outbox{}
+	s := &service{
+		repo:     &memRepo{users: map[uint]*domain.AdminUser{}},
+		tokens:   tokens,
+		codes:    &memCodes{},
+		mailer:   mails,
+		sessions: &revoker{},
+		opts:     domain.AccountOptions{URL: "https://panel.test/", InviteTTL: time.Hour, ResetTTL: time.Hour},
+	}
+	return s, tokens, mails
+}
+
+func TestInvitation(t *testing.T) {
+	ctx := context.Background()
+	s, _, mails := newTestService()
+
+	invited := &domain.AdminUser{Email: "new@arcaptcha.ir", FirstName: "New"}
+	require.NoError(t, s.InviteUser(ctx, invited))
+	assert.Equal(t, domain.RoleReadOnly, invited.Role)
+	assert.Equal(t, "new@arcaptcha.ir", mails.mails[0].To)
+	assert.Contains(t, mails.mails[0].Body, "https://panel.test/accept-invitation?token=")
+
+	_, err := s.Authenticate(ctx, invited.Email, "")
+	assert.ErrorIs(t, err, ErrInvalidPassword, "invited admins can not log in before accepting")
+
+	first := mails.token(t)
+	require.NoError(t, s.ResendInvitation(ctx, invited.ID))
+	assert.ErrorIs(t, s.AcceptInvitation(ctx, first, "password1"), ErrInvalidToken, "a resent invitation replaces the previous one")
+
+	token := mails.token(t)
+	assert.ErrorIs(t, s.ResetPassword(ctx, token, "password1"), ErrInvalidToken, "tokens only serve their purpose")
+	require.NoError(t, s.AcceptInvitation(ctx, token, "password1"))
+	assert.ErrorIs(t, s.AcceptInvitation(ctx, token, "password2"), ErrInvalidToken, "tokens are single-use")
+
+	_, err = s.Authenticate(ctx, invited.Email, "password1")
+	assert.NoError(t, err)
+	assert.ErrorIs(t, s.ResendInvitation(ctx, invited.ID), ErrInvitationAccepted)
+	assert.ErrorIs(t, s.InviteUser(ctx, &domain.AdminUser{Email: invited.Email}), ErrUserAlreadyExists)
+}
+
+func TestPasswordReset(t *testing.T) {
+	ctx := context.Background()
+	s, tokens, mails := newTestService()
+	require.NoError(t, s.CreateUser(ctx, &domain.AdminUser{Email: "admin@arcaptcha.ir"}, "old-password"))
+
+	require.NoError(t, s.RequestPasswordReset(ctx, "unknown@arcaptcha.ir"))
+	assert.Empty(t, mails.mails, "unknown emails are not told apart")
+
+	require.NoError(t, s.RequestPasswordReset(ctx, "admin@arcaptcha.ir"))
+	token := mails.token(t)
+	assert.Contains(t, mails.mails[0].Body, "https://panel.test/reset-password?token=")
+
+	tokens.tokens[0].ExpiresAt = time.Now().Add(-time.Second)
+	assert.ErrorIs(t, s.ResetPassword(ctx, token, "new-password"), ErrInvalidToken, "expired")
+
+	require.NoError(t, s.RequestPasswordReset(ctx, "admin@arcaptcha.ir"))
+	require.NoError(t, s.ResetPassword(ctx, mails.token(t), "new-password"))
+	assert.Equal(t, []uint{1}, s.sessions.(*revoker).users, "a reset password ends the sessions")
+	_, err := s.Authenticate(ctx, "admin@arcaptcha.ir", "old-password")
+	assert.ErrorIs(t, err, ErrInvalidPassword)
+	_, err = s.Authenticate(ctx, "admin@arcaptcha.ir", "new-password")
+	assert.NoError(t, err)
+}
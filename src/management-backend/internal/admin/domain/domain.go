@@ -9,10 +9,15 @@ import (
 type AdminUser struct {
 	common.BaseModel
 	Email        string    `gorm:"uniqueIndex;size:255" json:"email"`
-	PasswordHash string    `gorm:"size:255" json:"passwordhash"`
+	PasswordHash string    `gorm:"size:255" json:"-"`
 	FirstName    string    `gorm:"size:100" json:"first_name"`
 	LastName     string    `gorm:"size:100" json:"last_name"`
 	LastLogin    time.Time `json:"last_login"`
 	IsActive     bool      `gorm:"default:true" json:"is_active"`
-	Role         string    `gorm:"size:50;default:admin" json:"role"`
+	Role         string    `gorm:"size:50;default:'read-only';index" json:"role"`
+	// TOTPSecret is set once enrollment starts, it only protects logins once TOTPEnabled
+	TOTPSecret  string `gorm:"column:totp_secret;size:64" json:"-"`
+	TOTPEnabled bool   `gorm:"column:totp_enabled;not null;default:false" json:"totp_enabled"`
+	// TOTPLastStep is the time step of the last accepted code, older and equal steps are replays
+	TOTPLastStep int64 `gorm:"column:totp_last_step;not null;default:0" json:"-"`
 }
@@ -0,0 +1,81 @@
+package domain
+
+import "sort"
+
+// admin roles, a role grants a fixed set of permissions
+const (
+	RoleSuperAdmin = "superadmin"
+	RoleBilling    = "billing"
+	RoleSupport    = "support"
+	RoleReadOnly   = "read-only"
+)
+
+// Permission allows an action on the management API, named <resource>:<action>
+type Permission string
+
+const (
+	PermUsersRead          Permission = "users:read"
+	PermUsersWrite         Permission = "users:write"
+	PermUsersDeactivate    Permission = "users:deactivate" // also unlocks logins locked by failed attempts
+	PermUsersRoles         Permission = "users:roles"
+	PermUsersTwoFactor     Permission = "users:2fa" // reset the second factor of an admin who lost it
+	PermPlansRead          Permission = "plans:read"
+	PermPlansWrite         Permission = "plans:write"
+	PermSubscriptionsRead  Permission = "subscriptions:read"  // plans assigned to users, their history and usage
+	PermSubscriptionsWrite Permission = "subscriptions:write" // assign, activate, change, suspend, resume and cancel user plans
+	PermLimitationsRead    Permission = "limitations:read"
+	PermLimitationsWrite   Permission = "limitations:write"
+	PermAuditRead          Permission = "audit:read"
+)
+
+var readPermissions = []Permission{PermUsersRead, PermPlansRead, PermSubscriptionsRead, PermLimitationsRead}
+
+var rolePermissions = map[string][]Permission{
+	RoleSuperAdmin: {
+		PermUsersRead, PermUsersWrite, PermUsersDeactivate, PermUsersRoles, PermUsersTwoFactor,
+		PermPlansRead, PermPlansWrite, PermSubscriptionsRead, PermSubscriptionsWrite,
+		PermLimitationsRead, PermLimitationsWrite, PermAuditRead,
+	},
+	RoleBilling:  append([]Permission{PermPlansWrite, PermSubscriptionsWrite, PermLimitationsWrite}, readPermissions...),
+	RoleSupport:  append([]Permission{PermUsersWrite, PermUsersDeactivate}, readPermissions...),
+	RoleReadOnly: readPermissions,
+}
+
+// Roles returns the known roles sorted by name
+func Roles() []string {
+	roles := make([]string, 0, len(rolePermissions))
+	for r := range rolePermissions {
+		roles = append(roles, r)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Permissions returns the permissions of role, none for an unknown role
+func Permissions(role string) []Permission {
+	return append([]Permission(nil), rolePermissions[role]...)
+}
+
+func HasPermission(role string, perm Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// Covers reports whether role has every permission of other
+func Covers(role, other string) bool {
+	for _, p := range rolePermissions[other] {
+		if !HasPermission(role, p) {
+			return false
+		}
+	}
+	return true
+}
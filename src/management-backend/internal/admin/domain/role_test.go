@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasPermission(t *testing.T) {
+	for _, r := range Roles() {
+		assert.True(t, HasPermission(r, PermPlansRead), "every role reads plans: %s", r)
+	}
+	for _, r := range Roles() {
+		assert.Equal(t, r == RoleSuperAdmin, HasPermission(r, PermUsersRoles), "only superadmins manage roles: %s", r)
+		assert.Equal(t, r == RoleSuperAdmin, HasPermission(r, PermAuditRead), "only superadmins read the audit log: %s", r)
+	}
+
+	assert.True(t, HasPermission(RoleBilling, PermPlansWrite))
+	assert.False(t, HasPermission(RoleBilling, PermUsersDeactivate))
+	assert.True(t, HasPermission(RoleSupport, PermUsersDeactivate))
+	assert.False(t, HasPermission(RoleSupport, PermSubscriptionsWrite))
+	assert.False(t, HasPermission(RoleReadOnly, PermUsersWrite))
+
+	assert.False(t, HasPermission("admin", PermPlansRead), "unknown roles have no permissions")
+	assert.False(t, HasPermission("", PermPlansRead))
+}
+
+func TestCovers(t *testing.T) {
+	for _, r := range Roles() {
+		assert.True(t, Covers(RoleSuperAdmin, r), r)
+		assert.True(t, Covers(r, RoleReadOnly), r)
+		assert.True(t, Covers(r, r), r)
+	}
+	assert.False(t, Covers(RoleSupport, RoleSuperAdmin))
+	assert.False(t, Covers(RoleSupport, RoleBilling))
+	assert.False(t, Covers(RoleBilling, RoleSupport))
+	assert.False(t, Covers("", RoleReadOnly))
+}
@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// purposes of admin tokens
+const (
+	TokenInvite = "invite"
+	TokenReset  = "reset"
+	// TokenTwoFactor is the challenge between the password and the code step of a login
+	TokenTwoFactor = "2fa"
+)
+
+// AdminToken is a single-use, expiring token mailed to an admin, only its hash is stored
+type AdminToken struct {
+	ID        uint       `gorm:"primaryKey"`
+	CreatedAt time.Time  `gorm:"not null"`
+	UserID    uint       `gorm:"not null;index"`
+	Purpose   string     `gorm:"size:20;not null"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time // set once the token is used or replaced
+}
+
+// Mail is a plain text mail to a single address
+type Mail struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// AccountOptions configures invitations, password resets and two-factor authentication
+type AccountOptions struct {
+	URL       string // admin panel the mailed links point to
+	InviteTTL time.Duration
+	ResetTTL  time.Duration
+	TwoFactor TwoFactorOptions
+}
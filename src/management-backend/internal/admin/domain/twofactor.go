@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// AdminRecoveryCode is a single-use code logging in without the authenticator app, only its hash is stored
+type AdminRecoveryCode struct {
+	ID        uint      `gorm:"primaryKey"`
+	CreatedAt time.Time `gorm:"not null"`
+	UserID    uint      `gorm:"not null;index"`
+	CodeHash  string    `gorm:"size:64;not null"`
+	UsedAt    *time.Time
+}
+
+// TwoFactorSetup is the secret to add to an authenticator app, URL carries it as a QR code payload
+type TwoFactorSetup struct {
+	Secret string
+	URL    string
+}
+
+// TwoFactorChallenge is the second step of a login, Setup is set when the admin has to enroll first
+type TwoFactorChallenge struct {
+	Token     string
+	ExpiresAt time.Time
+	Setup     *TwoFactorSetup
+}
+
+type TwoFactorOptions struct {
+	Issuer string // name authenticator apps list the account under
+	// RequiredRoles must log in with a second factor, admins of other roles may enable it
+	RequiredRoles []string
+	ChallengeTTL  time.Duration
+}
+
+func (o TwoFactorOptions) Required(role string) bool {
+	return slices.Contains(o.RequiredRoles, role)
+}
@@ -2,6 +2,7 @@ package port
 
 import (
 	"context"
+	"time"
 
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/domain"
 )
@@ -16,6 +17,19 @@ type Service interface {
 	ListUsers(ctx context.Context, limit, offset int, filters map[string]string) ([]*domain.AdminUser, error)
 	ToggleUserActive(ctx context.Context, id uint) error
 	ChangePassword(ctx context.Context, id uint, currentPassword, newPassword string) error
+	SetUserRole(ctx context.Context, id uint, role string) error
+	InviteUser(ctx context.Context, user *domain.AdminUser) error
+	ResendInvitation(ctx context.Context, id uint) error
+	AcceptInvitation(ctx context.Context, token, password string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, password string) error
+	LoginChallenge(ctx context.Context, user *domain.AdminUser) (*domain.TwoFactorChallenge, error)
+	VerifyLogin(ctx context.Context, challenge, code, recoveryCode string) (*domain.AdminUser, []string, error)
+	SetupTwoFactor(ctx context.Context, id uint) (*domain.TwoFactorSetup, error)
+	EnableTwoFactor(ctx context.Context, id uint, code string) ([]string, error)
+	DisableTwoFactor(ctx context.Context, id uint, password string) error
+	RegenerateRecoveryCodes(ctx context.Context, id uint, code string) ([]string, error)
+	ResetTwoFactor(ctx context.Context, id uint) error
 }
 
 type Repository interface {
@@ -26,4 +40,34 @@ type Repository interface {
 	Delete(ctx context.Context, id uint) error
 	List(ctx context.Context, limit, offset int, filters map[string]string) ([]*domain.AdminUser, error)
 	ToggleActive(ctx context.Context, id uint) error
+	// CountByRole counts the active admins of role
+	CountByRole(ctx context.Context, role string) (int64, error)
+	// UseTOTPStep records step as the last accepted TOTP step of the user,
+	// false when it or a later step was accepted already
+	UseTOTPStep(ctx context.Context, id uint, step int64) (bool, error)
+}
+
+type TokenRepository interface {
+	Create(ctx context.Context, token *domain.AdminToken) error
+	// Consume marks the unused, unexpired token of hash and purpose used and returns it
+	Consume(ctx context.Context, hash, purpose string, now time.Time) (*domain.AdminToken, error)
+	// Revoke marks the unused tokens of the user and purpose used
+	Revoke(ctx context.Context, userID uint, purpose string, now time.Time) error
+}
+
+type RecoveryCodeRepository interface {
+	// Replace deletes the codes of the user and stores hashes instead, no hashes only deletes
+	Replace(ctx context.Context, userID uint, hashes []string, now time.Time) error
+	// Consume marks the unused code of the user and hash used, false when there is none
+	Consume(ctx context.Context, userID uint, hash string, now time.Time) (bool, error)
+}
+
+// SessionRevoker ends the sessions of an admin whose password, role or state changed
+type SessionRevoker interface {
+	RevokeUser(ctx context.Context, userID uint) error
+}
+
+// Mailer delivers mails to admins
+type Mailer interface {
+	Send(ctx context.Context, mail *domain.Mail) error
 }
@@ -4,36 +4,69 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	"google.golang.org/grpc"
+	"gorm.io/gorm"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/domain"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/port"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/api/pb"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/audit"
+	auditD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/audit/domain"
 )
 
 var (
 	ErrUserNotFound      = errors.New("user not found")
 	ErrInvalidPassword   = errors.New("invalid password")
 	ErrUserAlreadyExists = errors.New("user already exists")
+	ErrInvalidRole       = errors.New("invalid role")
+	ErrLastSuperAdmin    = errors.New("the last superadmin can not lose the role")
+	// ErrInvalidCredentials wraps why a login failed, callers tell users no more than this
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrUserDeactivated    = errors.New("account is deactivated")
 )
 
+// dummyHash is compared against when there is no password to compare, so unknown emails
+// and invited admins take as long as wrong passwords
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+	return hash
+})
+
 type service struct {
 	repo       port.Repository
+	tokens     port.TokenRepository
+	codes      port.RecoveryCodeRepository
+	mailer     port.Mailer
+	sessions   port.SessionRevoker
+	opts       domain.AccountOptions
 	userClient pb.UserServiceClient
 }
 
-func NewService(repo port.Repository, cc *grpc.ClientConn) port.Service {
+func NewService(repo port.Repository, tokens port.TokenRepository, codes port.RecoveryCodeRepository, mailer port.Mailer,
+	sessions port.SessionRevoker, opts domain.AccountOptions, cc *grpc.ClientConn) port.Service {
 	return &service{
 		repo:       repo,
+		tokens:     tokens,
+		codes:      codes,
+		mailer:     mailer,
+		sessions:   sessions,
+		opts:       opts,
 		userClient: pb.NewUserServiceClient(cc),
 	}
 }
 
+// CreateUser creates an active admin, read-only unless user has a role
 func (s *service) CreateUser(ctx context.Context, user *domain.AdminUser, password string) error {
-	existing, _ := s.repo.GetByEmail(ctx, user.Email)
-	if existing != nil {
+	if user.Role == "" {
+		user.Role = domain.RoleReadOnly
+	}
+	if !domain.ValidRole(user.Role) {
+		return ErrInvalidRole
+	}
+	if _, err := s.repo.GetByEmail(ctx, user.Email); err == nil {
 		return ErrUserAlreadyExists
 	}
 
@@ -44,23 +77,36 @@ func (s *service) CreateUser(ctx context.Context, user *domain.AdminUser, passwo
 
 	user.PasswordHash = string(hashedPassword)
 	user.IsActive = true
-	user.Role = "admin"
 
-	return s.repo.Create(ctx, user)
+	if err := s.repo.Create(ctx, user); err != nil {
+		return err
+	}
+	audit.SetAfter(ctx, auditD.EntityUser, user.ID, user)
+	return nil
 }
 
+// Authenticate returns ErrInvalidCredentials wrapping ErrUserNotFound, ErrInvalidPassword or
+// ErrUserDeactivated, in about the same time for each
 func (s *service) Authenticate(ctx context.Context, email, password string) (*domain.AdminUser, error) {
 	user, err := s.repo.GetByEmail(ctx, email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		_ = bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, ErrUserNotFound)
+	}
 	if err != nil {
-		return nil, ErrUserNotFound
+		return nil, err
 	}
 
-	if !user.IsActive {
-		return nil, errors.New("account is deactivated")
+	hash := []byte(user.PasswordHash)
+	if len(hash) == 0 {
+		hash = dummyHash()
 	}
-
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return nil, ErrInvalidPassword
+	if err := bcrypt.CompareHashAndPassword(hash, []byte(password)); err != nil || user.PasswordHash == "" {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, ErrInvalidPassword)
+	}
+	// checked after the password so the state of an account is not told to anyone guessing
+	if !user.IsActive {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, ErrUserDeactivated)
 	}
 
 	user.LastLogin = time.Now()
@@ -80,11 +126,27 @@ func (s *service) GetUserByEmail(ctx context.Context, email string) (*domain.Adm
 }
 
 func (s *service) UpdateUser(ctx context.Context, user *domain.AdminUser) error {
-	return s.repo.Update(ctx, user)
+	s.snapshotBefore(ctx, user.ID)
+	if err := s.repo.Update(ctx, user); err != nil {
+		return err
+	}
+	audit.SetAfter(ctx, auditD.EntityUser, user.ID, user)
+	return nil
 }
 
 func (s *service) DeleteUser(ctx context.Context, id uint) error {
-	return s.repo.Delete(ctx, id)
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if err := s.keepSuperAdmin(ctx, user); err != nil {
+		return err
+	}
+	audit.SetBefore(ctx, auditD.EntityUser, id, user)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	return s.sessions.RevokeUser(ctx, id)
 }
 
 func (s *service) ListUsers(ctx context.Context, limit, offset int, filters map[string]string) ([]*domain.AdminUser, error) {
@@ -96,8 +158,21 @@ func (s *service) ListUsers(ctx context.Context, limit, offset int, filters map[
 	return []*domain.AdminUser{}, nil
 }
 
+// ToggleUserActive activates or deactivates an admin, keeping at least one active superadmin
 func (s *service) ToggleUserActive(ctx context.Context, id uint) error {
-	return s.repo.ToggleActive(ctx, id)
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if err := s.keepSuperAdmin(ctx, user); err != nil {
+		return err
+	}
+	audit.SetBefore(ctx, auditD.EntityUser, id, user)
+	if err := s.repo.ToggleActive(ctx, id); err != nil {
+		return err
+	}
+	s.snapshotAfter(ctx, id)
+	return s.sessions.RevokeUser(ctx, id)
 }
 
 func (s *service) ChangePassword(ctx context.Context, id uint, currentPassword, newPassword string) error {
@@ -115,6 +190,71 @@ func (s *service) ChangePassword(ctx context.Context, id uint, currentPassword,
 		return err
 	}
 
+	audit.SetBefore(ctx, auditD.EntityUser, user.ID, user)
 	user.PasswordHash = string(hashedPassword)
-	return s.repo.Update(ctx, user)
+	if err := s.repo.Update(ctx, user); err != nil {
+		return err
+	}
+	audit.SetAfter(ctx, auditD.EntityUser, user.ID, user)
+	return s.sessions.RevokeUser(ctx, user.ID)
+}
+
+// SetUserRole changes the role of an admin, keeping at least one superadmin
+func (s *service) SetUserRole(ctx context.Context, id uint, role string) error {
+	if !domain.ValidRole(role) {
+		return ErrInvalidRole
+	}
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if user.Role == role {
+		return nil
+	}
+	if err := s.keepSuperAdmin(ctx, user); err != nil {
+		return err
+	}
+
+	audit.SetBefore(ctx, auditD.EntityUser, user.ID, user)
+	user.Role = role
+	if err := s.repo.Update(ctx, user); err != nil {
+		return err
+	}
+	audit.SetAfter(ctx, auditD.EntityUser, user.ID, user)
+	// tokens carry the role
+	return s.sessions.RevokeUser(ctx, user.ID)
+}
+
+// keepSuperAdmin returns ErrLastSuperAdmin when user is the only active superadmin
+func (s *service) keepSuperAdmin(ctx context.Context, user *domain.AdminUser) error {
+	if user.Role != domain.RoleSuperAdmin || !user.IsActive {
+		return nil
+	}
+	count, err := s.repo.CountByRole(ctx, domain.RoleSuperAdmin)
+	if err != nil {
+		return err
+	}
+	if count <= 1 {
+		return ErrLastSuperAdmin
+	}
+	return nil
+}
+
+// snapshotBefore records the stored user as the audit before snapshot of an audited request
+func (s *service) snapshotBefore(ctx context.Context, id uint) {
+	if !audit.Recording(ctx) {
+		return
+	}
+	if user, err := s.repo.GetByID(ctx, id); err == nil {
+		audit.SetBefore(ctx, auditD.EntityUser, id, user)
+	}
+}
+
+func (s *service) snapshotAfter(ctx context.Context, id uint) {
+	if !audit.Recording(ctx) {
+		return
+	}
+	if user, err := s.repo.GetByID(ctx, id); err == nil {
+		audit.SetAfter(ctx, auditD.EntityUser, id, user)
+	}
 }
@@ -0,0 +1,53 @@
+package user
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/domain"
+)
+
+func TestAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	s, _, _ := newTestService()
+	active := &domain.AdminUser{Email: "active@arcaptcha.ir"}
+	require.NoError(t, s.CreateUser(ctx, active, "password1"))
+	inactive := &domain.AdminUser{Email: "inactive@arcaptcha.ir"}
+	require.NoError(t, s.CreateUser(ctx, inactive, "password1"))
+	require.NoError(t, s.repo.ToggleActive(ctx, inactive.ID))
+
+	for _, tc := range []struct {
+		email, password string
+		reason          error
+	}{
+		{"unknown@arcaptcha.ir", "password1", ErrUserNotFound},
+		{"active@arcaptcha.ir", "wrong", ErrInvalidPassword},
+		{"inactive@arcaptcha.ir", "wrong", ErrInvalidPassword},
+		{"inactive@arcaptcha.ir", "password1", ErrUserDeactivated},
+	} {
+		_, err := s.Authenticate(ctx, tc.email, tc.password)
+		assert.ErrorIs(t, err, ErrInvalidCredentials, tc.email)
+		assert.ErrorIs(t, err, tc.reason, tc.email)
+	}
+
+	user, err := s.Authenticate(ctx, "active@arcaptcha.ir", "password1")
+	require.NoError(t, err)
+	assert.False(t, user.LastLogin.IsZero())
+}
+
+func TestToggleUserActive_KeepsSuperAdmin(t *testing.T) {
+	ctx := context.Background()
+	s, _, _ := newTestService()
+	root := &domain.AdminUser{Email: "root@arcaptcha.ir", Role: domain.RoleSuperAdmin, IsActive: true}
+	require.NoError(t, s.CreateUser(ctx, root, "password1"))
+	other := &domain.AdminUser{Email: "other@arcaptcha.ir", Role: domain.RoleSuperAdmin, IsActive: true}
+	require.NoError(t, s.CreateUser(ctx, other, "password1"))
+
+	require.NoError(t, s.ToggleUserActive(ctx, other.ID))
+	assert.ErrorIs(t, s.ToggleUserActive(ctx, root.ID), ErrLastSuperAdmin, "the last active superadmin stays active")
+	assert.ErrorIs(t, s.DeleteUser(ctx, root.ID), ErrLastSuperAdmin)
+	require.NoError(t, s.DeleteUser(ctx, other.ID), "an inactive superadmin can be removed")
+	assert.ErrorIs(t, s.ToggleUserActive(ctx, other.ID), ErrUserNotFound)
+}
@@ -0,0 +1,265 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/audit"
+	auditD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/audit/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/pkg/totp"
+)
+
+var (
+	ErrInvalidCode       = errors.New("invalid two-factor code")
+	ErrTwoFactorEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorDisabled = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotSetUp = errors.New("two-factor setup was not started")
+	ErrTwoFactorRequired = errors.New("two-factor authentication is required for the role")
+)
+
+const recoveryCodeCount = 10
+
+// LoginChallenge returns the second step of a password login, nil when the admin neither
+// enabled two-factor authentication nor has a role requiring it. Admins who have to enroll
+// get the secret to add to their authenticator app with the challenge.
+func (s *service) LoginChallenge(ctx context.Context, user *domain.AdminUser) (*domain.TwoFactorChallenge, error) {
+	if !user.TOTPEnabled && !s.opts.TwoFactor.Required(user.Role) {
+		return nil, nil
+	}
+
+	challenge := &domain.TwoFactorChallenge{ExpiresAt: time.Now().Add(s.opts.TwoFactor.ChallengeTTL)}
+	if !user.TOTPEnabled {
+		setup, err := s.pendingSetup(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		challenge.Setup = setup
+	}
+	token, err := s.issueToken(ctx, user.ID, domain.TokenTwoFactor, s.opts.TwoFactor.ChallengeTTL)
+	if err != nil {
+		return nil, err
+	}
+	challenge.Token = token
+	return challenge, nil
+}
+
+// VerifyLogin completes a login with a TOTP or a recovery code. The challenge is single-use,
+// a wrong code means logging in again, ErrInvalidCode comes with the user of the challenge so
+// the failure can be counted. Completing an enrollment returns the new recovery codes.
+func (s *service) VerifyLogin(ctx context.Context, challenge, code, recoveryCode string) (*domain.AdminUser, []string, error) {
+	t, err := s.tokens.Consume(ctx, hashToken(challenge), domain.TokenTwoFactor, time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := s.repo.GetByID(ctx, t.UserID)
+	if err != nil || !user.IsActive {
+		return nil, nil, ErrInvalidToken
+	}
+
+	if recoveryCode != "" {
+		if !user.TOTPEnabled {
+			return user, nil, ErrInvalidCode
+		}
+		ok, err := s.codes.Consume(ctx, user.ID, hashToken(normalizeRecoveryCode(recoveryCode)), time.Now())
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			return user, nil, ErrInvalidCode
+		}
+		return user, nil, nil
+	}
+
+	err = s.checkCode(ctx, user, code)
+	if errors.Is(err, ErrInvalidCode) {
+		return user, nil, err
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if user.TOTPEnabled {
+		return user, nil, nil
+	}
+	codes, err := s.enable(ctx, user)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, codes, nil
+}
+
+// SetupTwoFactor starts the enrollment of an admin, EnableTwoFactor completes it
+func (s *service) SetupTwoFactor(ctx context.Context, id uint) (*domain.TwoFactorSetup, error) {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorEnabled
+	}
+	return s.pendingSetup(ctx, user)
+}
+
+// EnableTwoFactor turns two-factor authentication on once the admin proves their app
+// generates codes, returning the recovery codes
+func (s *service) EnableTwoFactor(ctx context.Context, id uint, code string) ([]string, error) {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorNotSetUp
+	}
+	if err := s.checkCode(ctx, user, code); err != nil {
+		return nil, err
+	}
+	return s.enable(ctx, user)
+}
+
+// DisableTwoFactor turns two-factor authentication off for admins whose role does not require it
+func (s *service) DisableTwoFactor(ctx context.Context, id uint, password string) error {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if !user.TOTPEnabled {
+		return ErrTwoFactorDisabled
+	}
+	if s.opts.TwoFactor.Required(user.Role) {
+		return ErrTwoFactorRequired
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return ErrInvalidPassword
+	}
+	return s.clearTwoFactor(ctx, user)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes, the previous ones stop working
+func (s *service) RegenerateRecoveryCodes(ctx context.Context, id uint, code string) ([]string, error) {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if !user.TOTPEnabled {
+		return nil, ErrTwoFactorDisabled
+	}
+	if err := s.checkCode(ctx, user, code); err != nil {
+		return nil, err
+	}
+	return s.newRecoveryCodes(ctx, user.ID)
+}
+
+// ResetTwoFactor removes the second factor of an admin who lost it and ends their sessions,
+// they enroll again on their next login when their role requires it
+func (s *service) ResetTwoFactor(ctx context.Context, id uint) error {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if !user.TOTPEnabled && user.TOTPSecret == "" {
+		return ErrTwoFactorDisabled
+	}
+	if err := s.clearTwoFactor(ctx, user); err != nil {
+		return err
+	}
+	if err := s.tokens.Revoke(ctx, id, domain.TokenTwoFactor, time.Now()); err != nil {
+		return err
+	}
+	return s.sessions.RevokeUser(ctx, id)
+}
+
+// pendingSetup keeps the secret of an enrollment that was started, so logging in again
+// does not invalidate an app that already added it
+func (s *service) pendingSetup(ctx context.Context, user *domain.AdminUser) (*domain.TwoFactorSetup, error) {
+	if user.TOTPSecret == "" {
+		secret, err := totp.NewSecret()
+		if err != nil {
+			return nil, err
+		}
+		user.TOTPSecret = secret
+		user.TOTPLastStep = 0
+		if err := s.repo.Update(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+	return &domain.TwoFactorSetup{
+		Secret: user.TOTPSecret,
+		URL:    totp.URL(s.opts.TwoFactor.Issuer, user.Email, user.TOTPSecret),
+	}, nil
+}
+
+// checkCode accepts every TOTP code of user once
+func (s *service) checkCode(ctx context.Context, user *domain.AdminUser, code string) error {
+	step, ok := totp.Validate(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return ErrInvalidCode
+	}
+	fresh, err := s.repo.UseTOTPStep(ctx, user.ID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return ErrInvalidCode
+	}
+	// saving user later must not roll the step back
+	user.TOTPLastStep = step
+	return nil
+}
+
+func (s *service) enable(ctx context.Context, user *domain.AdminUser) ([]string, error) {
+	audit.SetBefore(ctx, auditD.EntityUser, user.ID, user)
+	user.TOTPEnabled = true
+	if err := s.repo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	audit.SetAfter(ctx, auditD.EntityUser, user.ID, user)
+	return s.newRecoveryCodes(ctx, user.ID)
+}
+
+func (s *service) clearTwoFactor(ctx context.Context, user *domain.AdminUser) error {
+	audit.SetBefore(ctx, auditD.EntityUser, user.ID, user)
+	user.TOTPSecret = ""
+	user.TOTPEnabled = false
+	user.TOTPLastStep = 0
+	if err := s.repo.Update(ctx, user); err != nil {
+		return err
+	}
+	audit.SetAfter(ctx, auditD.EntityUser, user.ID, user)
+	return s.codes.Replace(ctx, user.ID, nil, time.Now())
+}
+
+// newRecoveryCodes replaces the recovery codes of the user, returning them while only their hashes are stored
+func (s *service) newRecoveryCodes(ctx context.Context, userID uint) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+		codes[i] = code[:4] + "-" + code[4:]
+		hashes[i] = hashToken(code)
+	}
+	if err := s.codes.Replace(ctx, userID, hashes, time.Now()); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// normalizeRecoveryCode accepts codes typed without the dash or in upper case
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -0,0 +1,172 @@
+package user
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/pkg/totp"
+)
+
+type memCodes struct {
+	codes []*domain.AdminRecoveryCode
+}
+
+func (r *memCodes) Replace(_ context.Context, userID uint, hashes []string, now time.Time) error {
+	kept := r.codes[:0]
+	for _, c := range r.codes {
+		if c.UserID != userID {
+			kept = append(kept, c)
+		}
+	}
+	for _, h := range hashes {
+		kept = append(kept, &domain.AdminRecoveryCode{CreatedAt: now, UserID: userID, CodeHash: h})
+	}
+	r.codes = kept
+	return nil
+}
+
+func (r *memCodes) Consume(_ context.Context, userID uint, hash string, now time.Time) (bool, error) {
+	for _, c := range r.codes {
+		if c.UserID == userID && c.CodeHash == hash && c.UsedAt == nil {
+			c.UsedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// code returns the code of secret steps away from the current step,
+// every accepted code has to be of a later step than the previous one
+func code(t *testing.T, secret string, steps int64) string {
+	c, err := totp.Code(secret, totp.Step(time.Now())+steps)
+	require.NoError(t, err)
+	return c
+}
+
+func newTwoFactorService(t *testing.T, role string) (*service, *domain.AdminUser) {
+	s, _, _ := newTestService()
+	s.opts.TwoFactor = domain.TwoFactorOptions{
+		Issuer:        "Arcaptcha",
+		RequiredRoles: []string{domain.RoleSuperAdmin},
+		ChallengeTTL:  time.Minute,
+	}
+	user := &domain.AdminUser{Email: "admin@arcaptcha.ir", Role: role}
+	require.NoError(t, s.CreateUser(context.Background(), user, "password1"))
+	return s, user
+}
+
+func TestTwoFactor_EnrollAtLogin(t *testing.T) {
+	ctx := context.Background()
+	s, user := newTwoFactorService(t, domain.RoleSuperAdmin)
+
+	first, err := s.LoginChallenge(ctx, user)
+	require.NoError(t, err)
+	require.NotNil(t, first.Setup, "a required second factor is enrolled at login")
+	assert.Contains(t, first.Setup.URL, "otpauth://totp/Arcaptcha:admin@arcaptcha.ir?")
+
+	user, _ = s.repo.GetByID(ctx, user.ID)
+	challenge, err := s.LoginChallenge(ctx, user)
+	require.NoError(t, err)
+	secret := challenge.Setup.Secret
+	assert.Equal(t, first.Setup.Secret, secret, "logging in again keeps the pending secret")
+	_, _, err = s.VerifyLogin(ctx, first.Token, code(t, secret, -1), "")
+	assert.ErrorIs(t, err, ErrInvalidToken, "a new login replaces the challenge")
+
+	_, _, err = s.VerifyLogin(ctx, challenge.Token, "000000", "")
+	assert.ErrorIs(t, err, ErrInvalidCode)
+	_, _, err = s.VerifyLogin(ctx, challenge.Token, code(t, secret, -1), "")
+	assert.ErrorIs(t, err, ErrInvalidToken, "challenges are single-use")
+
+	challenge, err = s.LoginChallenge(ctx, user)
+	require.NoError(t, err)
+	used := code(t, secret, -1)
+	verified, codes, err := s.VerifyLogin(ctx, challenge.Token, used, "")
+	require.NoError(t, err)
+	assert.True(t, verified.TOTPEnabled)
+	assert.Len(t, codes, recoveryCodeCount)
+
+	user, _ = s.repo.GetByID(ctx, user.ID)
+	challenge, err = s.LoginChallenge(ctx, user)
+	require.NoError(t, err)
+	assert.Nil(t, challenge.Setup)
+	_, _, err = s.VerifyLogin(ctx, challenge.Token, used, "")
+	assert.ErrorIs(t, err, ErrInvalidCode, "codes can not be replayed")
+
+	for i, recovery := range []string{strings.ToUpper(strings.ReplaceAll(codes[0], "-", "")), codes[0]} {
+		challenge, err = s.LoginChallenge(ctx, user)
+		require.NoError(t, err)
+		_, fresh, err := s.VerifyLogin(ctx, challenge.Token, "", recovery)
+		if i == 0 {
+			assert.NoError(t, err)
+			assert.Empty(t, fresh)
+		} else {
+			assert.ErrorIs(t, err, ErrInvalidCode, "recovery codes are single-use")
+		}
+	}
+
+	assert.ErrorIs(t, s.DisableTwoFactor(ctx, user.ID, "password1"), ErrTwoFactorRequired)
+}
+
+func TestTwoFactor_Optional(t *testing.T) {
+	ctx := context.Background()
+	s, user := newTwoFactorService(t, domain.RoleReadOnly)
+
+	challenge, err := s.LoginChallenge(ctx, user)
+	require.NoError(t, err)
+	assert.Nil(t, challenge, "the role does not require a second factor")
+
+	_, err = s.EnableTwoFactor(ctx, user.ID, "123456")
+	assert.ErrorIs(t, err, ErrTwoFactorNotSetUp)
+	setup, err := s.SetupTwoFactor(ctx, user.ID)
+	require.NoError(t, err)
+	_, err = s.EnableTwoFactor(ctx, user.ID, "000000")
+	assert.ErrorIs(t, err, ErrInvalidCode)
+	codes, err := s.EnableTwoFactor(ctx, user.ID, code(t, setup.Secret, -1))
+	require.NoError(t, err)
+	_, err = s.SetupTwoFactor(ctx, user.ID)
+	assert.ErrorIs(t, err, ErrTwoFactorEnabled)
+
+	renewed, err := s.RegenerateRecoveryCodes(ctx, user.ID, code(t, setup.Secret, 0))
+	require.NoError(t, err)
+	assert.NotEqual(t, codes, renewed)
+	user, _ = s.repo.GetByID(ctx, user.ID)
+	challenge, err = s.LoginChallenge(ctx, user)
+	require.NoError(t, err)
+	_, _, err = s.VerifyLogin(ctx, challenge.Token, "", codes[0])
+	assert.ErrorIs(t, err, ErrInvalidCode, "regenerating replaces the recovery codes")
+
+	assert.ErrorIs(t, s.DisableTwoFactor(ctx, user.ID, "wrong"), ErrInvalidPassword)
+	require.NoError(t, s.DisableTwoFactor(ctx, user.ID, "password1"))
+	user, _ = s.repo.GetByID(ctx, user.ID)
+	assert.False(t, user.TOTPEnabled)
+	assert.Empty(t, user.TOTPSecret)
+	assert.Empty(t, s.codes.(*memCodes).codes)
+}
+
+func TestTwoFactor_Reset(t *testing.T) {
+	ctx := context.Background()
+	s, user := newTwoFactorService(t, domain.RoleSuperAdmin)
+	assert.ErrorIs(t, s.ResetTwoFactor(ctx, user.ID), ErrTwoFactorDisabled)
+
+	challenge, err := s.LoginChallenge(ctx, user)
+	require.NoError(t, err)
+	_, _, err = s.VerifyLogin(ctx, challenge.Token, code(t, challenge.Setup.Secret, 0), "")
+	require.NoError(t, err)
+	pending, err := s.LoginChallenge(ctx, user)
+	require.NoError(t, err)
+
+	require.NoError(t, s.ResetTwoFactor(ctx, user.ID))
+	assert.Equal(t, []uint{user.ID}, s.sessions.(*revoker).users, "a reset ends the sessions")
+	_, _, err = s.VerifyLogin(ctx, pending.Token, code(t, challenge.Setup.Secret, 1), "")
+	assert.ErrorIs(t, err, ErrInvalidToken, "a reset ends pending logins")
+
+	user, _ = s.repo.GetByID(ctx, user.ID)
+	challenge, err = s.LoginChallenge(ctx, user)
+	require.NoError(t, err)
+	require.NotNil(t, challenge.Setup, "the role requires enrolling again")
+}
@@ -3,30 +3,113 @@ package dto
 import "time"
 
 type LoginRequest struct {
-	Email        string `json:"email" validate:"required,email"`
-	Password     string `json:"password" validate:"required"`
-	CaptchaToken string `json:"captcha_token" validate:"required"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+	// CaptchaToken is only required once the account or IP failed LOGIN_CAPTCHA_AFTER logins
+	CaptchaToken string `json:"captcha_token"`
 }
 
+// LoginResponse carries a short-lived access token and the refresh token renewing it
 type LoginResponse struct {
-	Token string       `json:"token"`
-	User  UserResponse `json:"user"`
+	Token            string       `json:"token"`
+	ExpiresAt        time.Time    `json:"expires_at"`
+	RefreshToken     string       `json:"refresh_token"`
+	RefreshExpiresAt time.Time    `json:"refresh_expires_at"`
+	User             UserResponse `json:"user"`
+	// RecoveryCodes are only returned by the login that completes a two-factor enrollment
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+// TwoFactorChallengeResponse is returned by a login that needs a TOTP code, setup is set when the admin
+// has to add the secret to their authenticator app first
+type TwoFactorChallengeResponse struct {
+	ChallengeToken string                  `json:"challenge_token"`
+	ExpiresAt      time.Time               `json:"expires_at"`
+	Setup          *TwoFactorSetupResponse `json:"setup,omitempty"`
+}
+
+// VerifyTwoFactorRequest completes a login with a TOTP code or a recovery code
+type VerifyTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required_without=RecoveryCode"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// RecoveryCodesResponse lists the single-use recovery codes, they are shown only once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// RefreshRequest exchanges a refresh token for new tokens, the refresh token can only be used once
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type LogoutRequest struct {
+	All bool `json:"all"` // end every session of the user instead of this one
 }
 
 type UserResponse struct {
-	ID        uint      `json:"id"`
-	Email     string    `json:"email"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	Role      string    `json:"role"`
-	CreatedAt time.Time `json:"created_at"`
+	ID               uint      `json:"id"`
+	Email            string    `json:"email"`
+	FirstName        string    `json:"first_name"`
+	LastName         string    `json:"last_name"`
+	Role             string    `json:"role"`
+	CreatedAt        time.Time `json:"created_at"`
+	TwoFactorEnabled bool      `json:"two_factor_enabled"`
 }
 
 type CreateUserRequest struct {
 	Email     string `json:"email" validate:"required,email"`
-	Phone     string `json:"phone" validate:"required"`
+	Phone     string `json:"phone"`
 	FirstName string `json:"first_name" validate:"required"`
 	LastName  string `json:"last_name" validate:"required"`
+	Role      string `json:"role" example:"support"` // defaults to read-only, other roles require users:roles
+}
+
+// AcceptInvitationRequest sets the password of an invited admin
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest replaces a forgotten password with the mailed token
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8,max=72"`
+}
+
+// SetUserRoleRequest replaces the role of an admin
+type SetUserRoleRequest struct {
+	Role string `json:"role" example:"billing" validate:"required"`
+}
+
+// RoleResponse is a role and the permissions it grants
+type RoleResponse struct {
+	Name        string   `json:"name" example:"billing"`
+	Permissions []string `json:"permissions" example:"plans:read,plans:write"`
 }
 
 type ListUsersResponse struct {
@@ -48,6 +131,60 @@ type PlanResponse struct {
 	EndDate   string `json:"endDate" example:"2025-12-31"`
 }
 
+// AssignPlanRequest purchases a plan for a user for the given term
+type AssignPlanRequest struct {
+	PlanID    uint   `json:"plan_id" example:"2" validate:"required"`
+	Months    int    `json:"months" example:"6" validate:"gte=0"` // must match a plan price, defaults to 1
+	AutoRenew bool   `json:"auto_renew" example:"true"`           // renew for the same term when it ends
+	Pending   bool   `json:"pending" example:"false"`             // the term starts on POST /users/{id}/plans/activate
+	Reason    string `json:"reason" example:"annual contract"`
+}
+
+// StartTrialRequest starts the trial of a plan for a user
+type StartTrialRequest struct {
+	PlanID uint   `json:"plan_id" example:"2" validate:"required"`
+	Reason string `json:"reason" example:"sales demo"`
+}
+
+// AutoRenewRequest turns auto-renewal of a user's plan on or off
+type AutoRenewRequest struct {
+	Enabled bool `json:"enabled" example:"true"`
+}
+
+// ChangePlanRequest moves a user to another plan mid-term
+type ChangePlanRequest struct {
+	PlanID         uint   `json:"plan_id" example:"3" validate:"required"`
+	DeferDowngrade bool   `json:"defer_downgrade" example:"true"` // apply downgrades at the end of the current term
+	Reason         string `json:"reason" example:"customer request"`
+}
+
+// ScheduleChangeRequest schedules a plan change or cancellation of a user's plan
+type ScheduleChangeRequest struct {
+	Action      string    `json:"action" example:"change" validate:"required,oneof=change cancel"`
+	PlanID      uint      `json:"plan_id" example:"1" validate:"required_if=Action change"`
+	EffectiveAt time.Time `json:"effective_at"` // omit for the end of the current term
+	Reason      string    `json:"reason" example:"switch to Basic next month"`
+}
+
+// PlanTransitionRequest explains a lifecycle change of a user's plan
+type PlanTransitionRequest struct {
+	Reason string `json:"reason" example:"payment overdue"`
+}
+
+// PlanLimitationRequest sets the quota value of a limitation on a plan
+type PlanLimitationRequest struct {
+	LimitationID uint `json:"limitation_id" example:"1" validate:"required"` // taken from the path on updates
+	Value        int  `json:"value" example:"1000" validate:"gte=0"`
+	UnitPrice    int  `json:"unit_price" example:"50" validate:"gte=0"`
+	TrialValue   *int `json:"trial_value,omitempty" example:"100" validate:"omitempty,gte=0"` // quota during a trial, Value when omitted
+}
+
+// PlanPriceRequest sets the price of buying a plan for a term
+type PlanPriceRequest struct {
+	Months int   `json:"months" example:"6" validate:"gte=1"`
+	Price  int64 `json:"price" example:"500000" validate:"gte=0"`
+}
+
 // Error response
 type Error struct {
 	Code    int    `json:"code" example:"400"`
@@ -56,4 +193,39 @@ type Error struct {
 
 type ToggleUserActiveRequest struct {
     Active bool `json:"active"`
-}
\ No newline at end of file
+}
+// AuditLogResponse is a recorded admin mutation
+type AuditLogResponse struct {
+	ID         uint                   `json:"id"`
+	UserID     uint                   `json:"user_id"`
+	Action     string                 `json:"action" example:"PUT /api/plans/:id"`
+	EntityType string                 `json:"entity_type" example:"plan"`
+	EntityID   uint                   `json:"entity_id"`
+	RequestID  string                 `json:"request_id"`
+	IPAddress  string                 `json:"ip_address"`
+	UserAgent  string                 `json:"user_agent"`
+	Metadata   map[string]interface{} `json:"metadata"`
+	Before     map[string]interface{} `json:"before,omitempty"`
+	After      map[string]interface{} `json:"after,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+type ListAuditLogsResponse struct {
+	Logs       []AuditLogResponse `json:"logs"`
+	Pagination Pagination         `json:"pagination"`
+}
+
+// LoginEventResponse is a recorded login attempt
+type LoginEventResponse struct {
+	ID        uint      `json:"id"`
+	Email     string    `json:"email"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	Outcome   string    `json:"outcome" example:"invalid_password"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ListLoginEventsResponse struct {
+	Events     []LoginEventResponse `json:"events"`
+	Pagination Pagination           `json:"pagination"`
+}
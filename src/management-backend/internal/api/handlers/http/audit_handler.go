@@ -0,0 +1,110 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/api/dto"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/audit/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/audit/port"
+)
+
+type AuditHandler struct {
+	service port.Service
+}
+
+func NewAuditHandler(s port.Service) *AuditHandler {
+	return &AuditHandler{service: s}
+}
+
+// @Summary      List the audit log of admin mutations (paginated + filter), newest first
+// @Tags         audit
+// @Produce      json
+// @Param        page         query  int     false  "Page number"
+// @Param        limit        query  int     false  "Page size"
+// @Param        user_id      query  int     false  "Only mutations of this admin"
+// @Param        action       query  string  false  "Only this action, e.g. PUT /api/plans/:id"
+// @Param        entity_type  query  string  false  "Only this entity type: user, plan or limitation"
+// @Param        entity_id    query  int     false  "Only this entity"
+// @Param        request_id   query  string  false  "Only this request"
+// @Param        from         query  string  false  "RFC 3339 time, only mutations at or after it"
+// @Param        to           query  string  false  "RFC 3339 time, only mutations before it"
+// @Success      200  {object}  dto.ListAuditLogsResponse
+// @Failure      default  {object}  dto.Error
+// @Router       /audit-logs [get]
+func (h *AuditHandler) ListAuditLogs(c echo.Context) error {
+	filter := &domain.AuditFilter{
+		Action:     c.QueryParam("action"),
+		EntityType: c.QueryParam("entity_type"),
+		RequestID:  c.QueryParam("request_id"),
+		Page:       parseQueryParamInt(c, "page", 1),
+		Size:       parseQueryParamInt(c, "limit", domain.DefaultPageSize),
+	}
+
+	var err error
+	if filter.UserID, err = parseUintQuery(c, "user_id"); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid user_id"})
+	}
+	if filter.EntityID, err = parseUintQuery(c, "entity_id"); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid entity_id"})
+	}
+	if filter.From, err = parseTimeQuery(c, "from"); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "from must be an RFC 3339 time"})
+	}
+	if filter.To, err = parseTimeQuery(c, "to"); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "to must be an RFC 3339 time"})
+	}
+
+	list, err := h.service.List(c.Request().Context(), filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": "Failed to fetch audit logs"})
+	}
+
+	logs := make([]dto.AuditLogResponse, len(list.Logs))
+	for i, l := range list.Logs {
+		logs[i] = dto.AuditLogResponse{
+			ID:         l.ID,
+			UserID:     l.UserID,
+			Action:     l.Action,
+			EntityType: l.EntityType,
+			EntityID:   l.EntityID,
+			RequestID:  l.RequestID,
+			IPAddress:  l.IPAddress,
+			UserAgent:  l.UserAgent,
+			Metadata:   l.Metadata,
+			Before:     l.Before,
+			After:      l.After,
+			CreatedAt:  l.CreatedAt,
+		}
+	}
+
+	return c.JSON(http.StatusOK, dto.ListAuditLogsResponse{
+		Logs: logs,
+		Pagination: dto.Pagination{
+			Page:  filter.Page,
+			Limit: filter.Size,
+			Total: int(list.Total),
+		},
+	})
+}
+
+// parseUintQuery returns 0 for a missing query param
+func parseUintQuery(c echo.Context, name string) (uint, error) {
+	param := c.QueryParam(name)
+	if param == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseUint(param, 10, 32)
+	return uint(v), err
+}
+
+// parseTimeQuery returns the zero time for a missing query param
+func parseTimeQuery(c echo.Context, name string) (time.Time, error) {
+	param := c.QueryParam(name)
+	if param == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, param)
+}
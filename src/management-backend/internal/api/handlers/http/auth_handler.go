@@ -2,37 +2,48 @@ package http
 
 import (
 	"errors"
-	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
-	"github.com/arcaptcha/arcaptcha-go"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 
-	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/config"
+	admin "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/domain"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/port"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/api/dto"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/auth"
+	authD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/auth/domain"
+	authP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/auth/port"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/security"
+	securityD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/security/domain"
+	securityP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/security/port"
 )
 
 var ErrCaptchaFailed = errors.New("captcha failed")
 
 type AuthHandler struct {
-	service   port.Service
-	cfg       config.JWTConfig
-	arcaptcha config.ArcaptchaConfig
+	service  port.Service
+	auth     authP.Service
+	security securityP.Service
+	captcha  securityP.CaptchaVerifier
 }
 
-func NewAuthHandler(s port.Service, c config.JWTConfig, a config.ArcaptchaConfig) *AuthHandler {
-	return &AuthHandler{service: s, cfg: c, arcaptcha: a}
+func NewAuthHandler(s port.Service, auth authP.Service, sec securityP.Service, captcha securityP.CaptchaVerifier) *AuthHandler {
+	return &AuthHandler{service: s, auth: auth, security: sec, captcha: captcha}
 }
 
 // @Summary      User login with captcha
+// @Description  Admins with two-factor authentication get a challenge to complete with /auth/login/2fa instead of tokens.
+// @Description  The captcha token may be left out until the account or IP failed LOGIN_CAPTCHA_AFTER logins.
 // @Tags         user
 // @Accept       json
 // @Produce      json
 // @Param        loginRequest  body  dto.LoginRequest true "Login credentials"
 // @Success      200  {object}  dto.LoginResponse
+// @Success      202  {object}  dto.TwoFactorChallengeResponse  "A TOTP code is required"
+// @Failure      429  {object}  dto.Error  "Too many failed logins of the account or IP, see Retry-After"
 // @Failure      default  {object}  dto.Error
 // @Router       /auth/login [post]
 func (h *AuthHandler) Login(c echo.Context) error {
@@ -41,51 +52,419 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
 	}
 
-	if err := h.arcaptchaVerify(req.CaptchaToken); err != nil {
-		code := http.StatusUnauthorized
-		return c.JSON(code, &dto.Error{Code: code, Message: ErrCaptchaFailed.Error()})
+	ctx := c.Request().Context()
+	attempt := loginAttempt(c, req.Email)
+	if wait, err := h.security.Check(ctx, attempt); err != nil {
+		return loginRefused(c, wait, err)
 	}
 
-	user, err := h.service.Authenticate(c.Request().Context(), req.Email, req.Password)
+	required, err := h.security.CaptchaRequired(ctx, attempt)
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, map[string]interface{}{"error": "Invalid credentials"})
+		return c.JSON(http.StatusServiceUnavailable, map[string]interface{}{"error": "Authentication unavailable"})
+	}
+	if required {
+		solved, err := h.captcha.Verify(ctx, req.CaptchaToken)
+		if err != nil {
+			return c.JSON(http.StatusServiceUnavailable, map[string]interface{}{"error": "Captcha verification unavailable"})
+		}
+		if !solved {
+			// not counted, or anyone could lock an account without solving captchas
+			h.security.Record(ctx, attempt, securityD.OutcomeCaptchaFailed)
+			code := http.StatusUnauthorized
+			return c.JSON(code, &dto.Error{Code: code, Message: ErrCaptchaFailed.Error()})
+		}
 	}
 
-	token := jwt.New(jwt.SigningMethodHS256)
-	claims := token.Claims.(jwt.MapClaims)
-	claims["userID"] = user.ID
-	claims["email"] = user.Email
-	claims["role"] = user.Role
-	claims["exp"] = time.Now().Add(time.Hour * time.Duration(h.cfg.Expiration)).Unix()
+	user, err := h.service.Authenticate(ctx, req.Email, req.Password)
+	if errors.Is(err, admin.ErrInvalidCredentials) {
+		return h.failLogin(c, attempt, credentialsOutcome(err), "Invalid credentials")
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": "Failed to log in"})
+	}
 
-	tokenString, err := token.SignedString([]byte(h.cfg.Secret))
+	challenge, err := h.service.LoginChallenge(ctx, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": "Failed to start two-factor authentication"})
+	}
+	if challenge != nil {
+		// the failures are only forgotten once the second factor is verified
+		h.security.Record(ctx, attempt, securityD.OutcomeChallenged)
+		res := dto.TwoFactorChallengeResponse{ChallengeToken: challenge.Token, ExpiresAt: challenge.ExpiresAt}
+		if challenge.Setup != nil {
+			res.Setup = &dto.TwoFactorSetupResponse{Secret: challenge.Setup.Secret, OTPAuthURL: challenge.Setup.URL}
+		}
+		return c.JSON(http.StatusAccepted, res)
+	}
+	h.security.Succeed(ctx, attempt)
+
+	pair, err := h.auth.Issue(ctx, user)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": "Failed to generate token"})
 	}
 
-	response := dto.LoginResponse{
-		Token: tokenString,
+	return c.JSON(http.StatusOK, loginResponse(pair, user))
+}
+
+// @Summary      Complete a login with a TOTP or recovery code
+// @Description  The challenge is single-use, after a wrong code the admin logs in again. A login completing an enrollment returns the recovery codes.
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Param        request  body  dto.VerifyTwoFactorRequest  true  "Challenge of the login and a code"
+// @Success      200  {object}  dto.LoginResponse
+// @Failure      429  {object}  dto.Error  "Too many failed logins of the IP, see Retry-After"
+// @Failure      default  {object}  dto.Error
+// @Router       /auth/login/2fa [post]
+func (h *AuthHandler) VerifyTwoFactor(c echo.Context) error {
+	var req dto.VerifyTwoFactorRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
+	}
+	if ok, err := validateRequest(c, req); !ok {
+		return err
+	}
+
+	// the account is only known once the challenge is, it was checked by the password step
+	ctx := c.Request().Context()
+	attempt := loginAttempt(c, "")
+	if wait, err := h.security.Check(ctx, attempt); err != nil {
+		return loginRefused(c, wait, err)
+	}
+
+	user, codes, err := h.service.VerifyLogin(ctx, req.ChallengeToken, req.Code, req.RecoveryCode)
+	if user != nil {
+		attempt.Email = user.Email
+	}
+	switch {
+	case errors.Is(err, admin.ErrInvalidToken):
+		return h.failLogin(c, attempt, securityD.OutcomeInvalidChallenge, "Challenge expired or already used, log in again")
+	case errors.Is(err, admin.ErrInvalidCode):
+		return h.failLogin(c, attempt, securityD.OutcomeInvalidCode, err.Error())
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": "Failed to verify code"})
+	}
+	h.security.Succeed(ctx, attempt)
+
+	pair, err := h.auth.Issue(ctx, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": "Failed to generate token"})
+	}
+
+	res := loginResponse(pair, user)
+	res.RecoveryCodes = codes
+	return c.JSON(http.StatusOK, res)
+}
+
+// @Summary      Exchange a refresh token for new access and refresh tokens
+// @Description  Refresh tokens rotate, presenting one that was already exchanged ends its session
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Param        request  body  dto.RefreshRequest  true  "Refresh token"
+// @Success      200  {object}  dto.LoginResponse
+// @Failure      default  {object}  dto.Error
+// @Router       /auth/refresh [post]
+func (h *AuthHandler) Refresh(c echo.Context) error {
+	var req dto.RefreshRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
+	}
+	if ok, err := validateRequest(c, req); !ok {
+		return err
+	}
+
+	pair, user, err := h.auth.Refresh(c.Request().Context(), req.RefreshToken)
+	switch {
+	case errors.Is(err, auth.ErrRefreshReused):
+		return c.JSON(http.StatusUnauthorized, map[string]interface{}{"error": err.Error()})
+	case errors.Is(err, auth.ErrInvalidToken):
+		return c.JSON(http.StatusUnauthorized, map[string]interface{}{"error": "Invalid refresh token"})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": "Failed to refresh token"})
+	}
+
+	return c.JSON(http.StatusOK, loginResponse(pair, user))
+}
+
+// @Summary      End the session of the access token, or every session of the user
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Param        request  body  dto.LogoutRequest  false  "Whether to end every session"
+// @Success      200  {string}  string  "Logged out"
+// @Failure      default  {object}  dto.Error
+// @Router       /auth/logout [post]
+func (h *AuthHandler) Logout(c echo.Context) error {
+	var req dto.LogoutRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
+	}
+	claims, ok := c.Get("claims").(*authD.Claims)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]interface{}{"error": "Invalid token"})
+	}
+
+	if err := h.auth.Logout(c.Request().Context(), claims, req.All); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": "Failed to log out"})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"message": "Logged out"})
+}
+
+func loginAttempt(c echo.Context, email string) *securityD.Attempt {
+	return &securityD.Attempt{Email: email, IPAddress: c.RealIP(), UserAgent: c.Request().UserAgent()}
+}
+
+// failLogin counts the failed attempt and answers 401 with message
+func (h *AuthHandler) failLogin(c echo.Context, attempt *securityD.Attempt, outcome, message string) error {
+	if err := h.security.Fail(c.Request().Context(), attempt, outcome); err != nil {
+		return c.JSON(http.StatusServiceUnavailable, map[string]interface{}{"error": "Authentication unavailable"})
+	}
+	return c.JSON(http.StatusUnauthorized, map[string]interface{}{"error": message})
+}
+
+// loginRefused answers a throttled or locked login, logins are refused as well while
+// the failed login counters can not be read
+func loginRefused(c echo.Context, wait time.Duration, err error) error {
+	if !errors.Is(err, security.ErrThrottled) && !errors.Is(err, security.ErrLocked) {
+		return c.JSON(http.StatusServiceUnavailable, map[string]interface{}{"error": "Authentication unavailable"})
+	}
+	c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	return c.JSON(http.StatusTooManyRequests, map[string]interface{}{"error": err.Error()})
+}
+
+// credentialsOutcome is the login event outcome of an ErrInvalidCredentials
+func credentialsOutcome(err error) string {
+	switch {
+	case errors.Is(err, admin.ErrUserNotFound):
+		return securityD.OutcomeUnknownUser
+	case errors.Is(err, admin.ErrUserDeactivated):
+		return securityD.OutcomeDeactivated
+	}
+	return securityD.OutcomeInvalidPassword
+}
+
+func loginResponse(pair *authD.TokenPair, user *domain.AdminUser) dto.LoginResponse {
+	return dto.LoginResponse{
+		Token:            pair.AccessToken,
+		ExpiresAt:        pair.AccessExpiresAt,
+		RefreshToken:     pair.RefreshToken,
+		RefreshExpiresAt: pair.RefreshExpiresAt,
 		User: dto.UserResponse{
-			ID:        user.ID,
-			Email:     user.Email,
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-			Role:      user.Role,
-			CreatedAt: user.CreatedAt,
+			ID:               user.ID,
+			Email:            user.Email,
+			FirstName:        user.FirstName,
+			LastName:         user.LastName,
+			Role:             user.Role,
+			CreatedAt:        user.CreatedAt,
+			TwoFactorEnabled: user.TOTPEnabled,
 		},
 	}
+}
+
+// @Summary      Accept an invitation by setting a password
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Param        request  body  dto.AcceptInvitationRequest  true  "Mailed token and new password"
+// @Success      200  {string}  string  "Password set"
+// @Failure      default  {object}  dto.Error
+// @Router       /auth/invitations/accept [post]
+func (h *AuthHandler) AcceptInvitation(c echo.Context) error {
+	var req dto.AcceptInvitationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
+	}
+	if ok, err := validateRequest(c, req); !ok {
+		return err
+	}
 
-	return c.JSON(http.StatusOK, response)
+	if err := h.service.AcceptInvitation(c.Request().Context(), req.Token, req.Password); err != nil {
+		return tokenError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"message": "Password set, you can log in now"})
 }
 
-func (h *AuthHandler) arcaptchaVerify(token string) error {
-	website := arcaptcha.NewWebsite(h.arcaptcha.SiteKey, h.arcaptcha.SecretKey)
-	res, err := website.Verify(token)
+// @Summary      Mail a password reset link
+// @Description  Always succeeds for a valid email so the response does not tell which emails are admins
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Param        request  body  dto.ForgotPasswordRequest  true  "Admin email"
+// @Success      200  {string}  string  "Reset link sent if the email is an admin"
+// @Failure      default  {object}  dto.Error
+// @Router       /auth/password/forgot [post]
+func (h *AuthHandler) ForgotPassword(c echo.Context) error {
+	var req dto.ForgotPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
+	}
+	if ok, err := validateRequest(c, req); !ok {
+		return err
+	}
+
+	if err := h.service.RequestPasswordReset(c.Request().Context(), req.Email); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": "Failed to send reset link"})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"message": "If the email belongs to an admin, a reset link was sent"})
+}
+
+// @Summary      Reset a forgotten password with the mailed token
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Param        request  body  dto.ResetPasswordRequest  true  "Mailed token and new password"
+// @Success      200  {string}  string  "Password reset"
+// @Failure      default  {object}  dto.Error
+// @Router       /auth/password/reset [post]
+func (h *AuthHandler) ResetPassword(c echo.Context) error {
+	var req dto.ResetPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
+	}
+	if ok, err := validateRequest(c, req); !ok {
+		return err
+	}
+
+	if err := h.service.ResetPassword(c.Request().Context(), req.Token, req.Password); err != nil {
+		return tokenError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"message": "Password reset, you can log in now"})
+}
+
+// @Summary      Change the password of the logged in user
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Param        request  body  dto.ChangePasswordRequest  true  "Current and new password"
+// @Success      200  {string}  string  "Password changed"
+// @Failure      default  {object}  dto.Error
+// @Router       /auth/password [put]
+func (h *AuthHandler) ChangePassword(c echo.Context) error {
+	var req dto.ChangePasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
+	}
+	if ok, err := validateRequest(c, req); !ok {
+		return err
+	}
+
+	err := h.service.ChangePassword(c.Request().Context(), currentUserID(c), req.CurrentPassword, req.NewPassword)
+	switch {
+	case errors.Is(err, admin.ErrInvalidPassword):
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Current password is wrong"})
+	case errors.Is(err, admin.ErrUserNotFound):
+		return c.JSON(http.StatusNotFound, map[string]interface{}{"error": "User not found"})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": "Failed to change password"})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"message": "Password changed"})
+}
+
+// @Summary      Start enrolling the logged in user in two-factor authentication
+// @Description  Returns the secret to add to an authenticator app, /auth/2fa/enable completes the enrollment
+// @Tags         user
+// @Produce      json
+// @Success      200  {object}  dto.TwoFactorSetupResponse
+// @Failure      default  {object}  dto.Error
+// @Router       /auth/2fa/setup [post]
+func (h *AuthHandler) SetupTwoFactor(c echo.Context) error {
+	setup, err := h.service.SetupTwoFactor(c.Request().Context(), currentUserID(c))
+	if err != nil {
+		return twoFactorError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.TwoFactorSetupResponse{Secret: setup.Secret, OTPAuthURL: setup.URL})
+}
+
+// @Summary      Enable two-factor authentication with a code of the authenticator app
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Param        request  body  dto.TwoFactorCodeRequest  true  "TOTP code"
+// @Success      200  {object}  dto.RecoveryCodesResponse
+// @Failure      default  {object}  dto.Error
+// @Router       /auth/2fa/enable [post]
+func (h *AuthHandler) EnableTwoFactor(c echo.Context) error {
+	var req dto.TwoFactorCodeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
+	}
+	if ok, err := validateRequest(c, req); !ok {
+		return err
+	}
+
+	codes, err := h.service.EnableTwoFactor(c.Request().Context(), currentUserID(c), req.Code)
+	if err != nil {
+		return twoFactorError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// @Summary      Disable two-factor authentication, unless the role requires it
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Param        request  body  dto.DisableTwoFactorRequest  true  "Current password"
+// @Success      200  {string}  string  "Two-factor authentication disabled"
+// @Failure      default  {object}  dto.Error
+// @Router       /auth/2fa/disable [post]
+func (h *AuthHandler) DisableTwoFactor(c echo.Context) error {
+	var req dto.DisableTwoFactorRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
+	}
+	if ok, err := validateRequest(c, req); !ok {
+		return err
+	}
+
+	if err := h.service.DisableTwoFactor(c.Request().Context(), currentUserID(c), req.Password); err != nil {
+		return twoFactorError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"message": "Two-factor authentication disabled"})
+}
+
+// @Summary      Replace the recovery codes of the logged in user
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Param        request  body  dto.TwoFactorCodeRequest  true  "TOTP code"
+// @Success      200  {object}  dto.RecoveryCodesResponse
+// @Failure      default  {object}  dto.Error
+// @Router       /auth/2fa/recovery-codes [post]
+func (h *AuthHandler) RegenerateRecoveryCodes(c echo.Context) error {
+	var req dto.TwoFactorCodeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
+	}
+	if ok, err := validateRequest(c, req); !ok {
+		return err
+	}
+
+	codes, err := h.service.RegenerateRecoveryCodes(c.Request().Context(), currentUserID(c), req.Code)
 	if err != nil {
-		return fmt.Errorf("%w: %w", ErrCaptchaFailed, err)
+		return twoFactorError(c, err)
 	}
-	if !res.Success {
-		return ErrCaptchaFailed
+	return c.JSON(http.StatusOK, dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func twoFactorError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, admin.ErrUserNotFound):
+		return c.JSON(http.StatusNotFound, map[string]interface{}{"error": "User not found"})
+	case errors.Is(err, admin.ErrInvalidCode):
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+	case errors.Is(err, admin.ErrInvalidPassword):
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Password is wrong"})
+	case errors.Is(err, admin.ErrTwoFactorEnabled), errors.Is(err, admin.ErrTwoFactorDisabled),
+		errors.Is(err, admin.ErrTwoFactorNotSetUp), errors.Is(err, admin.ErrTwoFactorRequired):
+		return c.JSON(http.StatusConflict, map[string]interface{}{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": "Failed to update two-factor authentication"})
+}
+
+func tokenError(c echo.Context, err error) error {
+	if errors.Is(err, admin.ErrInvalidToken) {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
 	}
-	return nil
+	return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": "Failed to set password"})
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/swaggo/echo-swagger"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/app"
 	_ "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/docs"
+	adminD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/domain"
 	mw "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/api/middleware"
 )
 
@@ -15,6 +16,9 @@ type Handler struct {
 	auth *AuthHandler
 	user *UserHandler
 	plan *PlanHandler
+	lim  *LimitationHandler
+	aud  *AuditHandler
+	sec  *SecurityHandler
 }
 
 // @title           Arcaptcha Internship Project API
@@ -26,9 +30,12 @@ func NewHandler(a app.App) *Handler {
 	return &Handler{
 		app:  a,
 		echo: echo.New(),
-		auth: NewAuthHandler(a.UserService(), a.Config().JWT, a.Config().Arcaptcha),
+		auth: NewAuthHandler(a.UserService(), a.AuthService(), a.SecurityService(), a.CaptchaVerifier()),
 		user: NewUserHandler(a.UserService()),
 		plan: NewPlanHandler(a.PlanService()),
+		lim:  NewLimitationHandler(a.PlanService()),
+		aud:  NewAuditHandler(a.AuditService()),
+		sec:  NewSecurityHandler(a.SecurityService(), a.UserService()),
 	}
 }
 
@@ -42,26 +49,81 @@ func (h *Handler) SetupRoutes() *echo.Echo {
 	//public routes
 	e.GET("api/swagger/*", echoSwagger.WrapHandler)
 	e.POST("/api/auth/login", h.auth.Login)
+	e.POST("/api/auth/login/2fa", h.auth.VerifyTwoFactor)
+	e.POST("/api/auth/refresh", h.auth.Refresh)
+	e.POST("/api/auth/invitations/accept", h.auth.AcceptInvitation)
+	e.POST("/api/auth/password/forgot", h.auth.ForgotPassword)
+	e.POST("/api/auth/password/reset", h.auth.ResetPassword)
 
 	//protected routes
 	api := e.Group("/api")
 	api.Use(mw.NewAuthMiddleware(h.app).ValidateJWT())
+	api.Use(mw.Audit(h.app.AuditService(), h.app.Logger()))
+	can := mw.RequirePermission
+
+	//account routes, open to every role
+	api.PUT("/auth/password", h.auth.ChangePassword)
+	api.POST("/auth/logout", h.auth.Logout)
+	api.POST("/auth/2fa/setup", h.auth.SetupTwoFactor)
+	api.POST("/auth/2fa/enable", h.auth.EnableTwoFactor)
+	api.POST("/auth/2fa/disable", h.auth.DisableTwoFactor)
+	api.POST("/auth/2fa/recovery-codes", h.auth.RegenerateRecoveryCodes)
 
 	//user routes
-	api.GET("/users", h.user.ListUsers)
-	api.POST("/users", h.user.CreateUser)
-	api.GET("/users/:id", h.user.GetUser)
-	api.PUT("/users/:id", h.user.UpdateUser)
-	api.PATCH("/users/:id/toggle-active", h.user.ToggleUserActive)
-	api.DELETE("/users/:id", h.user.DeleteUser)
+	api.GET("/users", h.user.ListUsers, can(adminD.PermUsersRead))
+	api.POST("/users", h.user.CreateUser, can(adminD.PermUsersWrite))
+	api.GET("/users/:id", h.user.GetUser, can(adminD.PermUsersRead))
+	api.PUT("/users/:id/role", h.user.SetUserRole, can(adminD.PermUsersRoles))
+	api.POST("/users/:id/invitation", h.user.ResendInvitation, can(adminD.PermUsersWrite))
+	api.DELETE("/users/:id/2fa", h.user.ResetTwoFactor, can(adminD.PermUsersTwoFactor))
+	api.DELETE("/users/:id/lockout", h.sec.UnlockUser, can(adminD.PermUsersDeactivate))
+	api.PUT("/users/:id", h.user.UpdateUser, can(adminD.PermUsersWrite))
+	api.PATCH("/users/:id/toggle-active", h.user.ToggleUserActive, can(adminD.PermUsersDeactivate))
+	api.DELETE("/users/:id", h.user.DeleteUser, can(adminD.PermUsersWrite))
+	api.POST("/users/:id/plans", h.plan.AssignPlan, can(adminD.PermSubscriptionsWrite))
+	api.POST("/users/:id/trial", h.plan.StartTrial, can(adminD.PermSubscriptionsWrite))
+	api.POST("/users/:id/plans/change", h.plan.ChangeUserPlan, can(adminD.PermSubscriptionsWrite))
+	api.PUT("/users/:id/plans/auto-renew", h.plan.SetAutoRenew, can(adminD.PermSubscriptionsWrite))
+	api.POST("/users/:id/plans/activate", h.plan.ActivateUserPlan, can(adminD.PermSubscriptionsWrite))
+	api.POST("/users/:id/plans/suspend", h.plan.SuspendUserPlan, can(adminD.PermSubscriptionsWrite))
+	api.POST("/users/:id/plans/resume", h.plan.ResumeUserPlan, can(adminD.PermSubscriptionsWrite))
+	api.POST("/users/:id/plans/cancel", h.plan.CancelUserPlan, can(adminD.PermSubscriptionsWrite))
+	api.GET("/users/:id/plan-history", h.plan.GetPlanHistory, can(adminD.PermSubscriptionsRead))
+	api.GET("/users/:id/scheduled-changes", h.plan.ListScheduledChanges, can(adminD.PermSubscriptionsRead))
+	api.POST("/users/:id/scheduled-changes", h.plan.ScheduleChange, can(adminD.PermSubscriptionsWrite))
+	api.DELETE("/users/:id/scheduled-changes/:changeId", h.plan.RevokeScheduledChange, can(adminD.PermSubscriptionsWrite))
+	api.GET("/users/:id/usage-statement", h.plan.GetUsageStatement, can(adminD.PermSubscriptionsRead))
 
 	//plan routes
-	api.GET("/plans", h.plan.ListPlans)
-	api.POST("/plans", h.plan.CreatePlan)
-	api.GET("/plans/:id", h.plan.GetPlan)
-	api.PUT("/plans/:id", h.plan.UpdatePlan)
-	api.PATCH("/plans/:id/toggle-active", h.plan.TogglePlanActive)
-	api.DELETE("/plans/:id", h.plan.DeletePlan)
+	api.GET("/plans", h.plan.ListPlans, can(adminD.PermPlansRead))
+	api.POST("/plans", h.plan.CreatePlan, can(adminD.PermPlansWrite))
+	api.GET("/plans/:id", h.plan.GetPlan, can(adminD.PermPlansRead))
+	api.PUT("/plans/:id", h.plan.UpdatePlan, can(adminD.PermPlansWrite))
+	api.PATCH("/plans/:id/toggle-active", h.plan.TogglePlanActive, can(adminD.PermPlansWrite))
+	api.DELETE("/plans/:id", h.plan.DeletePlan, can(adminD.PermPlansWrite))
+	api.GET("/plans/:id/prices", h.plan.ListPlanPrices, can(adminD.PermPlansRead))
+	api.POST("/plans/:id/prices", h.plan.SetPlanPrice, can(adminD.PermPlansWrite))
+	api.PUT("/plans/:id/prices/:months", h.plan.UpdatePlanPrice, can(adminD.PermPlansWrite))
+	api.DELETE("/plans/:id/prices/:months", h.plan.DeletePlanPrice, can(adminD.PermPlansWrite))
+
+	//limitation routes
+	api.GET("/limitations", h.lim.ListLimitations, can(adminD.PermLimitationsRead))
+	api.POST("/limitations", h.lim.CreateLimitation, can(adminD.PermLimitationsWrite))
+	api.PUT("/limitations/:id", h.lim.UpdateLimitation, can(adminD.PermLimitationsWrite))
+	api.DELETE("/limitations/:id", h.lim.DeleteLimitation, can(adminD.PermLimitationsWrite))
+
+	//plan quota routes
+	api.GET("/plans/:id/limitations", h.lim.ListPlanLimitations, can(adminD.PermPlansRead))
+	api.POST("/plans/:id/limitations", h.lim.AssignLimitationToPlan, can(adminD.PermPlansWrite))
+	api.PUT("/plans/:id/limitations/:limitationId", h.lim.UpdatePlanLimitation, can(adminD.PermPlansWrite))
+	api.DELETE("/plans/:id/limitations/:limitationId", h.lim.RemoveLimitationFromPlan, can(adminD.PermPlansWrite))
+
+	//role routes
+	api.GET("/roles", h.user.ListRoles, can(adminD.PermUsersRead))
+
+	//audit routes
+	api.GET("/audit-logs", h.aud.ListAuditLogs, can(adminD.PermAuditRead))
+	api.GET("/login-events", h.sec.ListLoginEvents, can(adminD.PermAuditRead))
 
 	return e
 }
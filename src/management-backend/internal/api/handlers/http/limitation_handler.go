@@ -0,0 +1,234 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/api/dto"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/plan/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/plan/port"
+)
+
+type LimitationHandler struct {
+	service port.Service
+}
+
+func NewLimitationHandler(s port.Service) *LimitationHandler {
+	return &LimitationHandler{service: s}
+}
+
+// @Summary      List limitations
+// @Tags         limitation
+// @Produce      json
+// @Success      200  {array}  domain.Limitation
+// @Failure      default  {object}  dto.Error
+// @Router       /limitations [get]
+func (h *LimitationHandler) ListLimitations(c echo.Context) error {
+	limitations, err := h.service.ListLimitations(c.Request().Context())
+	if err != nil {
+		return upstreamError(c, err, "Failed to fetch limitations")
+	}
+
+	return c.JSON(http.StatusOK, limitations)
+}
+
+// @Summary      Create limitation
+// @Tags         limitation
+// @Accept       json
+// @Produce      json
+// @Param        limitation  body  domain.Limitation  true  "Limitation object"
+// @Success      201  {object}  domain.Limitation
+// @Failure      default  {object}  dto.Error
+// @Router       /limitations [post]
+func (h *LimitationHandler) CreateLimitation(c echo.Context) error {
+	var limitation domain.Limitation
+	if err := c.Bind(&limitation); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
+	}
+	if ok, err := validateRequest(c, limitation); !ok {
+		return err
+	}
+
+	if err := h.service.CreateLimitation(c.Request().Context(), &limitation); err != nil {
+		return upstreamError(c, err, err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, limitation)
+}
+
+// @Summary      Update limitation
+// @Tags         limitation
+// @Accept       json
+// @Produce      json
+// @Param        id          path  string             true  "Limitation ID"
+// @Param        limitation  body  domain.Limitation  true  "Limitation object"
+// @Success      200  {object}  domain.Limitation
+// @Failure      default  {object}  dto.Error
+// @Router       /limitations/{id} [put]
+func (h *LimitationHandler) UpdateLimitation(c echo.Context) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid limitation ID"})
+	}
+
+	var limitation domain.Limitation
+	if err := c.Bind(&limitation); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
+	}
+	limitation.ID = id
+	if ok, err := validateRequest(c, limitation); !ok {
+		return err
+	}
+
+	if err := h.service.UpdateLimitation(c.Request().Context(), &limitation); err != nil {
+		return upstreamError(c, err, "Failed to update limitation")
+	}
+
+	return c.JSON(http.StatusOK, limitation)
+}
+
+// @Summary      Delete limitation
+// @Tags         limitation
+// @Produce      json
+// @Param        id  path  string  true  "Limitation ID"
+// @Success      200  {string}  string  "Limitation deleted"
+// @Failure      default  {object}  dto.Error
+// @Router       /limitations/{id} [delete]
+func (h *LimitationHandler) DeleteLimitation(c echo.Context) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid limitation ID"})
+	}
+
+	if err := h.service.DeleteLimitation(c.Request().Context(), id); err != nil {
+		return upstreamError(c, err, "Failed to delete limitation")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"message": "Limitation deleted successfully"})
+}
+
+// @Summary      List quotas of a plan
+// @Tags         limitation
+// @Produce      json
+// @Param        id  path  string  true  "Plan ID"
+// @Success      200  {array}  domain.PlanLimitation
+// @Failure      default  {object}  dto.Error
+// @Router       /plans/{id}/limitations [get]
+func (h *LimitationHandler) ListPlanLimitations(c echo.Context) error {
+	planID, err := parseUintParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid plan ID"})
+	}
+
+	planLimitations, err := h.service.GetPlanLimitations(c.Request().Context(), planID)
+	if err != nil {
+		return upstreamError(c, err, "Failed to fetch plan limitations")
+	}
+
+	return c.JSON(http.StatusOK, planLimitations)
+}
+
+// @Summary      Attach a limitation to a plan
+// @Tags         limitation
+// @Accept       json
+// @Produce      json
+// @Param        id     path  string                     true  "Plan ID"
+// @Param        quota  body  dto.PlanLimitationRequest  true  "Limitation and quota value"
+// @Success      201  {object}  domain.PlanLimitation
+// @Failure      default  {object}  dto.Error
+// @Router       /plans/{id}/limitations [post]
+func (h *LimitationHandler) AssignLimitationToPlan(c echo.Context) error {
+	planID, err := parseUintParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid plan ID"})
+	}
+
+	var req dto.PlanLimitationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
+	}
+	if ok, err := validateRequest(c, req); !ok {
+		return err
+	}
+
+	planLimitation := &domain.PlanLimitation{
+		PlanID:     planID,
+		Limitation: domain.Limitation{ID: req.LimitationID},
+		Value:      req.Value,
+		UnitPrice:  req.UnitPrice,
+		TrialValue: req.TrialValue,
+	}
+	if err := h.service.AssignLimitationToPlan(c.Request().Context(), planLimitation); err != nil {
+		return upstreamError(c, err, err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, planLimitation)
+}
+
+// @Summary      Update the quota of a limitation on a plan
+// @Tags         limitation
+// @Accept       json
+// @Produce      json
+// @Param        id            path  string                     true  "Plan ID"
+// @Param        limitationId  path  string                     true  "Limitation ID"
+// @Param        quota         body  dto.PlanLimitationRequest  true  "Quota value"
+// @Success      200  {object}  domain.PlanLimitation
+// @Failure      default  {object}  dto.Error
+// @Router       /plans/{id}/limitations/{limitationId} [put]
+func (h *LimitationHandler) UpdatePlanLimitation(c echo.Context) error {
+	planID, err := parseUintParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid plan ID"})
+	}
+	limitationID, err := parseUintParam(c, "limitationId")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid limitation ID"})
+	}
+
+	var req dto.PlanLimitationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
+	}
+	req.LimitationID = limitationID
+	if ok, err := validateRequest(c, req); !ok {
+		return err
+	}
+
+	planLimitation := &domain.PlanLimitation{
+		PlanID:     planID,
+		Limitation: domain.Limitation{ID: limitationID},
+		Value:      req.Value,
+		UnitPrice:  req.UnitPrice,
+		TrialValue: req.TrialValue,
+	}
+	if err := h.service.UpdatePlanLimitation(c.Request().Context(), planLimitation); err != nil {
+		return upstreamError(c, err, "Failed to update plan limitation")
+	}
+
+	return c.JSON(http.StatusOK, planLimitation)
+}
+
+// @Summary      Detach a limitation from a plan
+// @Tags         limitation
+// @Produce      json
+// @Param        id            path  string  true  "Plan ID"
+// @Param        limitationId  path  string  true  "Limitation ID"
+// @Success      200  {string}  string  "Limitation removed"
+// @Failure      default  {object}  dto.Error
+// @Router       /plans/{id}/limitations/{limitationId} [delete]
+func (h *LimitationHandler) RemoveLimitationFromPlan(c echo.Context) error {
+	planID, err := parseUintParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid plan ID"})
+	}
+	limitationID, err := parseUintParam(c, "limitationId")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid limitation ID"})
+	}
+
+	if err := h.service.RemoveLimitationFromPlan(c.Request().Context(), planID, limitationID); err != nil {
+		return upstreamError(c, err, "Failed to remove limitation from plan")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"message": "Limitation removed from plan successfully"})
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/plan/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/plan/port"
+)
+
+// limitations answers every limitation call of LimitationHandler with err
+type limitations struct {
+	port.Service
+	err      error
+	assigned []*domain.PlanLimitation
+}
+
+func (s *limitations) UpdateLimitation(context.Context, *domain.Limitation) error { return s.err }
+func (s *limitations) DeleteLimitation(context.Context, uint) error               { return s.err }
+
+func (s *limitations) AssignLimitationToPlan(_ context.Context, pl *domain.PlanLimitation) error {
+	s.assigned = append(s.assigned, pl)
+	return s.err
+}
+
+func (s *limitations) UpdatePlanLimitation(context.Context, *domain.PlanLimitation) error {
+	return s.err
+}
+
+func (s *limitations) RemoveLimitationFromPlan(context.Context, uint, uint) error { return s.err }
+
+func TestLimitationHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name, method, target, body string
+		err                        error
+		code                       int
+	}{
+		{"unknown limitation", http.MethodPut, "/limitations/9", `{"title":"calls"}`,
+			status.Error(codes.NotFound, "record not found"), http.StatusNotFound},
+		{"unknown limitation deleted", http.MethodDelete, "/limitations/9", "",
+			status.Error(codes.NotFound, "record not found"), http.StatusNotFound},
+		{"unknown pair", http.MethodPut, "/plans/1/limitations/9", `{"value":10}`,
+			status.Error(codes.NotFound, "record not found"), http.StatusNotFound},
+		{"unknown pair removed", http.MethodDelete, "/plans/1/limitations/9", "",
+			status.Error(codes.NotFound, "record not found"), http.StatusNotFound},
+		{"rejected upstream", http.MethodPost, "/plans/1/limitations", `{"limitation_id":2,"value":10}`,
+			status.Error(codes.InvalidArgument, "plan and limitation ids are required"), http.StatusBadRequest},
+		{"missing limitation", http.MethodPost, "/plans/1/limitations", `{"value":10}`,
+			nil, http.StatusBadRequest},
+		{"failure", http.MethodDelete, "/limitations/9", "",
+			errors.New("connection refused"), http.StatusInternalServerError},
+		{"update takes the limitation from the path", http.MethodPut, "/plans/1/limitations/9", `{"value":10}`,
+			nil, http.StatusOK},
+	}
+	for _, tt := range tests {
+		s := &limitations{err: tt.err}
+		h := NewLimitationHandler(s)
+		e := echo.New()
+		e.PUT("/limitations/:id", h.UpdateLimitation)
+		e.DELETE("/limitations/:id", h.DeleteLimitation)
+		e.POST("/plans/:id/limitations", h.AssignLimitationToPlan)
+		e.PUT("/plans/:id/limitations/:limitationId", h.UpdatePlanLimitation)
+		e.DELETE("/plans/:id/limitations/:limitationId", h.RemoveLimitationFromPlan)
+
+		req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, tt.code, rec.Code, "%s: %s", tt.name, rec.Body)
+		if tt.name == "missing limitation" {
+			assert.Empty(t, s.assigned, "an assignment without a limitation is not sent")
+		}
+	}
+}
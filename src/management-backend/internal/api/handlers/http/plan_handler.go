@@ -1,11 +1,13 @@
 package http
 
 import (
-	"fmt"
+	"context"
 	"net/http"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/api/dto"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/plan/domain"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/plan/port"
 )
@@ -25,18 +27,8 @@ func (h *PlanHandler) CreatePlan(c echo.Context) error {
 	if err := c.Bind(&plan); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
 	}
-	if err := Validate.Struct(plan); err != nil {
-		validationErrors := err.(validator.ValidationErrors)
-
-		errors := make(map[string]string)
-		for _, e := range validationErrors {
-			errors[e.Field()] = fmt.Sprintf("failed on '%s' validation", e.Tag())
-		}
-
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"error":  "validation failed",
-			"fields": errors,
-		})
+	if ok, err := validateRequest(c, plan); !ok {
+		return err
 	}
 
 	if err := h.service.CreatePlan(c.Request().Context(), &plan); err != nil {
@@ -121,6 +113,150 @@ func (h *PlanHandler) DeletePlan(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]interface{}{"message": "Plan deleted successfully"})
 }
 
+// @Summary      List the prices of a plan
+// @Tags         plan
+// @Produce      json
+// @Param        id  path  string  true  "Plan ID"
+// @Success      200  {array}  domain.PlanPrice
+// @Failure      default  {object}  dto.Error
+// @Router       /plans/{id}/prices [get]
+func (h *PlanHandler) ListPlanPrices(c echo.Context) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid plan ID"})
+	}
+
+	prices, err := h.service.ListPlanPrices(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": "Failed to fetch plan prices"})
+	}
+
+	return c.JSON(http.StatusOK, prices)
+}
+
+// @Summary      Set the price of a plan for a term
+// @Tags         plan
+// @Accept       json
+// @Produce      json
+// @Param        id     path  string                true  "Plan ID"
+// @Param        price  body  dto.PlanPriceRequest  true  "Term and price"
+// @Success      201  {object}  domain.PlanPrice
+// @Failure      default  {object}  dto.Error
+// @Router       /plans/{id}/prices [post]
+func (h *PlanHandler) SetPlanPrice(c echo.Context) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid plan ID"})
+	}
+
+	var req dto.PlanPriceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
+	}
+	if ok, err := validateRequest(c, req); !ok {
+		return err
+	}
+
+	price := &domain.PlanPrice{Months: req.Months, Price: req.Price}
+	if err := h.service.SetPlanPrice(c.Request().Context(), id, price); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, price)
+}
+
+// @Summary      Update the price of a plan term
+// @Tags         plan
+// @Accept       json
+// @Produce      json
+// @Param        id      path  string                true  "Plan ID"
+// @Param        months  path  string                true  "Term in months"
+// @Param        price   body  dto.PlanPriceRequest  true  "New price, months is taken from the path"
+// @Success      200  {object}  domain.PlanPrice
+// @Failure      default  {object}  dto.Error
+// @Router       /plans/{id}/prices/{months} [put]
+func (h *PlanHandler) UpdatePlanPrice(c echo.Context) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid plan ID"})
+	}
+	months, err := parseUintParam(c, "months")
+	if err != nil || months == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid term"})
+	}
+
+	var req dto.PlanPriceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
+	}
+	req.Months = int(months)
+	if ok, err := validateRequest(c, req); !ok {
+		return err
+	}
+
+	price := &domain.PlanPrice{Months: req.Months, Price: req.Price}
+	if err := h.service.SetPlanPrice(c.Request().Context(), id, price); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, price)
+}
+
+// @Summary      Delete the price of a plan term
+// @Tags         plan
+// @Produce      json
+// @Param        id      path  string  true  "Plan ID"
+// @Param        months  path  string  true  "Term in months"
+// @Success      200  {string}  string  "Price deleted"
+// @Failure      default  {object}  dto.Error
+// @Router       /plans/{id}/prices/{months} [delete]
+func (h *PlanHandler) DeletePlanPrice(c echo.Context) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid plan ID"})
+	}
+	months, err := parseUintParam(c, "months")
+	if err != nil || months == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid term"})
+	}
+
+	if err := h.service.DeletePlanPrice(c.Request().Context(), id, int(months)); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": "Failed to delete plan price"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"message": "Plan price deleted successfully"})
+}
+
+// @Summary      Get the PAYG usage statement of a user
+// @Tags         plan
+// @Produce      json
+// @Param        id      path   string  true   "User ID"
+// @Param        period  query  string  false  "Any date within the billing period (YYYY-MM-DD), defaults to the current period"
+// @Success      200  {object}  domain.UsageStatement
+// @Failure      default  {object}  dto.Error
+// @Router       /users/{id}/usage-statement [get]
+func (h *PlanHandler) GetUsageStatement(c echo.Context) error {
+	userID, err := parseUintParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid user ID"})
+	}
+
+	var period time.Time
+	if p := c.QueryParam("period"); p != "" {
+		period, err = time.Parse(time.DateOnly, p)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid period, expected YYYY-MM-DD"})
+		}
+	}
+
+	statement, err := h.service.GetUsageStatement(c.Request().Context(), userID, period)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": "Failed to fetch usage statement"})
+	}
+
+	return c.JSON(http.StatusOK, statement)
+}
+
 // @Summary      Get active plans of a user
 // @Tags         plan
 // @Produce      json
@@ -135,11 +271,314 @@ func (h *PlanHandler) UserPlan(c echo.Context) error { return nil }
 // @Accept       json
 // @Produce      json
 // @Param        id    path  string     true  "User ID"
-// @Param        plan  body  dto.PlanResponse true  "Plan object"
-// @Success      201  {object}  dto.PlanResponse
+// @Param        plan  body  dto.AssignPlanRequest true  "Plan and term"
+// @Success      201  {string}  string  "Plan assigned"
 // @Failure      default  {object}  dto.Error
 // @Router       /users/{id}/plans [post]
-func (h *PlanHandler) AssignPlan(c echo.Context) error { return nil }
+func (h *PlanHandler) AssignPlan(c echo.Context) error {
+	userID, err := parseUintParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid user ID"})
+	}
+
+	var req dto.AssignPlanRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
+	}
+	if ok, err := validateRequest(c, req); !ok {
+		return err
+	}
+
+	change := domain.PlanChange{By: actor(c), Reason: req.Reason}
+	assignment := domain.PlanAssignment{PlanID: req.PlanID, Months: req.Months, AutoRenew: req.AutoRenew, Pending: req.Pending}
+	if err := h.service.AssignPlan(c.Request().Context(), userID, assignment, change); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{"message": "Plan assigned successfully"})
+}
+
+// @Summary      Start the trial of a plan for a user
+// @Description  A user gets one trial per email and oauth ID and must not have a plan
+// @Tags         plan
+// @Accept       json
+// @Produce      json
+// @Param        id     path  string                 true  "User ID"
+// @Param        trial  body  dto.StartTrialRequest  true  "Trial plan"
+// @Success      201  {string}  string  "Trial started"
+// @Failure      default  {object}  dto.Error
+// @Router       /users/{id}/trial [post]
+func (h *PlanHandler) StartTrial(c echo.Context) error {
+	userID, err := parseUintParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid user ID"})
+	}
+
+	var req dto.StartTrialRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
+	}
+	if ok, err := validateRequest(c, req); !ok {
+		return err
+	}
+
+	change := domain.PlanChange{By: actor(c), Reason: req.Reason}
+	if err := h.service.StartTrial(c.Request().Context(), userID, req.PlanID, change); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{"message": "Trial started successfully"})
+}
+
+// @Summary      Turn auto-renewal of a user's plan on or off
+// @Tags         plan
+// @Accept       json
+// @Produce      json
+// @Param        id       path  string                true  "User ID"
+// @Param        setting  body  dto.AutoRenewRequest  true  "Auto-renew setting"
+// @Success      200  {string}  string  "Auto-renew updated"
+// @Failure      default  {object}  dto.Error
+// @Router       /users/{id}/plans/auto-renew [put]
+func (h *PlanHandler) SetAutoRenew(c echo.Context) error {
+	userID, err := parseUintParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid user ID"})
+	}
+
+	var req dto.AutoRenewRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
+	}
+
+	if err := h.service.SetAutoRenew(c.Request().Context(), userID, req.Enabled); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"message": "Auto-renew updated successfully"})
+}
+
+// @Summary      Upgrade or downgrade a user's plan mid-term
+// @Tags         plan
+// @Accept       json
+// @Produce      json
+// @Param        id      path  string                 true  "User ID"
+// @Param        change  body  dto.ChangePlanRequest  true  "Target plan"
+// @Success      200  {object}  domain.PlanChangeResult
+// @Failure      default  {object}  dto.Error
+// @Router       /users/{id}/plans/change [post]
+func (h *PlanHandler) ChangeUserPlan(c echo.Context) error {
+	userID, err := parseUintParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid user ID"})
+	}
+
+	var req dto.ChangePlanRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
+	}
+	if ok, err := validateRequest(c, req); !ok {
+		return err
+	}
+
+	change := domain.PlanChange{By: actor(c), Reason: req.Reason}
+	result, err := h.service.ChangeUserPlan(c.Request().Context(), userID, req.PlanID, req.DeferDowngrade, change)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// @Summary      Activate a user's pending plan, starting its term
+// @Tags         plan
+// @Accept       json
+// @Produce      json
+// @Param        id      path  string                     true  "User ID"
+// @Param        change  body  dto.PlanTransitionRequest  false "Reason of the change"
+// @Success      200  {string}  string  "Plan activated"
+// @Failure      default  {object}  dto.Error
+// @Router       /users/{id}/plans/activate [post]
+func (h *PlanHandler) ActivateUserPlan(c echo.Context) error {
+	return h.transitionUserPlan(c, h.service.ActivateUserPlan, "Plan activated successfully")
+}
+
+// @Summary      Suspend a user's plan
+// @Tags         plan
+// @Accept       json
+// @Produce      json
+// @Param        id      path  string                     true  "User ID"
+// @Param        change  body  dto.PlanTransitionRequest  false "Reason of the change"
+// @Success      200  {string}  string  "Plan suspended"
+// @Failure      default  {object}  dto.Error
+// @Router       /users/{id}/plans/suspend [post]
+func (h *PlanHandler) SuspendUserPlan(c echo.Context) error {
+	return h.transitionUserPlan(c, h.service.SuspendUserPlan, "Plan suspended successfully")
+}
+
+// @Summary      Resume a suspended user plan
+// @Tags         plan
+// @Accept       json
+// @Produce      json
+// @Param        id      path  string                     true  "User ID"
+// @Param        change  body  dto.PlanTransitionRequest  false "Reason of the change"
+// @Success      200  {string}  string  "Plan resumed"
+// @Failure      default  {object}  dto.Error
+// @Router       /users/{id}/plans/resume [post]
+func (h *PlanHandler) ResumeUserPlan(c echo.Context) error {
+	return h.transitionUserPlan(c, h.service.ResumeUserPlan, "Plan resumed successfully")
+}
+
+// @Summary      Cancel a user's plan
+// @Tags         plan
+// @Accept       json
+// @Produce      json
+// @Param        id      path  string                     true  "User ID"
+// @Param        change  body  dto.PlanTransitionRequest  false "Reason of the change"
+// @Success      200  {string}  string  "Plan canceled"
+// @Failure      default  {object}  dto.Error
+// @Router       /users/{id}/plans/cancel [post]
+func (h *PlanHandler) CancelUserPlan(c echo.Context) error {
+	return h.transitionUserPlan(c, h.service.CancelUserPlan, "Plan canceled successfully")
+}
+
+func (h *PlanHandler) transitionUserPlan(c echo.Context,
+	transition func(ctx context.Context, userID uint, change domain.PlanChange) error, message string,
+) error {
+	userID, err := parseUintParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid user ID"})
+	}
+
+	var req dto.PlanTransitionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
+	}
+
+	change := domain.PlanChange{By: actor(c), Reason: req.Reason}
+	if err := transition(c.Request().Context(), userID, change); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"message": message})
+}
+
+// @Summary      Schedule a plan change or cancellation
+// @Tags         plan
+// @Accept       json
+// @Produce      json
+// @Param        id      path  string                     true  "User ID"
+// @Param        change  body  dto.ScheduleChangeRequest  true  "Scheduled change"
+// @Success      201  {object}  domain.ScheduledChange
+// @Failure      default  {object}  dto.Error
+// @Router       /users/{id}/scheduled-changes [post]
+func (h *PlanHandler) ScheduleChange(c echo.Context) error {
+	userID, err := parseUintParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid user ID"})
+	}
+
+	var req dto.ScheduleChangeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
+	}
+	if ok, err := validateRequest(c, req); !ok {
+		return err
+	}
+
+	scheduled, err := h.service.ScheduleChange(c.Request().Context(), userID, &domain.ScheduledChange{
+		Action:      req.Action,
+		PlanID:      req.PlanID,
+		EffectiveAt: req.EffectiveAt,
+		ChangedBy:   actor(c),
+		Reason:      req.Reason,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, scheduled)
+}
+
+// @Summary      List pending scheduled changes of a user's plan
+// @Tags         plan
+// @Produce      json
+// @Param        id  path  string  true  "User ID"
+// @Success      200  {array}  domain.ScheduledChange
+// @Failure      default  {object}  dto.Error
+// @Router       /users/{id}/scheduled-changes [get]
+func (h *PlanHandler) ListScheduledChanges(c echo.Context) error {
+	userID, err := parseUintParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid user ID"})
+	}
+
+	changes, err := h.service.ListScheduledChanges(c.Request().Context(), userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": "Failed to fetch scheduled changes"})
+	}
+
+	return c.JSON(http.StatusOK, changes)
+}
+
+// @Summary      Revoke a pending scheduled change
+// @Tags         plan
+// @Produce      json
+// @Param        id        path  string  true  "User ID"
+// @Param        changeId  path  string  true  "Scheduled change ID"
+// @Success      200  {string}  string  "Scheduled change revoked"
+// @Failure      default  {object}  dto.Error
+// @Router       /users/{id}/scheduled-changes/{changeId} [delete]
+func (h *PlanHandler) RevokeScheduledChange(c echo.Context) error {
+	userID, err := parseUintParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid user ID"})
+	}
+	changeID, err := parseUintParam(c, "changeId")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid scheduled change ID"})
+	}
+
+	if err := h.service.RevokeScheduledChange(c.Request().Context(), userID, changeID); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": "Failed to revoke scheduled change"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"message": "Scheduled change revoked successfully"})
+}
+
+// @Summary      Get the lifecycle history of a user's plans
+// @Tags         plan
+// @Produce      json
+// @Param        id            path   string  true   "User ID"
+// @Param        action        query  string  false  "Only entries of this action"
+// @Param        metadata_key  query  string  false  "Only entries whose metadata has this key"
+// @Param        metadata      query  string  false  "JSON object, only entries whose metadata contains its keys and values"
+// @Success      200  {array}  domain.PlanHistory
+// @Failure      default  {object}  dto.Error
+// @Router       /users/{id}/plan-history [get]
+func (h *PlanHandler) GetPlanHistory(c echo.Context) error {
+	userID, err := parseUintParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid user ID"})
+	}
+
+	filter := domain.PlanHistoryFilter{
+		UserID:      userID,
+		Action:      c.QueryParam("action"),
+		MetadataKey: c.QueryParam("metadata_key"),
+	}
+	if metadata := c.QueryParam("metadata"); metadata != "" {
+		if err := filter.Metadata.Scan(metadata); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "metadata must be a JSON object"})
+		}
+	}
+
+	history, err := h.service.GetPlanHistory(c.Request().Context(), filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": "Failed to fetch plan history"})
+	}
+
+	return c.JSON(http.StatusOK, history)
+}
 
 // @Summary      Renew a user's plan
 // @Tags         plan
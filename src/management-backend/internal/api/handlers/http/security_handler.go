@@ -0,0 +1,101 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/port"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/api/dto"
+	securityD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/security/domain"
+	securityP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/security/port"
+)
+
+type SecurityHandler struct {
+	service securityP.Service
+	users   port.Service
+}
+
+func NewSecurityHandler(s securityP.Service, users port.Service) *SecurityHandler {
+	return &SecurityHandler{service: s, users: users}
+}
+
+// @Summary      List the login attempts (paginated + filter), newest first
+// @Tags         security
+// @Produce      json
+// @Param        page     query  int     false  "Page number"
+// @Param        limit    query  int     false  "Page size"
+// @Param        email    query  string  false  "Only attempts for this email"
+// @Param        ip       query  string  false  "Only attempts from this IP"
+// @Param        outcome  query  string  false  "Only this outcome, e.g. success, invalid_password, locked"
+// @Param        from     query  string  false  "RFC 3339 time, only attempts at or after it"
+// @Param        to       query  string  false  "RFC 3339 time, only attempts before it"
+// @Success      200  {object}  dto.ListLoginEventsResponse
+// @Failure      default  {object}  dto.Error
+// @Router       /login-events [get]
+func (h *SecurityHandler) ListLoginEvents(c echo.Context) error {
+	filter := &securityD.LoginEventFilter{
+		Email:     c.QueryParam("email"),
+		IPAddress: c.QueryParam("ip"),
+		Outcome:   c.QueryParam("outcome"),
+		Page:      parseQueryParamInt(c, "page", 1),
+		Size:      parseQueryParamInt(c, "limit", securityD.DefaultPageSize),
+	}
+
+	var err error
+	if filter.From, err = parseTimeQuery(c, "from"); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "from must be an RFC 3339 time"})
+	}
+	if filter.To, err = parseTimeQuery(c, "to"); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "to must be an RFC 3339 time"})
+	}
+
+	list, err := h.service.ListEvents(c.Request().Context(), filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": "Failed to fetch login events"})
+	}
+
+	events := make([]dto.LoginEventResponse, len(list.Events))
+	for i, e := range list.Events {
+		events[i] = dto.LoginEventResponse{
+			ID:        e.ID,
+			Email:     e.Email,
+			IPAddress: e.IPAddress,
+			UserAgent: e.UserAgent,
+			Outcome:   e.Outcome,
+			CreatedAt: e.CreatedAt,
+		}
+	}
+
+	return c.JSON(http.StatusOK, dto.ListLoginEventsResponse{
+		Events: events,
+		Pagination: dto.Pagination{
+			Page:  filter.Page,
+			Limit: filter.Size,
+			Total: int(list.Total),
+		},
+	})
+}
+
+// @Summary      Unlock the login of a user locked out by failed logins
+// @Description  Forgets the failed logins of the account, failures counted against IPs are kept
+// @Tags         security
+// @Produce      json
+// @Param        id  path  string  true  "User ID"
+// @Success      200  {string}  string  "Login unlocked"
+// @Failure      default  {object}  dto.Error
+// @Router       /users/{id}/lockout [delete]
+func (h *SecurityHandler) UnlockUser(c echo.Context) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid user ID"})
+	}
+
+	user, err := h.users.GetUserByID(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{"error": "User not found"})
+	}
+	if err := h.service.Unlock(c.Request().Context(), user.Email); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": "Failed to unlock login"})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"message": "Login unlocked"})
+}
@@ -1,9 +1,11 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
+	admin "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/domain"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/port"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/api/dto"
@@ -17,7 +19,7 @@ func NewUserHandler(s port.Service) *UserHandler {
 	return &UserHandler{service: s}
 }
 
-// @Summary      Create new user
+// @Summary      Invite a new user, who sets their password from the mailed link
 // @Tags         user
 // @Accept       json
 // @Produce      json
@@ -30,27 +32,46 @@ func (h *UserHandler) CreateUser(c echo.Context) error {
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
 	}
-
-	//generating a temp password (but i think we should send via email)
-	tempPassword := "TempPassword123!" // todo: generate secure random password
+	if ok, err := validateRequest(c, req); !ok {
+		return err
+	}
+	if req.Role != "" && req.Role != domain.RoleReadOnly && !domain.HasPermission(currentRole(c), domain.PermUsersRoles) {
+		return c.JSON(http.StatusForbidden, map[string]interface{}{
+			"error":      "Permission denied",
+			"permission": domain.PermUsersRoles,
+		})
+	}
 
 	user := &domain.AdminUser{
 		Email:     req.Email,
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
+		Role:      req.Role,
 	}
 
-	if err := h.service.CreateUser(c.Request().Context(), user, tempPassword); err != nil {
+	if err := h.service.InviteUser(c.Request().Context(), user); err != nil {
+		switch {
+		case errors.Is(err, admin.ErrMailNotSent):
+			return c.JSON(http.StatusBadGateway, map[string]interface{}{
+				"error": "User created but the invitation could not be mailed, resend it",
+				"id":    user.ID,
+			})
+		case errors.Is(err, admin.ErrInvalidRole):
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		case errors.Is(err, admin.ErrUserAlreadyExists):
+			return c.JSON(http.StatusConflict, map[string]interface{}{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": err.Error()})
 	}
 
 	response := dto.UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Role:      user.Role,
-		CreatedAt: user.CreatedAt,
+		ID:               user.ID,
+		Email:            user.Email,
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+		Role:             user.Role,
+		CreatedAt:        user.CreatedAt,
+		TwoFactorEnabled: user.TOTPEnabled,
 	}
 
 	return c.JSON(http.StatusCreated, response)
@@ -86,12 +107,13 @@ func (h *UserHandler) ListUsers(c echo.Context) error {
 	userResponses := make([]dto.UserResponse, len(users))
 	for i, user := range users {
 		userResponses[i] = dto.UserResponse{
-			ID:        user.ID,
-			Email:     user.Email,
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-			Role:      user.Role,
-			CreatedAt: user.CreatedAt,
+			ID:               user.ID,
+			Email:            user.Email,
+			FirstName:        user.FirstName,
+			LastName:         user.LastName,
+			Role:             user.Role,
+			CreatedAt:        user.CreatedAt,
+			TwoFactorEnabled: user.TOTPEnabled,
 		}
 	}
 
@@ -129,12 +151,13 @@ func (h *UserHandler) GetUser(c echo.Context) error {
 	}
 
 	response := dto.UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Role:      user.Role,
-		CreatedAt: user.CreatedAt,
+		ID:               user.ID,
+		Email:            user.Email,
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+		Role:             user.Role,
+		CreatedAt:        user.CreatedAt,
+		TwoFactorEnabled: user.TOTPEnabled,
 	}
 
 	return c.JSON(http.StatusOK, response)
@@ -159,6 +182,9 @@ func (h *UserHandler) UpdateUser(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, map[string]interface{}{"error": "User not found"})
 	}
+	if !domain.Covers(currentRole(c), user.Role) {
+		return c.JSON(http.StatusForbidden, map[string]interface{}{"error": "User has permissions you lack"})
+	}
 
 	var updateData struct {
 		FirstName string `json:"first_name"`
@@ -184,12 +210,13 @@ func (h *UserHandler) UpdateUser(c echo.Context) error {
 	}
 
 	response := dto.UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Role:      user.Role,
-		CreatedAt: user.CreatedAt,
+		ID:               user.ID,
+		Email:            user.Email,
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+		Role:             user.Role,
+		CreatedAt:        user.CreatedAt,
+		TwoFactorEnabled: user.TOTPEnabled,
 	}
 
 	return c.JSON(http.StatusOK, response)
@@ -210,7 +237,22 @@ func (h *UserHandler) ToggleUserActive(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid user ID"})
 	}
 
-	if err := h.service.ToggleUserActive(c.Request().Context(), id); err != nil {
+	ctx := c.Request().Context()
+	user, err := h.service.GetUserByID(ctx, id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{"error": "User not found"})
+	}
+	if !domain.Covers(currentRole(c), user.Role) {
+		return c.JSON(http.StatusForbidden, map[string]interface{}{"error": "User has permissions you lack"})
+	}
+
+	if err := h.service.ToggleUserActive(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, admin.ErrUserNotFound):
+			return c.JSON(http.StatusNotFound, map[string]interface{}{"error": "User not found"})
+		case errors.Is(err, admin.ErrLastSuperAdmin):
+			return c.JSON(http.StatusConflict, map[string]interface{}{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": "Failed to toggle user status"})
 	}
 
@@ -223,9 +265,143 @@ func (h *UserHandler) DeleteUser(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid user ID"})
 	}
 
-	if err := h.service.DeleteUser(c.Request().Context(), id); err != nil {
+	ctx := c.Request().Context()
+	user, err := h.service.GetUserByID(ctx, id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{"error": "User not found"})
+	}
+	if !domain.Covers(currentRole(c), user.Role) {
+		return c.JSON(http.StatusForbidden, map[string]interface{}{"error": "User has permissions you lack"})
+	}
+
+	if err := h.service.DeleteUser(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, admin.ErrUserNotFound):
+			return c.JSON(http.StatusNotFound, map[string]interface{}{"error": "User not found"})
+		case errors.Is(err, admin.ErrLastSuperAdmin):
+			return c.JSON(http.StatusConflict, map[string]interface{}{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": "Failed to delete user"})
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{"message": "User deleted successfully"})
 }
+
+// @Summary      Change the role of a user
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                  true  "User ID"
+// @Param        role  body  dto.SetUserRoleRequest  true  "New role"
+// @Success      200  {object}  dto.UserResponse
+// @Failure      default  {object}  dto.Error
+// @Router       /users/{id}/role [put]
+func (h *UserHandler) SetUserRole(c echo.Context) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid user ID"})
+	}
+
+	var req dto.SetUserRoleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
+	}
+	if ok, err := validateRequest(c, req); !ok {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.SetUserRole(ctx, id, req.Role); err != nil {
+		switch {
+		case errors.Is(err, admin.ErrInvalidRole):
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error(), "roles": domain.Roles()})
+		case errors.Is(err, admin.ErrUserNotFound):
+			return c.JSON(http.StatusNotFound, map[string]interface{}{"error": "User not found"})
+		case errors.Is(err, admin.ErrLastSuperAdmin):
+			return c.JSON(http.StatusConflict, map[string]interface{}{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": "Failed to change user role"})
+	}
+
+	user, err := h.service.GetUserByID(ctx, id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{"error": "User not found"})
+	}
+
+	return c.JSON(http.StatusOK, dto.UserResponse{
+		ID:               user.ID,
+		Email:            user.Email,
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+		Role:             user.Role,
+		CreatedAt:        user.CreatedAt,
+		TwoFactorEnabled: user.TOTPEnabled,
+	})
+}
+
+// @Summary      List the roles and their permissions
+// @Tags         user
+// @Produce      json
+// @Success      200  {array}  dto.RoleResponse
+// @Failure      default  {object}  dto.Error
+// @Router       /roles [get]
+func (h *UserHandler) ListRoles(c echo.Context) error {
+	roles := domain.Roles()
+	res := make([]dto.RoleResponse, len(roles))
+	for i, r := range roles {
+		perms := domain.Permissions(r)
+		res[i] = dto.RoleResponse{Name: r, Permissions: make([]string, len(perms))}
+		for j, p := range perms {
+			res[i].Permissions[j] = string(p)
+		}
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// @Summary      Reset the two-factor authentication of a user who lost their authenticator
+// @Description  Ends the sessions of the user, they enroll again on their next login if their role requires it
+// @Tags         user
+// @Produce      json
+// @Param        id  path  string  true  "User ID"
+// @Success      200  {string}  string  "Two-factor authentication reset"
+// @Failure      default  {object}  dto.Error
+// @Router       /users/{id}/2fa [delete]
+func (h *UserHandler) ResetTwoFactor(c echo.Context) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid user ID"})
+	}
+
+	if err := h.service.ResetTwoFactor(c.Request().Context(), id); err != nil {
+		return twoFactorError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"message": "Two-factor authentication reset"})
+}
+
+// @Summary      Mail a new invitation to a user who has not accepted theirs
+// @Tags         user
+// @Produce      json
+// @Param        id  path  string  true  "User ID"
+// @Success      200  {string}  string  "Invitation sent"
+// @Failure      default  {object}  dto.Error
+// @Router       /users/{id}/invitation [post]
+func (h *UserHandler) ResendInvitation(c echo.Context) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid user ID"})
+	}
+
+	if err := h.service.ResendInvitation(c.Request().Context(), id); err != nil {
+		switch {
+		case errors.Is(err, admin.ErrUserNotFound):
+			return c.JSON(http.StatusNotFound, map[string]interface{}{"error": "User not found"})
+		case errors.Is(err, admin.ErrInvitationAccepted):
+			return c.JSON(http.StatusConflict, map[string]interface{}{"error": err.Error()})
+		case errors.Is(err, admin.ErrMailNotSent):
+			return c.JSON(http.StatusBadGateway, map[string]interface{}{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": "Failed to resend invitation"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"message": "Invitation sent"})
+}
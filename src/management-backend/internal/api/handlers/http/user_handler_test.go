@@ -0,0 +1,132 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	admin "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/port"
+)
+
+// users implements the admin service calls of UserHandler on a map
+type users struct {
+	port.Service
+	byID    map[uint]*domain.AdminUser
+	deleted []uint
+	toggled []uint
+}
+
+func (s *users) GetUserByID(_ context.Context, id uint) (*domain.AdminUser, error) {
+	u, ok := s.byID[id]
+	if !ok {
+		return nil, admin.ErrUserNotFound
+	}
+	copied := *u
+	return &copied, nil
+}
+
+func (s *users) InviteUser(_ context.Context, user *domain.AdminUser) error {
+	if user.Role == "" {
+		user.Role = domain.RoleReadOnly
+	}
+	user.ID = uint(len(s.byID) + 1)
+	s.byID[user.ID] = user
+	return nil
+}
+
+func (s *users) UpdateUser(_ context.Context, user *domain.AdminUser) error {
+	s.byID[user.ID] = user
+	return nil
+}
+
+func (s *users) DeleteUser(_ context.Context, id uint) error {
+	s.deleted = append(s.deleted, id)
+	return nil
+}
+
+func (s *users) ToggleUserActive(_ context.Context, id uint) error {
+	s.toggled = append(s.toggled, id)
+	return nil
+}
+
+func serve(h echo.HandlerFunc, role, method, target, body string, params ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.Set("role", role)
+	if len(params) > 0 {
+		c.SetParamNames("id")
+		c.SetParamValues(params...)
+	}
+	_ = h(c)
+	return rec
+}
+
+func TestCreateUser_Role(t *testing.T) {
+	body := func(role string) string {
+		return `{"email":"new@example.com","first_name":"New","last_name":"Admin","role":"` + role + `"}`
+	}
+	tests := []struct {
+		caller, role string
+		code         int
+	}{
+		{domain.RoleSupport, "", http.StatusCreated},
+		{domain.RoleSupport, domain.RoleReadOnly, http.StatusCreated},
+		{domain.RoleSupport, domain.RoleSupport, http.StatusForbidden},
+		{domain.RoleSupport, domain.RoleSuperAdmin, http.StatusForbidden},
+		{domain.RoleSuperAdmin, domain.RoleSuperAdmin, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		s := &users{byID: map[uint]*domain.AdminUser{}}
+		rec := serve(NewUserHandler(s).CreateUser, tt.caller, http.MethodPost, "/users", body(tt.role))
+		assert.Equal(t, tt.code, rec.Code, "%s creating %q: %s", tt.caller, tt.role, rec.Body)
+		assert.Equal(t, tt.code == http.StatusCreated, len(s.byID) == 1, "%s creating %q", tt.caller, tt.role)
+	}
+}
+
+func TestUpdateToggleAndDeleteUser_Outranked(t *testing.T) {
+	s := &users{byID: map[uint]*domain.AdminUser{
+		1: {Email: "root@example.com", Role: domain.RoleSuperAdmin},
+		2: {Email: "viewer@example.com", Role: domain.RoleReadOnly},
+	}}
+	for id, u := range s.byID {
+		u.ID = id
+	}
+	h := NewUserHandler(s)
+	update := `{"email":"taken@example.com"}`
+
+	rec := serve(h.UpdateUser, domain.RoleSupport, http.MethodPut, "/users/1", update, "1")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, "root@example.com", s.byID[1].Email)
+	rec = serve(h.DeleteUser, domain.RoleSupport, http.MethodDelete, "/users/1", "", "1")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Empty(t, s.deleted)
+
+	rec = serve(h.UpdateUser, domain.RoleSupport, http.MethodPut, "/users/2", update, "2")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "taken@example.com", s.byID[2].Email)
+	rec = serve(h.DeleteUser, domain.RoleSupport, http.MethodDelete, "/users/2", "", "2")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []uint{2}, s.deleted)
+
+	rec = serve(h.ToggleUserActive, domain.RoleSupport, http.MethodPatch, "/users/1", `{"active":false}`, "1")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Empty(t, s.toggled)
+	rec = serve(h.ToggleUserActive, domain.RoleSupport, http.MethodPatch, "/users/2", `{"active":false}`, "2")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []uint{2}, s.toggled)
+	rec = serve(h.ToggleUserActive, domain.RoleSupport, http.MethodPatch, "/users/9", `{"active":false}`, "9")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve(h.DeleteUser, domain.RoleSuperAdmin, http.MethodDelete, "/users/1", "", "1")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = serve(h.DeleteUser, domain.RoleSupport, http.MethodDelete, "/users/9", "", "9")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -1,9 +1,14 @@
 package http
 
 import (
+	"fmt"
+	"net/http"
 	"strconv"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func parseUintParam(c echo.Context, paramName string) (uint, error) {
@@ -27,3 +32,57 @@ func parseQueryParamInt(c echo.Context, paramName string, defaultValue int) int
 	}
 	return value
 }
+
+// validateRequest runs struct validation and writes a 400 response listing the failed fields.
+// It returns false when the response has already been written.
+func validateRequest(c echo.Context, req interface{}) (bool, error) {
+	err := Validate.Struct(req)
+	if err == nil {
+		return true, nil
+	}
+
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return false, c.JSON(http.StatusBadRequest, map[string]interface{}{"error": "Invalid request"})
+	}
+
+	errors := make(map[string]string)
+	for _, e := range validationErrors {
+		errors[e.Field()] = fmt.Sprintf("failed on '%s' validation", e.Tag())
+	}
+
+	return false, c.JSON(http.StatusBadRequest, map[string]interface{}{
+		"error":  "validation failed",
+		"fields": errors,
+	})
+}
+
+// upstreamError writes the response of a failed userplan call, message is returned for internal errors
+func upstreamError(c echo.Context, err error, message string) error {
+	st := status.Convert(err)
+	switch st.Code() {
+	case codes.NotFound:
+		return c.JSON(http.StatusNotFound, map[string]interface{}{"error": st.Message()})
+	case codes.InvalidArgument:
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{"error": st.Message()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]interface{}{"error": message})
+}
+
+// currentUserID is the ID of the authenticated admin
+func currentUserID(c echo.Context) uint {
+	id, _ := c.Get("userID").(uint)
+	return id
+}
+
+// currentRole is the role of the authenticated admin
+func currentRole(c echo.Context) string {
+	role, _ := c.Get("role").(string)
+	return role
+}
+
+// actor identifies the authenticated admin for change records
+func actor(c echo.Context) string {
+	email, _ := c.Get("email").(string)
+	return email
+}
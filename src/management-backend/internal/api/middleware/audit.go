@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/audit"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/audit/port"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/common"
+)
+
+const auditWriteTimeout = 5 * time.Second
+
+// Audit records every mutating request with the admin of the JWT and the before/after snapshots
+// the services put in the request context, it must run after ValidateJWT
+func Audit(s port.Service, log *zap.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			switch c.Request().Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				return next(c)
+			}
+
+			ctx, entry := audit.WithEntry(c.Request().Context())
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			err := next(c)
+
+			record := newAuditLog(c, entry, err)
+			// the request context may be canceled once the response is written
+			wctx, cancel := context.WithTimeout(context.Background(), auditWriteTimeout)
+			defer cancel()
+			if werr := s.Record(wctx, record); werr != nil {
+				log.Error("Failed to record audit log", zap.Error(werr),
+					zap.String("request_id", record.RequestID), zap.String("action", record.Action))
+			}
+			return err
+		}
+	}
+}
+
+func newAuditLog(c echo.Context, entry *audit.Entry, err error) *common.AuditLog {
+	req := c.Request()
+	status := c.Response().Status
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		status = he.Code
+	} else if err != nil && !c.Response().Committed {
+		status = http.StatusInternalServerError
+	}
+
+	metadata := common.JSON{
+		"method": req.Method,
+		"path":   req.URL.Path,
+		"status": status,
+	}
+	if err != nil {
+		metadata["error"] = err.Error()
+	}
+
+	entityType, entityID := entry.EntityType, entry.EntityID
+	if entityType == "" {
+		entityType = routeEntity(c.Path())
+	}
+	if entityID == 0 {
+		if id, perr := strconv.ParseUint(c.Param("id"), 10, 32); perr == nil {
+			entityID = uint(id)
+		}
+	}
+
+	return &common.AuditLog{
+		UserID:     claimUserID(c.Get("userID")),
+		Action:     req.Method + " " + c.Path(),
+		EntityType: entityType,
+		EntityID:   entityID,
+		RequestID:  c.Response().Header().Get(echo.HeaderXRequestID), // needs middleware.RequestID
+		IPAddress:  c.RealIP(),
+		UserAgent:  req.UserAgent(),
+		Metadata:   metadata,
+		Before:     entry.Before,
+		After:      entry.After,
+	}
+}
+
+// routeEntity names the entity of a route by its first segment after /api, /api/plans/:id is a plan
+func routeEntity(path string) string {
+	segments := strings.Split(strings.TrimPrefix(path, "/api/"), "/")
+	return strings.TrimSuffix(segments[0], "s")
+}
+
+// claimUserID reads the userID claim, a number decoded as float64 from the JWT or already a uint
+func claimUserID(v interface{}) uint {
+	switch id := v.(type) {
+	case float64:
+		return uint(id)
+	case uint:
+		return id
+	case string:
+		n, _ := strconv.ParseUint(id, 10, 64)
+		return uint(n)
+	}
+	return 0
+}
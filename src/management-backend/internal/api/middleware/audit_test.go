@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/audit"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/audit/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/common"
+)
+
+type recorder struct {
+	logs []*common.AuditLog
+}
+
+func (r *recorder) Record(_ context.Context, log *common.AuditLog) error {
+	r.logs = append(r.logs, log)
+	return nil
+}
+
+func (r *recorder) List(context.Context, *domain.AuditFilter) (*domain.AuditLogList, error) {
+	return nil, nil
+}
+
+func TestAudit(t *testing.T) {
+	type plan struct {
+		Name     string `json:"name"`
+		IsActive bool   `json:"is_active"`
+	}
+
+	rec := &recorder{}
+	e := echo.New()
+	e.Use(middleware.RequestID())
+	api := e.Group("/api", func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("userID", float64(7)) // as decoded from the JWT claims
+			return next(c)
+		}
+	})
+	api.Use(Audit(rec, zap.NewNop()))
+	api.PATCH("/plans/:id/toggle-active", func(c echo.Context) error {
+		ctx := c.Request().Context()
+		audit.SetBefore(ctx, domain.EntityPlan, 3, plan{Name: "pro", IsActive: true})
+		audit.SetAfter(ctx, domain.EntityPlan, 3, plan{Name: "pro"})
+		return c.NoContent(http.StatusOK)
+	})
+	api.DELETE("/limitations/:id", func(c echo.Context) error {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{"error": "not found"})
+	})
+	api.GET("/plans/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPatch, "/api/plans/3/toggle-active", nil),
+		httptest.NewRequest(http.MethodDelete, "/api/limitations/5", nil),
+		httptest.NewRequest(http.MethodGet, "/api/plans/3", nil),
+	} {
+		e.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	require.Len(t, rec.logs, 2, "reads are not audited")
+
+	toggle := rec.logs[0]
+	assert.Equal(t, uint(7), toggle.UserID)
+	assert.Equal(t, "PATCH /api/plans/:id/toggle-active", toggle.Action)
+	assert.Equal(t, domain.EntityPlan, toggle.EntityType)
+	assert.Equal(t, uint(3), toggle.EntityID)
+	assert.NotEmpty(t, toggle.RequestID)
+	assert.Equal(t, true, toggle.Before["is_active"])
+	assert.Equal(t, false, toggle.After["is_active"])
+	assert.Equal(t, http.StatusOK, toggle.Metadata["status"])
+
+	del := rec.logs[1]
+	assert.Equal(t, domain.EntityLimitation, del.EntityType, "entity taken from the route without service hooks")
+	assert.Equal(t, uint(5), del.EntityID)
+	assert.Equal(t, http.StatusNotFound, del.Metadata["status"])
+	assert.Nil(t, del.Before)
+	assert.NotEqual(t, toggle.RequestID, del.RequestID)
+}
@@ -1,13 +1,15 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/app"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/auth"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
 )
 
 type AuthMiddleware struct {
@@ -18,6 +20,7 @@ func NewAuthMiddleware(a app.App) *AuthMiddleware {
 	return &AuthMiddleware{app: a}
 }
 
+// ValidateJWT rejects expired and revoked access tokens, and fails closed when the revocation list is unreachable
 func (m *AuthMiddleware) ValidateJWT() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -31,22 +34,21 @@ func (m *AuthMiddleware) ValidateJWT() echo.MiddlewareFunc {
 				return c.JSON(http.StatusUnauthorized, map[string]interface{}{"error": "Bearer token required"})
 			}
 
-			token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-				return []byte(m.app.Config().JWT.Secret), nil
-			})
-
-			if err != nil || !token.Valid {
+			claims, err := m.app.AuthService().Validate(c.Request().Context(), tokenString)
+			switch {
+			case errors.Is(err, auth.ErrTokenRevoked):
+				return c.JSON(http.StatusUnauthorized, map[string]interface{}{"error": "Token revoked"})
+			case errors.Is(err, auth.ErrInvalidToken):
 				return c.JSON(http.StatusUnauthorized, map[string]interface{}{"error": "Invalid token"})
+			case err != nil:
+				m.app.Logger().Error("Failed to check token revocation", zap.Error(err))
+				return c.JSON(http.StatusServiceUnavailable, map[string]interface{}{"error": "Authentication unavailable"})
 			}
 
-			claims, ok := token.Claims.(jwt.MapClaims)
-			if !ok {
-				return c.JSON(http.StatusUnauthorized, map[string]interface{}{"error": "Invalid token claims"})
-			}
-
-			c.Set("userID", claims["userID"])
-			c.Set("email", claims["email"])
-			c.Set("role", claims["role"])
+			c.Set("userID", claims.UserID)
+			c.Set("email", claims.Email)
+			c.Set("role", claims.Role)
+			c.Set("claims", claims)
 
 			return next(c)
 		}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/domain"
+)
+
+// RequirePermission rejects requests whose JWT role lacks perm, it must run after ValidateJWT
+func RequirePermission(perm domain.Permission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			role, _ := c.Get("role").(string)
+			if !domain.HasPermission(role, perm) {
+				return c.JSON(http.StatusForbidden, map[string]interface{}{
+					"error":      "Permission denied",
+					"permission": perm,
+				})
+			}
+			return next(c)
+		}
+	}
+}
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.36.7
+// 	protoc-gen-go v1.36.8
 // 	protoc        v3.12.4
 // source: userplan.proto
 
@@ -418,15 +418,20 @@ func (x *UserActivationRequest) GetActive() bool {
 }
 
 type Plan struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	DurationDays  int64                  `protobuf:"varint,4,opt,name=duration_days,json=durationDays,proto3" json:"duration_days,omitempty"`
-	Price         float64                `protobuf:"fixed64,5,opt,name=price,proto3" json:"price,omitempty"`
-	IsActive      bool                   `protobuf:"varint,6,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Id                 uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name               string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description        string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	DurationDays       int64                  `protobuf:"varint,4,opt,name=duration_days,json=durationDays,proto3" json:"duration_days,omitempty"`
+	IsActive           bool                   `protobuf:"varint,6,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	Payg               bool                   `protobuf:"varint,7,opt,name=payg,proto3" json:"payg,omitempty"`
+	Prices             []*PlanPrice           `protobuf:"bytes,8,rep,name=prices,proto3" json:"prices,omitempty"`                                                         // one price per purchasable term
+	GracePeriodDays    int32                  `protobuf:"varint,9,opt,name=grace_period_days,json=gracePeriodDays,proto3" json:"grace_period_days,omitempty"`             // days a plan stays past due after its term before it expires
+	TrialDays          int32                  `protobuf:"varint,10,opt,name=trial_days,json=trialDays,proto3" json:"trial_days,omitempty"`                                // 0 when the plan has no trial
+	TrialConvertPlanId uint64                 `protobuf:"varint,11,opt,name=trial_convert_plan_id,json=trialConvertPlanId,proto3" json:"trial_convert_plan_id,omitempty"` // paid plan a trial converts to, 0 to expire instead
+	TrialConvertMonths int32                  `protobuf:"varint,12,opt,name=trial_convert_months,json=trialConvertMonths,proto3" json:"trial_convert_months,omitempty"`   // term of the converted plan
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *Plan) Reset() {
@@ -487,42 +492,77 @@ func (x *Plan) GetDurationDays() int64 {
 	return 0
 }
 
-func (x *Plan) GetPrice() float64 {
+func (x *Plan) GetIsActive() bool {
 	if x != nil {
-		return x.Price
+		return x.IsActive
 	}
-	return 0
+	return false
 }
 
-func (x *Plan) GetIsActive() bool {
+func (x *Plan) GetPayg() bool {
 	if x != nil {
-		return x.IsActive
+		return x.Payg
 	}
 	return false
 }
 
-type PlanAssignmentRequest struct {
+func (x *Plan) GetPrices() []*PlanPrice {
+	if x != nil {
+		return x.Prices
+	}
+	return nil
+}
+
+func (x *Plan) GetGracePeriodDays() int32 {
+	if x != nil {
+		return x.GracePeriodDays
+	}
+	return 0
+}
+
+func (x *Plan) GetTrialDays() int32 {
+	if x != nil {
+		return x.TrialDays
+	}
+	return 0
+}
+
+func (x *Plan) GetTrialConvertPlanId() uint64 {
+	if x != nil {
+		return x.TrialConvertPlanId
+	}
+	return 0
+}
+
+func (x *Plan) GetTrialConvertMonths() int32 {
+	if x != nil {
+		return x.TrialConvertMonths
+	}
+	return 0
+}
+
+type PlanPrice struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // from path
-	PlanId        uint64                 `protobuf:"varint,2,opt,name=plan_id,json=planId,proto3" json:"plan_id,omitempty"` // from path
+	Months        int32                  `protobuf:"varint,1,opt,name=months,proto3" json:"months,omitempty"`
+	Price         int64                  `protobuf:"varint,2,opt,name=price,proto3" json:"price,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PlanAssignmentRequest) Reset() {
-	*x = PlanAssignmentRequest{}
+func (x *PlanPrice) Reset() {
+	*x = PlanPrice{}
 	mi := &file_userplan_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PlanAssignmentRequest) String() string {
+func (x *PlanPrice) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PlanAssignmentRequest) ProtoMessage() {}
+func (*PlanPrice) ProtoMessage() {}
 
-func (x *PlanAssignmentRequest) ProtoReflect() protoreflect.Message {
+func (x *PlanPrice) ProtoReflect() protoreflect.Message {
 	mi := &file_userplan_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -534,46 +574,52 @@ func (x *PlanAssignmentRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PlanAssignmentRequest.ProtoReflect.Descriptor instead.
-func (*PlanAssignmentRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use PlanPrice.ProtoReflect.Descriptor instead.
+func (*PlanPrice) Descriptor() ([]byte, []int) {
 	return file_userplan_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *PlanAssignmentRequest) GetUserId() uint64 {
+func (x *PlanPrice) GetMonths() int32 {
 	if x != nil {
-		return x.UserId
+		return x.Months
 	}
 	return 0
 }
 
-func (x *PlanAssignmentRequest) GetPlanId() uint64 {
+func (x *PlanPrice) GetPrice() int64 {
 	if x != nil {
-		return x.PlanId
+		return x.Price
 	}
 	return 0
 }
 
-type UserPlanRequest struct {
+type PlanAssignmentRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // from path
+	PlanId        uint64                 `protobuf:"varint,2,opt,name=plan_id,json=planId,proto3" json:"plan_id,omitempty"` // from path
+	Months        int32                  `protobuf:"varint,3,opt,name=months,proto3" json:"months,omitempty"`               // purchased term, must match a plan price; ignored for PAYG plans
+	ChangedBy     string                 `protobuf:"bytes,4,opt,name=changed_by,json=changedBy,proto3" json:"changed_by,omitempty"`
+	Reason        string                 `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	AutoRenew     bool                   `protobuf:"varint,6,opt,name=auto_renew,json=autoRenew,proto3" json:"auto_renew,omitempty"` // renew for the same term when it ends
+	Pending       bool                   `protobuf:"varint,7,opt,name=pending,proto3" json:"pending,omitempty"`                      // wait for ActivateUserPlan, the term starts on activation
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UserPlanRequest) Reset() {
-	*x = UserPlanRequest{}
+func (x *PlanAssignmentRequest) Reset() {
+	*x = PlanAssignmentRequest{}
 	mi := &file_userplan_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UserPlanRequest) String() string {
+func (x *PlanAssignmentRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UserPlanRequest) ProtoMessage() {}
+func (*PlanAssignmentRequest) ProtoMessage() {}
 
-func (x *UserPlanRequest) ProtoReflect() protoreflect.Message {
+func (x *PlanAssignmentRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_userplan_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -585,39 +631,84 @@ func (x *UserPlanRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UserPlanRequest.ProtoReflect.Descriptor instead.
-func (*UserPlanRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use PlanAssignmentRequest.ProtoReflect.Descriptor instead.
+func (*PlanAssignmentRequest) Descriptor() ([]byte, []int) {
 	return file_userplan_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *UserPlanRequest) GetUserId() uint64 {
+func (x *PlanAssignmentRequest) GetUserId() uint64 {
 	if x != nil {
 		return x.UserId
 	}
 	return 0
 }
 
-type RenewPlanRequest struct {
+func (x *PlanAssignmentRequest) GetPlanId() uint64 {
+	if x != nil {
+		return x.PlanId
+	}
+	return 0
+}
+
+func (x *PlanAssignmentRequest) GetMonths() int32 {
+	if x != nil {
+		return x.Months
+	}
+	return 0
+}
+
+func (x *PlanAssignmentRequest) GetChangedBy() string {
+	if x != nil {
+		return x.ChangedBy
+	}
+	return ""
+}
+
+func (x *PlanAssignmentRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *PlanAssignmentRequest) GetAutoRenew() bool {
+	if x != nil {
+		return x.AutoRenew
+	}
+	return false
+}
+
+func (x *PlanAssignmentRequest) GetPending() bool {
+	if x != nil {
+		return x.Pending
+	}
+	return false
+}
+
+type StartTrialRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // from path
+	PlanId        uint64                 `protobuf:"varint,2,opt,name=plan_id,json=planId,proto3" json:"plan_id,omitempty"`
+	ChangedBy     string                 `protobuf:"bytes,3,opt,name=changed_by,json=changedBy,proto3" json:"changed_by,omitempty"`
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RenewPlanRequest) Reset() {
-	*x = RenewPlanRequest{}
+func (x *StartTrialRequest) Reset() {
+	*x = StartTrialRequest{}
 	mi := &file_userplan_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RenewPlanRequest) String() string {
+func (x *StartTrialRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RenewPlanRequest) ProtoMessage() {}
+func (*StartTrialRequest) ProtoMessage() {}
 
-func (x *RenewPlanRequest) ProtoReflect() protoreflect.Message {
+func (x *StartTrialRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_userplan_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -629,40 +720,61 @@ func (x *RenewPlanRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RenewPlanRequest.ProtoReflect.Descriptor instead.
-func (*RenewPlanRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use StartTrialRequest.ProtoReflect.Descriptor instead.
+func (*StartTrialRequest) Descriptor() ([]byte, []int) {
 	return file_userplan_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *RenewPlanRequest) GetUserId() uint64 {
+func (x *StartTrialRequest) GetUserId() uint64 {
 	if x != nil {
 		return x.UserId
 	}
 	return 0
 }
 
-// Plan management messages
-type CreatePlanRequest struct {
+func (x *StartTrialRequest) GetPlanId() uint64 {
+	if x != nil {
+		return x.PlanId
+	}
+	return 0
+}
+
+func (x *StartTrialRequest) GetChangedBy() string {
+	if x != nil {
+		return x.ChangedBy
+	}
+	return ""
+}
+
+func (x *StartTrialRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type AutoRenewRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Plan          *Plan                  `protobuf:"bytes,1,opt,name=plan,proto3" json:"plan,omitempty"`
+	UserId        uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // from path
+	Enabled       bool                   `protobuf:"varint,2,opt,name=enabled,proto3" json:"enabled,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreatePlanRequest) Reset() {
-	*x = CreatePlanRequest{}
+func (x *AutoRenewRequest) Reset() {
+	*x = AutoRenewRequest{}
 	mi := &file_userplan_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreatePlanRequest) String() string {
+func (x *AutoRenewRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreatePlanRequest) ProtoMessage() {}
+func (*AutoRenewRequest) ProtoMessage() {}
 
-func (x *CreatePlanRequest) ProtoReflect() protoreflect.Message {
+func (x *AutoRenewRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_userplan_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -674,39 +786,46 @@ func (x *CreatePlanRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreatePlanRequest.ProtoReflect.Descriptor instead.
-func (*CreatePlanRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use AutoRenewRequest.ProtoReflect.Descriptor instead.
+func (*AutoRenewRequest) Descriptor() ([]byte, []int) {
 	return file_userplan_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *CreatePlanRequest) GetPlan() *Plan {
+func (x *AutoRenewRequest) GetUserId() uint64 {
 	if x != nil {
-		return x.Plan
+		return x.UserId
 	}
-	return nil
+	return 0
 }
 
-type PlanIDRequest struct {
+func (x *AutoRenewRequest) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+type UserPlanRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"` // from path
+	UserId        uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // from path
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PlanIDRequest) Reset() {
-	*x = PlanIDRequest{}
+func (x *UserPlanRequest) Reset() {
+	*x = UserPlanRequest{}
 	mi := &file_userplan_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PlanIDRequest) String() string {
+func (x *UserPlanRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PlanIDRequest) ProtoMessage() {}
+func (*UserPlanRequest) ProtoMessage() {}
 
-func (x *PlanIDRequest) ProtoReflect() protoreflect.Message {
+func (x *UserPlanRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_userplan_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -718,39 +837,42 @@ func (x *PlanIDRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PlanIDRequest.ProtoReflect.Descriptor instead.
-func (*PlanIDRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use UserPlanRequest.ProtoReflect.Descriptor instead.
+func (*UserPlanRequest) Descriptor() ([]byte, []int) {
 	return file_userplan_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *PlanIDRequest) GetId() uint64 {
+func (x *UserPlanRequest) GetUserId() uint64 {
 	if x != nil {
-		return x.Id
+		return x.UserId
 	}
 	return 0
 }
 
-type PlanNameRequest struct {
+type RenewPlanRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // from path
+	UserId        uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`    // from path
+	EndDate       int64                  `protobuf:"varint,2,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"` // Unix timestamp, 0 to renew for the purchased term
+	ChangedBy     string                 `protobuf:"bytes,3,opt,name=changed_by,json=changedBy,proto3" json:"changed_by,omitempty"`
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PlanNameRequest) Reset() {
-	*x = PlanNameRequest{}
+func (x *RenewPlanRequest) Reset() {
+	*x = RenewPlanRequest{}
 	mi := &file_userplan_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PlanNameRequest) String() string {
+func (x *RenewPlanRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PlanNameRequest) ProtoMessage() {}
+func (*RenewPlanRequest) ProtoMessage() {}
 
-func (x *PlanNameRequest) ProtoReflect() protoreflect.Message {
+func (x *RenewPlanRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_userplan_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -762,39 +884,64 @@ func (x *PlanNameRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PlanNameRequest.ProtoReflect.Descriptor instead.
-func (*PlanNameRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use RenewPlanRequest.ProtoReflect.Descriptor instead.
+func (*RenewPlanRequest) Descriptor() ([]byte, []int) {
 	return file_userplan_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *PlanNameRequest) GetName() string {
+func (x *RenewPlanRequest) GetUserId() uint64 {
 	if x != nil {
-		return x.Name
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *RenewPlanRequest) GetEndDate() int64 {
+	if x != nil {
+		return x.EndDate
+	}
+	return 0
+}
+
+func (x *RenewPlanRequest) GetChangedBy() string {
+	if x != nil {
+		return x.ChangedBy
 	}
 	return ""
 }
 
-type UpdatePlanRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Plan          *Plan                  `protobuf:"bytes,1,opt,name=plan,proto3" json:"plan,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *RenewPlanRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
 }
 
-func (x *UpdatePlanRequest) Reset() {
-	*x = UpdatePlanRequest{}
+type ChangePlanRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	UserId         uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                         // from path
+	PlanId         uint64                 `protobuf:"varint,2,opt,name=plan_id,json=planId,proto3" json:"plan_id,omitempty"`                         // plan to move to
+	DeferDowngrade bool                   `protobuf:"varint,3,opt,name=defer_downgrade,json=deferDowngrade,proto3" json:"defer_downgrade,omitempty"` // apply a downgrade at the end of the current term
+	ChangedBy      string                 `protobuf:"bytes,4,opt,name=changed_by,json=changedBy,proto3" json:"changed_by,omitempty"`
+	Reason         string                 `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ChangePlanRequest) Reset() {
+	*x = ChangePlanRequest{}
 	mi := &file_userplan_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdatePlanRequest) String() string {
+func (x *ChangePlanRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdatePlanRequest) ProtoMessage() {}
+func (*ChangePlanRequest) ProtoMessage() {}
 
-func (x *UpdatePlanRequest) ProtoReflect() protoreflect.Message {
+func (x *ChangePlanRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_userplan_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -806,40 +953,70 @@ func (x *UpdatePlanRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdatePlanRequest.ProtoReflect.Descriptor instead.
-func (*UpdatePlanRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ChangePlanRequest.ProtoReflect.Descriptor instead.
+func (*ChangePlanRequest) Descriptor() ([]byte, []int) {
 	return file_userplan_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *UpdatePlanRequest) GetPlan() *Plan {
+func (x *ChangePlanRequest) GetUserId() uint64 {
 	if x != nil {
-		return x.Plan
+		return x.UserId
 	}
-	return nil
+	return 0
 }
 
-type ListPlansRequest struct {
+func (x *ChangePlanRequest) GetPlanId() uint64 {
+	if x != nil {
+		return x.PlanId
+	}
+	return 0
+}
+
+func (x *ChangePlanRequest) GetDeferDowngrade() bool {
+	if x != nil {
+		return x.DeferDowngrade
+	}
+	return false
+}
+
+func (x *ChangePlanRequest) GetChangedBy() string {
+	if x != nil {
+		return x.ChangedBy
+	}
+	return ""
+}
+
+func (x *ChangePlanRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ChangePlanResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Limit         int32                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
-	Offset        int32                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	Action        string                 `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`        // upgrade or downgrade
+	Proration     int64                  `protobuf:"varint,2,opt,name=proration,proto3" json:"proration,omitempty"` // amount due for the rest of the term, negative for a credit
+	Deferred      bool                   `protobuf:"varint,3,opt,name=deferred,proto3" json:"deferred,omitempty"`
+	EffectiveAt   int64                  `protobuf:"varint,4,opt,name=effective_at,json=effectiveAt,proto3" json:"effective_at,omitempty"` // Unix timestamp
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListPlansRequest) Reset() {
-	*x = ListPlansRequest{}
+func (x *ChangePlanResponse) Reset() {
+	*x = ChangePlanResponse{}
 	mi := &file_userplan_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListPlansRequest) String() string {
+func (x *ChangePlanResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListPlansRequest) ProtoMessage() {}
+func (*ChangePlanResponse) ProtoMessage() {}
 
-func (x *ListPlansRequest) ProtoReflect() protoreflect.Message {
+func (x *ChangePlanResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_userplan_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -851,48 +1028,1734 @@ func (x *ListPlansRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListPlansRequest.ProtoReflect.Descriptor instead.
-func (*ListPlansRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ChangePlanResponse.ProtoReflect.Descriptor instead.
+func (*ChangePlanResponse) Descriptor() ([]byte, []int) {
 	return file_userplan_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *ListPlansRequest) GetLimit() int32 {
+func (x *ChangePlanResponse) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ChangePlanResponse) GetProration() int64 {
+	if x != nil {
+		return x.Proration
+	}
+	return 0
+}
+
+func (x *ChangePlanResponse) GetDeferred() bool {
+	if x != nil {
+		return x.Deferred
+	}
+	return false
+}
+
+func (x *ChangePlanResponse) GetEffectiveAt() int64 {
+	if x != nil {
+		return x.EffectiveAt
+	}
+	return 0
+}
+
+type PlanTransitionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // from path
+	ChangedBy     string                 `protobuf:"bytes,2,opt,name=changed_by,json=changedBy,proto3" json:"changed_by,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlanTransitionRequest) Reset() {
+	*x = PlanTransitionRequest{}
+	mi := &file_userplan_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanTransitionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanTransitionRequest) ProtoMessage() {}
+
+func (x *PlanTransitionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanTransitionRequest.ProtoReflect.Descriptor instead.
+func (*PlanTransitionRequest) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *PlanTransitionRequest) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *PlanTransitionRequest) GetChangedBy() string {
+	if x != nil {
+		return x.ChangedBy
+	}
+	return ""
+}
+
+func (x *PlanTransitionRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ScheduleChangeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                // from path
+	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`                               // change or cancel
+	PlanId        uint64                 `protobuf:"varint,3,opt,name=plan_id,json=planId,proto3" json:"plan_id,omitempty"`                // target plan of change actions
+	EffectiveAt   int64                  `protobuf:"varint,4,opt,name=effective_at,json=effectiveAt,proto3" json:"effective_at,omitempty"` // Unix timestamp, 0 for the end of the current term
+	ChangedBy     string                 `protobuf:"bytes,5,opt,name=changed_by,json=changedBy,proto3" json:"changed_by,omitempty"`
+	Reason        string                 `protobuf:"bytes,6,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScheduleChangeRequest) Reset() {
+	*x = ScheduleChangeRequest{}
+	mi := &file_userplan_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScheduleChangeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleChangeRequest) ProtoMessage() {}
+
+func (x *ScheduleChangeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleChangeRequest.ProtoReflect.Descriptor instead.
+func (*ScheduleChangeRequest) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ScheduleChangeRequest) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *ScheduleChangeRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ScheduleChangeRequest) GetPlanId() uint64 {
+	if x != nil {
+		return x.PlanId
+	}
+	return 0
+}
+
+func (x *ScheduleChangeRequest) GetEffectiveAt() int64 {
+	if x != nil {
+		return x.EffectiveAt
+	}
+	return 0
+}
+
+func (x *ScheduleChangeRequest) GetChangedBy() string {
+	if x != nil {
+		return x.ChangedBy
+	}
+	return ""
+}
+
+func (x *ScheduleChangeRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ScheduledChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserPlanId    uint64                 `protobuf:"varint,2,opt,name=user_plan_id,json=userPlanId,proto3" json:"user_plan_id,omitempty"`
+	Action        string                 `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	PlanId        uint64                 `protobuf:"varint,4,opt,name=plan_id,json=planId,proto3" json:"plan_id,omitempty"`
+	EffectiveAt   int64                  `protobuf:"varint,5,opt,name=effective_at,json=effectiveAt,proto3" json:"effective_at,omitempty"` // Unix timestamp
+	Status        string                 `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	ChangedBy     string                 `protobuf:"bytes,7,opt,name=changed_by,json=changedBy,proto3" json:"changed_by,omitempty"`
+	Reason        string                 `protobuf:"bytes,8,opt,name=reason,proto3" json:"reason,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // Unix timestamp
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScheduledChange) Reset() {
+	*x = ScheduledChange{}
+	mi := &file_userplan_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScheduledChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduledChange) ProtoMessage() {}
+
+func (x *ScheduledChange) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduledChange.ProtoReflect.Descriptor instead.
+func (*ScheduledChange) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ScheduledChange) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ScheduledChange) GetUserPlanId() uint64 {
+	if x != nil {
+		return x.UserPlanId
+	}
+	return 0
+}
+
+func (x *ScheduledChange) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ScheduledChange) GetPlanId() uint64 {
+	if x != nil {
+		return x.PlanId
+	}
+	return 0
+}
+
+func (x *ScheduledChange) GetEffectiveAt() int64 {
+	if x != nil {
+		return x.EffectiveAt
+	}
+	return 0
+}
+
+func (x *ScheduledChange) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ScheduledChange) GetChangedBy() string {
+	if x != nil {
+		return x.ChangedBy
+	}
+	return ""
+}
+
+func (x *ScheduledChange) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ScheduledChange) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+type ListScheduledChangesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Changes       []*ScheduledChange     `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListScheduledChangesResponse) Reset() {
+	*x = ListScheduledChangesResponse{}
+	mi := &file_userplan_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListScheduledChangesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListScheduledChangesResponse) ProtoMessage() {}
+
+func (x *ListScheduledChangesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListScheduledChangesResponse.ProtoReflect.Descriptor instead.
+func (*ListScheduledChangesResponse) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListScheduledChangesResponse) GetChanges() []*ScheduledChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+type ScheduledChangeIDRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // from path
+	Id            uint64                 `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`                       // from path
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScheduledChangeIDRequest) Reset() {
+	*x = ScheduledChangeIDRequest{}
+	mi := &file_userplan_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScheduledChangeIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduledChangeIDRequest) ProtoMessage() {}
+
+func (x *ScheduledChangeIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduledChangeIDRequest.ProtoReflect.Descriptor instead.
+func (*ScheduledChangeIDRequest) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ScheduledChangeIDRequest) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *ScheduledChangeIDRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type PlanHistoryEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserPlanId    uint64                 `protobuf:"varint,2,opt,name=user_plan_id,json=userPlanId,proto3" json:"user_plan_id,omitempty"`
+	Action        string                 `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	FromStatus    string                 `protobuf:"bytes,4,opt,name=from_status,json=fromStatus,proto3" json:"from_status,omitempty"`
+	ToStatus      string                 `protobuf:"bytes,5,opt,name=to_status,json=toStatus,proto3" json:"to_status,omitempty"`
+	OldPlanId     uint64                 `protobuf:"varint,6,opt,name=old_plan_id,json=oldPlanId,proto3" json:"old_plan_id,omitempty"` // 0 for new assignments
+	NewPlanId     uint64                 `protobuf:"varint,7,opt,name=new_plan_id,json=newPlanId,proto3" json:"new_plan_id,omitempty"` // 0 for cancellations
+	ChangedBy     string                 `protobuf:"bytes,8,opt,name=changed_by,json=changedBy,proto3" json:"changed_by,omitempty"`
+	Reason        string                 `protobuf:"bytes,9,opt,name=reason,proto3" json:"reason,omitempty"`
+	ChangedAt     int64                  `protobuf:"varint,10,opt,name=changed_at,json=changedAt,proto3" json:"changed_at,omitempty"` // Unix timestamp
+	Metadata      string                 `protobuf:"bytes,11,opt,name=metadata,proto3" json:"metadata,omitempty"`                     // JSON object, empty when there is none
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlanHistoryEntry) Reset() {
+	*x = PlanHistoryEntry{}
+	mi := &file_userplan_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanHistoryEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanHistoryEntry) ProtoMessage() {}
+
+func (x *PlanHistoryEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanHistoryEntry.ProtoReflect.Descriptor instead.
+func (*PlanHistoryEntry) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *PlanHistoryEntry) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *PlanHistoryEntry) GetUserPlanId() uint64 {
+	if x != nil {
+		return x.UserPlanId
+	}
+	return 0
+}
+
+func (x *PlanHistoryEntry) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *PlanHistoryEntry) GetFromStatus() string {
+	if x != nil {
+		return x.FromStatus
+	}
+	return ""
+}
+
+func (x *PlanHistoryEntry) GetToStatus() string {
+	if x != nil {
+		return x.ToStatus
+	}
+	return ""
+}
+
+func (x *PlanHistoryEntry) GetOldPlanId() uint64 {
+	if x != nil {
+		return x.OldPlanId
+	}
+	return 0
+}
+
+func (x *PlanHistoryEntry) GetNewPlanId() uint64 {
+	if x != nil {
+		return x.NewPlanId
+	}
+	return 0
+}
+
+func (x *PlanHistoryEntry) GetChangedBy() string {
+	if x != nil {
+		return x.ChangedBy
+	}
+	return ""
+}
+
+func (x *PlanHistoryEntry) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *PlanHistoryEntry) GetChangedAt() int64 {
+	if x != nil {
+		return x.ChangedAt
+	}
+	return 0
+}
+
+func (x *PlanHistoryEntry) GetMetadata() string {
+	if x != nil {
+		return x.Metadata
+	}
+	return ""
+}
+
+type PlanHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`               // from path
+	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`                              // only entries of this action
+	MetadataKey   string                 `protobuf:"bytes,3,opt,name=metadata_key,json=metadataKey,proto3" json:"metadata_key,omitempty"` // only entries whose metadata has this key
+	Metadata      string                 `protobuf:"bytes,4,opt,name=metadata,proto3" json:"metadata,omitempty"`                          // JSON object, only entries whose metadata contains its keys and values
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlanHistoryRequest) Reset() {
+	*x = PlanHistoryRequest{}
+	mi := &file_userplan_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanHistoryRequest) ProtoMessage() {}
+
+func (x *PlanHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanHistoryRequest.ProtoReflect.Descriptor instead.
+func (*PlanHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *PlanHistoryRequest) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *PlanHistoryRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *PlanHistoryRequest) GetMetadataKey() string {
+	if x != nil {
+		return x.MetadataKey
+	}
+	return ""
+}
+
+func (x *PlanHistoryRequest) GetMetadata() string {
+	if x != nil {
+		return x.Metadata
+	}
+	return ""
+}
+
+type PlanHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*PlanHistoryEntry    `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlanHistoryResponse) Reset() {
+	*x = PlanHistoryResponse{}
+	mi := &file_userplan_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanHistoryResponse) ProtoMessage() {}
+
+func (x *PlanHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanHistoryResponse.ProtoReflect.Descriptor instead.
+func (*PlanHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *PlanHistoryResponse) GetEntries() []*PlanHistoryEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+// Plan management messages
+type CreatePlanRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Plan          *Plan                  `protobuf:"bytes,1,opt,name=plan,proto3" json:"plan,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreatePlanRequest) Reset() {
+	*x = CreatePlanRequest{}
+	mi := &file_userplan_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreatePlanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePlanRequest) ProtoMessage() {}
+
+func (x *CreatePlanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePlanRequest.ProtoReflect.Descriptor instead.
+func (*CreatePlanRequest) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *CreatePlanRequest) GetPlan() *Plan {
+	if x != nil {
+		return x.Plan
+	}
+	return nil
+}
+
+type PlanIDRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"` // from path
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlanIDRequest) Reset() {
+	*x = PlanIDRequest{}
+	mi := &file_userplan_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanIDRequest) ProtoMessage() {}
+
+func (x *PlanIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanIDRequest.ProtoReflect.Descriptor instead.
+func (*PlanIDRequest) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *PlanIDRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type PlanNameRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // from path
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlanNameRequest) Reset() {
+	*x = PlanNameRequest{}
+	mi := &file_userplan_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanNameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanNameRequest) ProtoMessage() {}
+
+func (x *PlanNameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanNameRequest.ProtoReflect.Descriptor instead.
+func (*PlanNameRequest) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *PlanNameRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type UpdatePlanRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Plan          *Plan                  `protobuf:"bytes,1,opt,name=plan,proto3" json:"plan,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePlanRequest) Reset() {
+	*x = UpdatePlanRequest{}
+	mi := &file_userplan_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePlanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePlanRequest) ProtoMessage() {}
+
+func (x *UpdatePlanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePlanRequest.ProtoReflect.Descriptor instead.
+func (*UpdatePlanRequest) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *UpdatePlanRequest) GetPlan() *Plan {
+	if x != nil {
+		return x.Plan
+	}
+	return nil
+}
+
+type ListPlansRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         int32                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPlansRequest) Reset() {
+	*x = ListPlansRequest{}
+	mi := &file_userplan_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPlansRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPlansRequest) ProtoMessage() {}
+
+func (x *ListPlansRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPlansRequest.ProtoReflect.Descriptor instead.
+func (*ListPlansRequest) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ListPlansRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListPlansRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ListPlansResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Plans         []*Plan                `protobuf:"bytes,1,rep,name=plans,proto3" json:"plans,omitempty"`
+	Total         int64                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPlansResponse) Reset() {
+	*x = ListPlansResponse{}
+	mi := &file_userplan_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPlansResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPlansResponse) ProtoMessage() {}
+
+func (x *ListPlansResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPlansResponse.ProtoReflect.Descriptor instead.
+func (*ListPlansResponse) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ListPlansResponse) GetPlans() []*Plan {
+	if x != nil {
+		return x.Plans
+	}
+	return nil
+}
+
+func (x *ListPlansResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type PlanPriceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlanId        uint64                 `protobuf:"varint,1,opt,name=plan_id,json=planId,proto3" json:"plan_id,omitempty"` // from path
+	Months        int32                  `protobuf:"varint,2,opt,name=months,proto3" json:"months,omitempty"`
+	Price         int64                  `protobuf:"varint,3,opt,name=price,proto3" json:"price,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlanPriceRequest) Reset() {
+	*x = PlanPriceRequest{}
+	mi := &file_userplan_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanPriceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanPriceRequest) ProtoMessage() {}
+
+func (x *PlanPriceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanPriceRequest.ProtoReflect.Descriptor instead.
+func (*PlanPriceRequest) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *PlanPriceRequest) GetPlanId() uint64 {
+	if x != nil {
+		return x.PlanId
+	}
+	return 0
+}
+
+func (x *PlanPriceRequest) GetMonths() int32 {
+	if x != nil {
+		return x.Months
+	}
+	return 0
+}
+
+func (x *PlanPriceRequest) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+type PlanPriceIDRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlanId        uint64                 `protobuf:"varint,1,opt,name=plan_id,json=planId,proto3" json:"plan_id,omitempty"` // from path
+	Months        int32                  `protobuf:"varint,2,opt,name=months,proto3" json:"months,omitempty"`               // from path
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlanPriceIDRequest) Reset() {
+	*x = PlanPriceIDRequest{}
+	mi := &file_userplan_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanPriceIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanPriceIDRequest) ProtoMessage() {}
+
+func (x *PlanPriceIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanPriceIDRequest.ProtoReflect.Descriptor instead.
+func (*PlanPriceIDRequest) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *PlanPriceIDRequest) GetPlanId() uint64 {
+	if x != nil {
+		return x.PlanId
+	}
+	return 0
+}
+
+func (x *PlanPriceIDRequest) GetMonths() int32 {
+	if x != nil {
+		return x.Months
+	}
+	return 0
+}
+
+type ListPlanPricesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prices        []*PlanPrice           `protobuf:"bytes,1,rep,name=prices,proto3" json:"prices,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPlanPricesResponse) Reset() {
+	*x = ListPlanPricesResponse{}
+	mi := &file_userplan_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPlanPricesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPlanPricesResponse) ProtoMessage() {}
+
+func (x *ListPlanPricesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPlanPricesResponse.ProtoReflect.Descriptor instead.
+func (*ListPlanPricesResponse) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ListPlanPricesResponse) GetPrices() []*PlanPrice {
+	if x != nil {
+		return x.Prices
+	}
+	return nil
+}
+
+type Limitation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Limitation) Reset() {
+	*x = Limitation{}
+	mi := &file_userplan_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Limitation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Limitation) ProtoMessage() {}
+
+func (x *Limitation) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Limitation.ProtoReflect.Descriptor instead.
+func (*Limitation) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *Limitation) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Limitation) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+type PlanLimitation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlanId        uint64                 `protobuf:"varint,1,opt,name=plan_id,json=planId,proto3" json:"plan_id,omitempty"`
+	Limitation    *Limitation            `protobuf:"bytes,2,opt,name=limitation,proto3" json:"limitation,omitempty"`
+	Value         int64                  `protobuf:"varint,3,opt,name=value,proto3" json:"value,omitempty"`
+	UnitPrice     int64                  `protobuf:"varint,4,opt,name=unit_price,json=unitPrice,proto3" json:"unit_price,omitempty"`          // price per consumed unit on PAYG plans
+	TrialValue    *int64                 `protobuf:"varint,5,opt,name=trial_value,json=trialValue,proto3,oneof" json:"trial_value,omitempty"` // overrides value during a trial
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlanLimitation) Reset() {
+	*x = PlanLimitation{}
+	mi := &file_userplan_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanLimitation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanLimitation) ProtoMessage() {}
+
+func (x *PlanLimitation) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanLimitation.ProtoReflect.Descriptor instead.
+func (*PlanLimitation) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *PlanLimitation) GetPlanId() uint64 {
+	if x != nil {
+		return x.PlanId
+	}
+	return 0
+}
+
+func (x *PlanLimitation) GetLimitation() *Limitation {
+	if x != nil {
+		return x.Limitation
+	}
+	return nil
+}
+
+func (x *PlanLimitation) GetValue() int64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *PlanLimitation) GetUnitPrice() int64 {
+	if x != nil {
+		return x.UnitPrice
+	}
+	return 0
+}
+
+func (x *PlanLimitation) GetTrialValue() int64 {
+	if x != nil && x.TrialValue != nil {
+		return *x.TrialValue
+	}
+	return 0
+}
+
+type CreateLimitationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limitation    *Limitation            `protobuf:"bytes,1,opt,name=limitation,proto3" json:"limitation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateLimitationRequest) Reset() {
+	*x = CreateLimitationRequest{}
+	mi := &file_userplan_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateLimitationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateLimitationRequest) ProtoMessage() {}
+
+func (x *CreateLimitationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateLimitationRequest.ProtoReflect.Descriptor instead.
+func (*CreateLimitationRequest) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *CreateLimitationRequest) GetLimitation() *Limitation {
+	if x != nil {
+		return x.Limitation
+	}
+	return nil
+}
+
+type UpdateLimitationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limitation    *Limitation            `protobuf:"bytes,1,opt,name=limitation,proto3" json:"limitation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateLimitationRequest) Reset() {
+	*x = UpdateLimitationRequest{}
+	mi := &file_userplan_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateLimitationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateLimitationRequest) ProtoMessage() {}
+
+func (x *UpdateLimitationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateLimitationRequest.ProtoReflect.Descriptor instead.
+func (*UpdateLimitationRequest) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *UpdateLimitationRequest) GetLimitation() *Limitation {
+	if x != nil {
+		return x.Limitation
+	}
+	return nil
+}
+
+type LimitationIDRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"` // from path
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LimitationIDRequest) Reset() {
+	*x = LimitationIDRequest{}
+	mi := &file_userplan_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LimitationIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LimitationIDRequest) ProtoMessage() {}
+
+func (x *LimitationIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LimitationIDRequest.ProtoReflect.Descriptor instead.
+func (*LimitationIDRequest) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *LimitationIDRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListLimitationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limitations   []*Limitation          `protobuf:"bytes,1,rep,name=limitations,proto3" json:"limitations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLimitationsResponse) Reset() {
+	*x = ListLimitationsResponse{}
+	mi := &file_userplan_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLimitationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLimitationsResponse) ProtoMessage() {}
+
+func (x *ListLimitationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLimitationsResponse.ProtoReflect.Descriptor instead.
+func (*ListLimitationsResponse) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *ListLimitationsResponse) GetLimitations() []*Limitation {
+	if x != nil {
+		return x.Limitations
+	}
+	return nil
+}
+
+type ListPlanLimitationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limitations   []*PlanLimitation      `protobuf:"bytes,1,rep,name=limitations,proto3" json:"limitations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPlanLimitationsResponse) Reset() {
+	*x = ListPlanLimitationsResponse{}
+	mi := &file_userplan_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPlanLimitationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPlanLimitationsResponse) ProtoMessage() {}
+
+func (x *ListPlanLimitationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPlanLimitationsResponse.ProtoReflect.Descriptor instead.
+func (*ListPlanLimitationsResponse) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ListPlanLimitationsResponse) GetLimitations() []*PlanLimitation {
+	if x != nil {
+		return x.Limitations
+	}
+	return nil
+}
+
+type PlanLimitationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlanId        uint64                 `protobuf:"varint,1,opt,name=plan_id,json=planId,proto3" json:"plan_id,omitempty"`                   // from path
+	LimitationId  uint64                 `protobuf:"varint,2,opt,name=limitation_id,json=limitationId,proto3" json:"limitation_id,omitempty"` // from path
+	Value         int64                  `protobuf:"varint,3,opt,name=value,proto3" json:"value,omitempty"`
+	UnitPrice     int64                  `protobuf:"varint,4,opt,name=unit_price,json=unitPrice,proto3" json:"unit_price,omitempty"`
+	TrialValue    *int64                 `protobuf:"varint,5,opt,name=trial_value,json=trialValue,proto3,oneof" json:"trial_value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlanLimitationRequest) Reset() {
+	*x = PlanLimitationRequest{}
+	mi := &file_userplan_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanLimitationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanLimitationRequest) ProtoMessage() {}
+
+func (x *PlanLimitationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanLimitationRequest.ProtoReflect.Descriptor instead.
+func (*PlanLimitationRequest) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *PlanLimitationRequest) GetPlanId() uint64 {
+	if x != nil {
+		return x.PlanId
+	}
+	return 0
+}
+
+func (x *PlanLimitationRequest) GetLimitationId() uint64 {
+	if x != nil {
+		return x.LimitationId
+	}
+	return 0
+}
+
+func (x *PlanLimitationRequest) GetValue() int64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *PlanLimitationRequest) GetUnitPrice() int64 {
+	if x != nil {
+		return x.UnitPrice
+	}
+	return 0
+}
+
+func (x *PlanLimitationRequest) GetTrialValue() int64 {
+	if x != nil && x.TrialValue != nil {
+		return *x.TrialValue
+	}
+	return 0
+}
+
+type PlanLimitationIDRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlanId        uint64                 `protobuf:"varint,1,opt,name=plan_id,json=planId,proto3" json:"plan_id,omitempty"`                   // from path
+	LimitationId  uint64                 `protobuf:"varint,2,opt,name=limitation_id,json=limitationId,proto3" json:"limitation_id,omitempty"` // from path
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlanLimitationIDRequest) Reset() {
+	*x = PlanLimitationIDRequest{}
+	mi := &file_userplan_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanLimitationIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanLimitationIDRequest) ProtoMessage() {}
+
+func (x *PlanLimitationIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanLimitationIDRequest.ProtoReflect.Descriptor instead.
+func (*PlanLimitationIDRequest) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *PlanLimitationIDRequest) GetPlanId() uint64 {
+	if x != nil {
+		return x.PlanId
+	}
+	return 0
+}
+
+func (x *PlanLimitationIDRequest) GetLimitationId() uint64 {
+	if x != nil {
+		return x.LimitationId
+	}
+	return 0
+}
+
+type QuotaRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Limitation    string                 `protobuf:"bytes,2,opt,name=limitation,proto3" json:"limitation,omitempty"` // limitation title
+	Amount        int64                  `protobuf:"varint,3,opt,name=amount,proto3" json:"amount,omitempty"`        // units to check or consume, defaults to 1 for checks
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuotaRequest) Reset() {
+	*x = QuotaRequest{}
+	mi := &file_userplan_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuotaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuotaRequest) ProtoMessage() {}
+
+func (x *QuotaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuotaRequest.ProtoReflect.Descriptor instead.
+func (*QuotaRequest) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *QuotaRequest) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *QuotaRequest) GetLimitation() string {
+	if x != nil {
+		return x.Limitation
+	}
+	return ""
+}
+
+func (x *QuotaRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+type Quota struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limitation    string                 `protobuf:"bytes,1,opt,name=limitation,proto3" json:"limitation,omitempty"`
+	Limit         int64                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Used          int64                  `protobuf:"varint,3,opt,name=used,proto3" json:"used,omitempty"`
+	Remaining     int64                  `protobuf:"varint,4,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	Allowed       bool                   `protobuf:"varint,5,opt,name=allowed,proto3" json:"allowed,omitempty"`
+	PeriodStart   int64                  `protobuf:"varint,6,opt,name=period_start,json=periodStart,proto3" json:"period_start,omitempty"` // Unix timestamp
+	PeriodEnd     int64                  `protobuf:"varint,7,opt,name=period_end,json=periodEnd,proto3" json:"period_end,omitempty"`       // Unix timestamp
+	Metered       bool                   `protobuf:"varint,8,opt,name=metered,proto3" json:"metered,omitempty"`                            // PAYG usage is billed instead of capped
+	PastDue       bool                   `protobuf:"varint,9,opt,name=past_due,json=pastDue,proto3" json:"past_due,omitempty"`             // the plan's term ended and it is in its grace period
+	Trial         bool                   `protobuf:"varint,10,opt,name=trial,proto3" json:"trial,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Quota) Reset() {
+	*x = Quota{}
+	mi := &file_userplan_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Quota) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Quota) ProtoMessage() {}
+
+func (x *Quota) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Quota.ProtoReflect.Descriptor instead.
+func (*Quota) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *Quota) GetLimitation() string {
+	if x != nil {
+		return x.Limitation
+	}
+	return ""
+}
+
+func (x *Quota) GetLimit() int64 {
 	if x != nil {
 		return x.Limit
 	}
 	return 0
 }
 
-func (x *ListPlansRequest) GetOffset() int32 {
+func (x *Quota) GetUsed() int64 {
 	if x != nil {
-		return x.Offset
+		return x.Used
 	}
 	return 0
 }
 
-type ListPlansResponse struct {
+func (x *Quota) GetRemaining() int64 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+func (x *Quota) GetAllowed() bool {
+	if x != nil {
+		return x.Allowed
+	}
+	return false
+}
+
+func (x *Quota) GetPeriodStart() int64 {
+	if x != nil {
+		return x.PeriodStart
+	}
+	return 0
+}
+
+func (x *Quota) GetPeriodEnd() int64 {
+	if x != nil {
+		return x.PeriodEnd
+	}
+	return 0
+}
+
+func (x *Quota) GetMetered() bool {
+	if x != nil {
+		return x.Metered
+	}
+	return false
+}
+
+func (x *Quota) GetPastDue() bool {
+	if x != nil {
+		return x.PastDue
+	}
+	return false
+}
+
+func (x *Quota) GetTrial() bool {
+	if x != nil {
+		return x.Trial
+	}
+	return false
+}
+
+type UsageResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Plans         []*Plan                `protobuf:"bytes,1,rep,name=plans,proto3" json:"plans,omitempty"`
-	Total         int64                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Quotas        []*Quota               `protobuf:"bytes,1,rep,name=quotas,proto3" json:"quotas,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListPlansResponse) Reset() {
-	*x = ListPlansResponse{}
-	mi := &file_userplan_proto_msgTypes[16]
+func (x *UsageResponse) Reset() {
+	*x = UsageResponse{}
+	mi := &file_userplan_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListPlansResponse) String() string {
+func (x *UsageResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListPlansResponse) ProtoMessage() {}
+func (*UsageResponse) ProtoMessage() {}
 
-func (x *ListPlansResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_userplan_proto_msgTypes[16]
+func (x *UsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -903,19 +2766,216 @@ func (x *ListPlansResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListPlansResponse.ProtoReflect.Descriptor instead.
-func (*ListPlansResponse) Descriptor() ([]byte, []int) {
-	return file_userplan_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use UsageResponse.ProtoReflect.Descriptor instead.
+func (*UsageResponse) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{44}
 }
 
-func (x *ListPlansResponse) GetPlans() []*Plan {
+func (x *UsageResponse) GetQuotas() []*Quota {
 	if x != nil {
-		return x.Plans
+		return x.Quotas
 	}
 	return nil
 }
 
-func (x *ListPlansResponse) GetTotal() int64 {
+type UsageStatementRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Period        int64                  `protobuf:"varint,2,opt,name=period,proto3" json:"period,omitempty"` // Unix timestamp within the requested period, 0 for the current one
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UsageStatementRequest) Reset() {
+	*x = UsageStatementRequest{}
+	mi := &file_userplan_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UsageStatementRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsageStatementRequest) ProtoMessage() {}
+
+func (x *UsageStatementRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsageStatementRequest.ProtoReflect.Descriptor instead.
+func (*UsageStatementRequest) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *UsageStatementRequest) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *UsageStatementRequest) GetPeriod() int64 {
+	if x != nil {
+		return x.Period
+	}
+	return 0
+}
+
+type UsageStatementLine struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limitation    string                 `protobuf:"bytes,1,opt,name=limitation,proto3" json:"limitation,omitempty"`
+	Units         int64                  `protobuf:"varint,2,opt,name=units,proto3" json:"units,omitempty"`
+	UnitPrice     int64                  `protobuf:"varint,3,opt,name=unit_price,json=unitPrice,proto3" json:"unit_price,omitempty"`
+	Amount        int64                  `protobuf:"varint,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UsageStatementLine) Reset() {
+	*x = UsageStatementLine{}
+	mi := &file_userplan_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UsageStatementLine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsageStatementLine) ProtoMessage() {}
+
+func (x *UsageStatementLine) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsageStatementLine.ProtoReflect.Descriptor instead.
+func (*UsageStatementLine) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *UsageStatementLine) GetLimitation() string {
+	if x != nil {
+		return x.Limitation
+	}
+	return ""
+}
+
+func (x *UsageStatementLine) GetUnits() int64 {
+	if x != nil {
+		return x.Units
+	}
+	return 0
+}
+
+func (x *UsageStatementLine) GetUnitPrice() int64 {
+	if x != nil {
+		return x.UnitPrice
+	}
+	return 0
+}
+
+func (x *UsageStatementLine) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+type UsageStatement struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	PlanId        uint64                 `protobuf:"varint,2,opt,name=plan_id,json=planId,proto3" json:"plan_id,omitempty"`
+	PeriodStart   int64                  `protobuf:"varint,3,opt,name=period_start,json=periodStart,proto3" json:"period_start,omitempty"` // Unix timestamp
+	PeriodEnd     int64                  `protobuf:"varint,4,opt,name=period_end,json=periodEnd,proto3" json:"period_end,omitempty"`       // Unix timestamp
+	Lines         []*UsageStatementLine  `protobuf:"bytes,5,rep,name=lines,proto3" json:"lines,omitempty"`
+	Total         int64                  `protobuf:"varint,6,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UsageStatement) Reset() {
+	*x = UsageStatement{}
+	mi := &file_userplan_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UsageStatement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsageStatement) ProtoMessage() {}
+
+func (x *UsageStatement) ProtoReflect() protoreflect.Message {
+	mi := &file_userplan_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsageStatement.ProtoReflect.Descriptor instead.
+func (*UsageStatement) Descriptor() ([]byte, []int) {
+	return file_userplan_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *UsageStatement) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *UsageStatement) GetPlanId() uint64 {
+	if x != nil {
+		return x.PlanId
+	}
+	return 0
+}
+
+func (x *UsageStatement) GetPeriodStart() int64 {
+	if x != nil {
+		return x.PeriodStart
+	}
+	return 0
+}
+
+func (x *UsageStatement) GetPeriodEnd() int64 {
+	if x != nil {
+		return x.PeriodEnd
+	}
+	return 0
+}
+
+func (x *UsageStatement) GetLines() []*UsageStatementLine {
+	if x != nil {
+		return x.Lines
+	}
+	return nil
+}
+
+func (x *UsageStatement) GetTotal() int64 {
 	if x != nil {
 		return x.Total
 	}
@@ -952,21 +3012,118 @@ const file_userplan_proto_rawDesc = "" +
 	"\x04page\x18\x04 \x01(\x03R\x04page\"H\n" +
 	"\x15UserActivationRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12\x16\n" +
-	"\x06active\x18\x02 \x01(\bR\x06active\"\xa4\x01\n" +
+	"\x06active\x18\x02 \x01(\bR\x06active\"\x8c\x03\n" +
 	"\x04Plan\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
 	"\vdescription\x18\x03 \x01(\tR\vdescription\x12#\n" +
-	"\rduration_days\x18\x04 \x01(\x03R\fdurationDays\x12\x14\n" +
-	"\x05price\x18\x05 \x01(\x01R\x05price\x12\x1b\n" +
-	"\tis_active\x18\x06 \x01(\bR\bisActive\"I\n" +
+	"\rduration_days\x18\x04 \x01(\x03R\fdurationDays\x12\x1b\n" +
+	"\tis_active\x18\x06 \x01(\bR\bisActive\x12\x12\n" +
+	"\x04payg\x18\a \x01(\bR\x04payg\x12+\n" +
+	"\x06prices\x18\b \x03(\v2\x13.userplan.PlanPriceR\x06prices\x12*\n" +
+	"\x11grace_period_days\x18\t \x01(\x05R\x0fgracePeriodDays\x12\x1d\n" +
+	"\n" +
+	"trial_days\x18\n" +
+	" \x01(\x05R\ttrialDays\x121\n" +
+	"\x15trial_convert_plan_id\x18\v \x01(\x04R\x12trialConvertPlanId\x120\n" +
+	"\x14trial_convert_months\x18\f \x01(\x05R\x12trialConvertMonthsJ\x04\b\x05\x10\x06R\x05price\"9\n" +
+	"\tPlanPrice\x12\x16\n" +
+	"\x06months\x18\x01 \x01(\x05R\x06months\x12\x14\n" +
+	"\x05price\x18\x02 \x01(\x03R\x05price\"\xd1\x01\n" +
 	"\x15PlanAssignmentRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12\x17\n" +
-	"\aplan_id\x18\x02 \x01(\x04R\x06planId\"*\n" +
+	"\aplan_id\x18\x02 \x01(\x04R\x06planId\x12\x16\n" +
+	"\x06months\x18\x03 \x01(\x05R\x06months\x12\x1d\n" +
+	"\n" +
+	"changed_by\x18\x04 \x01(\tR\tchangedBy\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reason\x12\x1d\n" +
+	"\n" +
+	"auto_renew\x18\x06 \x01(\bR\tautoRenew\x12\x18\n" +
+	"\apending\x18\a \x01(\bR\apending\"|\n" +
+	"\x11StartTrialRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12\x17\n" +
+	"\aplan_id\x18\x02 \x01(\x04R\x06planId\x12\x1d\n" +
+	"\n" +
+	"changed_by\x18\x03 \x01(\tR\tchangedBy\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\"E\n" +
+	"\x10AutoRenewRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12\x18\n" +
+	"\aenabled\x18\x02 \x01(\bR\aenabled\"*\n" +
 	"\x0fUserPlanRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\x04R\x06userId\"+\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\"}\n" +
 	"\x10RenewPlanRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\x04R\x06userId\"7\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12\x19\n" +
+	"\bend_date\x18\x02 \x01(\x03R\aendDate\x12\x1d\n" +
+	"\n" +
+	"changed_by\x18\x03 \x01(\tR\tchangedBy\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\"\xa5\x01\n" +
+	"\x11ChangePlanRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12\x17\n" +
+	"\aplan_id\x18\x02 \x01(\x04R\x06planId\x12'\n" +
+	"\x0fdefer_downgrade\x18\x03 \x01(\bR\x0edeferDowngrade\x12\x1d\n" +
+	"\n" +
+	"changed_by\x18\x04 \x01(\tR\tchangedBy\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reason\"\x89\x01\n" +
+	"\x12ChangePlanResponse\x12\x16\n" +
+	"\x06action\x18\x01 \x01(\tR\x06action\x12\x1c\n" +
+	"\tproration\x18\x02 \x01(\x03R\tproration\x12\x1a\n" +
+	"\bdeferred\x18\x03 \x01(\bR\bdeferred\x12!\n" +
+	"\feffective_at\x18\x04 \x01(\x03R\veffectiveAt\"g\n" +
+	"\x15PlanTransitionRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12\x1d\n" +
+	"\n" +
+	"changed_by\x18\x02 \x01(\tR\tchangedBy\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"\xbb\x01\n" +
+	"\x15ScheduleChangeRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12\x17\n" +
+	"\aplan_id\x18\x03 \x01(\x04R\x06planId\x12!\n" +
+	"\feffective_at\x18\x04 \x01(\x03R\veffectiveAt\x12\x1d\n" +
+	"\n" +
+	"changed_by\x18\x05 \x01(\tR\tchangedBy\x12\x16\n" +
+	"\x06reason\x18\x06 \x01(\tR\x06reason\"\x85\x02\n" +
+	"\x0fScheduledChange\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12 \n" +
+	"\fuser_plan_id\x18\x02 \x01(\x04R\n" +
+	"userPlanId\x12\x16\n" +
+	"\x06action\x18\x03 \x01(\tR\x06action\x12\x17\n" +
+	"\aplan_id\x18\x04 \x01(\x04R\x06planId\x12!\n" +
+	"\feffective_at\x18\x05 \x01(\x03R\veffectiveAt\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"changed_by\x18\a \x01(\tR\tchangedBy\x12\x16\n" +
+	"\x06reason\x18\b \x01(\tR\x06reason\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\t \x01(\x03R\tcreatedAt\"S\n" +
+	"\x1cListScheduledChangesResponse\x123\n" +
+	"\achanges\x18\x01 \x03(\v2\x19.userplan.ScheduledChangeR\achanges\"C\n" +
+	"\x18ScheduledChangeIDRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x04R\x02id\"\xcc\x02\n" +
+	"\x10PlanHistoryEntry\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12 \n" +
+	"\fuser_plan_id\x18\x02 \x01(\x04R\n" +
+	"userPlanId\x12\x16\n" +
+	"\x06action\x18\x03 \x01(\tR\x06action\x12\x1f\n" +
+	"\vfrom_status\x18\x04 \x01(\tR\n" +
+	"fromStatus\x12\x1b\n" +
+	"\tto_status\x18\x05 \x01(\tR\btoStatus\x12\x1e\n" +
+	"\vold_plan_id\x18\x06 \x01(\x04R\toldPlanId\x12\x1e\n" +
+	"\vnew_plan_id\x18\a \x01(\x04R\tnewPlanId\x12\x1d\n" +
+	"\n" +
+	"changed_by\x18\b \x01(\tR\tchangedBy\x12\x16\n" +
+	"\x06reason\x18\t \x01(\tR\x06reason\x12\x1d\n" +
+	"\n" +
+	"changed_at\x18\n" +
+	" \x01(\x03R\tchangedAt\x12\x1a\n" +
+	"\bmetadata\x18\v \x01(\tR\bmetadata\"\x84\x01\n" +
+	"\x12PlanHistoryRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12!\n" +
+	"\fmetadata_key\x18\x03 \x01(\tR\vmetadataKey\x12\x1a\n" +
+	"\bmetadata\x18\x04 \x01(\tR\bmetadata\"K\n" +
+	"\x13PlanHistoryResponse\x124\n" +
+	"\aentries\x18\x01 \x03(\v2\x1a.userplan.PlanHistoryEntryR\aentries\"7\n" +
 	"\x11CreatePlanRequest\x12\"\n" +
 	"\x04plan\x18\x01 \x01(\v2\x0e.userplan.PlanR\x04plan\"\x1f\n" +
 	"\rPlanIDRequest\x12\x0e\n" +
@@ -980,19 +3137,123 @@ const file_userplan_proto_rawDesc = "" +
 	"\x06offset\x18\x02 \x01(\x05R\x06offset\"O\n" +
 	"\x11ListPlansResponse\x12$\n" +
 	"\x05plans\x18\x01 \x03(\v2\x0e.userplan.PlanR\x05plans\x12\x14\n" +
-	"\x05total\x18\x02 \x01(\x03R\x05total2\x85\x02\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\"Y\n" +
+	"\x10PlanPriceRequest\x12\x17\n" +
+	"\aplan_id\x18\x01 \x01(\x04R\x06planId\x12\x16\n" +
+	"\x06months\x18\x02 \x01(\x05R\x06months\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\x03R\x05price\"E\n" +
+	"\x12PlanPriceIDRequest\x12\x17\n" +
+	"\aplan_id\x18\x01 \x01(\x04R\x06planId\x12\x16\n" +
+	"\x06months\x18\x02 \x01(\x05R\x06months\"E\n" +
+	"\x16ListPlanPricesResponse\x12+\n" +
+	"\x06prices\x18\x01 \x03(\v2\x13.userplan.PlanPriceR\x06prices\"2\n" +
+	"\n" +
+	"Limitation\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\"\xca\x01\n" +
+	"\x0ePlanLimitation\x12\x17\n" +
+	"\aplan_id\x18\x01 \x01(\x04R\x06planId\x124\n" +
+	"\n" +
+	"limitation\x18\x02 \x01(\v2\x14.userplan.LimitationR\n" +
+	"limitation\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\x03R\x05value\x12\x1d\n" +
+	"\n" +
+	"unit_price\x18\x04 \x01(\x03R\tunitPrice\x12$\n" +
+	"\vtrial_value\x18\x05 \x01(\x03H\x00R\n" +
+	"trialValue\x88\x01\x01B\x0e\n" +
+	"\f_trial_value\"O\n" +
+	"\x17CreateLimitationRequest\x124\n" +
+	"\n" +
+	"limitation\x18\x01 \x01(\v2\x14.userplan.LimitationR\n" +
+	"limitation\"O\n" +
+	"\x17UpdateLimitationRequest\x124\n" +
+	"\n" +
+	"limitation\x18\x01 \x01(\v2\x14.userplan.LimitationR\n" +
+	"limitation\"%\n" +
+	"\x13LimitationIDRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"Q\n" +
+	"\x17ListLimitationsResponse\x126\n" +
+	"\vlimitations\x18\x01 \x03(\v2\x14.userplan.LimitationR\vlimitations\"Y\n" +
+	"\x1bListPlanLimitationsResponse\x12:\n" +
+	"\vlimitations\x18\x01 \x03(\v2\x18.userplan.PlanLimitationR\vlimitations\"\xc0\x01\n" +
+	"\x15PlanLimitationRequest\x12\x17\n" +
+	"\aplan_id\x18\x01 \x01(\x04R\x06planId\x12#\n" +
+	"\rlimitation_id\x18\x02 \x01(\x04R\flimitationId\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\x03R\x05value\x12\x1d\n" +
+	"\n" +
+	"unit_price\x18\x04 \x01(\x03R\tunitPrice\x12$\n" +
+	"\vtrial_value\x18\x05 \x01(\x03H\x00R\n" +
+	"trialValue\x88\x01\x01B\x0e\n" +
+	"\f_trial_value\"W\n" +
+	"\x17PlanLimitationIDRequest\x12\x17\n" +
+	"\aplan_id\x18\x01 \x01(\x04R\x06planId\x12#\n" +
+	"\rlimitation_id\x18\x02 \x01(\x04R\flimitationId\"_\n" +
+	"\fQuotaRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12\x1e\n" +
+	"\n" +
+	"limitation\x18\x02 \x01(\tR\n" +
+	"limitation\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\x03R\x06amount\"\x96\x02\n" +
+	"\x05Quota\x12\x1e\n" +
+	"\n" +
+	"limitation\x18\x01 \x01(\tR\n" +
+	"limitation\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x03R\x05limit\x12\x12\n" +
+	"\x04used\x18\x03 \x01(\x03R\x04used\x12\x1c\n" +
+	"\tremaining\x18\x04 \x01(\x03R\tremaining\x12\x18\n" +
+	"\aallowed\x18\x05 \x01(\bR\aallowed\x12!\n" +
+	"\fperiod_start\x18\x06 \x01(\x03R\vperiodStart\x12\x1d\n" +
+	"\n" +
+	"period_end\x18\a \x01(\x03R\tperiodEnd\x12\x18\n" +
+	"\ametered\x18\b \x01(\bR\ametered\x12\x19\n" +
+	"\bpast_due\x18\t \x01(\bR\apastDue\x12\x14\n" +
+	"\x05trial\x18\n" +
+	" \x01(\bR\x05trial\"8\n" +
+	"\rUsageResponse\x12'\n" +
+	"\x06quotas\x18\x01 \x03(\v2\x0f.userplan.QuotaR\x06quotas\"H\n" +
+	"\x15UsageStatementRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12\x16\n" +
+	"\x06period\x18\x02 \x01(\x03R\x06period\"\x81\x01\n" +
+	"\x12UsageStatementLine\x12\x1e\n" +
+	"\n" +
+	"limitation\x18\x01 \x01(\tR\n" +
+	"limitation\x12\x14\n" +
+	"\x05units\x18\x02 \x01(\x03R\x05units\x12\x1d\n" +
+	"\n" +
+	"unit_price\x18\x03 \x01(\x03R\tunitPrice\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\x03R\x06amount\"\xce\x01\n" +
+	"\x0eUsageStatement\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12\x17\n" +
+	"\aplan_id\x18\x02 \x01(\x04R\x06planId\x12!\n" +
+	"\fperiod_start\x18\x03 \x01(\x03R\vperiodStart\x12\x1d\n" +
+	"\n" +
+	"period_end\x18\x04 \x01(\x03R\tperiodEnd\x122\n" +
+	"\x05lines\x18\x05 \x03(\v2\x1c.userplan.UsageStatementLineR\x05lines\x12\x14\n" +
+	"\x05total\x18\x06 \x01(\x03R\x05total2\x85\x02\n" +
 	"\vUserService\x12;\n" +
 	"\tListUsers\x12\x14.userplan.UserFilter\x1a\x18.userplan.PaginatedUsers\x12:\n" +
 	"\n" +
 	"CreateUser\x12\x1b.userplan.CreateUserRequest\x1a\x0f.userplan.Empty\x12:\n" +
 	"\n" +
 	"UpdateUser\x12\x1b.userplan.UpdateUserRequest\x1a\x0f.userplan.Empty\x12A\n" +
-	"\rSetUserActive\x12\x1f.userplan.UserActivationRequest\x1a\x0f.userplan.Empty2\xeb\x04\n" +
+	"\rSetUserActive\x12\x1f.userplan.UserActivationRequest\x1a\x0f.userplan.Empty2\xe2\f\n" +
 	"\vPlanService\x12>\n" +
 	"\n" +
 	"AssignPlan\x12\x1f.userplan.PlanAssignmentRequest\x1a\x0f.userplan.Empty\x128\n" +
 	"\vGetUserPlan\x12\x19.userplan.UserPlanRequest\x1a\x0e.userplan.Plan\x12<\n" +
-	"\rRenewUserPlan\x12\x1a.userplan.RenewPlanRequest\x1a\x0f.userplan.Empty\x129\n" +
+	"\rRenewUserPlan\x12\x1a.userplan.RenewPlanRequest\x1a\x0f.userplan.Empty\x12K\n" +
+	"\x0eChangeUserPlan\x12\x1b.userplan.ChangePlanRequest\x1a\x1c.userplan.ChangePlanResponse\x12;\n" +
+	"\fSetAutoRenew\x12\x1a.userplan.AutoRenewRequest\x1a\x0f.userplan.Empty\x12:\n" +
+	"\n" +
+	"StartTrial\x12\x1b.userplan.StartTrialRequest\x1a\x0f.userplan.Empty\x12D\n" +
+	"\x10ActivateUserPlan\x12\x1f.userplan.PlanTransitionRequest\x1a\x0f.userplan.Empty\x12C\n" +
+	"\x0fSuspendUserPlan\x12\x1f.userplan.PlanTransitionRequest\x1a\x0f.userplan.Empty\x12B\n" +
+	"\x0eResumeUserPlan\x12\x1f.userplan.PlanTransitionRequest\x1a\x0f.userplan.Empty\x12B\n" +
+	"\x0eCancelUserPlan\x12\x1f.userplan.PlanTransitionRequest\x1a\x0f.userplan.Empty\x12M\n" +
+	"\x0eGetPlanHistory\x12\x1c.userplan.PlanHistoryRequest\x1a\x1d.userplan.PlanHistoryResponse\x12T\n" +
+	"\x16ScheduleUserPlanChange\x12\x1f.userplan.ScheduleChangeRequest\x1a\x19.userplan.ScheduledChange\x12Y\n" +
+	"\x14ListScheduledChanges\x12\x19.userplan.UserPlanRequest\x1a&.userplan.ListScheduledChangesResponse\x12L\n" +
+	"\x15RevokeScheduledChange\x12\".userplan.ScheduledChangeIDRequest\x1a\x0f.userplan.Empty\x129\n" +
 	"\n" +
 	"CreatePlan\x12\x1b.userplan.CreatePlanRequest\x1a\x0e.userplan.Plan\x126\n" +
 	"\vGetPlanByID\x12\x17.userplan.PlanIDRequest\x1a\x0e.userplan.Plan\x12:\n" +
@@ -1002,7 +3263,25 @@ const file_userplan_proto_rawDesc = "" +
 	"\n" +
 	"DeletePlan\x12\x17.userplan.PlanIDRequest\x1a\x0f.userplan.Empty\x12D\n" +
 	"\tListPlans\x12\x1a.userplan.ListPlansRequest\x1a\x1b.userplan.ListPlansResponse\x12<\n" +
-	"\x10TogglePlanActive\x12\x17.userplan.PlanIDRequest\x1a\x0f.userplan.EmptyB4Z2hamgit.ir/arcaptcha/arcaptcha-dumbledore/protos;pbb\x06proto3"
+	"\x10TogglePlanActive\x12\x17.userplan.PlanIDRequest\x1a\x0f.userplan.Empty\x12?\n" +
+	"\fSetPlanPrice\x12\x1a.userplan.PlanPriceRequest\x1a\x13.userplan.PlanPrice\x12K\n" +
+	"\x0eListPlanPrices\x12\x17.userplan.PlanIDRequest\x1a .userplan.ListPlanPricesResponse\x12@\n" +
+	"\x0fDeletePlanPrice\x12\x1c.userplan.PlanPriceIDRequest\x1a\x0f.userplan.Empty2\x87\x05\n" +
+	"\x11LimitationService\x12E\n" +
+	"\x0fListLimitations\x12\x0f.userplan.Empty\x1a!.userplan.ListLimitationsResponse\x12K\n" +
+	"\x10CreateLimitation\x12!.userplan.CreateLimitationRequest\x1a\x14.userplan.Limitation\x12K\n" +
+	"\x10UpdateLimitation\x12!.userplan.UpdateLimitationRequest\x1a\x14.userplan.Limitation\x12B\n" +
+	"\x10DeleteLimitation\x12\x1d.userplan.LimitationIDRequest\x1a\x0f.userplan.Empty\x12U\n" +
+	"\x13ListPlanLimitations\x12\x17.userplan.PlanIDRequest\x1a%.userplan.ListPlanLimitationsResponse\x12S\n" +
+	"\x16AssignLimitationToPlan\x12\x1f.userplan.PlanLimitationRequest\x1a\x18.userplan.PlanLimitation\x12Q\n" +
+	"\x14UpdatePlanLimitation\x12\x1f.userplan.PlanLimitationRequest\x1a\x18.userplan.PlanLimitation\x12N\n" +
+	"\x18RemoveLimitationFromPlan\x12!.userplan.PlanLimitationIDRequest\x1a\x0f.userplan.Empty2\x8e\x02\n" +
+	"\fUsageService\x125\n" +
+	"\n" +
+	"CheckQuota\x12\x16.userplan.QuotaRequest\x1a\x0f.userplan.Quota\x127\n" +
+	"\fConsumeQuota\x12\x16.userplan.QuotaRequest\x1a\x0f.userplan.Quota\x12>\n" +
+	"\bGetUsage\x12\x19.userplan.UserPlanRequest\x1a\x17.userplan.UsageResponse\x12N\n" +
+	"\x11GetUsageStatement\x12\x1f.userplan.UsageStatementRequest\x1a\x18.userplan.UsageStatementB4Z2hamgit.ir/arcaptcha/arcaptcha-dumbledore/protos;pbb\x06proto3"
 
 var (
 	file_userplan_proto_rawDescOnce sync.Once
@@ -1016,66 +3295,160 @@ func file_userplan_proto_rawDescGZIP() []byte {
 	return file_userplan_proto_rawDescData
 }
 
-var file_userplan_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_userplan_proto_msgTypes = make([]protoimpl.MessageInfo, 48)
 var file_userplan_proto_goTypes = []any{
-	(*Empty)(nil),                 // 0: userplan.Empty
-	(*User)(nil),                  // 1: userplan.User
-	(*UserFilter)(nil),            // 2: userplan.UserFilter
-	(*CreateUserRequest)(nil),     // 3: userplan.CreateUserRequest
-	(*UpdateUserRequest)(nil),     // 4: userplan.UpdateUserRequest
-	(*PaginatedUsers)(nil),        // 5: userplan.PaginatedUsers
-	(*UserActivationRequest)(nil), // 6: userplan.UserActivationRequest
-	(*Plan)(nil),                  // 7: userplan.Plan
-	(*PlanAssignmentRequest)(nil), // 8: userplan.PlanAssignmentRequest
-	(*UserPlanRequest)(nil),       // 9: userplan.UserPlanRequest
-	(*RenewPlanRequest)(nil),      // 10: userplan.RenewPlanRequest
-	(*CreatePlanRequest)(nil),     // 11: userplan.CreatePlanRequest
-	(*PlanIDRequest)(nil),         // 12: userplan.PlanIDRequest
-	(*PlanNameRequest)(nil),       // 13: userplan.PlanNameRequest
-	(*UpdatePlanRequest)(nil),     // 14: userplan.UpdatePlanRequest
-	(*ListPlansRequest)(nil),      // 15: userplan.ListPlansRequest
-	(*ListPlansResponse)(nil),     // 16: userplan.ListPlansResponse
+	(*Empty)(nil),                        // 0: userplan.Empty
+	(*User)(nil),                         // 1: userplan.User
+	(*UserFilter)(nil),                   // 2: userplan.UserFilter
+	(*CreateUserRequest)(nil),            // 3: userplan.CreateUserRequest
+	(*UpdateUserRequest)(nil),            // 4: userplan.UpdateUserRequest
+	(*PaginatedUsers)(nil),               // 5: userplan.PaginatedUsers
+	(*UserActivationRequest)(nil),        // 6: userplan.UserActivationRequest
+	(*Plan)(nil),                         // 7: userplan.Plan
+	(*PlanPrice)(nil),                    // 8: userplan.PlanPrice
+	(*PlanAssignmentRequest)(nil),        // 9: userplan.PlanAssignmentRequest
+	(*StartTrialRequest)(nil),            // 10: userplan.StartTrialRequest
+	(*AutoRenewRequest)(nil),             // 11: userplan.AutoRenewRequest
+	(*UserPlanRequest)(nil),              // 12: userplan.UserPlanRequest
+	(*RenewPlanRequest)(nil),             // 13: userplan.RenewPlanRequest
+	(*ChangePlanRequest)(nil),            // 14: userplan.ChangePlanRequest
+	(*ChangePlanResponse)(nil),           // 15: userplan.ChangePlanResponse
+	(*PlanTransitionRequest)(nil),        // 16: userplan.PlanTransitionRequest
+	(*ScheduleChangeRequest)(nil),        // 17: userplan.ScheduleChangeRequest
+	(*ScheduledChange)(nil),              // 18: userplan.ScheduledChange
+	(*ListScheduledChangesResponse)(nil), // 19: userplan.ListScheduledChangesResponse
+	(*ScheduledChangeIDRequest)(nil),     // 20: userplan.ScheduledChangeIDRequest
+	(*PlanHistoryEntry)(nil),             // 21: userplan.PlanHistoryEntry
+	(*PlanHistoryRequest)(nil),           // 22: userplan.PlanHistoryRequest
+	(*PlanHistoryResponse)(nil),          // 23: userplan.PlanHistoryResponse
+	(*CreatePlanRequest)(nil),            // 24: userplan.CreatePlanRequest
+	(*PlanIDRequest)(nil),                // 25: userplan.PlanIDRequest
+	(*PlanNameRequest)(nil),              // 26: userplan.PlanNameRequest
+	(*UpdatePlanRequest)(nil),            // 27: userplan.UpdatePlanRequest
+	(*ListPlansRequest)(nil),             // 28: userplan.ListPlansRequest
+	(*ListPlansResponse)(nil),            // 29: userplan.ListPlansResponse
+	(*PlanPriceRequest)(nil),             // 30: userplan.PlanPriceRequest
+	(*PlanPriceIDRequest)(nil),           // 31: userplan.PlanPriceIDRequest
+	(*ListPlanPricesResponse)(nil),       // 32: userplan.ListPlanPricesResponse
+	(*Limitation)(nil),                   // 33: userplan.Limitation
+	(*PlanLimitation)(nil),               // 34: userplan.PlanLimitation
+	(*CreateLimitationRequest)(nil),      // 35: userplan.CreateLimitationRequest
+	(*UpdateLimitationRequest)(nil),      // 36: userplan.UpdateLimitationRequest
+	(*LimitationIDRequest)(nil),          // 37: userplan.LimitationIDRequest
+	(*ListLimitationsResponse)(nil),      // 38: userplan.ListLimitationsResponse
+	(*ListPlanLimitationsResponse)(nil),  // 39: userplan.ListPlanLimitationsResponse
+	(*PlanLimitationRequest)(nil),        // 40: userplan.PlanLimitationRequest
+	(*PlanLimitationIDRequest)(nil),      // 41: userplan.PlanLimitationIDRequest
+	(*QuotaRequest)(nil),                 // 42: userplan.QuotaRequest
+	(*Quota)(nil),                        // 43: userplan.Quota
+	(*UsageResponse)(nil),                // 44: userplan.UsageResponse
+	(*UsageStatementRequest)(nil),        // 45: userplan.UsageStatementRequest
+	(*UsageStatementLine)(nil),           // 46: userplan.UsageStatementLine
+	(*UsageStatement)(nil),               // 47: userplan.UsageStatement
 }
 var file_userplan_proto_depIdxs = []int32{
 	1,  // 0: userplan.CreateUserRequest.user:type_name -> userplan.User
 	1,  // 1: userplan.UpdateUserRequest.user:type_name -> userplan.User
 	1,  // 2: userplan.PaginatedUsers.users:type_name -> userplan.User
-	7,  // 3: userplan.CreatePlanRequest.plan:type_name -> userplan.Plan
-	7,  // 4: userplan.UpdatePlanRequest.plan:type_name -> userplan.Plan
-	7,  // 5: userplan.ListPlansResponse.plans:type_name -> userplan.Plan
-	2,  // 6: userplan.UserService.ListUsers:input_type -> userplan.UserFilter
-	3,  // 7: userplan.UserService.CreateUser:input_type -> userplan.CreateUserRequest
-	4,  // 8: userplan.UserService.UpdateUser:input_type -> userplan.UpdateUserRequest
-	6,  // 9: userplan.UserService.SetUserActive:input_type -> userplan.UserActivationRequest
-	8,  // 10: userplan.PlanService.AssignPlan:input_type -> userplan.PlanAssignmentRequest
-	9,  // 11: userplan.PlanService.GetUserPlan:input_type -> userplan.UserPlanRequest
-	10, // 12: userplan.PlanService.RenewUserPlan:input_type -> userplan.RenewPlanRequest
-	11, // 13: userplan.PlanService.CreatePlan:input_type -> userplan.CreatePlanRequest
-	12, // 14: userplan.PlanService.GetPlanByID:input_type -> userplan.PlanIDRequest
-	13, // 15: userplan.PlanService.GetPlanByName:input_type -> userplan.PlanNameRequest
-	14, // 16: userplan.PlanService.UpdatePlan:input_type -> userplan.UpdatePlanRequest
-	12, // 17: userplan.PlanService.DeletePlan:input_type -> userplan.PlanIDRequest
-	15, // 18: userplan.PlanService.ListPlans:input_type -> userplan.ListPlansRequest
-	12, // 19: userplan.PlanService.TogglePlanActive:input_type -> userplan.PlanIDRequest
-	5,  // 20: userplan.UserService.ListUsers:output_type -> userplan.PaginatedUsers
-	0,  // 21: userplan.UserService.CreateUser:output_type -> userplan.Empty
-	0,  // 22: userplan.UserService.UpdateUser:output_type -> userplan.Empty
-	0,  // 23: userplan.UserService.SetUserActive:output_type -> userplan.Empty
-	0,  // 24: userplan.PlanService.AssignPlan:output_type -> userplan.Empty
-	7,  // 25: userplan.PlanService.GetUserPlan:output_type -> userplan.Plan
-	0,  // 26: userplan.PlanService.RenewUserPlan:output_type -> userplan.Empty
-	7,  // 27: userplan.PlanService.CreatePlan:output_type -> userplan.Plan
-	7,  // 28: userplan.PlanService.GetPlanByID:output_type -> userplan.Plan
-	7,  // 29: userplan.PlanService.GetPlanByName:output_type -> userplan.Plan
-	7,  // 30: userplan.PlanService.UpdatePlan:output_type -> userplan.Plan
-	0,  // 31: userplan.PlanService.DeletePlan:output_type -> userplan.Empty
-	16, // 32: userplan.PlanService.ListPlans:output_type -> userplan.ListPlansResponse
-	0,  // 33: userplan.PlanService.TogglePlanActive:output_type -> userplan.Empty
-	20, // [20:34] is the sub-list for method output_type
-	6,  // [6:20] is the sub-list for method input_type
-	6,  // [6:6] is the sub-list for extension type_name
-	6,  // [6:6] is the sub-list for extension extendee
-	0,  // [0:6] is the sub-list for field type_name
+	8,  // 3: userplan.Plan.prices:type_name -> userplan.PlanPrice
+	18, // 4: userplan.ListScheduledChangesResponse.changes:type_name -> userplan.ScheduledChange
+	21, // 5: userplan.PlanHistoryResponse.entries:type_name -> userplan.PlanHistoryEntry
+	7,  // 6: userplan.CreatePlanRequest.plan:type_name -> userplan.Plan
+	7,  // 7: userplan.UpdatePlanRequest.plan:type_name -> userplan.Plan
+	7,  // 8: userplan.ListPlansResponse.plans:type_name -> userplan.Plan
+	8,  // 9: userplan.ListPlanPricesResponse.prices:type_name -> userplan.PlanPrice
+	33, // 10: userplan.PlanLimitation.limitation:type_name -> userplan.Limitation
+	33, // 11: userplan.CreateLimitationRequest.limitation:type_name -> userplan.Limitation
+	33, // 12: userplan.UpdateLimitationRequest.limitation:type_name -> userplan.Limitation
+	33, // 13: userplan.ListLimitationsResponse.limitations:type_name -> userplan.Limitation
+	34, // 14: userplan.ListPlanLimitationsResponse.limitations:type_name -> userplan.PlanLimitation
+	43, // 15: userplan.UsageResponse.quotas:type_name -> userplan.Quota
+	46, // 16: userplan.UsageStatement.lines:type_name -> userplan.UsageStatementLine
+	2,  // 17: userplan.UserService.ListUsers:input_type -> userplan.UserFilter
+	3,  // 18: userplan.UserService.CreateUser:input_type -> userplan.CreateUserRequest
+	4,  // 19: userplan.UserService.UpdateUser:input_type -> userplan.UpdateUserRequest
+	6,  // 20: userplan.UserService.SetUserActive:input_type -> userplan.UserActivationRequest
+	9,  // 21: userplan.PlanService.AssignPlan:input_type -> userplan.PlanAssignmentRequest
+	12, // 22: userplan.PlanService.GetUserPlan:input_type -> userplan.UserPlanRequest
+	13, // 23: userplan.PlanService.RenewUserPlan:input_type -> userplan.RenewPlanRequest
+	14, // 24: userplan.PlanService.ChangeUserPlan:input_type -> userplan.ChangePlanRequest
+	11, // 25: userplan.PlanService.SetAutoRenew:input_type -> userplan.AutoRenewRequest
+	10, // 26: userplan.PlanService.StartTrial:input_type -> userplan.StartTrialRequest
+	16, // 27: userplan.PlanService.ActivateUserPlan:input_type -> userplan.PlanTransitionRequest
+	16, // 28: userplan.PlanService.SuspendUserPlan:input_type -> userplan.PlanTransitionRequest
+	16, // 29: userplan.PlanService.ResumeUserPlan:input_type -> userplan.PlanTransitionRequest
+	16, // 30: userplan.PlanService.CancelUserPlan:input_type -> userplan.PlanTransitionRequest
+	22, // 31: userplan.PlanService.GetPlanHistory:input_type -> userplan.PlanHistoryRequest
+	17, // 32: userplan.PlanService.ScheduleUserPlanChange:input_type -> userplan.ScheduleChangeRequest
+	12, // 33: userplan.PlanService.ListScheduledChanges:input_type -> userplan.UserPlanRequest
+	20, // 34: userplan.PlanService.RevokeScheduledChange:input_type -> userplan.ScheduledChangeIDRequest
+	24, // 35: userplan.PlanService.CreatePlan:input_type -> userplan.CreatePlanRequest
+	25, // 36: userplan.PlanService.GetPlanByID:input_type -> userplan.PlanIDRequest
+	26, // 37: userplan.PlanService.GetPlanByName:input_type -> userplan.PlanNameRequest
+	27, // 38: userplan.PlanService.UpdatePlan:input_type -> userplan.UpdatePlanRequest
+	25, // 39: userplan.PlanService.DeletePlan:input_type -> userplan.PlanIDRequest
+	28, // 40: userplan.PlanService.ListPlans:input_type -> userplan.ListPlansRequest
+	25, // 41: userplan.PlanService.TogglePlanActive:input_type -> userplan.PlanIDRequest
+	30, // 42: userplan.PlanService.SetPlanPrice:input_type -> userplan.PlanPriceRequest
+	25, // 43: userplan.PlanService.ListPlanPrices:input_type -> userplan.PlanIDRequest
+	31, // 44: userplan.PlanService.DeletePlanPrice:input_type -> userplan.PlanPriceIDRequest
+	0,  // 45: userplan.LimitationService.ListLimitations:input_type -> userplan.Empty
+	35, // 46: userplan.LimitationService.CreateLimitation:input_type -> userplan.CreateLimitationRequest
+	36, // 47: userplan.LimitationService.UpdateLimitation:input_type -> userplan.UpdateLimitationRequest
+	37, // 48: userplan.LimitationService.DeleteLimitation:input_type -> userplan.LimitationIDRequest
+	25, // 49: userplan.LimitationService.ListPlanLimitations:input_type -> userplan.PlanIDRequest
+	40, // 50: userplan.LimitationService.AssignLimitationToPlan:input_type -> userplan.PlanLimitationRequest
+	40, // 51: userplan.LimitationService.UpdatePlanLimitation:input_type -> userplan.PlanLimitationRequest
+	41, // 52: userplan.LimitationService.RemoveLimitationFromPlan:input_type -> userplan.PlanLimitationIDRequest
+	42, // 53: userplan.UsageService.CheckQuota:input_type -> userplan.QuotaRequest
+	42, // 54: userplan.UsageService.ConsumeQuota:input_type -> userplan.QuotaRequest
+	12, // 55: userplan.UsageService.GetUsage:input_type -> userplan.UserPlanRequest
+	45, // 56: userplan.UsageService.GetUsageStatement:input_type -> userplan.UsageStatementRequest
+	5,  // 57: userplan.UserService.ListUsers:output_type -> userplan.PaginatedUsers
+	0,  // 58: userplan.UserService.CreateUser:output_type -> userplan.Empty
+	0,  // 59: userplan.UserService.UpdateUser:output_type -> userplan.Empty
+	0,  // 60: userplan.UserService.SetUserActive:output_type -> userplan.Empty
+	0,  // 61: userplan.PlanService.AssignPlan:output_type -> userplan.Empty
+	7,  // 62: userplan.PlanService.GetUserPlan:output_type -> userplan.Plan
+	0,  // 63: userplan.PlanService.RenewUserPlan:output_type -> userplan.Empty
+	15, // 64: userplan.PlanService.ChangeUserPlan:output_type -> userplan.ChangePlanResponse
+	0,  // 65: userplan.PlanService.SetAutoRenew:output_type -> userplan.Empty
+	0,  // 66: userplan.PlanService.StartTrial:output_type -> userplan.Empty
+	0,  // 67: userplan.PlanService.ActivateUserPlan:output_type -> userplan.Empty
+	0,  // 68: userplan.PlanService.SuspendUserPlan:output_type -> userplan.Empty
+	0,  // 69: userplan.PlanService.ResumeUserPlan:output_type -> userplan.Empty
+	0,  // 70: userplan.PlanService.CancelUserPlan:output_type -> userplan.Empty
+	23, // 71: userplan.PlanService.GetPlanHistory:output_type -> userplan.PlanHistoryResponse
+	18, // 72: userplan.PlanService.ScheduleUserPlanChange:output_type -> userplan.ScheduledChange
+	19, // 73: userplan.PlanService.ListScheduledChanges:output_type -> userplan.ListScheduledChangesResponse
+	0,  // 74: userplan.PlanService.RevokeScheduledChange:output_type -> userplan.Empty
+	7,  // 75: userplan.PlanService.CreatePlan:output_type -> userplan.Plan
+	7,  // 76: userplan.PlanService.GetPlanByID:output_type -> userplan.Plan
+	7,  // 77: userplan.PlanService.GetPlanByName:output_type -> userplan.Plan
+	7,  // 78: userplan.PlanService.UpdatePlan:output_type -> userplan.Plan
+	0,  // 79: userplan.PlanService.DeletePlan:output_type -> userplan.Empty
+	29, // 80: userplan.PlanService.ListPlans:output_type -> userplan.ListPlansResponse
+	0,  // 81: userplan.PlanService.TogglePlanActive:output_type -> userplan.Empty
+	8,  // 82: userplan.PlanService.SetPlanPrice:output_type -> userplan.PlanPrice
+	32, // 83: userplan.PlanService.ListPlanPrices:output_type -> userplan.ListPlanPricesResponse
+	0,  // 84: userplan.PlanService.DeletePlanPrice:output_type -> userplan.Empty
+	38, // 85: userplan.LimitationService.ListLimitations:output_type -> userplan.ListLimitationsResponse
+	33, // 86: userplan.LimitationService.CreateLimitation:output_type -> userplan.Limitation
+	33, // 87: userplan.LimitationService.UpdateLimitation:output_type -> userplan.Limitation
+	0,  // 88: userplan.LimitationService.DeleteLimitation:output_type -> userplan.Empty
+	39, // 89: userplan.LimitationService.ListPlanLimitations:output_type -> userplan.ListPlanLimitationsResponse
+	34, // 90: userplan.LimitationService.AssignLimitationToPlan:output_type -> userplan.PlanLimitation
+	34, // 91: userplan.LimitationService.UpdatePlanLimitation:output_type -> userplan.PlanLimitation
+	0,  // 92: userplan.LimitationService.RemoveLimitationFromPlan:output_type -> userplan.Empty
+	43, // 93: userplan.UsageService.CheckQuota:output_type -> userplan.Quota
+	43, // 94: userplan.UsageService.ConsumeQuota:output_type -> userplan.Quota
+	44, // 95: userplan.UsageService.GetUsage:output_type -> userplan.UsageResponse
+	47, // 96: userplan.UsageService.GetUsageStatement:output_type -> userplan.UsageStatement
+	57, // [57:97] is the sub-list for method output_type
+	17, // [17:57] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
 }
 
 func init() { file_userplan_proto_init() }
@@ -1083,15 +3456,17 @@ func file_userplan_proto_init() {
 	if File_userplan_proto != nil {
 		return
 	}
+	file_userplan_proto_msgTypes[34].OneofWrappers = []any{}
+	file_userplan_proto_msgTypes[40].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_userplan_proto_rawDesc), len(file_userplan_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   17,
+			NumMessages:   48,
 			NumExtensions: 0,
-			NumServices:   2,
+			NumServices:   4,
 		},
 		GoTypes:           file_userplan_proto_goTypes,
 		DependencyIndexes: file_userplan_proto_depIdxs,
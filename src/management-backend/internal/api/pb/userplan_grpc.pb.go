@@ -235,16 +235,30 @@ var UserService_ServiceDesc = grpc.ServiceDesc{
 }
 
 const (
-	PlanService_AssignPlan_FullMethodName       = "/userplan.PlanService/AssignPlan"
-	PlanService_GetUserPlan_FullMethodName      = "/userplan.PlanService/GetUserPlan"
-	PlanService_RenewUserPlan_FullMethodName    = "/userplan.PlanService/RenewUserPlan"
-	PlanService_CreatePlan_FullMethodName       = "/userplan.PlanService/CreatePlan"
-	PlanService_GetPlanByID_FullMethodName      = "/userplan.PlanService/GetPlanByID"
-	PlanService_GetPlanByName_FullMethodName    = "/userplan.PlanService/GetPlanByName"
-	PlanService_UpdatePlan_FullMethodName       = "/userplan.PlanService/UpdatePlan"
-	PlanService_DeletePlan_FullMethodName       = "/userplan.PlanService/DeletePlan"
-	PlanService_ListPlans_FullMethodName        = "/userplan.PlanService/ListPlans"
-	PlanService_TogglePlanActive_FullMethodName = "/userplan.PlanService/TogglePlanActive"
+	PlanService_AssignPlan_FullMethodName             = "/userplan.PlanService/AssignPlan"
+	PlanService_GetUserPlan_FullMethodName            = "/userplan.PlanService/GetUserPlan"
+	PlanService_RenewUserPlan_FullMethodName          = "/userplan.PlanService/RenewUserPlan"
+	PlanService_ChangeUserPlan_FullMethodName         = "/userplan.PlanService/ChangeUserPlan"
+	PlanService_SetAutoRenew_FullMethodName           = "/userplan.PlanService/SetAutoRenew"
+	PlanService_StartTrial_FullMethodName             = "/userplan.PlanService/StartTrial"
+	PlanService_ActivateUserPlan_FullMethodName       = "/userplan.PlanService/ActivateUserPlan"
+	PlanService_SuspendUserPlan_FullMethodName        = "/userplan.PlanService/SuspendUserPlan"
+	PlanService_ResumeUserPlan_FullMethodName         = "/userplan.PlanService/ResumeUserPlan"
+	PlanService_CancelUserPlan_FullMethodName         = "/userplan.PlanService/CancelUserPlan"
+	PlanService_GetPlanHistory_FullMethodName         = "/userplan.PlanService/GetPlanHistory"
+	PlanService_ScheduleUserPlanChange_FullMethodName = "/userplan.PlanService/ScheduleUserPlanChange"
+	PlanService_ListScheduledChanges_FullMethodName   = "/userplan.PlanService/ListScheduledChanges"
+	PlanService_RevokeScheduledChange_FullMethodName  = "/userplan.PlanService/RevokeScheduledChange"
+	PlanService_CreatePlan_FullMethodName             = "/userplan.PlanService/CreatePlan"
+	PlanService_GetPlanByID_FullMethodName            = "/userplan.PlanService/GetPlanByID"
+	PlanService_GetPlanByName_FullMethodName          = "/userplan.PlanService/GetPlanByName"
+	PlanService_UpdatePlan_FullMethodName             = "/userplan.PlanService/UpdatePlan"
+	PlanService_DeletePlan_FullMethodName             = "/userplan.PlanService/DeletePlan"
+	PlanService_ListPlans_FullMethodName              = "/userplan.PlanService/ListPlans"
+	PlanService_TogglePlanActive_FullMethodName       = "/userplan.PlanService/TogglePlanActive"
+	PlanService_SetPlanPrice_FullMethodName           = "/userplan.PlanService/SetPlanPrice"
+	PlanService_ListPlanPrices_FullMethodName         = "/userplan.PlanService/ListPlanPrices"
+	PlanService_DeletePlanPrice_FullMethodName        = "/userplan.PlanService/DeletePlanPrice"
 )
 
 // PlanServiceClient is the client API for PlanService service.
@@ -254,6 +268,19 @@ type PlanServiceClient interface {
 	AssignPlan(ctx context.Context, in *PlanAssignmentRequest, opts ...grpc.CallOption) (*Empty, error)
 	GetUserPlan(ctx context.Context, in *UserPlanRequest, opts ...grpc.CallOption) (*Plan, error)
 	RenewUserPlan(ctx context.Context, in *RenewPlanRequest, opts ...grpc.CallOption) (*Empty, error)
+	ChangeUserPlan(ctx context.Context, in *ChangePlanRequest, opts ...grpc.CallOption) (*ChangePlanResponse, error)
+	SetAutoRenew(ctx context.Context, in *AutoRenewRequest, opts ...grpc.CallOption) (*Empty, error)
+	StartTrial(ctx context.Context, in *StartTrialRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Plan lifecycle methods, every change is recorded in the plan history
+	ActivateUserPlan(ctx context.Context, in *PlanTransitionRequest, opts ...grpc.CallOption) (*Empty, error)
+	SuspendUserPlan(ctx context.Context, in *PlanTransitionRequest, opts ...grpc.CallOption) (*Empty, error)
+	ResumeUserPlan(ctx context.Context, in *PlanTransitionRequest, opts ...grpc.CallOption) (*Empty, error)
+	CancelUserPlan(ctx context.Context, in *PlanTransitionRequest, opts ...grpc.CallOption) (*Empty, error)
+	GetPlanHistory(ctx context.Context, in *PlanHistoryRequest, opts ...grpc.CallOption) (*PlanHistoryResponse, error)
+	// Scheduled plan changes, applied by the expiration job once effective
+	ScheduleUserPlanChange(ctx context.Context, in *ScheduleChangeRequest, opts ...grpc.CallOption) (*ScheduledChange, error)
+	ListScheduledChanges(ctx context.Context, in *UserPlanRequest, opts ...grpc.CallOption) (*ListScheduledChangesResponse, error)
+	RevokeScheduledChange(ctx context.Context, in *ScheduledChangeIDRequest, opts ...grpc.CallOption) (*Empty, error)
 	// Plan management methods
 	CreatePlan(ctx context.Context, in *CreatePlanRequest, opts ...grpc.CallOption) (*Plan, error)
 	GetPlanByID(ctx context.Context, in *PlanIDRequest, opts ...grpc.CallOption) (*Plan, error)
@@ -262,6 +289,10 @@ type PlanServiceClient interface {
 	DeletePlan(ctx context.Context, in *PlanIDRequest, opts ...grpc.CallOption) (*Empty, error)
 	ListPlans(ctx context.Context, in *ListPlansRequest, opts ...grpc.CallOption) (*ListPlansResponse, error)
 	TogglePlanActive(ctx context.Context, in *PlanIDRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Plan price methods
+	SetPlanPrice(ctx context.Context, in *PlanPriceRequest, opts ...grpc.CallOption) (*PlanPrice, error)
+	ListPlanPrices(ctx context.Context, in *PlanIDRequest, opts ...grpc.CallOption) (*ListPlanPricesResponse, error)
+	DeletePlanPrice(ctx context.Context, in *PlanPriceIDRequest, opts ...grpc.CallOption) (*Empty, error)
 }
 
 type planServiceClient struct {
@@ -302,6 +333,116 @@ func (c *planServiceClient) RenewUserPlan(ctx context.Context, in *RenewPlanRequ
 	return out, nil
 }
 
+func (c *planServiceClient) ChangeUserPlan(ctx context.Context, in *ChangePlanRequest, opts ...grpc.CallOption) (*ChangePlanResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChangePlanResponse)
+	err := c.cc.Invoke(ctx, PlanService_ChangeUserPlan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *planServiceClient) SetAutoRenew(ctx context.Context, in *AutoRenewRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, PlanService_SetAutoRenew_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *planServiceClient) StartTrial(ctx context.Context, in *StartTrialRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, PlanService_StartTrial_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *planServiceClient) ActivateUserPlan(ctx context.Context, in *PlanTransitionRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, PlanService_ActivateUserPlan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *planServiceClient) SuspendUserPlan(ctx context.Context, in *PlanTransitionRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, PlanService_SuspendUserPlan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *planServiceClient) ResumeUserPlan(ctx context.Context, in *PlanTransitionRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, PlanService_ResumeUserPlan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *planServiceClient) CancelUserPlan(ctx context.Context, in *PlanTransitionRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, PlanService_CancelUserPlan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *planServiceClient) GetPlanHistory(ctx context.Context, in *PlanHistoryRequest, opts ...grpc.CallOption) (*PlanHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PlanHistoryResponse)
+	err := c.cc.Invoke(ctx, PlanService_GetPlanHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *planServiceClient) ScheduleUserPlanChange(ctx context.Context, in *ScheduleChangeRequest, opts ...grpc.CallOption) (*ScheduledChange, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScheduledChange)
+	err := c.cc.Invoke(ctx, PlanService_ScheduleUserPlanChange_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *planServiceClient) ListScheduledChanges(ctx context.Context, in *UserPlanRequest, opts ...grpc.CallOption) (*ListScheduledChangesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListScheduledChangesResponse)
+	err := c.cc.Invoke(ctx, PlanService_ListScheduledChanges_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *planServiceClient) RevokeScheduledChange(ctx context.Context, in *ScheduledChangeIDRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, PlanService_RevokeScheduledChange_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *planServiceClient) CreatePlan(ctx context.Context, in *CreatePlanRequest, opts ...grpc.CallOption) (*Plan, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(Plan)
@@ -372,6 +513,36 @@ func (c *planServiceClient) TogglePlanActive(ctx context.Context, in *PlanIDRequ
 	return out, nil
 }
 
+func (c *planServiceClient) SetPlanPrice(ctx context.Context, in *PlanPriceRequest, opts ...grpc.CallOption) (*PlanPrice, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PlanPrice)
+	err := c.cc.Invoke(ctx, PlanService_SetPlanPrice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *planServiceClient) ListPlanPrices(ctx context.Context, in *PlanIDRequest, opts ...grpc.CallOption) (*ListPlanPricesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPlanPricesResponse)
+	err := c.cc.Invoke(ctx, PlanService_ListPlanPrices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *planServiceClient) DeletePlanPrice(ctx context.Context, in *PlanPriceIDRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, PlanService_DeletePlanPrice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PlanServiceServer is the server API for PlanService service.
 // All implementations must embed UnimplementedPlanServiceServer
 // for forward compatibility.
@@ -379,6 +550,19 @@ type PlanServiceServer interface {
 	AssignPlan(context.Context, *PlanAssignmentRequest) (*Empty, error)
 	GetUserPlan(context.Context, *UserPlanRequest) (*Plan, error)
 	RenewUserPlan(context.Context, *RenewPlanRequest) (*Empty, error)
+	ChangeUserPlan(context.Context, *ChangePlanRequest) (*ChangePlanResponse, error)
+	SetAutoRenew(context.Context, *AutoRenewRequest) (*Empty, error)
+	StartTrial(context.Context, *StartTrialRequest) (*Empty, error)
+	// Plan lifecycle methods, every change is recorded in the plan history
+	ActivateUserPlan(context.Context, *PlanTransitionRequest) (*Empty, error)
+	SuspendUserPlan(context.Context, *PlanTransitionRequest) (*Empty, error)
+	ResumeUserPlan(context.Context, *PlanTransitionRequest) (*Empty, error)
+	CancelUserPlan(context.Context, *PlanTransitionRequest) (*Empty, error)
+	GetPlanHistory(context.Context, *PlanHistoryRequest) (*PlanHistoryResponse, error)
+	// Scheduled plan changes, applied by the expiration job once effective
+	ScheduleUserPlanChange(context.Context, *ScheduleChangeRequest) (*ScheduledChange, error)
+	ListScheduledChanges(context.Context, *UserPlanRequest) (*ListScheduledChangesResponse, error)
+	RevokeScheduledChange(context.Context, *ScheduledChangeIDRequest) (*Empty, error)
 	// Plan management methods
 	CreatePlan(context.Context, *CreatePlanRequest) (*Plan, error)
 	GetPlanByID(context.Context, *PlanIDRequest) (*Plan, error)
@@ -387,6 +571,10 @@ type PlanServiceServer interface {
 	DeletePlan(context.Context, *PlanIDRequest) (*Empty, error)
 	ListPlans(context.Context, *ListPlansRequest) (*ListPlansResponse, error)
 	TogglePlanActive(context.Context, *PlanIDRequest) (*Empty, error)
+	// Plan price methods
+	SetPlanPrice(context.Context, *PlanPriceRequest) (*PlanPrice, error)
+	ListPlanPrices(context.Context, *PlanIDRequest) (*ListPlanPricesResponse, error)
+	DeletePlanPrice(context.Context, *PlanPriceIDRequest) (*Empty, error)
 	mustEmbedUnimplementedPlanServiceServer()
 }
 
@@ -406,6 +594,39 @@ func (UnimplementedPlanServiceServer) GetUserPlan(context.Context, *UserPlanRequ
 func (UnimplementedPlanServiceServer) RenewUserPlan(context.Context, *RenewPlanRequest) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RenewUserPlan not implemented")
 }
+func (UnimplementedPlanServiceServer) ChangeUserPlan(context.Context, *ChangePlanRequest) (*ChangePlanResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangeUserPlan not implemented")
+}
+func (UnimplementedPlanServiceServer) SetAutoRenew(context.Context, *AutoRenewRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetAutoRenew not implemented")
+}
+func (UnimplementedPlanServiceServer) StartTrial(context.Context, *StartTrialRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartTrial not implemented")
+}
+func (UnimplementedPlanServiceServer) ActivateUserPlan(context.Context, *PlanTransitionRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ActivateUserPlan not implemented")
+}
+func (UnimplementedPlanServiceServer) SuspendUserPlan(context.Context, *PlanTransitionRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SuspendUserPlan not implemented")
+}
+func (UnimplementedPlanServiceServer) ResumeUserPlan(context.Context, *PlanTransitionRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeUserPlan not implemented")
+}
+func (UnimplementedPlanServiceServer) CancelUserPlan(context.Context, *PlanTransitionRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelUserPlan not implemented")
+}
+func (UnimplementedPlanServiceServer) GetPlanHistory(context.Context, *PlanHistoryRequest) (*PlanHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPlanHistory not implemented")
+}
+func (UnimplementedPlanServiceServer) ScheduleUserPlanChange(context.Context, *ScheduleChangeRequest) (*ScheduledChange, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ScheduleUserPlanChange not implemented")
+}
+func (UnimplementedPlanServiceServer) ListScheduledChanges(context.Context, *UserPlanRequest) (*ListScheduledChangesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListScheduledChanges not implemented")
+}
+func (UnimplementedPlanServiceServer) RevokeScheduledChange(context.Context, *ScheduledChangeIDRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeScheduledChange not implemented")
+}
 func (UnimplementedPlanServiceServer) CreatePlan(context.Context, *CreatePlanRequest) (*Plan, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreatePlan not implemented")
 }
@@ -427,6 +648,15 @@ func (UnimplementedPlanServiceServer) ListPlans(context.Context, *ListPlansReque
 func (UnimplementedPlanServiceServer) TogglePlanActive(context.Context, *PlanIDRequest) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method TogglePlanActive not implemented")
 }
+func (UnimplementedPlanServiceServer) SetPlanPrice(context.Context, *PlanPriceRequest) (*PlanPrice, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPlanPrice not implemented")
+}
+func (UnimplementedPlanServiceServer) ListPlanPrices(context.Context, *PlanIDRequest) (*ListPlanPricesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPlanPrices not implemented")
+}
+func (UnimplementedPlanServiceServer) DeletePlanPrice(context.Context, *PlanPriceIDRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePlanPrice not implemented")
+}
 func (UnimplementedPlanServiceServer) mustEmbedUnimplementedPlanServiceServer() {}
 func (UnimplementedPlanServiceServer) testEmbeddedByValue()                     {}
 
@@ -502,151 +732,447 @@ func _PlanService_RenewUserPlan_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
-func _PlanService_CreatePlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreatePlanRequest)
+func _PlanService_ChangeUserPlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangePlanRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(PlanServiceServer).CreatePlan(ctx, in)
+		return srv.(PlanServiceServer).ChangeUserPlan(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: PlanService_CreatePlan_FullMethodName,
+		FullMethod: PlanService_ChangeUserPlan_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(PlanServiceServer).CreatePlan(ctx, req.(*CreatePlanRequest))
+		return srv.(PlanServiceServer).ChangeUserPlan(ctx, req.(*ChangePlanRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _PlanService_GetPlanByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(PlanIDRequest)
+func _PlanService_SetAutoRenew_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AutoRenewRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(PlanServiceServer).GetPlanByID(ctx, in)
+		return srv.(PlanServiceServer).SetAutoRenew(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: PlanService_GetPlanByID_FullMethodName,
+		FullMethod: PlanService_SetAutoRenew_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(PlanServiceServer).GetPlanByID(ctx, req.(*PlanIDRequest))
+		return srv.(PlanServiceServer).SetAutoRenew(ctx, req.(*AutoRenewRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _PlanService_GetPlanByName_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(PlanNameRequest)
+func _PlanService_StartTrial_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartTrialRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(PlanServiceServer).GetPlanByName(ctx, in)
+		return srv.(PlanServiceServer).StartTrial(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: PlanService_GetPlanByName_FullMethodName,
+		FullMethod: PlanService_StartTrial_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(PlanServiceServer).GetPlanByName(ctx, req.(*PlanNameRequest))
+		return srv.(PlanServiceServer).StartTrial(ctx, req.(*StartTrialRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _PlanService_UpdatePlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(UpdatePlanRequest)
+func _PlanService_ActivateUserPlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlanTransitionRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(PlanServiceServer).UpdatePlan(ctx, in)
+		return srv.(PlanServiceServer).ActivateUserPlan(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: PlanService_UpdatePlan_FullMethodName,
+		FullMethod: PlanService_ActivateUserPlan_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(PlanServiceServer).UpdatePlan(ctx, req.(*UpdatePlanRequest))
+		return srv.(PlanServiceServer).ActivateUserPlan(ctx, req.(*PlanTransitionRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _PlanService_DeletePlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(PlanIDRequest)
+func _PlanService_SuspendUserPlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlanTransitionRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(PlanServiceServer).DeletePlan(ctx, in)
+		return srv.(PlanServiceServer).SuspendUserPlan(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: PlanService_DeletePlan_FullMethodName,
+		FullMethod: PlanService_SuspendUserPlan_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(PlanServiceServer).DeletePlan(ctx, req.(*PlanIDRequest))
+		return srv.(PlanServiceServer).SuspendUserPlan(ctx, req.(*PlanTransitionRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _PlanService_ListPlans_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ListPlansRequest)
+func _PlanService_ResumeUserPlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlanTransitionRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(PlanServiceServer).ListPlans(ctx, in)
+		return srv.(PlanServiceServer).ResumeUserPlan(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: PlanService_ListPlans_FullMethodName,
+		FullMethod: PlanService_ResumeUserPlan_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(PlanServiceServer).ListPlans(ctx, req.(*ListPlansRequest))
+		return srv.(PlanServiceServer).ResumeUserPlan(ctx, req.(*PlanTransitionRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _PlanService_TogglePlanActive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+func _PlanService_CancelUserPlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlanTransitionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlanServiceServer).CancelUserPlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlanService_CancelUserPlan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlanServiceServer).CancelUserPlan(ctx, req.(*PlanTransitionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlanService_GetPlanHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlanHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlanServiceServer).GetPlanHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlanService_GetPlanHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlanServiceServer).GetPlanHistory(ctx, req.(*PlanHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlanService_ScheduleUserPlanChange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScheduleChangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlanServiceServer).ScheduleUserPlanChange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlanService_ScheduleUserPlanChange_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlanServiceServer).ScheduleUserPlanChange(ctx, req.(*ScheduleChangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlanService_ListScheduledChanges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserPlanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlanServiceServer).ListScheduledChanges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlanService_ListScheduledChanges_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlanServiceServer).ListScheduledChanges(ctx, req.(*UserPlanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlanService_RevokeScheduledChange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScheduledChangeIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlanServiceServer).RevokeScheduledChange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlanService_RevokeScheduledChange_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlanServiceServer).RevokeScheduledChange(ctx, req.(*ScheduledChangeIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlanService_CreatePlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePlanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlanServiceServer).CreatePlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlanService_CreatePlan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlanServiceServer).CreatePlan(ctx, req.(*CreatePlanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlanService_GetPlanByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PlanIDRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(PlanServiceServer).TogglePlanActive(ctx, in)
+		return srv.(PlanServiceServer).GetPlanByID(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: PlanService_TogglePlanActive_FullMethodName,
+		FullMethod: PlanService_GetPlanByID_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(PlanServiceServer).TogglePlanActive(ctx, req.(*PlanIDRequest))
+		return srv.(PlanServiceServer).GetPlanByID(ctx, req.(*PlanIDRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-// PlanService_ServiceDesc is the grpc.ServiceDesc for PlanService service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var PlanService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "userplan.PlanService",
-	HandlerType: (*PlanServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "AssignPlan",
-			Handler:    _PlanService_AssignPlan_Handler,
-		},
-		{
-			MethodName: "GetUserPlan",
-			Handler:    _PlanService_GetUserPlan_Handler,
+func _PlanService_GetPlanByName_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlanNameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlanServiceServer).GetPlanByName(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlanService_GetPlanByName_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlanServiceServer).GetPlanByName(ctx, req.(*PlanNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlanService_UpdatePlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdatePlanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlanServiceServer).UpdatePlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlanService_UpdatePlan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlanServiceServer).UpdatePlan(ctx, req.(*UpdatePlanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlanService_DeletePlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlanIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlanServiceServer).DeletePlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlanService_DeletePlan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlanServiceServer).DeletePlan(ctx, req.(*PlanIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlanService_ListPlans_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPlansRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlanServiceServer).ListPlans(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlanService_ListPlans_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlanServiceServer).ListPlans(ctx, req.(*ListPlansRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlanService_TogglePlanActive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlanIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlanServiceServer).TogglePlanActive(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlanService_TogglePlanActive_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlanServiceServer).TogglePlanActive(ctx, req.(*PlanIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlanService_SetPlanPrice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlanPriceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlanServiceServer).SetPlanPrice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlanService_SetPlanPrice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlanServiceServer).SetPlanPrice(ctx, req.(*PlanPriceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlanService_ListPlanPrices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlanIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlanServiceServer).ListPlanPrices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlanService_ListPlanPrices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlanServiceServer).ListPlanPrices(ctx, req.(*PlanIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlanService_DeletePlanPrice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlanPriceIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlanServiceServer).DeletePlanPrice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlanService_DeletePlanPrice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlanServiceServer).DeletePlanPrice(ctx, req.(*PlanPriceIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PlanService_ServiceDesc is the grpc.ServiceDesc for PlanService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PlanService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "userplan.PlanService",
+	HandlerType: (*PlanServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AssignPlan",
+			Handler:    _PlanService_AssignPlan_Handler,
+		},
+		{
+			MethodName: "GetUserPlan",
+			Handler:    _PlanService_GetUserPlan_Handler,
 		},
 		{
 			MethodName: "RenewUserPlan",
 			Handler:    _PlanService_RenewUserPlan_Handler,
 		},
+		{
+			MethodName: "ChangeUserPlan",
+			Handler:    _PlanService_ChangeUserPlan_Handler,
+		},
+		{
+			MethodName: "SetAutoRenew",
+			Handler:    _PlanService_SetAutoRenew_Handler,
+		},
+		{
+			MethodName: "StartTrial",
+			Handler:    _PlanService_StartTrial_Handler,
+		},
+		{
+			MethodName: "ActivateUserPlan",
+			Handler:    _PlanService_ActivateUserPlan_Handler,
+		},
+		{
+			MethodName: "SuspendUserPlan",
+			Handler:    _PlanService_SuspendUserPlan_Handler,
+		},
+		{
+			MethodName: "ResumeUserPlan",
+			Handler:    _PlanService_ResumeUserPlan_Handler,
+		},
+		{
+			MethodName: "CancelUserPlan",
+			Handler:    _PlanService_CancelUserPlan_Handler,
+		},
+		{
+			MethodName: "GetPlanHistory",
+			Handler:    _PlanService_GetPlanHistory_Handler,
+		},
+		{
+			MethodName: "ScheduleUserPlanChange",
+			Handler:    _PlanService_ScheduleUserPlanChange_Handler,
+		},
+		{
+			MethodName: "ListScheduledChanges",
+			Handler:    _PlanService_ListScheduledChanges_Handler,
+		},
+		{
+			MethodName: "RevokeScheduledChange",
+			Handler:    _PlanService_RevokeScheduledChange_Handler,
+		},
 		{
 			MethodName: "CreatePlan",
 			Handler:    _PlanService_CreatePlan_Handler,
@@ -675,6 +1201,604 @@ var PlanService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "TogglePlanActive",
 			Handler:    _PlanService_TogglePlanActive_Handler,
 		},
+		{
+			MethodName: "SetPlanPrice",
+			Handler:    _PlanService_SetPlanPrice_Handler,
+		},
+		{
+			MethodName: "ListPlanPrices",
+			Handler:    _PlanService_ListPlanPrices_Handler,
+		},
+		{
+			MethodName: "DeletePlanPrice",
+			Handler:    _PlanService_DeletePlanPrice_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "userplan.proto",
+}
+
+const (
+	LimitationService_ListLimitations_FullMethodName          = "/userplan.LimitationService/ListLimitations"
+	LimitationService_CreateLimitation_FullMethodName         = "/userplan.LimitationService/CreateLimitation"
+	LimitationService_UpdateLimitation_FullMethodName         = "/userplan.LimitationService/UpdateLimitation"
+	LimitationService_DeleteLimitation_FullMethodName         = "/userplan.LimitationService/DeleteLimitation"
+	LimitationService_ListPlanLimitations_FullMethodName      = "/userplan.LimitationService/ListPlanLimitations"
+	LimitationService_AssignLimitationToPlan_FullMethodName   = "/userplan.LimitationService/AssignLimitationToPlan"
+	LimitationService_UpdatePlanLimitation_FullMethodName     = "/userplan.LimitationService/UpdatePlanLimitation"
+	LimitationService_RemoveLimitationFromPlan_FullMethodName = "/userplan.LimitationService/RemoveLimitationFromPlan"
+)
+
+// LimitationServiceClient is the client API for LimitationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type LimitationServiceClient interface {
+	ListLimitations(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ListLimitationsResponse, error)
+	CreateLimitation(ctx context.Context, in *CreateLimitationRequest, opts ...grpc.CallOption) (*Limitation, error)
+	UpdateLimitation(ctx context.Context, in *UpdateLimitationRequest, opts ...grpc.CallOption) (*Limitation, error)
+	DeleteLimitation(ctx context.Context, in *LimitationIDRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Plan limitation (quota) methods
+	ListPlanLimitations(ctx context.Context, in *PlanIDRequest, opts ...grpc.CallOption) (*ListPlanLimitationsResponse, error)
+	AssignLimitationToPlan(ctx context.Context, in *PlanLimitationRequest, opts ...grpc.CallOption) (*PlanLimitation, error)
+	UpdatePlanLimitation(ctx context.Context, in *PlanLimitationRequest, opts ...grpc.CallOption) (*PlanLimitation, error)
+	RemoveLimitationFromPlan(ctx context.Context, in *PlanLimitationIDRequest, opts ...grpc.CallOption) (*Empty, error)
+}
+
+type limitationServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLimitationServiceClient(cc grpc.ClientConnInterface) LimitationServiceClient {
+	return &limitationServiceClient{cc}
+}
+
+func (c *limitationServiceClient) ListLimitations(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ListLimitationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListLimitationsResponse)
+	err := c.cc.Invoke(ctx, LimitationService_ListLimitations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *limitationServiceClient) CreateLimitation(ctx context.Context, in *CreateLimitationRequest, opts ...grpc.CallOption) (*Limitation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Limitation)
+	err := c.cc.Invoke(ctx, LimitationService_CreateLimitation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *limitationServiceClient) UpdateLimitation(ctx context.Context, in *UpdateLimitationRequest, opts ...grpc.CallOption) (*Limitation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Limitation)
+	err := c.cc.Invoke(ctx, LimitationService_UpdateLimitation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *limitationServiceClient) DeleteLimitation(ctx context.Context, in *LimitationIDRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, LimitationService_DeleteLimitation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *limitationServiceClient) ListPlanLimitations(ctx context.Context, in *PlanIDRequest, opts ...grpc.CallOption) (*ListPlanLimitationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPlanLimitationsResponse)
+	err := c.cc.Invoke(ctx, LimitationService_ListPlanLimitations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *limitationServiceClient) AssignLimitationToPlan(ctx context.Context, in *PlanLimitationRequest, opts ...grpc.CallOption) (*PlanLimitation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PlanLimitation)
+	err := c.cc.Invoke(ctx, LimitationService_AssignLimitationToPlan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *limitationServiceClient) UpdatePlanLimitation(ctx context.Context, in *PlanLimitationRequest, opts ...grpc.CallOption) (*PlanLimitation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PlanLimitation)
+	err := c.cc.Invoke(ctx, LimitationService_UpdatePlanLimitation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *limitationServiceClient) RemoveLimitationFromPlan(ctx context.Context, in *PlanLimitationIDRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, LimitationService_RemoveLimitationFromPlan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LimitationServiceServer is the server API for LimitationService service.
+// All implementations must embed UnimplementedLimitationServiceServer
+// for forward compatibility.
+type LimitationServiceServer interface {
+	ListLimitations(context.Context, *Empty) (*ListLimitationsResponse, error)
+	CreateLimitation(context.Context, *CreateLimitationRequest) (*Limitation, error)
+	UpdateLimitation(context.Context, *UpdateLimitationRequest) (*Limitation, error)
+	DeleteLimitation(context.Context, *LimitationIDRequest) (*Empty, error)
+	// Plan limitation (quota) methods
+	ListPlanLimitations(context.Context, *PlanIDRequest) (*ListPlanLimitationsResponse, error)
+	AssignLimitationToPlan(context.Context, *PlanLimitationRequest) (*PlanLimitation, error)
+	UpdatePlanLimitation(context.Context, *PlanLimitationRequest) (*PlanLimitation, error)
+	RemoveLimitationFromPlan(context.Context, *PlanLimitationIDRequest) (*Empty, error)
+	mustEmbedUnimplementedLimitationServiceServer()
+}
+
+// UnimplementedLimitationServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedLimitationServiceServer struct{}
+
+func (UnimplementedLimitationServiceServer) ListLimitations(context.Context, *Empty) (*ListLimitationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLimitations not implemented")
+}
+func (UnimplementedLimitationServiceServer) CreateLimitation(context.Context, *CreateLimitationRequest) (*Limitation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateLimitation not implemented")
+}
+func (UnimplementedLimitationServiceServer) UpdateLimitation(context.Context, *UpdateLimitationRequest) (*Limitation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateLimitation not implemented")
+}
+func (UnimplementedLimitationServiceServer) DeleteLimitation(context.Context, *LimitationIDRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteLimitation not implemented")
+}
+func (UnimplementedLimitationServiceServer) ListPlanLimitations(context.Context, *PlanIDRequest) (*ListPlanLimitationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPlanLimitations not implemented")
+}
+func (UnimplementedLimitationServiceServer) AssignLimitationToPlan(context.Context, *PlanLimitationRequest) (*PlanLimitation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AssignLimitationToPlan not implemented")
+}
+func (UnimplementedLimitationServiceServer) UpdatePlanLimitation(context.Context, *PlanLimitationRequest) (*PlanLimitation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdatePlanLimitation not implemented")
+}
+func (UnimplementedLimitationServiceServer) RemoveLimitationFromPlan(context.Context, *PlanLimitationIDRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveLimitationFromPlan not implemented")
+}
+func (UnimplementedLimitationServiceServer) mustEmbedUnimplementedLimitationServiceServer() {}
+func (UnimplementedLimitationServiceServer) testEmbeddedByValue()                           {}
+
+// UnsafeLimitationServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LimitationServiceServer will
+// result in compilation errors.
+type UnsafeLimitationServiceServer interface {
+	mustEmbedUnimplementedLimitationServiceServer()
+}
+
+func RegisterLimitationServiceServer(s grpc.ServiceRegistrar, srv LimitationServiceServer) {
+	// If the following call pancis, it indicates UnimplementedLimitationServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&LimitationService_ServiceDesc, srv)
+}
+
+func _LimitationService_ListLimitations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LimitationServiceServer).ListLimitations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LimitationService_ListLimitations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LimitationServiceServer).ListLimitations(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LimitationService_CreateLimitation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateLimitationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LimitationServiceServer).CreateLimitation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LimitationService_CreateLimitation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LimitationServiceServer).CreateLimitation(ctx, req.(*CreateLimitationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LimitationService_UpdateLimitation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateLimitationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LimitationServiceServer).UpdateLimitation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LimitationService_UpdateLimitation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LimitationServiceServer).UpdateLimitation(ctx, req.(*UpdateLimitationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LimitationService_DeleteLimitation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LimitationIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LimitationServiceServer).DeleteLimitation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LimitationService_DeleteLimitation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LimitationServiceServer).DeleteLimitation(ctx, req.(*LimitationIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LimitationService_ListPlanLimitations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlanIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LimitationServiceServer).ListPlanLimitations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LimitationService_ListPlanLimitations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LimitationServiceServer).ListPlanLimitations(ctx, req.(*PlanIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LimitationService_AssignLimitationToPlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlanLimitationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LimitationServiceServer).AssignLimitationToPlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LimitationService_AssignLimitationToPlan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LimitationServiceServer).AssignLimitationToPlan(ctx, req.(*PlanLimitationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LimitationService_UpdatePlanLimitation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlanLimitationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LimitationServiceServer).UpdatePlanLimitation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LimitationService_UpdatePlanLimitation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LimitationServiceServer).UpdatePlanLimitation(ctx, req.(*PlanLimitationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LimitationService_RemoveLimitationFromPlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlanLimitationIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LimitationServiceServer).RemoveLimitationFromPlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LimitationService_RemoveLimitationFromPlan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LimitationServiceServer).RemoveLimitationFromPlan(ctx, req.(*PlanLimitationIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// LimitationService_ServiceDesc is the grpc.ServiceDesc for LimitationService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LimitationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "userplan.LimitationService",
+	HandlerType: (*LimitationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListLimitations",
+			Handler:    _LimitationService_ListLimitations_Handler,
+		},
+		{
+			MethodName: "CreateLimitation",
+			Handler:    _LimitationService_CreateLimitation_Handler,
+		},
+		{
+			MethodName: "UpdateLimitation",
+			Handler:    _LimitationService_UpdateLimitation_Handler,
+		},
+		{
+			MethodName: "DeleteLimitation",
+			Handler:    _LimitationService_DeleteLimitation_Handler,
+		},
+		{
+			MethodName: "ListPlanLimitations",
+			Handler:    _LimitationService_ListPlanLimitations_Handler,
+		},
+		{
+			MethodName: "AssignLimitationToPlan",
+			Handler:    _LimitationService_AssignLimitationToPlan_Handler,
+		},
+		{
+			MethodName: "UpdatePlanLimitation",
+			Handler:    _LimitationService_UpdatePlanLimitation_Handler,
+		},
+		{
+			MethodName: "RemoveLimitationFromPlan",
+			Handler:    _LimitationService_RemoveLimitationFromPlan_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "userplan.proto",
+}
+
+const (
+	UsageService_CheckQuota_FullMethodName        = "/userplan.UsageService/CheckQuota"
+	UsageService_ConsumeQuota_FullMethodName      = "/userplan.UsageService/ConsumeQuota"
+	UsageService_GetUsage_FullMethodName          = "/userplan.UsageService/GetUsage"
+	UsageService_GetUsageStatement_FullMethodName = "/userplan.UsageService/GetUsageStatement"
+)
+
+// UsageServiceClient is the client API for UsageService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type UsageServiceClient interface {
+	CheckQuota(ctx context.Context, in *QuotaRequest, opts ...grpc.CallOption) (*Quota, error)
+	ConsumeQuota(ctx context.Context, in *QuotaRequest, opts ...grpc.CallOption) (*Quota, error)
+	GetUsage(ctx context.Context, in *UserPlanRequest, opts ...grpc.CallOption) (*UsageResponse, error)
+	GetUsageStatement(ctx context.Context, in *UsageStatementRequest, opts ...grpc.CallOption) (*UsageStatement, error)
+}
+
+type usageServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewUsageServiceClient(cc grpc.ClientConnInterface) UsageServiceClient {
+	return &usageServiceClient{cc}
+}
+
+func (c *usageServiceClient) CheckQuota(ctx context.Context, in *QuotaRequest, opts ...grpc.CallOption) (*Quota, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Quota)
+	err := c.cc.Invoke(ctx, UsageService_CheckQuota_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usageServiceClient) ConsumeQuota(ctx context.Context, in *QuotaRequest, opts ...grpc.CallOption) (*Quota, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Quota)
+	err := c.cc.Invoke(ctx, UsageService_ConsumeQuota_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usageServiceClient) GetUsage(ctx context.Context, in *UserPlanRequest, opts ...grpc.CallOption) (*UsageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UsageResponse)
+	err := c.cc.Invoke(ctx, UsageService_GetUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usageServiceClient) GetUsageStatement(ctx context.Context, in *UsageStatementRequest, opts ...grpc.CallOption) (*UsageStatement, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UsageStatement)
+	err := c.cc.Invoke(ctx, UsageService_GetUsageStatement_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UsageServiceServer is the server API for UsageService service.
+// All implementations must embed UnimplementedUsageServiceServer
+// for forward compatibility.
+type UsageServiceServer interface {
+	CheckQuota(context.Context, *QuotaRequest) (*Quota, error)
+	ConsumeQuota(context.Context, *QuotaRequest) (*Quota, error)
+	GetUsage(context.Context, *UserPlanRequest) (*UsageResponse, error)
+	GetUsageStatement(context.Context, *UsageStatementRequest) (*UsageStatement, error)
+	mustEmbedUnimplementedUsageServiceServer()
+}
+
+// UnimplementedUsageServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedUsageServiceServer struct{}
+
+func (UnimplementedUsageServiceServer) CheckQuota(context.Context, *QuotaRequest) (*Quota, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckQuota not implemented")
+}
+func (UnimplementedUsageServiceServer) ConsumeQuota(context.Context, *QuotaRequest) (*Quota, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConsumeQuota not implemented")
+}
+func (UnimplementedUsageServiceServer) GetUsage(context.Context, *UserPlanRequest) (*UsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUsage not implemented")
+}
+func (UnimplementedUsageServiceServer) GetUsageStatement(context.Context, *UsageStatementRequest) (*UsageStatement, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUsageStatement not implemented")
+}
+func (UnimplementedUsageServiceServer) mustEmbedUnimplementedUsageServiceServer() {}
+func (UnimplementedUsageServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeUsageServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to UsageServiceServer will
+// result in compilation errors.
+type UnsafeUsageServiceServer interface {
+	mustEmbedUnimplementedUsageServiceServer()
+}
+
+func RegisterUsageServiceServer(s grpc.ServiceRegistrar, srv UsageServiceServer) {
+	// If the following call pancis, it indicates UnimplementedUsageServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&UsageService_ServiceDesc, srv)
+}
+
+func _UsageService_CheckQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QuotaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsageServiceServer).CheckQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UsageService_CheckQuota_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsageServiceServer).CheckQuota(ctx, req.(*QuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UsageService_ConsumeQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QuotaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsageServiceServer).ConsumeQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UsageService_ConsumeQuota_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsageServiceServer).ConsumeQuota(ctx, req.(*QuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UsageService_GetUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserPlanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsageServiceServer).GetUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UsageService_GetUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsageServiceServer).GetUsage(ctx, req.(*UserPlanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UsageService_GetUsageStatement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UsageStatementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsageServiceServer).GetUsageStatement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UsageService_GetUsageStatement_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsageServiceServer).GetUsageStatement(ctx, req.(*UsageStatementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// UsageService_ServiceDesc is the grpc.ServiceDesc for UsageService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var UsageService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "userplan.UsageService",
+	HandlerType: (*UsageServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CheckQuota",
+			Handler:    _UsageService_CheckQuota_Handler,
+		},
+		{
+			MethodName: "ConsumeQuota",
+			Handler:    _UsageService_ConsumeQuota_Handler,
+		},
+		{
+			MethodName: "GetUsage",
+			Handler:    _UsageService_GetUsage_Handler,
+		},
+		{
+			MethodName: "GetUsageStatement",
+			Handler:    _UsageService_GetUsageStatement_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "userplan.proto",
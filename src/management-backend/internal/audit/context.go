@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/common"
+)
+
+type entryKey struct{}
+
+// Entry collects what the services know about the entity a request changes,
+// it is carried in the request context by the audit middleware
+type Entry struct {
+	EntityType string
+	EntityID   uint
+	Before     common.JSON
+	After      common.JSON
+}
+
+// WithEntry returns a context services record snapshots into
+func WithEntry(ctx context.Context) (context.Context, *Entry) {
+	e := &Entry{}
+	return context.WithValue(ctx, entryKey{}, e), e
+}
+
+// Recording reports whether ctx belongs to an audited request,
+// services skip fetching snapshots otherwise
+func Recording(ctx context.Context) bool {
+	_, ok := ctx.Value(entryKey{}).(*Entry)
+	return ok
+}
+
+// SetBefore records the state of the entity before the change, the first snapshot wins
+func SetBefore(ctx context.Context, entityType string, id uint, v any) {
+	e, ok := ctx.Value(entryKey{}).(*Entry)
+	if !ok {
+		return
+	}
+	e.identify(entityType, id)
+	if e.Before == nil {
+		e.Before = Snapshot(v)
+	}
+}
+
+// SetAfter records the state of the entity after the change, the last snapshot wins
+func SetAfter(ctx context.Context, entityType string, id uint, v any) {
+	e, ok := ctx.Value(entryKey{}).(*Entry)
+	if !ok {
+		return
+	}
+	e.identify(entityType, id)
+	e.After = Snapshot(v)
+}
+
+func (e *Entry) identify(entityType string, id uint) {
+	if e.EntityType == "" {
+		e.EntityType = entityType
+	}
+	if e.EntityID == 0 {
+		e.EntityID = id
+	}
+}
+
+// Snapshot returns the json representation of v, fields hidden from json are left out
+func Snapshot(v any) common.JSON {
+	if v == nil {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var snap common.JSON
+	if err := snap.Scan(b); err != nil {
+		return nil
+	}
+	return snap
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"time"
+
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/common"
+)
+
+const DefaultPageSize = 20
+
+// audited entity types
+const (
+	EntityUser       = "user"
+	EntityPlan       = "plan"
+	EntityLimitation = "limitation"
+)
+
+// AuditFilter selects audit logs, zero fields match everything
+type AuditFilter struct {
+	UserID     uint
+	Action     string
+	EntityType string
+	EntityID   uint
+	RequestID  string
+	From       time.Time // inclusive
+	To         time.Time // exclusive
+	Page       int       // 1-based
+	Size       int
+}
+
+type AuditLogList struct {
+	Logs  []*common.AuditLog
+	Total int64
+}
@@ -0,0 +1,18 @@
+package port
+
+import (
+	"context"
+
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/audit/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/common"
+)
+
+type Service interface {
+	Record(ctx context.Context, log *common.AuditLog) error
+	List(ctx context.Context, filter *domain.AuditFilter) (*domain.AuditLogList, error)
+}
+
+type Repository interface {
+	Create(ctx context.Context, log *common.AuditLog) error
+	List(ctx context.Context, filter *domain.AuditFilter, limit, offset int) ([]*common.AuditLog, int64, error)
+}
@@ -0,0 +1,36 @@
+package audit
+
+import (
+	"context"
+
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/audit/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/audit/port"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/common"
+)
+
+type service struct {
+	repo port.Repository
+}
+
+func NewService(repo port.Repository) port.Service {
+	return &service{repo: repo}
+}
+
+func (s *service) Record(ctx context.Context, log *common.AuditLog) error {
+	return s.repo.Create(ctx, log)
+}
+
+// List returns a page of the matching logs, newest first
+func (s *service) List(ctx context.Context, filter *domain.AuditFilter) (*domain.AuditLogList, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Size < 1 {
+		filter.Size = domain.DefaultPageSize
+	}
+	logs, total, err := s.repo.List(ctx, filter, filter.Size, (filter.Page-1)*filter.Size)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.AuditLogList{Logs: logs, Total: total}, nil
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims of an access token, SessionID ties it to the refresh token it was issued with
+type Claims struct {
+	UserID    uint   `json:"userID"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	SessionID uint   `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+// Session is a login, its refresh token is replaced on every refresh
+type Session struct {
+	ID           uint      `gorm:"primaryKey"`
+	CreatedAt    time.Time `gorm:"not null"`
+	UserID       uint      `gorm:"not null;index"`
+	RefreshHash  string    `gorm:"size:64;not null;uniqueIndex"`
+	PreviousHash string    `gorm:"size:64;index"` // refresh token rotated out, presenting it again revokes the session
+	ExpiresAt    time.Time `gorm:"not null"`      // of the refresh token
+	RotatedAt    *time.Time
+	RevokedAt    *time.Time
+}
+
+func (Session) TableName() string { return "admin_sessions" }
+
+type TokenPair struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+type Options struct {
+	Secret     []byte
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
@@ -0,0 +1,40 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	adminD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/auth/domain"
+)
+
+type Service interface {
+	// Issue starts a session of user
+	Issue(ctx context.Context, user *adminD.AdminUser) (*domain.TokenPair, error)
+	// Refresh replaces the refresh token of a session and issues a new access token
+	Refresh(ctx context.Context, refreshToken string) (*domain.TokenPair, *adminD.AdminUser, error)
+	// Validate parses an access token and rejects revoked ones
+	Validate(ctx context.Context, accessToken string) (*domain.Claims, error)
+	// Logout ends the session of claims, or every session of its user when all is set
+	Logout(ctx context.Context, claims *domain.Claims, all bool) error
+	// RevokeUser ends every session of the user and rejects the access tokens issued so far
+	RevokeUser(ctx context.Context, userID uint) error
+}
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *domain.Session) error
+	// Rotate replaces the refresh token hash of the live session holding oldHash and returns the session
+	Rotate(ctx context.Context, oldHash, newHash string, expiresAt, now time.Time) (*domain.Session, error)
+	GetByPreviousHash(ctx context.Context, hash string) (*domain.Session, error)
+	Revoke(ctx context.Context, id uint, now time.Time) error
+	// RevokeUser revokes the live sessions of the user and returns the ID of its latest session
+	RevokeUser(ctx context.Context, userID uint, now time.Time) (uint, error)
+}
+
+// RevocationList rejects access tokens before they expire, entries only need to outlive the access tokens
+type RevocationList interface {
+	RevokeSession(ctx context.Context, sessionID uint, ttl time.Duration) error
+	// RevokeUser rejects the access tokens of the user's sessions up to lastSessionID, session IDs only grow
+	RevokeUser(ctx context.Context, userID, lastSessionID uint, ttl time.Duration) error
+	Revoked(ctx context.Context, claims *domain.Claims) (bool, error)
+}
@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+	adminD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/domain"
+	adminP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/port"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/auth/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/auth/port"
+)
+
+var (
+	ErrInvalidToken  = errors.New("invalid token")
+	ErrTokenRevoked  = errors.New("token is revoked")
+	ErrRefreshReused = errors.New("refresh token was already used, the session is revoked")
+)
+
+type service struct {
+	sessions port.SessionRepository
+	users    adminP.Repository
+	revoked  port.RevocationList
+	opts     domain.Options
+}
+
+func NewService(sessions port.SessionRepository, users adminP.Repository, revoked port.RevocationList, opts domain.Options) port.Service {
+	return &service{sessions: sessions, users: users, revoked: revoked, opts: opts}
+}
+
+func (s *service) Issue(ctx context.Context, user *adminD.AdminUser) (*domain.TokenPair, error) {
+	refresh, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	session := &domain.Session{
+		CreatedAt:   now,
+		UserID:      user.ID,
+		RefreshHash: hashToken(refresh),
+		ExpiresAt:   now.Add(s.opts.RefreshTTL),
+	}
+	if err := s.sessions.Create(ctx, session); err != nil {
+		return nil, err
+	}
+	return s.pair(user, session, refresh, now)
+}
+
+// Refresh rotates the refresh token, presenting a rotated out token means it leaked
+// so the whole session is revoked
+func (s *service) Refresh(ctx context.Context, refreshToken string) (*domain.TokenPair, *adminD.AdminUser, error) {
+	refresh, err := randomToken()
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	hash := hashToken(refreshToken)
+
+	session, err := s.sessions.Rotate(ctx, hash, hashToken(refresh), now.Add(s.opts.RefreshTTL), now)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		reused, err := s.sessions.GetByPreviousHash(ctx, hash)
+		if err != nil || reused.RevokedAt != nil {
+			return nil, nil, ErrInvalidToken
+		}
+		if err := s.revokeSession(ctx, reused.ID, now); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, ErrRefreshReused
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.users.GetByID(ctx, session.UserID)
+	if err != nil || !user.IsActive {
+		if err := s.revokeSession(ctx, session.ID, now); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, ErrInvalidToken
+	}
+	pair, err := s.pair(user, session, refresh, now)
+	return pair, user, err
+}
+
+func (s *service) Validate(ctx context.Context, accessToken string) (*domain.Claims, error) {
+	claims := &domain.Claims{}
+	_, err := jwt.ParseWithClaims(accessToken, claims, func(*jwt.Token) (interface{}, error) {
+		return s.opts.Secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	revoked, err := s.revoked.Revoked(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrTokenRevoked
+	}
+	return claims, nil
+}
+
+func (s *service) Logout(ctx context.Context, claims *domain.Claims, all bool) error {
+	if all {
+		return s.RevokeUser(ctx, claims.UserID)
+	}
+	return s.revokeSession(ctx, claims.SessionID, time.Now())
+}
+
+func (s *service) RevokeUser(ctx context.Context, userID uint) error {
+	last, err := s.sessions.RevokeUser(ctx, userID, time.Now())
+	if err != nil || last == 0 {
+		return err
+	}
+	return s.revoked.RevokeUser(ctx, userID, last, s.opts.AccessTTL)
+}
+
+func (s *service) revokeSession(ctx context.Context, id uint, now time.Time) error {
+	if err := s.sessions.Revoke(ctx, id, now); err != nil {
+		return err
+	}
+	return s.revoked.RevokeSession(ctx, id, s.opts.AccessTTL)
+}
+
+func (s *service) pair(user *adminD.AdminUser, session *domain.Session, refresh string, now time.Time) (*domain.TokenPair, error) {
+	jti, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := now.Add(s.opts.AccessTTL)
+	claims := domain.Claims{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Role:      user.Role,
+		SessionID: session.ID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   strconv.FormatUint(uint64(user.ID), 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+	access, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.opts.Secret)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.TokenPair{
+		AccessToken:      access,
+		AccessExpiresAt:  expiresAt,
+		RefreshToken:     refresh,
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
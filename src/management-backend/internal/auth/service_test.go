@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/adapter/revocation"
+	adminD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/domain"
+	adminP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/admin/port"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/auth/domain"
+)
+
+type memSessions struct {
+	sessions []*domain.Session
+}
+
+func (r *memSessions) Create(_ context.Context, s *domain.Session) error {
+	s.ID = uint(len(r.sessions) + 1)
+	r.sessions = append(r.sessions, s)
+	return nil
+}
+
+func (r *memSessions) Rotate(_ context.Context, oldHash, newHash string, expiresAt, now time.Time) (*domain.Session, error) {
+	for _, s := range r.sessions {
+		if s.RefreshHash == oldHash && s.RevokedAt == nil && s.ExpiresAt.After(now) {
+			s.PreviousHash, s.RefreshHash, s.ExpiresAt, s.RotatedAt = oldHash, newHash, expiresAt, &now
+			copied := *s
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memSessions) GetByPreviousHash(_ context.Context, hash string) (*domain.Session, error) {
+	for _, s := range r.sessions {
+		if s.PreviousHash == hash {
+			copied := *s
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memSessions) Revoke(_ context.Context, id uint, now time.Time) error {
+	r.sessions[id-1].RevokedAt = &now
+	return nil
+}
+
+func (r *memSessions) RevokeUser(_ context.Context, userID uint, now time.Time) (uint, error) {
+	var last uint
+	for _, s := range r.sessions {
+		if s.UserID == userID {
+			s.RevokedAt = &now
+			last = s.ID
+		}
+	}
+	return last, nil
+}
+
+// users returns the admins by ID, only GetByID is used
+type users struct {
+	adminP.Repository
+	byID map[uint]*adminD.AdminUser
+}
+
+func (u users) GetByID(_ context.Context, id uint) (*adminD.AdminUser, error) {
+	if user, ok := u.byID[id]; ok {
+		return user, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func newTestService() (*service, *adminD.AdminUser) {
+	admin := &adminD.AdminUser{Email: "admin@arcaptcha.ir", Role: adminD.RoleSupport, IsActive: true}
+	admin.ID = 7
+	s := &service{
+		sessions: &memSessions{},
+		users:    users{byID: map[uint]*adminD.AdminUser{admin.ID: admin}},
+		revoked:  revocation.NewMemory(),
+		opts:     domain.Options{Secret: []byte("secret"), AccessTTL: time.Minute, RefreshTTL: time.Hour},
+	}
+	return s, admin
+}
+
+func TestRefreshRotation(t *testing.T) {
+	ctx := context.Background()
+	s, admin := newTestService()
+
+	first, err := s.Issue(ctx, admin)
+	require.NoError(t, err)
+	claims, err := s.Validate(ctx, first.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, admin.ID, claims.UserID)
+	assert.Equal(t, adminD.RoleSupport, claims.Role)
+
+	second, user, err := s.Refresh(ctx, first.RefreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, admin.Email, user.Email)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+	_, err = s.Validate(ctx, second.AccessToken)
+	require.NoError(t, err)
+
+	_, _, err = s.Refresh(ctx, first.RefreshToken)
+	assert.ErrorIs(t, err, ErrRefreshReused)
+	_, err = s.Validate(ctx, second.AccessToken)
+	assert.ErrorIs(t, err, ErrTokenRevoked, "reusing a refresh token ends the session")
+	_, _, err = s.Refresh(ctx, second.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, _, err = s.Refresh(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestLogout(t *testing.T) {
+	ctx := context.Background()
+	s, admin := newTestService()
+
+	laptop, err := s.Issue(ctx, admin)
+	require.NoError(t, err)
+	phone, err := s.Issue(ctx, admin)
+	require.NoError(t, err)
+
+	claims, err := s.Validate(ctx, laptop.AccessToken)
+	require.NoError(t, err)
+	require.NoError(t, s.Logout(ctx, claims, false))
+	_, err = s.Validate(ctx, laptop.AccessToken)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+	_, err = s.Validate(ctx, phone.AccessToken)
+	assert.NoError(t, err, "other sessions stay")
+
+	claims, err = s.Validate(ctx, phone.AccessToken)
+	require.NoError(t, err)
+	require.NoError(t, s.Logout(ctx, claims, true))
+	_, err = s.Validate(ctx, phone.AccessToken)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+	_, _, err = s.Refresh(ctx, phone.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	again, err := s.Issue(ctx, admin)
+	require.NoError(t, err)
+	_, err = s.Validate(ctx, again.AccessToken)
+	assert.NoError(t, err, "sessions started after revoking the user are valid")
+}
+
+func TestRefresh_DeactivatedUser(t *testing.T) {
+	ctx := context.Background()
+	s, admin := newTestService()
+
+	pair, err := s.Issue(ctx, admin)
+	require.NoError(t, err)
+	admin.IsActive = false
+	_, _, err = s.Refresh(ctx, pair.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestValidate(t *testing.T) {
+	ctx := context.Background()
+	s, admin := newTestService()
+
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, domain.Claims{
+		UserID:           admin.ID,
+		Role:             adminD.RoleSuperAdmin,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+	_, err = s.Validate(ctx, unsigned)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	s.opts.AccessTTL = -time.Second
+	expired, err := s.Issue(ctx, admin)
+	require.NoError(t, err)
+	_, err = s.Validate(ctx, expired.AccessToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
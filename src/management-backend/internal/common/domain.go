@@ -17,23 +17,12 @@ type AuditLog struct {
 	BaseModel
 	UserID     uint   `gorm:"index" json:"user_id"` // admin who performed the action
 	Action     string `gorm:"size:255" json:"action"`
-	EntityType string `gorm:"size:255" json:"entity_type"`
-	EntityID   uint   `json:"entity_id"`
-	RequestID  string `gorm:"size:255" json:"request_id"`
+	EntityType string `gorm:"size:255;index:idx_audit_logs_entity" json:"entity_type"`
+	EntityID   uint   `gorm:"index:idx_audit_logs_entity" json:"entity_id"`
+	RequestID  string `gorm:"size:255;index" json:"request_id"`
 	IPAddress  string `gorm:"size:45" json:"ip_address"` // IPv4 or IPv6
 	UserAgent  string `gorm:"size:512" json:"user_agent"`
-	Metadata   JSON   `gorm:"type:json" json:"metadata"`
-}
-
-// for handling JSON data in gorm
-type JSON map[string]interface{}
-
-func (j *JSON) Scan(value interface{}) error {
-	// implement scan logic for JSON type
-	return nil
-}
-
-func (j JSON) Value() (interface{}, error) {
-	// implement value logic for JSON type
-	return nil, nil
+	Metadata   JSON   `gorm:"type:jsonb" json:"metadata"`
+	Before     JSON   `gorm:"type:jsonb" json:"before,omitempty"` // entity snapshot before the change
+	After      JSON   `gorm:"type:jsonb" json:"after,omitempty"`  // entity snapshot after the change
 }
@@ -1,12 +1,118 @@
 package domain
 
-import "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/common"
+import (
+	"time"
+
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/common"
+)
 
 type Plan struct {
 	common.BaseModel
-	Name        string  `gorm:"size:100;uniqueIndex" json:"name" validate:"required"`
-	Description string  `gorm:"size:500" json:"description"`
-	Price       float64 `gorm:"type:decimal(10,2)" json:"price" validate:"gte=0"`
-	Duration    int     `gorm:"default:30" json:"duration" validate:"gte=30"` // in days
-	IsActive    bool    `gorm:"default:true" json:"is_active"`
+	Name        string      `gorm:"size:100;uniqueIndex" json:"name" validate:"required"`
+	Description string      `gorm:"size:500" json:"description"`
+	Prices      []PlanPrice `gorm:"-" json:"prices" validate:"dive"`
+	Duration    int         `gorm:"default:30" json:"duration" validate:"gte=30"` // in days
+	IsActive    bool        `gorm:"default:true" json:"is_active"`
+	PAYG        bool        `gorm:"default:false" json:"payg"`
+	// days the plan stays past due after a term ends before it expires
+	GracePeriodDays int `gorm:"default:0" json:"grace_period_days" validate:"gte=0"`
+	// free trial length, trials convert to TrialConvertPlanID or expire when it is zero
+	TrialDays          int  `gorm:"default:0" json:"trial_days" validate:"gte=0"`
+	TrialConvertPlanID uint `json:"trial_convert_plan_id,omitempty"`
+	TrialConvertMonths int  `json:"trial_convert_months,omitempty" validate:"gte=0"`
+}
+
+// PlanPrice is the price of buying a plan for a term of Months
+type PlanPrice struct {
+	Months int   `json:"months" validate:"gte=1"`
+	Price  int64 `json:"price" validate:"gte=0"`
+}
+
+type Limitation struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title" validate:"required"`
+}
+
+// PlanLimitation is the quota a plan grants for a single limitation
+type PlanLimitation struct {
+	PlanID     uint       `json:"plan_id"`
+	Limitation Limitation `json:"limitation"`
+	Value      int        `json:"value" validate:"gte=0"`
+	UnitPrice  int        `json:"unit_price" validate:"gte=0"`                      // billed per unit on PAYG plans
+	TrialValue *int       `json:"trial_value,omitempty" validate:"omitempty,gte=0"` // replaces Value during a trial
+}
+
+// PlanAssignment is the plan and term purchased for a user
+type PlanAssignment struct {
+	PlanID    uint
+	Months    int
+	AutoRenew bool
+	Pending   bool // the term starts when the plan is activated
+}
+
+// PlanChange records which admin changed a user's plan and why
+type PlanChange struct {
+	By     string
+	Reason string
+}
+
+// PlanChangeResult is the outcome of moving a user to another plan mid-term
+type PlanChangeResult struct {
+	Action      string    `json:"action"`
+	Proration   int64     `json:"proration"` // amount due for the rest of the term, negative for a credit
+	Deferred    bool      `json:"deferred"`
+	EffectiveAt time.Time `json:"effective_at"`
+}
+
+// ScheduledChange is a change of a user's plan that applies at EffectiveAt
+type ScheduledChange struct {
+	ID          uint      `json:"id"`
+	UserPlanID  uint      `json:"user_plan_id"`
+	Action      string    `json:"action"` // change or cancel
+	PlanID      uint      `json:"plan_id,omitempty"`
+	EffectiveAt time.Time `json:"effective_at"`
+	Status      string    `json:"status"`
+	ChangedBy   string    `json:"changed_by"`
+	Reason      string    `json:"reason"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// PlanHistory is one lifecycle change of a user's plan
+type PlanHistory struct {
+	ID         uint        `json:"id"`
+	UserPlanID uint        `json:"user_plan_id"`
+	Action     string      `json:"action"`
+	FromStatus string      `json:"from_status"`
+	ToStatus   string      `json:"to_status"`
+	OldPlanID  uint        `json:"old_plan_id,omitempty"`
+	NewPlanID  uint        `json:"new_plan_id,omitempty"`
+	ChangedBy  string      `json:"changed_by"`
+	Reason     string      `json:"reason"`
+	ChangedAt  time.Time   `json:"changed_at"`
+	Metadata   common.JSON `json:"metadata,omitempty"`
+}
+
+// PlanHistoryFilter narrows the plan history of a user
+type PlanHistoryFilter struct {
+	UserID      uint
+	Action      string
+	MetadataKey string      // entries whose metadata has the key
+	Metadata    common.JSON // entries whose metadata contains these keys and values
+}
+
+// UsageStatement is the metered bill of a PAYG user for one billing period
+type UsageStatement struct {
+	UserID      uint                 `json:"user_id"`
+	PlanID      uint                 `json:"plan_id"`
+	PeriodStart time.Time            `json:"period_start"`
+	PeriodEnd   time.Time            `json:"period_end"`
+	Lines       []UsageStatementLine `json:"lines"`
+	Total       int64                `json:"total"`
+}
+
+type UsageStatementLine struct {
+	Limitation string `json:"limitation"`
+	Units      int64  `json:"units"`
+	UnitPrice  int64  `json:"unit_price"`
+	Amount     int64  `json:"amount"`
 }
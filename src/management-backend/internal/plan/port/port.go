@@ -2,6 +2,7 @@ package port
 
 import (
 	"context"
+	"time"
 
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/plan/domain"
 )
@@ -14,4 +15,31 @@ type Service interface {
 	DeletePlan(ctx context.Context, id uint) error
 	ListPlans(ctx context.Context, limit, offset int) ([]*domain.Plan, error)
 	TogglePlanActive(ctx context.Context, id uint) error
+	AssignPlan(ctx context.Context, userID uint, assignment domain.PlanAssignment, change domain.PlanChange) error
+	SetAutoRenew(ctx context.Context, userID uint, enabled bool) error
+	StartTrial(ctx context.Context, userID, planID uint, change domain.PlanChange) error
+	ChangeUserPlan(ctx context.Context, userID, planID uint, deferDowngrade bool, change domain.PlanChange) (*domain.PlanChangeResult, error)
+	ActivateUserPlan(ctx context.Context, userID uint, change domain.PlanChange) error
+	SuspendUserPlan(ctx context.Context, userID uint, change domain.PlanChange) error
+	ResumeUserPlan(ctx context.Context, userID uint, change domain.PlanChange) error
+	CancelUserPlan(ctx context.Context, userID uint, change domain.PlanChange) error
+	GetPlanHistory(ctx context.Context, filter domain.PlanHistoryFilter) ([]domain.PlanHistory, error)
+	ScheduleChange(ctx context.Context, userID uint, scheduled *domain.ScheduledChange) (*domain.ScheduledChange, error)
+	ListScheduledChanges(ctx context.Context, userID uint) ([]domain.ScheduledChange, error)
+	RevokeScheduledChange(ctx context.Context, userID, changeID uint) error
+
+	SetPlanPrice(ctx context.Context, planID uint, price *domain.PlanPrice) error
+	ListPlanPrices(ctx context.Context, planID uint) ([]domain.PlanPrice, error)
+	DeletePlanPrice(ctx context.Context, planID uint, months int) error
+
+	ListLimitations(ctx context.Context) ([]*domain.Limitation, error)
+	CreateLimitation(ctx context.Context, limitation *domain.Limitation) error
+	UpdateLimitation(ctx context.Context, limitation *domain.Limitation) error
+	DeleteLimitation(ctx context.Context, id uint) error
+	GetPlanLimitations(ctx context.Context, planID uint) ([]*domain.PlanLimitation, error)
+	AssignLimitationToPlan(ctx context.Context, planLimitation *domain.PlanLimitation) error
+	UpdatePlanLimitation(ctx context.Context, planLimitation *domain.PlanLimitation) error
+	RemoveLimitationFromPlan(ctx context.Context, planID, limitationID uint) error
+
+	GetUsageStatement(ctx context.Context, userID uint, period time.Time) (*domain.UsageStatement, error)
 }
@@ -3,10 +3,13 @@ package plan
 import (
 	"context"
 	"errors"
+	"time"
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/api/pb"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/audit"
+	auditD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/audit/domain"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/plan/domain"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/plan/port"
 )
@@ -17,31 +20,42 @@ var (
 )
 
 type service struct {
-	logger     *zap.Logger
-	planClient pb.PlanServiceClient
+	logger           *zap.Logger
+	planClient       pb.PlanServiceClient
+	limitationClient pb.LimitationServiceClient
+	usageClient      pb.UsageServiceClient
 }
 
 func NewService(logger *zap.Logger, cc *grpc.ClientConn) port.Service {
 	return &service{
-		logger:     logger,
-		planClient: pb.NewPlanServiceClient(cc),
+		logger:           logger,
+		planClient:       pb.NewPlanServiceClient(cc),
+		limitationClient: pb.NewLimitationServiceClient(cc),
+		usageClient:      pb.NewUsageServiceClient(cc),
 	}
 }
 
 func (s *service) CreatePlan(ctx context.Context, plan *domain.Plan) error {
 	grpcPlan := &pb.Plan{
-		Name:         plan.Name,
-		Description:  plan.Description,
-		DurationDays: int64(plan.Duration),
-		Price:        plan.Price,
-		IsActive:     plan.IsActive,
+		Name:               plan.Name,
+		Description:        plan.Description,
+		DurationDays:       int64(plan.Duration),
+		Prices:             planPricesDomain2Proto(plan.Prices),
+		GracePeriodDays:    int32(plan.GracePeriodDays),
+		TrialDays:          int32(plan.TrialDays),
+		TrialConvertPlanId: uint64(plan.TrialConvertPlanID),
+		TrialConvertMonths: int32(plan.TrialConvertMonths),
+		IsActive:           plan.IsActive,
+		Payg:               plan.PAYG,
 	}
 
-	_, err := s.planClient.CreatePlan(ctx, &pb.CreatePlanRequest{Plan: grpcPlan})
+	created, err := s.planClient.CreatePlan(ctx, &pb.CreatePlanRequest{Plan: grpcPlan})
 	if err != nil {
 		s.logger.Error("Failed to create plan via gRPC", zap.Error(err), zap.String("name", plan.Name))
 		return err
 	}
+	plan.ID = uint(created.GetId())
+	audit.SetAfter(ctx, auditD.EntityPlan, plan.ID, plan)
 
 	s.logger.Info("Successfully created plan via gRPC", zap.String("name", plan.Name))
 	return nil
@@ -55,11 +69,16 @@ func (s *service) GetPlanByID(ctx context.Context, id uint) (*domain.Plan, error
 	}
 
 	plan := &domain.Plan{
-		Name:        grpcPlan.Name,
-		Description: grpcPlan.Description,
-		Price:       grpcPlan.Price,
-		Duration:    int(grpcPlan.DurationDays),
-		IsActive:    grpcPlan.IsActive,
+		Name:               grpcPlan.Name,
+		Description:        grpcPlan.Description,
+		Prices:             planPricesProto2Domain(grpcPlan.Prices),
+		GracePeriodDays:    int(grpcPlan.GracePeriodDays),
+		TrialDays:          int(grpcPlan.TrialDays),
+		TrialConvertPlanID: uint(grpcPlan.TrialConvertPlanId),
+		TrialConvertMonths: int(grpcPlan.TrialConvertMonths),
+		Duration:           int(grpcPlan.DurationDays),
+		IsActive:           grpcPlan.IsActive,
+		PAYG:               grpcPlan.Payg,
 	}
 	plan.ID = uint(grpcPlan.Id)
 
@@ -75,11 +94,16 @@ func (s *service) GetPlanByName(ctx context.Context, name string) (*domain.Plan,
 	}
 
 	plan := &domain.Plan{
-		Name:        grpcPlan.Name,
-		Description: grpcPlan.Description,
-		Price:       grpcPlan.Price,
-		Duration:    int(grpcPlan.DurationDays),
-		IsActive:    grpcPlan.IsActive,
+		Name:               grpcPlan.Name,
+		Description:        grpcPlan.Description,
+		Prices:             planPricesProto2Domain(grpcPlan.Prices),
+		GracePeriodDays:    int(grpcPlan.GracePeriodDays),
+		TrialDays:          int(grpcPlan.TrialDays),
+		TrialConvertPlanID: uint(grpcPlan.TrialConvertPlanId),
+		TrialConvertMonths: int(grpcPlan.TrialConvertMonths),
+		Duration:           int(grpcPlan.DurationDays),
+		IsActive:           grpcPlan.IsActive,
+		PAYG:               grpcPlan.Payg,
 	}
 	plan.ID = uint(grpcPlan.Id)
 
@@ -89,25 +113,33 @@ func (s *service) GetPlanByName(ctx context.Context, name string) (*domain.Plan,
 
 func (s *service) UpdatePlan(ctx context.Context, plan *domain.Plan) error {
 	grpcPlan := &pb.Plan{
-		Id:           uint64(plan.ID),
-		Name:         plan.Name,
-		Description:  plan.Description,
-		DurationDays: int64(plan.Duration),
-		Price:        plan.Price,
-		IsActive:     plan.IsActive,
+		Id:                 uint64(plan.ID),
+		Name:               plan.Name,
+		Description:        plan.Description,
+		DurationDays:       int64(plan.Duration),
+		Prices:             planPricesDomain2Proto(plan.Prices),
+		GracePeriodDays:    int32(plan.GracePeriodDays),
+		TrialDays:          int32(plan.TrialDays),
+		TrialConvertPlanId: uint64(plan.TrialConvertPlanID),
+		TrialConvertMonths: int32(plan.TrialConvertMonths),
+		IsActive:           plan.IsActive,
+		Payg:               plan.PAYG,
 	}
 
+	s.snapshotBefore(ctx, plan.ID)
 	_, err := s.planClient.UpdatePlan(ctx, &pb.UpdatePlanRequest{Plan: grpcPlan})
 	if err != nil {
 		s.logger.Error("Failed to update plan via gRPC", zap.Error(err), zap.Uint("id", plan.ID), zap.String("name", plan.Name))
 		return err
 	}
+	s.snapshotAfter(ctx, plan.ID)
 
 	s.logger.Info("Successfully updated plan via gRPC", zap.Uint("id", plan.ID), zap.String("name", plan.Name))
 	return nil
 }
 
 func (s *service) DeletePlan(ctx context.Context, id uint) error {
+	s.snapshotBefore(ctx, id)
 	_, err := s.planClient.DeletePlan(ctx, &pb.PlanIDRequest{Id: uint64(id)})
 	if err != nil {
 		s.logger.Error("Failed to delete plan via gRPC", zap.Error(err), zap.Uint("id", id))
@@ -131,11 +163,16 @@ func (s *service) ListPlans(ctx context.Context, limit, offset int) ([]*domain.P
 	var plans []*domain.Plan
 	for _, grpcPlan := range response.Plans {
 		plan := &domain.Plan{
-			Name:        grpcPlan.Name,
-			Description: grpcPlan.Description,
-			Price:       grpcPlan.Price,
-			Duration:    int(grpcPlan.DurationDays),
-			IsActive:    grpcPlan.IsActive,
+			Name:               grpcPlan.Name,
+			Description:        grpcPlan.Description,
+			Prices:             planPricesProto2Domain(grpcPlan.Prices),
+			GracePeriodDays:    int(grpcPlan.GracePeriodDays),
+			TrialDays:          int(grpcPlan.TrialDays),
+			TrialConvertPlanID: uint(grpcPlan.TrialConvertPlanId),
+			TrialConvertMonths: int(grpcPlan.TrialConvertMonths),
+			Duration:           int(grpcPlan.DurationDays),
+			IsActive:           grpcPlan.IsActive,
+			PAYG:               grpcPlan.Payg,
 		}
 		plan.ID = uint(grpcPlan.Id)
 		plans = append(plans, plan)
@@ -146,12 +183,514 @@ func (s *service) ListPlans(ctx context.Context, limit, offset int) ([]*domain.P
 }
 
 func (s *service) TogglePlanActive(ctx context.Context, id uint) error {
+	s.snapshotBefore(ctx, id)
 	_, err := s.planClient.TogglePlanActive(ctx, &pb.PlanIDRequest{Id: uint64(id)})
 	if err != nil {
 		s.logger.Error("Failed to toggle plan active status via gRPC", zap.Error(err), zap.Uint("id", id))
 		return err
 	}
+	s.snapshotAfter(ctx, id)
 
 	s.logger.Info("Successfully toggled plan active status via gRPC", zap.Uint("id", id))
 	return nil
 }
+
+func (s *service) AssignPlan(ctx context.Context, userID uint, assignment domain.PlanAssignment, change domain.PlanChange) error {
+	_, err := s.planClient.AssignPlan(ctx, &pb.PlanAssignmentRequest{
+		UserId:    uint64(userID),
+		PlanId:    uint64(assignment.PlanID),
+		Months:    int32(assignment.Months),
+		AutoRenew: assignment.AutoRenew,
+		Pending:   assignment.Pending,
+		ChangedBy: change.By,
+		Reason:    change.Reason,
+	})
+	if err != nil {
+		s.logger.Error("Failed to assign plan via gRPC", zap.Error(err),
+			zap.Uint("user_id", userID), zap.Uint("plan_id", assignment.PlanID), zap.Int("months", assignment.Months))
+		return err
+	}
+
+	s.logger.Info("Successfully assigned plan via gRPC",
+		zap.Uint("user_id", userID), zap.Uint("plan_id", assignment.PlanID), zap.Int("months", assignment.Months))
+	return nil
+}
+
+func (s *service) SetAutoRenew(ctx context.Context, userID uint, enabled bool) error {
+	_, err := s.planClient.SetAutoRenew(ctx, &pb.AutoRenewRequest{UserId: uint64(userID), Enabled: enabled})
+	if err != nil {
+		s.logger.Error("Failed to set auto-renew via gRPC", zap.Error(err), zap.Uint("user_id", userID))
+		return err
+	}
+
+	s.logger.Info("Successfully set auto-renew via gRPC", zap.Uint("user_id", userID), zap.Bool("enabled", enabled))
+	return nil
+}
+
+func (s *service) StartTrial(ctx context.Context, userID, planID uint, change domain.PlanChange) error {
+	_, err := s.planClient.StartTrial(ctx, &pb.StartTrialRequest{
+		UserId:    uint64(userID),
+		PlanId:    uint64(planID),
+		ChangedBy: change.By,
+		Reason:    change.Reason,
+	})
+	if err != nil {
+		s.logger.Error("Failed to start trial via gRPC", zap.Error(err), zap.Uint("user_id", userID), zap.Uint("plan_id", planID))
+		return err
+	}
+
+	s.logger.Info("Successfully started trial via gRPC", zap.Uint("user_id", userID), zap.Uint("plan_id", planID))
+	return nil
+}
+
+func (s *service) ChangeUserPlan(ctx context.Context, userID, planID uint, deferDowngrade bool, change domain.PlanChange) (*domain.PlanChangeResult, error) {
+	response, err := s.planClient.ChangeUserPlan(ctx, &pb.ChangePlanRequest{
+		UserId:         uint64(userID),
+		PlanId:         uint64(planID),
+		DeferDowngrade: deferDowngrade,
+		ChangedBy:      change.By,
+		Reason:         change.Reason,
+	})
+	if err != nil {
+		s.logger.Error("Failed to change user plan via gRPC", zap.Error(err),
+			zap.Uint("user_id", userID), zap.Uint("plan_id", planID))
+		return nil, err
+	}
+
+	s.logger.Info("Successfully changed user plan via gRPC",
+		zap.Uint("user_id", userID), zap.Uint("plan_id", planID), zap.String("action", response.Action))
+	return &domain.PlanChangeResult{
+		Action:      response.Action,
+		Proration:   response.Proration,
+		Deferred:    response.Deferred,
+		EffectiveAt: time.Unix(response.EffectiveAt, 0),
+	}, nil
+}
+
+func (s *service) ActivateUserPlan(ctx context.Context, userID uint, change domain.PlanChange) error {
+	_, err := s.planClient.ActivateUserPlan(ctx, planTransitionRequest(userID, change))
+	if err != nil {
+		s.logger.Error("Failed to activate user plan via gRPC", zap.Error(err), zap.Uint("user_id", userID))
+		return err
+	}
+
+	s.logger.Info("Successfully activated user plan via gRPC", zap.Uint("user_id", userID))
+	return nil
+}
+
+func (s *service) SuspendUserPlan(ctx context.Context, userID uint, change domain.PlanChange) error {
+	_, err := s.planClient.SuspendUserPlan(ctx, planTransitionRequest(userID, change))
+	if err != nil {
+		s.logger.Error("Failed to suspend user plan via gRPC", zap.Error(err), zap.Uint("user_id", userID))
+		return err
+	}
+
+	s.logger.Info("Successfully suspended user plan via gRPC", zap.Uint("user_id", userID))
+	return nil
+}
+
+func (s *service) ResumeUserPlan(ctx context.Context, userID uint, change domain.PlanChange) error {
+	_, err := s.planClient.ResumeUserPlan(ctx, planTransitionRequest(userID, change))
+	if err != nil {
+		s.logger.Error("Failed to resume user plan via gRPC", zap.Error(err), zap.Uint("user_id", userID))
+		return err
+	}
+
+	s.logger.Info("Successfully resumed user plan via gRPC", zap.Uint("user_id", userID))
+	return nil
+}
+
+func (s *service) CancelUserPlan(ctx context.Context, userID uint, change domain.PlanChange) error {
+	_, err := s.planClient.CancelUserPlan(ctx, planTransitionRequest(userID, change))
+	if err != nil {
+		s.logger.Error("Failed to cancel user plan via gRPC", zap.Error(err), zap.Uint("user_id", userID))
+		return err
+	}
+
+	s.logger.Info("Successfully canceled user plan via gRPC", zap.Uint("user_id", userID))
+	return nil
+}
+
+func (s *service) GetPlanHistory(ctx context.Context, filter domain.PlanHistoryFilter) ([]domain.PlanHistory, error) {
+	userID := filter.UserID
+	req := &pb.PlanHistoryRequest{UserId: uint64(userID), Action: filter.Action, MetadataKey: filter.MetadataKey}
+	if len(filter.Metadata) > 0 {
+		metadata, err := filter.Metadata.Value()
+		if err != nil {
+			return nil, err
+		}
+		req.Metadata = metadata.(string)
+	}
+	response, err := s.planClient.GetPlanHistory(ctx, req)
+	if err != nil {
+		s.logger.Error("Failed to get plan history via gRPC", zap.Error(err), zap.Uint("user_id", userID))
+		return nil, err
+	}
+
+	history := make([]domain.PlanHistory, 0, len(response.Entries))
+	for _, e := range response.Entries {
+		entry := domain.PlanHistory{
+			ID:         uint(e.Id),
+			UserPlanID: uint(e.UserPlanId),
+			Action:     e.Action,
+			FromStatus: e.FromStatus,
+			ToStatus:   e.ToStatus,
+			OldPlanID:  uint(e.OldPlanId),
+			NewPlanID:  uint(e.NewPlanId),
+			ChangedBy:  e.ChangedBy,
+			Reason:     e.Reason,
+			ChangedAt:  time.Unix(e.ChangedAt, 0),
+		}
+		if e.Metadata != "" {
+			if err := entry.Metadata.Scan(e.Metadata); err != nil {
+				s.logger.Warn("Invalid plan history metadata", zap.Error(err), zap.Uint("id", entry.ID))
+			}
+		}
+		history = append(history, entry)
+	}
+
+	s.logger.Info("Successfully retrieved plan history via gRPC", zap.Uint("user_id", userID), zap.Int("count", len(history)))
+	return history, nil
+}
+
+func (s *service) ScheduleChange(ctx context.Context, userID uint, scheduled *domain.ScheduledChange) (*domain.ScheduledChange, error) {
+	req := &pb.ScheduleChangeRequest{
+		UserId:    uint64(userID),
+		Action:    scheduled.Action,
+		PlanId:    uint64(scheduled.PlanID),
+		ChangedBy: scheduled.ChangedBy,
+		Reason:    scheduled.Reason,
+	}
+	if !scheduled.EffectiveAt.IsZero() {
+		req.EffectiveAt = scheduled.EffectiveAt.Unix()
+	}
+
+	response, err := s.planClient.ScheduleUserPlanChange(ctx, req)
+	if err != nil {
+		s.logger.Error("Failed to schedule plan change via gRPC", zap.Error(err),
+			zap.Uint("user_id", userID), zap.String("action", scheduled.Action))
+		return nil, err
+	}
+
+	s.logger.Info("Successfully scheduled plan change via gRPC",
+		zap.Uint("user_id", userID), zap.Uint64("change_id", response.Id))
+	return scheduledChangeProto2Domain(response), nil
+}
+
+func (s *service) ListScheduledChanges(ctx context.Context, userID uint) ([]domain.ScheduledChange, error) {
+	response, err := s.planClient.ListScheduledChanges(ctx, &pb.UserPlanRequest{UserId: uint64(userID)})
+	if err != nil {
+		s.logger.Error("Failed to list scheduled changes via gRPC", zap.Error(err), zap.Uint("user_id", userID))
+		return nil, err
+	}
+
+	changes := make([]domain.ScheduledChange, 0, len(response.Changes))
+	for _, c := range response.Changes {
+		changes = append(changes, *scheduledChangeProto2Domain(c))
+	}
+
+	s.logger.Info("Successfully listed scheduled changes via gRPC", zap.Uint("user_id", userID), zap.Int("count", len(changes)))
+	return changes, nil
+}
+
+func (s *service) RevokeScheduledChange(ctx context.Context, userID, changeID uint) error {
+	_, err := s.planClient.RevokeScheduledChange(ctx, &pb.ScheduledChangeIDRequest{
+		UserId: uint64(userID),
+		Id:     uint64(changeID),
+	})
+	if err != nil {
+		s.logger.Error("Failed to revoke scheduled change via gRPC", zap.Error(err),
+			zap.Uint("user_id", userID), zap.Uint("change_id", changeID))
+		return err
+	}
+
+	s.logger.Info("Successfully revoked scheduled change via gRPC", zap.Uint("user_id", userID), zap.Uint("change_id", changeID))
+	return nil
+}
+
+func scheduledChangeProto2Domain(c *pb.ScheduledChange) *domain.ScheduledChange {
+	return &domain.ScheduledChange{
+		ID:          uint(c.Id),
+		UserPlanID:  uint(c.UserPlanId),
+		Action:      c.Action,
+		PlanID:      uint(c.PlanId),
+		EffectiveAt: time.Unix(c.EffectiveAt, 0),
+		Status:      c.Status,
+		ChangedBy:   c.ChangedBy,
+		Reason:      c.Reason,
+		CreatedAt:   time.Unix(c.CreatedAt, 0),
+	}
+}
+
+func planTransitionRequest(userID uint, change domain.PlanChange) *pb.PlanTransitionRequest {
+	return &pb.PlanTransitionRequest{
+		UserId:    uint64(userID),
+		ChangedBy: change.By,
+		Reason:    change.Reason,
+	}
+}
+
+func (s *service) ListLimitations(ctx context.Context) ([]*domain.Limitation, error) {
+	response, err := s.limitationClient.ListLimitations(ctx, &pb.Empty{})
+	if err != nil {
+		s.logger.Error("Failed to list limitations via gRPC", zap.Error(err))
+		return nil, err
+	}
+
+	limitations := make([]*domain.Limitation, 0, len(response.Limitations))
+	for _, grpcLimitation := range response.Limitations {
+		limitations = append(limitations, limitationProto2Domain(grpcLimitation))
+	}
+
+	s.logger.Info("Successfully listed limitations via gRPC", zap.Int("count", len(limitations)))
+	return limitations, nil
+}
+
+func (s *service) CreateLimitation(ctx context.Context, limitation *domain.Limitation) error {
+	grpcLimitation, err := s.limitationClient.CreateLimitation(ctx, &pb.CreateLimitationRequest{
+		Limitation: &pb.Limitation{Title: limitation.Title},
+	})
+	if err != nil {
+		s.logger.Error("Failed to create limitation via gRPC", zap.Error(err), zap.String("title", limitation.Title))
+		return err
+	}
+	limitation.ID = uint(grpcLimitation.Id)
+
+	s.logger.Info("Successfully created limitation via gRPC", zap.Uint("id", limitation.ID))
+	return nil
+}
+
+func (s *service) UpdateLimitation(ctx context.Context, limitation *domain.Limitation) error {
+	_, err := s.limitationClient.UpdateLimitation(ctx, &pb.UpdateLimitationRequest{
+		Limitation: &pb.Limitation{Id: uint64(limitation.ID), Title: limitation.Title},
+	})
+	if err != nil {
+		s.logger.Error("Failed to update limitation via gRPC", zap.Error(err), zap.Uint("id", limitation.ID))
+		return err
+	}
+
+	s.logger.Info("Successfully updated limitation via gRPC", zap.Uint("id", limitation.ID))
+	return nil
+}
+
+func (s *service) DeleteLimitation(ctx context.Context, id uint) error {
+	_, err := s.limitationClient.DeleteLimitation(ctx, &pb.LimitationIDRequest{Id: uint64(id)})
+	if err != nil {
+		s.logger.Error("Failed to delete limitation via gRPC", zap.Error(err), zap.Uint("id", id))
+		return err
+	}
+
+	s.logger.Info("Successfully deleted limitation via gRPC", zap.Uint("id", id))
+	return nil
+}
+
+func (s *service) GetPlanLimitations(ctx context.Context, planID uint) ([]*domain.PlanLimitation, error) {
+	response, err := s.limitationClient.ListPlanLimitations(ctx, &pb.PlanIDRequest{Id: uint64(planID)})
+	if err != nil {
+		s.logger.Error("Failed to list plan limitations via gRPC", zap.Error(err), zap.Uint("plan_id", planID))
+		return nil, err
+	}
+
+	planLimitations := make([]*domain.PlanLimitation, 0, len(response.Limitations))
+	for _, grpcPlanLimitation := range response.Limitations {
+		planLimitations = append(planLimitations, planLimitationProto2Domain(grpcPlanLimitation))
+	}
+
+	s.logger.Info("Successfully listed plan limitations via gRPC", zap.Uint("plan_id", planID), zap.Int("count", len(planLimitations)))
+	return planLimitations, nil
+}
+
+func (s *service) AssignLimitationToPlan(ctx context.Context, planLimitation *domain.PlanLimitation) error {
+	planID, limitationID := planLimitation.PlanID, planLimitation.Limitation.ID
+	grpcPlanLimitation, err := s.limitationClient.AssignLimitationToPlan(ctx, planLimitationDomain2Proto(planLimitation))
+	if err != nil {
+		s.logger.Error("Failed to assign limitation to plan via gRPC", zap.Error(err),
+			zap.Uint("plan_id", planID), zap.Uint("limitation_id", limitationID))
+		return err
+	}
+	*planLimitation = *planLimitationProto2Domain(grpcPlanLimitation)
+
+	s.logger.Info("Successfully assigned limitation to plan via gRPC",
+		zap.Uint("plan_id", planID), zap.Uint("limitation_id", limitationID))
+	return nil
+}
+
+func (s *service) UpdatePlanLimitation(ctx context.Context, planLimitation *domain.PlanLimitation) error {
+	planID, limitationID := planLimitation.PlanID, planLimitation.Limitation.ID
+	grpcPlanLimitation, err := s.limitationClient.UpdatePlanLimitation(ctx, planLimitationDomain2Proto(planLimitation))
+	if err != nil {
+		s.logger.Error("Failed to update plan limitation via gRPC", zap.Error(err),
+			zap.Uint("plan_id", planID), zap.Uint("limitation_id", limitationID))
+		return err
+	}
+	*planLimitation = *planLimitationProto2Domain(grpcPlanLimitation)
+
+	s.logger.Info("Successfully updated plan limitation via gRPC",
+		zap.Uint("plan_id", planID), zap.Uint("limitation_id", limitationID))
+	return nil
+}
+
+func (s *service) RemoveLimitationFromPlan(ctx context.Context, planID, limitationID uint) error {
+	_, err := s.limitationClient.RemoveLimitationFromPlan(ctx, &pb.PlanLimitationIDRequest{
+		PlanId:       uint64(planID),
+		LimitationId: uint64(limitationID),
+	})
+	if err != nil {
+		s.logger.Error("Failed to remove limitation from plan via gRPC", zap.Error(err),
+			zap.Uint("plan_id", planID), zap.Uint("limitation_id", limitationID))
+		return err
+	}
+
+	s.logger.Info("Successfully removed limitation from plan via gRPC",
+		zap.Uint("plan_id", planID), zap.Uint("limitation_id", limitationID))
+	return nil
+}
+
+func limitationProto2Domain(l *pb.Limitation) *domain.Limitation {
+	return &domain.Limitation{
+		ID:    uint(l.GetId()),
+		Title: l.GetTitle(),
+	}
+}
+
+func planLimitationProto2Domain(pl *pb.PlanLimitation) *domain.PlanLimitation {
+	planLimitation := &domain.PlanLimitation{
+		PlanID:     uint(pl.PlanId),
+		Limitation: *limitationProto2Domain(pl.Limitation),
+		Value:      int(pl.Value),
+		UnitPrice:  int(pl.UnitPrice),
+	}
+	if pl.TrialValue != nil {
+		trialValue := int(*pl.TrialValue)
+		planLimitation.TrialValue = &trialValue
+	}
+	return planLimitation
+}
+
+func planLimitationDomain2Proto(pl *domain.PlanLimitation) *pb.PlanLimitationRequest {
+	req := &pb.PlanLimitationRequest{
+		PlanId:       uint64(pl.PlanID),
+		LimitationId: uint64(pl.Limitation.ID),
+		Value:        int64(pl.Value),
+		UnitPrice:    int64(pl.UnitPrice),
+	}
+	if pl.TrialValue != nil {
+		trialValue := int64(*pl.TrialValue)
+		req.TrialValue = &trialValue
+	}
+	return req
+}
+
+func (s *service) GetUsageStatement(ctx context.Context, userID uint, period time.Time) (*domain.UsageStatement, error) {
+	req := &pb.UsageStatementRequest{UserId: uint64(userID)}
+	if !period.IsZero() {
+		req.Period = period.Unix()
+	}
+
+	grpcStatement, err := s.usageClient.GetUsageStatement(ctx, req)
+	if err != nil {
+		s.logger.Error("Failed to get usage statement via gRPC", zap.Error(err), zap.Uint("user_id", userID))
+		return nil, err
+	}
+
+	statement := &domain.UsageStatement{
+		UserID:      uint(grpcStatement.UserId),
+		PlanID:      uint(grpcStatement.PlanId),
+		PeriodStart: time.Unix(grpcStatement.PeriodStart, 0),
+		PeriodEnd:   time.Unix(grpcStatement.PeriodEnd, 0),
+		Lines:       make([]domain.UsageStatementLine, 0, len(grpcStatement.Lines)),
+		Total:       grpcStatement.Total,
+	}
+	for _, l := range grpcStatement.Lines {
+		statement.Lines = append(statement.Lines, domain.UsageStatementLine{
+			Limitation: l.Limitation,
+			Units:      l.Units,
+			UnitPrice:  l.UnitPrice,
+			Amount:     l.Amount,
+		})
+	}
+
+	s.logger.Info("Successfully retrieved usage statement via gRPC", zap.Uint("user_id", userID))
+	return statement, nil
+}
+
+func (s *service) SetPlanPrice(ctx context.Context, planID uint, price *domain.PlanPrice) error {
+	s.snapshotBefore(ctx, planID)
+	_, err := s.planClient.SetPlanPrice(ctx, &pb.PlanPriceRequest{
+		PlanId: uint64(planID),
+		Months: int32(price.Months),
+		Price:  price.Price,
+	})
+	if err != nil {
+		s.logger.Error("Failed to set plan price via gRPC", zap.Error(err),
+			zap.Uint("plan_id", planID), zap.Int("months", price.Months))
+		return err
+	}
+
+	s.snapshotAfter(ctx, planID)
+	s.logger.Info("Successfully set plan price via gRPC", zap.Uint("plan_id", planID), zap.Int("months", price.Months))
+	return nil
+}
+
+func (s *service) ListPlanPrices(ctx context.Context, planID uint) ([]domain.PlanPrice, error) {
+	response, err := s.planClient.ListPlanPrices(ctx, &pb.PlanIDRequest{Id: uint64(planID)})
+	if err != nil {
+		s.logger.Error("Failed to list plan prices via gRPC", zap.Error(err), zap.Uint("plan_id", planID))
+		return nil, err
+	}
+
+	s.logger.Info("Successfully listed plan prices via gRPC", zap.Uint("plan_id", planID), zap.Int("count", len(response.Prices)))
+	return planPricesProto2Domain(response.Prices), nil
+}
+
+func (s *service) DeletePlanPrice(ctx context.Context, planID uint, months int) error {
+	s.snapshotBefore(ctx, planID)
+	_, err := s.planClient.DeletePlanPrice(ctx, &pb.PlanPriceIDRequest{
+		PlanId: uint64(planID),
+		Months: int32(months),
+	})
+	if err != nil {
+		s.logger.Error("Failed to delete plan price via gRPC", zap.Error(err),
+			zap.Uint("plan_id", planID), zap.Int("months", months))
+		return err
+	}
+
+	s.snapshotAfter(ctx, planID)
+	s.logger.Info("Successfully deleted plan price via gRPC", zap.Uint("plan_id", planID), zap.Int("months", months))
+	return nil
+}
+
+// snapshotBefore records the plan as the audit before snapshot of an audited request
+func (s *service) snapshotBefore(ctx context.Context, id uint) {
+	if !audit.Recording(ctx) {
+		return
+	}
+	if plan, err := s.GetPlanByID(ctx, id); err == nil {
+		audit.SetBefore(ctx, auditD.EntityPlan, id, plan)
+	}
+}
+
+func (s *service) snapshotAfter(ctx context.Context, id uint) {
+	if !audit.Recording(ctx) {
+		return
+	}
+	if plan, err := s.GetPlanByID(ctx, id); err == nil {
+		audit.SetAfter(ctx, auditD.EntityPlan, id, plan)
+	}
+}
+
+func planPricesDomain2Proto(prices []domain.PlanPrice) []*pb.PlanPrice {
+	res := make([]*pb.PlanPrice, 0, len(prices))
+	for _, p := range prices {
+		res = append(res, &pb.PlanPrice{Months: int32(p.Months), Price: p.Price})
+	}
+	return res
+}
+
+func planPricesProto2Domain(prices []*pb.PlanPrice) []domain.PlanPrice {
+	res := make([]domain.PlanPrice, 0, len(prices))
+	for _, p := range prices {
+		res = append(res, domain.PlanPrice{Months: int(p.Months), Price: p.Price})
+	}
+	return res
+}
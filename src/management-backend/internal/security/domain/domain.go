@@ -0,0 +1,92 @@
+package domain
+
+import "time"
+
+const DefaultPageSize = 20
+
+// outcomes of login attempts
+const (
+	OutcomeSuccess          = "success"
+	OutcomeChallenged       = "2fa_challenged" // password accepted, the second factor is pending
+	OutcomeUnknownUser      = "unknown_user"
+	OutcomeInvalidPassword  = "invalid_password"
+	OutcomeDeactivated      = "deactivated"
+	OutcomeInvalidChallenge = "invalid_2fa_challenge"
+	OutcomeInvalidCode      = "invalid_2fa_code"
+	OutcomeCaptchaFailed    = "captcha_failed"
+	OutcomeThrottled        = "throttled"
+	OutcomeLocked           = "locked"
+)
+
+// Attempt is a login request, Email is empty on the second step until its challenge is verified
+type Attempt struct {
+	Email     string
+	IPAddress string
+	UserAgent string
+}
+
+// LoginEvent is the security log entry of a login attempt
+type LoginEvent struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"not null;index" json:"created_at"`
+	Email     string    `gorm:"size:255;index" json:"email"`
+	IPAddress string    `gorm:"size:45;index" json:"ip_address"` // IPv4 or IPv6
+	UserAgent string    `gorm:"size:512" json:"user_agent"`
+	Outcome   string    `gorm:"size:30;not null;index" json:"outcome"`
+}
+
+// LoginEventFilter selects login events, zero fields match everything
+type LoginEventFilter struct {
+	Email     string
+	IPAddress string
+	Outcome   string
+	From      time.Time // inclusive
+	To        time.Time // exclusive
+	Page      int       // 1-based
+	Size      int
+}
+
+type LoginEventList struct {
+	Events []*LoginEvent
+	Total  int64
+}
+
+// Failures counts the recent failed logins of an account or IP
+type Failures struct {
+	Count int
+	Last  time.Time
+}
+
+type Limit struct {
+	FreeAttempts int // failures the next attempt is not delayed after
+	LockAfter    int // failures locking out until LockDuration passed
+}
+
+// Policy throttles failed logins per account and per IP, every failure past the free attempts
+// doubles the delay before the next attempt up to MaxDelay, until the lock limit is reached
+type Policy struct {
+	Account   Limit
+	IP        Limit
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// LockDuration is also how long failures are remembered after the last one
+	LockDuration time.Duration
+	// CaptchaAfter failures of the account or IP require a captcha, 0 requires it on every login
+	CaptchaAfter int
+}
+
+// Wait returns how long after the last of failures the next attempt is allowed under limit,
+// locked once failures reach the lock limit
+func (p Policy) Wait(limit Limit, failures int) (wait time.Duration, locked bool) {
+	if limit.LockAfter > 0 && failures >= limit.LockAfter {
+		return p.LockDuration, true
+	}
+	if failures <= limit.FreeAttempts {
+		return 0, false
+	}
+	wait = p.MaxDelay
+	if n := failures - limit.FreeAttempts - 1; n < 32 && p.BaseDelay<<n < p.MaxDelay {
+		wait = p.BaseDelay << n
+	}
+	return wait, false
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyWait(t *testing.T) {
+	p := Policy{BaseDelay: time.Second, MaxDelay: 10 * time.Second, LockDuration: time.Hour}
+	limit := Limit{FreeAttempts: 2, LockAfter: 40}
+
+	for failures, want := range map[int]time.Duration{
+		0:  0,
+		2:  0,
+		3:  time.Second,
+		4:  2 * time.Second,
+		5:  4 * time.Second,
+		7:  10 * time.Second,
+		39: 10 * time.Second, // past the width of a shift
+	} {
+		wait, locked := p.Wait(limit, failures)
+		assert.Equal(t, want, wait, "%d failures", failures)
+		assert.False(t, locked)
+	}
+
+	wait, locked := p.Wait(limit, 40)
+	assert.True(t, locked)
+	assert.Equal(t, time.Hour, wait)
+}
@@ -0,0 +1,42 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/security/domain"
+)
+
+type Service interface {
+	// Check returns ErrThrottled or ErrLocked and how long to wait when the account or IP
+	// of attempt may not try to log in yet
+	Check(ctx context.Context, attempt *domain.Attempt) (time.Duration, error)
+	// Fail counts a failed login against the account and IP of attempt
+	Fail(ctx context.Context, attempt *domain.Attempt, outcome string) error
+	// Succeed forgets the failures of the account of attempt
+	Succeed(ctx context.Context, attempt *domain.Attempt)
+	// CaptchaRequired tells whether attempt has to solve a captcha before its credentials are checked
+	CaptchaRequired(ctx context.Context, attempt *domain.Attempt) (bool, error)
+	// Record logs an attempt that neither failed nor completed a login
+	Record(ctx context.Context, attempt *domain.Attempt, outcome string)
+	Unlock(ctx context.Context, email string) error
+	ListEvents(ctx context.Context, filter *domain.LoginEventFilter) (*domain.LoginEventList, error)
+}
+
+type EventRepository interface {
+	Create(ctx context.Context, event *domain.LoginEvent) error
+	List(ctx context.Context, filter *domain.LoginEventFilter, limit, offset int) ([]*domain.LoginEvent, int64, error)
+}
+
+// CaptchaVerifier checks the captcha token of a login, false for an unsolved or expired one
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token string) (bool, error)
+}
+
+// FailureCounter counts failed logins by key, the failures of a key are forgotten once ttl
+// passed without another one
+type FailureCounter interface {
+	Add(ctx context.Context, key string, now time.Time, ttl time.Duration) error
+	Get(ctx context.Context, key string) (domain.Failures, error)
+	Reset(ctx context.Context, key string) error
+}
@@ -0,0 +1,159 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/security/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/security/port"
+)
+
+var (
+	ErrThrottled = errors.New("too many failed logins, try again later")
+	ErrLocked    = errors.New("too many failed logins, the login is locked")
+)
+
+const (
+	accountKey = "login:failures:account:"
+	ipKey      = "login:failures:ip:"
+)
+
+type service struct {
+	log     *zap.Logger
+	events  port.EventRepository
+	counter port.FailureCounter
+	policy  domain.Policy
+}
+
+func NewService(log *zap.Logger, events port.EventRepository, counter port.FailureCounter, policy domain.Policy) port.Service {
+	return &service{log: log, events: events, counter: counter, policy: policy}
+}
+
+type limitedKey struct {
+	key   string
+	limit domain.Limit
+}
+
+// keys returns the counter keys of attempt, the IP and the account when its email is known
+func (s *service) keys(attempt *domain.Attempt) []limitedKey {
+	keys := []limitedKey{{key: ipKey + attempt.IPAddress, limit: s.policy.IP}}
+	if attempt.Email != "" {
+		keys = append(keys, limitedKey{key: accountKey + normalizeEmail(attempt.Email), limit: s.policy.Account})
+	}
+	return keys
+}
+
+// Check is called before the credentials are, so throttled attempts neither count nor tell
+// whether the password was right
+func (s *service) Check(ctx context.Context, attempt *domain.Attempt) (time.Duration, error) {
+	now := time.Now()
+	var wait time.Duration
+	var locked bool
+	for _, c := range s.keys(attempt) {
+		failures, err := s.counter.Get(ctx, c.key)
+		if err != nil {
+			return 0, err
+		}
+		w, l := s.policy.Wait(c.limit, failures.Count)
+		if remaining := failures.Last.Add(w).Sub(now); remaining > 0 {
+			wait = max(wait, remaining)
+			locked = locked || l
+		}
+	}
+
+	switch {
+	case wait <= 0:
+		return 0, nil
+	case locked:
+		s.Record(ctx, attempt, domain.OutcomeLocked)
+		return wait, ErrLocked
+	default:
+		s.Record(ctx, attempt, domain.OutcomeThrottled)
+		return wait, ErrThrottled
+	}
+}
+
+// CaptchaRequired spares the captcha until the account or IP of attempt failed CaptchaAfter times
+func (s *service) CaptchaRequired(ctx context.Context, attempt *domain.Attempt) (bool, error) {
+	if s.policy.CaptchaAfter <= 0 {
+		return true, nil
+	}
+	for _, c := range s.keys(attempt) {
+		failures, err := s.counter.Get(ctx, c.key)
+		if err != nil {
+			return true, err
+		}
+		if failures.Count >= s.policy.CaptchaAfter {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *service) Fail(ctx context.Context, attempt *domain.Attempt, outcome string) error {
+	s.Record(ctx, attempt, outcome)
+	now := time.Now()
+	for _, c := range s.keys(attempt) {
+		if err := s.counter.Add(ctx, c.key, now, s.policy.LockDuration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Succeed keeps the failures of the IP, an attacker logging into their own account
+// must not reset the counter of the IP they guess other passwords from. The login
+// succeeds even when the failures can not be forgotten, they expire anyway.
+func (s *service) Succeed(ctx context.Context, attempt *domain.Attempt) {
+	s.Record(ctx, attempt, domain.OutcomeSuccess)
+	if err := s.Unlock(ctx, attempt.Email); err != nil {
+		s.log.Error("failed to reset failed logins", zap.Error(err), zap.String("email", attempt.Email))
+	}
+}
+
+// Record never fails the login, the event is logged instead when it can not be stored
+func (s *service) Record(ctx context.Context, attempt *domain.Attempt, outcome string) {
+	event := &domain.LoginEvent{
+		CreatedAt: time.Now(),
+		Email:     attempt.Email,
+		IPAddress: attempt.IPAddress,
+		UserAgent: attempt.UserAgent,
+		Outcome:   outcome,
+	}
+	if err := s.events.Create(ctx, event); err != nil {
+		s.log.Error("failed to record login event",
+			zap.Error(err),
+			zap.String("email", event.Email),
+			zap.String("ip", event.IPAddress),
+			zap.String("outcome", event.Outcome),
+		)
+	}
+}
+
+// Unlock forgets the failures of the account of email
+func (s *service) Unlock(ctx context.Context, email string) error {
+	return s.counter.Reset(ctx, accountKey+normalizeEmail(email))
+}
+
+// ListEvents returns a page of the matching events, newest first
+func (s *service) ListEvents(ctx context.Context, filter *domain.LoginEventFilter) (*domain.LoginEventList, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Size < 1 {
+		filter.Size = domain.DefaultPageSize
+	}
+	events, total, err := s.events.List(ctx, filter, filter.Size, (filter.Page-1)*filter.Size)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.LoginEventList{Events: events, Total: total}, nil
+}
+
+// normalizeEmail counts the failures of an email in every spelling together
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
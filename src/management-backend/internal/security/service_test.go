@@ -0,0 +1,111 @@
+package security
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/adapter/throttle"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/security/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/internal/security/port"
+)
+
+type memEvents struct {
+	events []*domain.LoginEvent
+}
+
+func (r *memEvents) Create(_ context.Context, event *domain.LoginEvent) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *memEvents) List(context.Context, *domain.LoginEventFilter, int, int) ([]*domain.LoginEvent, int64, error) {
+	return r.events, int64(len(r.events)), nil
+}
+
+func (r *memEvents) outcomes() []string {
+	outcomes := make([]string, len(r.events))
+	for i, e := range r.events {
+		outcomes[i] = e.Outcome
+	}
+	return outcomes
+}
+
+func TestThrottling(t *testing.T) {
+	ctx := context.Background()
+	events := &memEvents{}
+	s := NewService(zap.NewNop(), events, throttle.NewMemory(), domain.Policy{
+		Account:      domain.Limit{FreeAttempts: 2, LockAfter: 4},
+		IP:           domain.Limit{FreeAttempts: 5, LockAfter: 8},
+		BaseDelay:    time.Minute,
+		MaxDelay:     4 * time.Minute,
+		LockDuration: time.Hour,
+	})
+	check := func(email, ip string) (time.Duration, error) {
+		return s.Check(ctx, &domain.Attempt{Email: email, IPAddress: ip})
+	}
+	fail := func(email, ip string) {
+		require.NoError(t, s.Fail(ctx, &domain.Attempt{Email: email, IPAddress: ip}, domain.OutcomeInvalidPassword))
+	}
+
+	fail("admin@arcaptcha.ir", "10.0.0.1")
+	fail("Admin@arcaptcha.ir", "10.0.0.1")
+	_, err := check("admin@arcaptcha.ir", "10.0.0.1")
+	assert.NoError(t, err, "free attempts are not delayed")
+
+	fail("admin@arcaptcha.ir", "10.0.0.1")
+	wait, err := check("admin@arcaptcha.ir", "10.0.0.2")
+	assert.ErrorIs(t, err, ErrThrottled, "the account is throttled from every IP")
+	assert.InDelta(t, time.Minute, wait, float64(time.Second))
+
+	fail("admin@arcaptcha.ir", "10.0.0.1")
+	wait, err = check("admin@arcaptcha.ir", "10.0.0.2")
+	assert.ErrorIs(t, err, ErrLocked)
+	assert.InDelta(t, time.Hour, wait, float64(time.Second))
+	_, err = check("other@arcaptcha.ir", "10.0.0.1")
+	assert.NoError(t, err, "the IP is below its own limits")
+
+	require.NoError(t, s.Unlock(ctx, "ADMIN@arcaptcha.ir"))
+	_, err = check("admin@arcaptcha.ir", "10.0.0.2")
+	assert.NoError(t, err)
+
+	fail("other@arcaptcha.ir", "10.0.0.1")
+	fail("other@arcaptcha.ir", "10.0.0.1")
+	s.Succeed(ctx, &domain.Attempt{Email: "other@arcaptcha.ir", IPAddress: "10.0.0.1"})
+	_, err = check("third@arcaptcha.ir", "10.0.0.1")
+	assert.ErrorIs(t, err, ErrThrottled, "a login does not reset the failures of its IP")
+	_, err = check("other@arcaptcha.ir", "10.0.0.2")
+	assert.NoError(t, err, "a login resets the failures of its account")
+
+	assert.Equal(t, []string{
+		domain.OutcomeInvalidPassword, domain.OutcomeInvalidPassword, domain.OutcomeInvalidPassword,
+		domain.OutcomeThrottled, domain.OutcomeInvalidPassword, domain.OutcomeLocked,
+		domain.OutcomeInvalidPassword, domain.OutcomeInvalidPassword, domain.OutcomeSuccess, domain.OutcomeThrottled,
+	}, events.outcomes())
+}
+
+func TestCaptchaRequired(t *testing.T) {
+	ctx := context.Background()
+	policy := domain.Policy{Account: domain.Limit{FreeAttempts: 5}, IP: domain.Limit{FreeAttempts: 5}, LockDuration: time.Hour}
+	required := func(s port.Service, email, ip string) bool {
+		ok, err := s.CaptchaRequired(ctx, &domain.Attempt{Email: email, IPAddress: ip})
+		require.NoError(t, err)
+		return ok
+	}
+
+	s := NewService(zap.NewNop(), &memEvents{}, throttle.NewMemory(), policy)
+	assert.True(t, required(s, "admin@arcaptcha.ir", "10.0.0.1"), "every login solves a captcha by default")
+
+	policy.CaptchaAfter = 2
+	s = NewService(zap.NewNop(), &memEvents{}, throttle.NewMemory(), policy)
+	assert.False(t, required(s, "admin@arcaptcha.ir", "10.0.0.1"))
+	for range 2 {
+		require.NoError(t, s.Fail(ctx, &domain.Attempt{Email: "admin@arcaptcha.ir", IPAddress: "10.0.0.1"}, domain.OutcomeInvalidPassword))
+	}
+	assert.True(t, required(s, "Admin@arcaptcha.ir", "10.0.0.2"), "failures of the account require a captcha from every IP")
+	assert.True(t, required(s, "other@arcaptcha.ir", "10.0.0.1"), "failures of the IP require a captcha for every account")
+	assert.False(t, required(s, "other@arcaptcha.ir", "10.0.0.2"))
+}
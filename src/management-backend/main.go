@@ -28,6 +28,13 @@ func main() {
 	log := logger.NewZapLogger(cfg.DevEnv)
 	defer log.Sync()
 
+	if flag.Arg(0) == "migrate" {
+		if err := cmd.Migrate(cfg, log, flag.Args()[1:]); err != nil {
+			log.Fatal("migration failed", zap.Error(err))
+		}
+		return
+	}
+
 	if err := cmd.Run(cfg, log); err != nil {
 		log.Fatal("application stopped", zap.Error(err))
 	}
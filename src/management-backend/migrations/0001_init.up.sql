@@ -0,0 +1,17 @@
+-- baseline schema, created only when missing so databases created by the former AutoMigrate start from this version
+
+CREATE TABLE IF NOT EXISTS admin_users (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    email varchar(255),
+    password_hash varchar(255),
+    first_name varchar(100),
+    last_name varchar(100),
+    last_login timestamptz,
+    is_active boolean DEFAULT true,
+    role varchar(50) DEFAULT 'admin'
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_admin_users_email ON admin_users (email);
+CREATE INDEX IF NOT EXISTS idx_admin_users_deleted_at ON admin_users (deleted_at);
@@ -0,0 +1,21 @@
+CREATE TABLE audit_logs (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id bigint,
+    action varchar(255),
+    entity_type varchar(255),
+    entity_id bigint,
+    request_id varchar(255),
+    ip_address varchar(45),
+    user_agent varchar(512),
+    metadata jsonb,
+    before jsonb,
+    after jsonb
+);
+CREATE INDEX idx_audit_logs_user_id ON audit_logs (user_id);
+CREATE INDEX idx_audit_logs_entity ON audit_logs (entity_type, entity_id);
+CREATE INDEX idx_audit_logs_request_id ON audit_logs (request_id);
+CREATE INDEX idx_audit_logs_created_at ON audit_logs (created_at);
+CREATE INDEX idx_audit_logs_deleted_at ON audit_logs (deleted_at);
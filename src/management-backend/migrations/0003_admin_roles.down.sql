@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_admin_users_role;
+ALTER TABLE admin_users ALTER COLUMN role SET DEFAULT 'admin';
+-- every admin could do everything before roles
+UPDATE admin_users SET role = 'admin';
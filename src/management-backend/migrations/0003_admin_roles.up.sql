@@ -0,0 +1,4 @@
+-- admin was the only role and allowed everything
+UPDATE admin_users SET role = 'superadmin' WHERE role = 'admin' OR role IS NULL;
+ALTER TABLE admin_users ALTER COLUMN role SET DEFAULT 'read-only';
+CREATE INDEX idx_admin_users_role ON admin_users (role);
@@ -0,0 +1,11 @@
+CREATE TABLE admin_tokens (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz NOT NULL,
+    user_id bigint NOT NULL REFERENCES admin_users (id) ON DELETE CASCADE,
+    purpose varchar(20) NOT NULL,
+    token_hash varchar(64) NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at timestamptz
+);
+CREATE UNIQUE INDEX idx_admin_tokens_token_hash ON admin_tokens (token_hash);
+CREATE INDEX idx_admin_tokens_user_id ON admin_tokens (user_id);
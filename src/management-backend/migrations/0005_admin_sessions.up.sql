@@ -0,0 +1,13 @@
+CREATE TABLE admin_sessions (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz NOT NULL,
+    user_id bigint NOT NULL REFERENCES admin_users (id) ON DELETE CASCADE,
+    refresh_hash varchar(64) NOT NULL,
+    previous_hash varchar(64),
+    expires_at timestamptz NOT NULL,
+    rotated_at timestamptz,
+    revoked_at timestamptz
+);
+CREATE UNIQUE INDEX idx_admin_sessions_refresh_hash ON admin_sessions (refresh_hash);
+CREATE INDEX idx_admin_sessions_previous_hash ON admin_sessions (previous_hash);
+CREATE INDEX idx_admin_sessions_user_id ON admin_sessions (user_id);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS admin_recovery_codes;
+
+ALTER TABLE admin_users
+    DROP COLUMN IF EXISTS totp_last_step,
+    DROP COLUMN IF EXISTS totp_enabled,
+    DROP COLUMN IF EXISTS totp_secret;
@@ -0,0 +1,13 @@
+ALTER TABLE admin_users
+    ADD COLUMN totp_secret varchar(64),
+    ADD COLUMN totp_enabled boolean NOT NULL DEFAULT false,
+    ADD COLUMN totp_last_step bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE admin_recovery_codes (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz NOT NULL,
+    user_id bigint NOT NULL REFERENCES admin_users (id) ON DELETE CASCADE,
+    code_hash varchar(64) NOT NULL,
+    used_at timestamptz
+);
+CREATE INDEX idx_admin_recovery_codes_user_id ON admin_recovery_codes (user_id);
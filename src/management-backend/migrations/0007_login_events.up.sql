@@ -0,0 +1,12 @@
+CREATE TABLE login_events (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz NOT NULL,
+    email varchar(255),
+    ip_address varchar(45),
+    user_agent varchar(512),
+    outcome varchar(30) NOT NULL
+);
+CREATE INDEX idx_login_events_created_at ON login_events (created_at);
+CREATE INDEX idx_login_events_email ON login_events (email);
+CREATE INDEX idx_login_events_ip_address ON login_events (ip_address);
+CREATE INDEX idx_login_events_outcome ON login_events (outcome);
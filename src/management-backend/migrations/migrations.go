@@ -0,0 +1,8 @@
+// Package migrations embeds the versioned schema migrations, named <version>_<name>.up.sql and
+// <version>_<name>.down.sql. Applied migrations must never be edited, change the schema with a new version.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
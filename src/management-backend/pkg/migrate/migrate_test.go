@@ -0,0 +1,67 @@
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/management-backend/migrations"
+)
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load(fstest.MapFS{
+		"0002_add_index.up.sql": {Data: []byte("CREATE INDEX i ON t (c);")},
+		"0001_init.up.sql":      {Data: []byte("CREATE TABLE t (c int);")},
+		"0001_init.down.sql":    {Data: []byte("DROP TABLE t;")},
+		"migrations.go":         {Data: []byte("package migrations")},
+	})
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+	assert.Equal(t, uint64(1), migrations[0].Version)
+	assert.Equal(t, "init", migrations[0].Name)
+	assert.Equal(t, "DROP TABLE t;", migrations[0].Down)
+	assert.NotEmpty(t, migrations[0].Checksum)
+	assert.Equal(t, uint64(2), migrations[1].Version)
+	assert.Empty(t, migrations[1].Down)
+
+	_, err = Load(fstest.MapFS{"init.up.sql": {}})
+	assert.ErrorIs(t, err, ErrInvalidName)
+	_, err = Load(fstest.MapFS{"0001_init.down.sql": {Data: []byte("DROP TABLE t;")}})
+	assert.ErrorIs(t, err, ErrMissingUp)
+	_, err = Load(fstest.MapFS{"0001_a.up.sql": {Data: []byte("SELECT 1;")}, "0001_b.up.sql": {Data: []byte("SELECT 1;")}})
+	assert.ErrorIs(t, err, ErrDuplicate)
+}
+
+func TestLoad_Embedded(t *testing.T) {
+	all, err := Load(migrations.FS)
+	require.NoError(t, err)
+	require.NotEmpty(t, all)
+	for _, m := range all {
+		assert.NotEmpty(t, m.Down, "%d_%s has no down migration", m.Version, m.Name)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	all, err := Load(fstest.MapFS{
+		"0001_init.up.sql": {Data: []byte("CREATE TABLE t (c int);")},
+		"0002_more.up.sql": {Data: []byte("CREATE TABLE u (c int);")},
+	})
+	require.NoError(t, err)
+	m := New(nil, all, zap.NewNop())
+
+	done := map[uint64]applied{1: {checksum: all[0].Checksum, name: "init"}}
+	assert.NoError(t, m.verify(done, false))
+	assert.ErrorIs(t, m.verify(done, true), ErrSchemaDrift)
+
+	done[2] = applied{checksum: all[1].Checksum, name: "more"}
+	assert.NoError(t, m.verify(done, true))
+
+	done[1] = applied{checksum: "edited", name: "init"}
+	assert.ErrorIs(t, m.verify(done, false), ErrSchemaDrift)
+
+	done[1] = applied{checksum: all[0].Checksum, name: "init"}
+	done[3] = applied{checksum: "x", name: "newer"}
+	assert.ErrorIs(t, m.verify(done, false), ErrSchemaDrift)
+}
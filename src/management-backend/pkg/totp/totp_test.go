@@ -0,0 +1,56 @@
+package totp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// base32 of the SHA-1 key of the RFC 6238 test vectors, "12345678901234567890"
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	// the last 6 digits of the 8 digit codes in RFC 6238 appendix B
+	for unix, want := range map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	} {
+		got, err := Code(rfcSecret, Step(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, want, got, "time %d", unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+	now := time.Now()
+
+	code, err := Code(secret, Step(now.Add(-Period)))
+	require.NoError(t, err)
+	step, ok := Validate(secret, code, now)
+	assert.True(t, ok, "the previous step is accepted for clock drift")
+	assert.Equal(t, Step(now)-1, step)
+
+	code, err = Code(secret, Step(now.Add(-3*Period)))
+	require.NoError(t, err)
+	_, ok = Validate(secret, code, now)
+	assert.False(t, ok)
+
+	_, ok = Validate(secret, "12345", now)
+	assert.False(t, ok)
+	_, ok = Validate("", "123456", now)
+	assert.False(t, ok)
+}
+
+func TestURL(t *testing.T) {
+	assert.Equal(t,
+		"otpauth://totp/Arcaptcha:admin@arcaptcha.ir?algorithm=SHA1&digits=6&issuer=Arcaptcha&period=30&secret="+rfcSecret,
+		URL("Arcaptcha", "admin@arcaptcha.ir", rfcSecret))
+}
@@ -1,19 +1,28 @@
 package app
 
 import (
+	"context"
 	"errors"
+	"fmt"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/config"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/adapter/notification"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/adapter/payment"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/adapter/repository"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan"
 	planD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/domain"
 	planP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/port"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/usage"
+	usageD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/usage/domain"
+	usageP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/usage/port"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/user"
 	userD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/user/domain"
 	userP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/user/port"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/migrations"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/pkg/database"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/pkg/migrate"
 )
 
 var (
@@ -27,14 +36,16 @@ type App interface {
 	DB() *gorm.DB
 	UserService() userP.Service
 	PlanService() planP.Service
+	UsageService() usageP.Service
 }
 
 type app struct {
-	cfg         config.Config
-	log         *zap.Logger
-	db          *gorm.DB
-	userService userP.Service
-	planService planP.Service
+	cfg          config.Config
+	log          *zap.Logger
+	db           *gorm.DB
+	userService  userP.Service
+	planService  planP.Service
+	usageService usageP.Service
 }
 
 func New(cfg config.Config, log *zap.Logger) (App, error) {
@@ -52,44 +63,104 @@ func New(cfg config.Config, log *zap.Logger) (App, error) {
 	userPlanRepo := repository.NewUserPlanRepository(db)
 	priceRepo := repository.NewPriceRepository(db)
 	limitationRepo := repository.NewLimitationRepository(db)
+	usageRepo := repository.NewUsageRepository(db)
+	scheduledChangeRepo := repository.NewScheduledChangeRepository(db)
+
+	charger, err := payment.New(cfg.Payment.Gateway)
+	if err != nil {
+		return nil, err
+	}
+	notifier, err := notification.New(cfg.Notification, log)
+	if err != nil {
+		return nil, err
+	}
 
 	// Initialize services
 	userService := user.New(userRepo)
-	planService := plan.New(planRepo, userPlanRepo, priceRepo, limitationRepo)
+	planService := plan.New(log, planRepo, userPlanRepo, priceRepo, limitationRepo, scheduledChangeRepo,
+		charger, notifier, userService)
+	usageService := usage.New(usageRepo, planService)
 
 	return &app{
-		cfg:         cfg,
-		log:         log,
-		db:          db,
-		userService: userService,
-		planService: planService,
+		cfg:          cfg,
+		log:          log,
+		db:           db,
+		userService:  userService,
+		planService:  planService,
+		usageService: usageService,
 	}, nil
 }
 
+// models are the tables of the services, checked against the schema on startup
+var models = []any{
+	&userD.User{},
+	&planD.Plan{},
+	&planD.Price{},
+	&planD.Limitation{},
+	&planD.PlanLimitation{},
+	&planD.UserPlan{},
+	&planD.PlanHistory{},
+	&planD.ScheduledChange{},
+	&planD.Trial{},
+	&planD.PlanReminder{},
+	&usageD.Usage{},
+}
+
+// initDB refuses to start on a schema that does not match the migrations of this version,
+// pending migrations are applied first when DB_MIGRATE is set
 func initDB(c config.DBConfig, log *zap.Logger) (*gorm.DB, error) {
-	dsn := database.PostgresDSN(
-		c.Host, c.Port, c.DBName, c.Schema, c.User, c.Password, c.AppName,
-	)
-	db, err := database.NewPostgresConnectionWithLogger(dsn, log)
+	db, err := openDB(c, log)
 	if err != nil {
 		return nil, err
 	}
-
-	//auto-migrate all models
-	err = db.AutoMigrate(
-		&userD.User{},
-		&planD.Plan{},
-		&planD.Price{},
-		&planD.Limitation{},
-		&planD.PlanLimitation{},
-		&planD.UserPlan{},
-	)
+	m, err := newMigrator(db, log)
 	if err != nil {
 		return nil, err
 	}
+
+	ctx := context.Background()
+	if c.Migrate {
+		if _, err := m.Up(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if err := m.Check(ctx); err != nil {
+		return nil, fmt.Errorf("%w, run the migrate command", err)
+	}
+	if err := migrate.CheckModels(db, models...); err != nil {
+		return nil, err
+	}
 	return db, nil
 }
 
+// NewMigrator returns the migrator of the service database
+func NewMigrator(c config.DBConfig, log *zap.Logger) (*migrate.Migrator, error) {
+	db, err := openDB(c, log)
+	if err != nil {
+		return nil, err
+	}
+	return newMigrator(db, log)
+}
+
+func newMigrator(db *gorm.DB, log *zap.Logger) (*migrate.Migrator, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	all, err := migrate.Load(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(sqlDB, all, log), nil
+}
+
+func openDB(c config.DBConfig, log *zap.Logger) (*gorm.DB, error) {
+	dsn := database.PostgresDSN(
+		c.Host, c.Port, c.DBName, c.Schema, c.User, c.Password, c.AppName,
+	)
+	return database.NewPostgresConnectionWithLogger(dsn, log)
+}
+
 func (a *app) Config() config.Config { return a.cfg }
 
 func (a *app) Logger() *zap.Logger { return a.log }
@@ -99,3 +170,5 @@ func (a *app) DB() *gorm.DB { return a.db }
 func (a *app) UserService() userP.Service { return a.userService }
 
 func (a *app) PlanService() planP.Service { return a.planService }
+
+func (a *app) UsageService() usageP.Service { return a.usageService }
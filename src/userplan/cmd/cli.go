@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"os/user"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/app"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/config"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/pkg/logger"
+)
+
+// env is the configuration and logger shared by the CLI commands, loaded on the first command that needs them
+type env struct {
+	cfg config.Config
+	log *zap.Logger
+}
+
+// NewCLI returns the userplan admin CLI, running it without a command starts the server
+func NewCLI() *cli.App {
+	e := &env{}
+	serve := e.action(func(c *cli.Context) error {
+		return Run(e.cfg, e.log)
+	})
+
+	return &cli.App{
+		Name:  "userplan",
+		Usage: "user plan service and admin tools",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "envfile", Usage: "path to configuration env file"},
+			&cli.DurationFlag{Name: "timeout", Value: 5 * time.Minute, Usage: "time limit of admin commands"},
+		},
+		Action: serve,
+		After: func(c *cli.Context) error {
+			if e.log != nil {
+				e.log.Sync()
+			}
+			return nil
+		},
+		Commands: []*cli.Command{
+			{
+				Name:   "serve",
+				Usage:  "run the gRPC server and the scheduler",
+				Action: serve,
+			},
+			migrateCommand(e),
+			{
+				Name:  "expire-plans",
+				Usage: "apply scheduled changes, renew and expire plans and send expiration reminders",
+				Flags: append(reportFlags(),
+					&cli.BoolFlag{Name: "dry-run", Usage: "list the plans that would change without changing them"},
+				),
+				Action: e.action(func(c *cli.Context) error {
+					//the plans changed are only listed when asked for, a large run then keeps just the counts
+					var report *Report
+					if c.Bool("dry-run") || c.IsSet("output") || c.IsSet("output-file") {
+						r := reportOf(c)
+						report = &r
+					}
+					return ExpirePlans(e.cfg, e.log, c.Duration("timeout"), c.Bool("dry-run"), report)
+				}),
+			},
+			{
+				Name:  "expiring",
+				Usage: "list plans expiring within --days days",
+				Flags: append(reportFlags(),
+					&cli.IntFlag{Name: "days", Value: 7, Usage: "days threshold of expiring plans"},
+					&cli.BoolFlag{Name: "notify", Usage: "send the expiration reminders of REMINDER_SCHEDULE instead of listing"},
+				),
+				Action: e.action(func(c *cli.Context) error {
+					if c.Bool("notify") {
+						return NotifyExpiringPlans(e.cfg, e.log)
+					}
+					return GetExpiringPlans(e.cfg, e.log, c.Int("days"), reportOf(c))
+				}),
+			},
+			planCommand(e),
+			userCommand(e),
+			assignCommand(e),
+		},
+	}
+}
+
+// action loads the configuration and logger before running fn
+func (e *env) action(fn cli.ActionFunc) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		if e.log == nil {
+			if envfile := c.String("envfile"); envfile != "" {
+				godotenv.Load(envfile)
+			}
+			cfg, err := config.ReadEnv()
+			if err != nil {
+				return err
+			}
+			e.cfg = cfg
+			e.log = logger.NewZapLogger(cfg.DevEnv)
+		}
+		return fn(c)
+	}
+}
+
+// withApp runs fn on a new app instance within the --timeout of the command
+func (e *env) withApp(fn func(ctx context.Context, c *cli.Context, a app.App) error) cli.ActionFunc {
+	return e.action(func(c *cli.Context) error {
+		a, err := app.New(e.cfg, e.log)
+		if err != nil {
+			e.log.Error("Failed to create app instance", zap.Error(err))
+			return err
+		}
+		ctx, cancel := context.WithTimeout(c.Context, c.Duration("timeout"))
+		defer cancel()
+		return fn(ctx, c, a)
+	})
+}
+
+func reportFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Value: OutputTable, Usage: "report format: json, csv or table"},
+		&cli.StringFlag{Name: "output-file", Value: "-", Usage: "file the report is written to, - for stdout"},
+	}
+}
+
+func reportOf(c *cli.Context) Report {
+	return Report{Format: c.String("output"), File: c.String("output-file")}
+}
+
+// operator is recorded as the author of plan changes made from the CLI
+func operator() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return "cli:" + u.Username
+	}
+	return "cli"
+}
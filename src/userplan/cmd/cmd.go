@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"errors"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
@@ -9,6 +11,7 @@ import (
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/app"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/config"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/api/handlers/grpc"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/pkg/metrics"
 )
 
 func Run(cfg config.Config, log *zap.Logger) (err error) {
@@ -21,6 +24,26 @@ func Run(cfg config.Config, log *zap.Logger) (err error) {
 		return err
 	}
 
+	if cfg.Metrics.Addr != "" {
+		metricsServer := metrics.NewServer(cfg.Metrics.Addr)
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Error("metrics server failed", zap.Error(err))
+			}
+		}()
+		defer metricsServer.Close()
+	}
+
+	if cfg.Scheduler.Enabled {
+		jobs, err := newScheduler(a)
+		if err != nil {
+			return err
+		}
+		jobs.Start()
+		defer jobs.Stop()
+		log.Info("scheduler started")
+	}
+
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 	serverErr := make(chan error, 1)
@@ -2,16 +2,27 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"go.uber.org/zap"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/app"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/config"
+	planD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/pkg/database"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/pkg/metrics"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/pkg/util"
 )
 
-// runs the plan expiration process
-func ExpirePlans(cfg config.Config, log *zap.Logger) error {
-	log.Info("Starting plan expiration process")
+// applies due scheduled plan changes, runs the plan expiration process and sends expiration reminders.
+// report, when set, lists the plans changed; on dry runs nothing is changed and it lists the plans that would change
+func ExpirePlans(cfg config.Config, log *zap.Logger, timeout time.Duration, dryRun bool, report *Report) error {
+	if report != nil {
+		if err := report.Validate(); err != nil {
+			return err
+		}
+	}
+	log.Info("Starting plan expiration process", zap.Bool("dry_run", dryRun))
 
 	a, err := app.New(cfg, log)
 	if err != nil {
@@ -19,27 +30,117 @@ func ExpirePlans(cfg config.Config, log *zap.Logger) error {
 		return err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	//batches are committed as they finish, a run cut off by the timeout is resumed by the next one
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
+	//dry runs change nothing, real ones must not overlap the scheduled run of a replica
+	if !dryRun {
+		unlock, err := lockJob(ctx, database.NewAdvisoryLocker(a.DB()), jobExpirePlans)
+		if err != nil {
+			log.Error("Failed to take the expiration lock", zap.Error(err))
+			return err
+		}
+		defer unlock()
+	}
+
 	planService := a.PlanService()
 	if planService == nil {
 		log.Error("Plan service not available")
 		return err
 	}
 
-	err = planService.ExpirePlans(ctx)
+	result, err := expirePlans(ctx, a, planD.ExpirationOptions{BatchSize: cfg.Expiration.BatchSize, DryRun: dryRun, Report: report != nil})
+	if report != nil {
+		if werr := write(*report, stateChangeHeader, util.Map(result.Changes, newStateChangeRecord)); werr != nil {
+			log.Error("Failed to write report", zap.Error(werr))
+			if err == nil {
+				err = werr
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if result.Failed > 0 {
+		return fmt.Errorf("%d plans failed to expire: %v", result.Failed, result.FailedIDs)
+	}
+	if dryRun {
+		log.Info("Dry run completed, no plan was changed")
+		return nil
+	}
+
+	sent, err := planService.SendExpirationReminders(ctx, cfg.Reminder.Schedule)
 	if err != nil {
-		log.Error("Failed to expire plans", zap.Error(err))
+		log.Error("Failed to send expiration reminders", zap.Error(err))
 		return err
 	}
+	log.Info("Expiration reminders sent", zap.Int("count", sent))
 
 	log.Info("Plan expiration process completed successfully")
 	return nil
 }
 
+// expirePlans runs the expiration process, reporting its counts to the log and the metrics
+func expirePlans(ctx context.Context, a app.App, opts planD.ExpirationOptions) (*planD.ExpirationResult, error) {
+	log := a.Logger()
+	start := time.Now()
+	result, err := a.PlanService().ExpirePlans(ctx, opts)
+	if !opts.DryRun {
+		metrics.ObserveExpiration(result.Expired, result.PastDue, result.Skipped, result.Failed, time.Since(start))
+	}
+
+	fields := []zap.Field{
+		zap.Bool("dry_run", opts.DryRun),
+		zap.Int("expired", result.Expired),
+		zap.Int("past_due", result.PastDue),
+		zap.Int("skipped", result.Skipped),
+		zap.Int("failed", result.Failed),
+		zap.Int("batches", result.Batches),
+		zap.Duration("duration", time.Since(start)),
+	}
+	if opts.Report || opts.DryRun {
+		fields = append(fields, zap.Int("changes", len(result.Changes)))
+	}
+	if err != nil {
+		log.Error("Failed to expire plans", append(fields, zap.Error(err))...)
+		return result, err
+	}
+	if result.Failed > 0 {
+		log.Warn("Plans failed to expire", zap.Uints("user_plan_ids", result.FailedIDs))
+	}
+	log.Info("Plan expiration finished", fields...)
+	return result, nil
+}
+
+// sends the expiration reminders of REMINDER_SCHEDULE to subscribed users
+func NotifyExpiringPlans(cfg config.Config, log *zap.Logger) error {
+	log.Info("Sending expiration reminders", zap.Ints("schedule", cfg.Reminder.Schedule))
+
+	a, err := app.New(cfg, log)
+	if err != nil {
+		log.Error("Failed to create app instance", zap.Error(err))
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	sent, err := a.PlanService().SendExpirationReminders(ctx, cfg.Reminder.Schedule)
+	if err != nil {
+		log.Error("Failed to send expiration reminders", zap.Error(err))
+		return err
+	}
+
+	log.Info("Expiration reminders sent", zap.Int("count", sent))
+	return nil
+}
+
 // gets plans that are expiring soon
-func GetExpiringPlans(cfg config.Config, log *zap.Logger, daysThreshold int) error {
+func GetExpiringPlans(cfg config.Config, log *zap.Logger, daysThreshold int, report Report) error {
+	if err := report.Validate(); err != nil {
+		return err
+	}
 	log.Info("Getting expiring plans", zap.Int("days_threshold", daysThreshold))
 
 	a, err := app.New(cfg, log)
@@ -64,13 +165,5 @@ func GetExpiringPlans(cfg config.Config, log *zap.Logger, daysThreshold int) err
 	}
 
 	log.Info("Found expiring plans", zap.Int("count", len(plans)))
-	for _, plan := range plans {
-		log.Info("Expiring plan",
-			zap.Uint("user_id", plan.UserID),
-			zap.Uint("plan_id", plan.PlanID),
-			zap.Time("expires_at", plan.ExTime),
-		)
-	}
-
-	return nil
+	return write(report, expiringPlanHeader, util.Map(plans, newExpiringPlanRecord))
 }
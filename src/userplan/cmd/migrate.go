@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/app"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/pkg/migrate"
+)
+
+func migrateCommand(e *env) *cli.Command {
+	up := e.withMigrator(func(ctx context.Context, c *cli.Context, m *migrate.Migrator) error {
+		n, err := m.Up(ctx)
+		if err != nil {
+			return err
+		}
+		e.log.Info("database migrated", zap.Int("applied", n))
+		return nil
+	})
+
+	return &cli.Command{
+		Name:   "migrate",
+		Usage:  "apply the pending schema migrations",
+		Action: up,
+		Subcommands: []*cli.Command{
+			{
+				Name:   "up",
+				Usage:  "apply the pending schema migrations",
+				Action: up,
+			},
+			{
+				Name:  "down",
+				Usage: "roll back the last applied migrations",
+				Flags: []cli.Flag{
+					&cli.IntFlag{Name: "steps", Value: 1, Usage: "number of migrations to roll back"},
+				},
+				Action: e.withMigrator(func(ctx context.Context, c *cli.Context, m *migrate.Migrator) error {
+					n, err := m.Down(ctx, c.Int("steps"))
+					if err != nil {
+						return err
+					}
+					e.log.Info("migrations rolled back", zap.Int("rolled_back", n))
+					return nil
+				}),
+			},
+			{
+				Name:  "status",
+				Usage: "list the migrations and when they were applied",
+				Flags: reportFlags(),
+				Action: e.withMigrator(func(ctx context.Context, c *cli.Context, m *migrate.Migrator) error {
+					report := reportOf(c)
+					if err := report.Validate(); err != nil {
+						return err
+					}
+					statuses, err := m.Status(ctx)
+					if err != nil {
+						return err
+					}
+					records := make([]migrationRecord, 0, len(statuses))
+					for _, s := range statuses {
+						records = append(records, newMigrationRecord(s))
+					}
+					return write(report, migrationHeader, records)
+				}),
+			},
+		},
+	}
+}
+
+// withMigrator runs fn on the migrator of the service database, without the schema check of app.New
+func (e *env) withMigrator(fn func(ctx context.Context, c *cli.Context, m *migrate.Migrator) error) cli.ActionFunc {
+	return e.action(func(c *cli.Context) error {
+		m, err := app.NewMigrator(e.cfg.DB, e.log)
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(c.Context, c.Duration("timeout"))
+		defer cancel()
+		return fn(ctx, c, m)
+	})
+}
@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	planD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/domain"
+	userD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/user/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/pkg/migrate"
+)
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputCSV   = "csv"
+)
+
+var ErrUnknownOutput = errors.New("output must be one of json, csv or table")
+
+// Report is where a CLI command writes the user plans it changed or found
+type Report struct {
+	Format string
+	// File receives the report, empty or - for stdout
+	File string
+}
+
+func (r Report) Validate() error {
+	switch r.Format {
+	case OutputTable, OutputJSON, OutputCSV:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownOutput, r.Format)
+	}
+}
+
+type record interface {
+	row() []string
+}
+
+// stateChangeRecord is a user plan changed, or to be changed on dry runs, by the expiration process
+type stateChangeRecord struct {
+	UserPlanID uint      `json:"user_plan_id"`
+	UserID     uint      `json:"user_id"`
+	OldPlanID  uint      `json:"old_plan_id"`
+	NewPlanID  uint      `json:"new_plan_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Action     string    `json:"action"`
+	Reason     string    `json:"reason"`
+	ExTime     time.Time `json:"ex_time"`
+}
+
+var stateChangeHeader = []string{"user_plan_id", "user_id", "old_plan_id", "new_plan_id", "from_status", "to_status", "action", "reason", "ex_time"}
+
+func newStateChangeRecord(c *planD.StateChange) stateChangeRecord {
+	return stateChangeRecord{
+		UserPlanID: c.UserPlanID,
+		UserID:     c.UserID,
+		OldPlanID:  c.OldPlanID,
+		NewPlanID:  c.NewPlanID,
+		FromStatus: c.FromStatus,
+		ToStatus:   c.ToStatus,
+		Action:     c.Action,
+		Reason:     c.Reason,
+		ExTime:     c.ExTime,
+	}
+}
+
+func (r stateChangeRecord) row() []string {
+	return []string{uintString(r.UserPlanID), uintString(r.UserID), uintString(r.OldPlanID), uintString(r.NewPlanID),
+		r.FromStatus, r.ToStatus, r.Action, r.Reason, timeString(r.ExTime)}
+}
+
+// expiringPlanRecord is a user plan expiring within the requested days
+type expiringPlanRecord struct {
+	UserPlanID uint      `json:"user_plan_id"`
+	UserID     uint      `json:"user_id"`
+	PlanID     uint      `json:"plan_id"`
+	Status     string    `json:"status"`
+	AutoRenew  bool      `json:"auto_renew"`
+	ExTime     time.Time `json:"ex_time"`
+	ExpiresAt  time.Time `json:"expires_at"` // end of the grace period
+}
+
+var expiringPlanHeader = []string{"user_plan_id", "user_id", "plan_id", "status", "auto_renew", "ex_time", "expires_at"}
+
+func newExpiringPlanRecord(up *planD.UserPlan) expiringPlanRecord {
+	return expiringPlanRecord{
+		UserPlanID: up.ID,
+		UserID:     up.UserID,
+		PlanID:     up.PlanID,
+		Status:     up.Status,
+		AutoRenew:  up.AutoRenew,
+		ExTime:     up.ExTime,
+		ExpiresAt:  up.GraceEnd(),
+	}
+}
+
+func (r expiringPlanRecord) row() []string {
+	return []string{uintString(r.UserPlanID), uintString(r.UserID), uintString(r.PlanID), r.Status,
+		strconv.FormatBool(r.AutoRenew), timeString(r.ExTime), timeString(r.ExpiresAt)}
+}
+
+// planRecord is a plan with its term prices
+type planRecord struct {
+	ID                 uint          `json:"id"`
+	Title              string        `json:"title"`
+	PAYG               bool          `json:"payg"`
+	Custom             bool          `json:"custom"`
+	GracePeriodDays    int           `json:"grace_period_days"`
+	TrialDays          int           `json:"trial_days"`
+	TrialConvertPlanID uint          `json:"trial_convert_plan_id,omitempty"`
+	Prices             []priceRecord `json:"prices"`
+}
+
+var planHeader = []string{"id", "title", "payg", "custom", "grace_period_days", "trial_days", "trial_convert_plan_id", "prices"}
+
+func newPlanRecord(p *planD.Plan, prices []*planD.Price) planRecord {
+	r := planRecord{
+		ID:              p.ID,
+		Title:           p.Title,
+		PAYG:            p.PAYG,
+		Custom:          p.Custom,
+		GracePeriodDays: p.GracePeriodDays,
+		TrialDays:       p.TrialDays,
+		Prices:          make([]priceRecord, 0, len(prices)),
+	}
+	if p.TrialConvertPlanID != nil {
+		r.TrialConvertPlanID = *p.TrialConvertPlanID
+	}
+	for _, price := range prices {
+		r.Prices = append(r.Prices, priceRecord{PlanID: p.ID, Months: price.Month, Price: price.Price})
+	}
+	return r
+}
+
+// row lists the prices as months=price pairs, the format plan create accepts
+func (r planRecord) row() []string {
+	prices := make([]string, 0, len(r.Prices))
+	for _, p := range r.Prices {
+		prices = append(prices, fmt.Sprintf("%d=%d", p.Months, p.Price))
+	}
+	var convertPlan string
+	if r.TrialConvertPlanID != 0 {
+		convertPlan = uintString(r.TrialConvertPlanID)
+	}
+	return []string{uintString(r.ID), r.Title, strconv.FormatBool(r.PAYG), strconv.FormatBool(r.Custom),
+		strconv.Itoa(r.GracePeriodDays), strconv.Itoa(r.TrialDays), convertPlan, strings.Join(prices, " ")}
+}
+
+type priceRecord struct {
+	PlanID uint `json:"plan_id"`
+	Months int  `json:"months"`
+	Price  int  `json:"price"`
+}
+
+var priceHeader = []string{"plan_id", "months", "price"}
+
+func (r priceRecord) row() []string {
+	return []string{uintString(r.PlanID), strconv.Itoa(r.Months), strconv.Itoa(r.Price)}
+}
+
+// userRecord is a user as exported by user export and read by user import, passwords are never exported
+type userRecord struct {
+	ID                     uint      `json:"id,omitempty"`
+	Email                  string    `json:"email"`
+	Name                   string    `json:"name"`
+	Phone                  string    `json:"phone"`
+	CompanyName            string    `json:"company_name"`
+	JobTitle               string    `json:"job_title"`
+	OauthID                string    `json:"oauth_id"`
+	Active                 bool      `json:"active"`
+	SubscribeNews          bool      `json:"subscribe_news"`
+	SubscribeNotifications bool      `json:"subscribe_notifications"`
+	CreatedAt              time.Time `json:"created_at"`
+}
+
+var userHeader = []string{"id", "email", "name", "phone", "company_name", "job_title", "oauth_id",
+	"active", "subscribe_news", "subscribe_notifications", "created_at"}
+
+func newUserRecord(u *userD.User) userRecord {
+	return userRecord{
+		ID:                     u.ID,
+		Email:                  u.Email,
+		Name:                   u.Name,
+		Phone:                  u.Phone,
+		CompanyName:            u.CompanyName,
+		JobTitle:               u.JobTitle,
+		OauthID:                u.OauthID,
+		Active:                 u.Active,
+		SubscribeNews:          u.SubscribeNews,
+		SubscribeNotifications: u.SubscribeNotifications,
+		CreatedAt:              u.CreatedAt,
+	}
+}
+
+func (r userRecord) row() []string {
+	return []string{uintString(r.ID), r.Email, r.Name, r.Phone, r.CompanyName, r.JobTitle, r.OauthID,
+		strconv.FormatBool(r.Active), strconv.FormatBool(r.SubscribeNews), strconv.FormatBool(r.SubscribeNotifications),
+		timeString(r.CreatedAt)}
+}
+
+// migrationRecord is a schema migration of the binary or the database
+type migrationRecord struct {
+	Version   uint64     `json:"version"`
+	Name      string     `json:"name"`
+	State     string     `json:"state"`
+	AppliedAt *time.Time `json:"applied_at"`
+}
+
+var migrationHeader = []string{"version", "name", "state", "applied_at"}
+
+func newMigrationRecord(s migrate.Status) migrationRecord {
+	r := migrationRecord{Version: s.Version, Name: s.Name, State: "pending", AppliedAt: s.AppliedAt}
+	switch {
+	case !s.Known:
+		r.State = "unknown"
+	case s.Modified:
+		r.State = "modified"
+	case s.AppliedAt != nil:
+		r.State = "applied"
+	}
+	return r
+}
+
+func (r migrationRecord) row() []string {
+	var appliedAt string
+	if r.AppliedAt != nil {
+		appliedAt = timeString(*r.AppliedAt)
+	}
+	return []string{strconv.FormatUint(r.Version, 10), r.Name, r.State, appliedAt}
+}
+
+// write writes records in the report format
+func write[T record](report Report, header []string, records []T) (err error) {
+	var w io.Writer = os.Stdout
+	if report.File != "" && report.File != "-" {
+		f, err := os.Create(report.File)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		w = f
+	}
+
+	switch report.Format {
+	case OutputJSON:
+		if records == nil {
+			records = []T{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	case OutputCSV:
+		cw := csv.NewWriter(w)
+		cw.Write(header)
+		for _, r := range records {
+			cw.Write(r.row())
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(header, "\t")))
+		for _, r := range records {
+			fmt.Fprintln(tw, strings.Join(r.row(), "\t"))
+		}
+		return tw.Flush()
+	}
+}
+
+func uintString(v uint) string {
+	return strconv.FormatUint(uint64(v), 10)
+}
+
+func timeString(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
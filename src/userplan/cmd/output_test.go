@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	planD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/domain"
+)
+
+func TestWrite(t *testing.T) {
+	changes := []stateChangeRecord{newStateChangeRecord(&planD.StateChange{
+		UserPlanID: 3, UserID: 9, OldPlanID: 1, NewPlanID: 1,
+		FromStatus: planD.PlanStatusActive, ToStatus: planD.PlanStatusExpired,
+		Action: planD.PlanActionExpire, Reason: "term ended",
+		ExTime: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+	})}
+	dir := t.TempDir()
+
+	csvFile := filepath.Join(dir, "report.csv")
+	require.NoError(t, write(Report{Format: OutputCSV, File: csvFile}, stateChangeHeader, changes))
+	b, err := os.ReadFile(csvFile)
+	require.NoError(t, err)
+	assert.Equal(t, "user_plan_id,user_id,old_plan_id,new_plan_id,from_status,to_status,action,reason,ex_time\n"+
+		"3,9,1,1,active,expired,expire,term ended,2025-01-02T00:00:00Z\n", string(b))
+
+	jsonFile := filepath.Join(dir, "report.json")
+	require.NoError(t, write(Report{Format: OutputJSON, File: jsonFile}, stateChangeHeader, []stateChangeRecord(nil)))
+	b, err = os.ReadFile(jsonFile)
+	require.NoError(t, err)
+	var decoded []stateChangeRecord
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.NotNil(t, decoded)
+	assert.Empty(t, decoded)
+
+	assert.ErrorIs(t, Report{Format: "xml"}.Validate(), ErrUnknownOutput)
+}
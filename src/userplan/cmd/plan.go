@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/app"
+	planD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/domain"
+)
+
+var ErrInvalidPrice = errors.New("price must be given as months=price")
+
+func planCommand(e *env) *cli.Command {
+	planFlag := &cli.UintFlag{Name: "plan", Required: true, Usage: "plan ID"}
+	monthsFlag := &cli.IntFlag{Name: "months", Required: true, Usage: "term length in months"}
+
+	return &cli.Command{
+		Name:  "plan",
+		Usage: "manage plans and their prices",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "create",
+				Usage: "create a plan",
+				Flags: append(reportFlags(),
+					&cli.StringFlag{Name: "title", Required: true},
+					&cli.BoolFlag{Name: "payg", Usage: "bill metered usage instead of a fixed term"},
+					&cli.IntFlag{Name: "grace-days", Usage: "days the plan stays past due after its term ends"},
+					&cli.IntFlag{Name: "trial-days", Usage: "length of the free trial"},
+					&cli.UintFlag{Name: "trial-convert-plan", Usage: "plan trials convert to, trials expire without one"},
+					&cli.IntFlag{Name: "trial-convert-months", Value: 1, Usage: "term trials convert to"},
+					&cli.StringSliceFlag{Name: "price", Usage: "price of a term as months=price, repeatable"},
+				),
+				Action: e.withApp(createPlan),
+			},
+			{
+				Name:  "list",
+				Usage: "list plans and their prices",
+				Flags: append(reportFlags(),
+					&cli.BoolFlag{Name: "all", Usage: "include inactive plans"},
+				),
+				Action: e.withApp(listPlans),
+			},
+			{
+				Name:  "price",
+				Usage: "manage the prices of a plan",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "list",
+						Usage:  "list the prices of a plan",
+						Flags:  append(reportFlags(), planFlag),
+						Action: e.withApp(listPrices),
+					},
+					{
+						Name:  "set",
+						Usage: "set the price of a term, creating it when missing",
+						Flags: []cli.Flag{planFlag, monthsFlag,
+							&cli.IntFlag{Name: "price", Required: true},
+						},
+						Action: e.withApp(func(ctx context.Context, c *cli.Context, a app.App) error {
+							return a.PlanService().SetPlanPrice(ctx, c.Uint("plan"), c.Int("months"), c.Int("price"))
+						}),
+					},
+					{
+						Name:  "delete",
+						Usage: "delete the price of a term",
+						Flags: []cli.Flag{planFlag, monthsFlag},
+						Action: e.withApp(func(ctx context.Context, c *cli.Context, a app.App) error {
+							return a.PlanService().DeletePlanPrice(ctx, c.Uint("plan"), c.Int("months"))
+						}),
+					},
+				},
+			},
+		},
+	}
+}
+
+func assignCommand(e *env) *cli.Command {
+	return &cli.Command{
+		Name:  "assign",
+		Usage: "assign a plan to a user, canceling the plan it replaces",
+		Flags: []cli.Flag{
+			&cli.UintFlag{Name: "user", Required: true, Usage: "user ID"},
+			&cli.UintFlag{Name: "plan", Required: true, Usage: "plan ID"},
+			&cli.IntFlag{Name: "months", Value: 1, Usage: "term to purchase, ignored for PAYG plans"},
+			&cli.BoolFlag{Name: "auto-renew"},
+			&cli.StringFlag{Name: "reason", Usage: "recorded in the plan history"},
+			&cli.StringFlag{Name: "by", Value: operator(), Usage: "recorded in the plan history as the author of the change"},
+		},
+		Action: e.withApp(func(ctx context.Context, c *cli.Context, a app.App) error {
+			req := &planD.AssignPlanRequest{
+				UserID:    c.Uint("user"),
+				PlanID:    c.Uint("plan"),
+				Months:    c.Int("months"),
+				AutoRenew: c.Bool("auto-renew"),
+				Change:    planD.Change{By: c.String("by"), Reason: c.String("reason")},
+			}
+			if err := a.PlanService().AssignPlan(ctx, req); err != nil {
+				return err
+			}
+			a.Logger().Info("plan assigned", zap.Uint("user_id", req.UserID), zap.Uint("plan_id", req.PlanID))
+			return nil
+		}),
+	}
+}
+
+func createPlan(ctx context.Context, c *cli.Context, a app.App) error {
+	report := reportOf(c)
+	if err := report.Validate(); err != nil {
+		return err
+	}
+	prices, err := parsePrices(c.StringSlice("price"))
+	if err != nil {
+		return err
+	}
+
+	plan := &planD.Plan{
+		Title:              c.String("title"),
+		PAYG:               c.Bool("payg"),
+		GracePeriodDays:    c.Int("grace-days"),
+		TrialDays:          c.Int("trial-days"),
+		TrialConvertMonths: c.Int("trial-convert-months"),
+	}
+	if id := c.Uint("trial-convert-plan"); id != 0 {
+		plan.TrialConvertPlanID = &id
+	}
+	if err := a.PlanService().CreatePlan(ctx, plan); err != nil {
+		return err
+	}
+	for _, p := range prices {
+		if err := a.PlanService().SetPlanPrice(ctx, plan.ID, p.Month, p.Price); err != nil {
+			return fmt.Errorf("plan %d created, setting its %d month price: %w", plan.ID, p.Month, err)
+		}
+	}
+	return write(report, planHeader, []planRecord{newPlanRecord(plan, prices)})
+}
+
+func listPlans(ctx context.Context, c *cli.Context, a app.App) error {
+	report := reportOf(c)
+	if err := report.Validate(); err != nil {
+		return err
+	}
+	plans, err := a.PlanService().ListPlans(ctx, c.Bool("all"))
+	if err != nil {
+		return err
+	}
+	records := make([]planRecord, 0, len(plans))
+	for _, plan := range plans {
+		prices, err := a.PlanService().GetPlanPrices(ctx, plan.ID)
+		if err != nil {
+			return err
+		}
+		records = append(records, newPlanRecord(plan, prices))
+	}
+	return write(report, planHeader, records)
+}
+
+func listPrices(ctx context.Context, c *cli.Context, a app.App) error {
+	report := reportOf(c)
+	if err := report.Validate(); err != nil {
+		return err
+	}
+	prices, err := a.PlanService().GetPlanPrices(ctx, c.Uint("plan"))
+	if err != nil {
+		return err
+	}
+	records := make([]priceRecord, 0, len(prices))
+	for _, p := range prices {
+		records = append(records, priceRecord{PlanID: p.PlanID, Months: p.Month, Price: p.Price})
+	}
+	return write(report, priceHeader, records)
+}
+
+// parsePrices parses months=price pairs
+func parsePrices(values []string) ([]*planD.Price, error) {
+	prices := make([]*planD.Price, 0, len(values))
+	for _, v := range values {
+		months, price, ok := strings.Cut(v, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidPrice, v)
+		}
+		m, err := strconv.Atoi(strings.TrimSpace(months))
+		if err != nil || m <= 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidPrice, v)
+		}
+		p, err := strconv.Atoi(strings.TrimSpace(price))
+		if err != nil || p < 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidPrice, v)
+		}
+		prices = append(prices, &planD.Price{Month: m, Price: p})
+	}
+	return prices, nil
+}
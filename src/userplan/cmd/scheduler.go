@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/app"
+	planD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/pkg/database"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/pkg/scheduler"
+)
+
+// jobExpirePlans is also locked by manual runs of the expire-plans command
+const jobExpirePlans = "userplan.expire-plans"
+
+var ErrJobRunning = errors.New("the job is already running")
+
+// newScheduler registers the plan jobs, a replica only runs a job while it holds its advisory lock
+func newScheduler(a app.App) (*scheduler.Scheduler, error) {
+	cfg := a.Config()
+	planService := a.PlanService()
+	s := scheduler.New(database.NewAdvisoryLocker(a.DB()), a.Logger())
+
+	jobs := []scheduler.Job{
+		{
+			Name:    jobExpirePlans,
+			Spec:    cfg.Scheduler.ExpireSpec,
+			Timeout: cfg.Scheduler.JobTimeout,
+			Run: func(ctx context.Context) error {
+				_, err := expirePlans(ctx, a, planD.ExpirationOptions{BatchSize: cfg.Expiration.BatchSize})
+				return err
+			},
+		},
+		{
+			Name:    "userplan.expiration-reminders",
+			Spec:    cfg.Scheduler.NotifySpec,
+			Timeout: cfg.Scheduler.JobTimeout,
+			Run: func(ctx context.Context) error {
+				sent, err := planService.SendExpirationReminders(ctx, cfg.Reminder.Schedule)
+				a.Logger().Info("Expiration reminders sent", zap.Int("count", sent))
+				return err
+			},
+		},
+		//expire-plans applies the due changes as well, sharing its lock keeps the two off the same rows
+		{
+			Name:    "userplan.scheduled-changes",
+			Lock:    jobExpirePlans,
+			Spec:    cfg.Scheduler.ChangesSpec,
+			Timeout: cfg.Scheduler.JobTimeout,
+			Run:     planService.ApplyScheduledChanges,
+		},
+	}
+	for _, job := range jobs {
+		if err := s.Add(job); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// lockJob takes the lock the scheduler runs the job name under, failing with ErrJobRunning
+// while a replica runs it
+func lockJob(ctx context.Context, locker scheduler.Locker, name string) (func(), error) {
+	acquired, unlock, err := locker.TryLock(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, fmt.Errorf("%w: %s", ErrJobRunning, name)
+	}
+	return unlock, nil
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLocker struct {
+	held map[string]bool
+}
+
+func (l *fakeLocker) TryLock(_ context.Context, name string) (bool, func(), error) {
+	if l.held[name] {
+		return false, nil, nil
+	}
+	l.held[name] = true
+	return true, func() { delete(l.held, name) }, nil
+}
+
+func TestLockJob(t *testing.T) {
+	ctx := context.Background()
+	locker := &fakeLocker{held: make(map[string]bool)}
+
+	unlock, err := lockJob(ctx, locker, jobExpirePlans)
+	require.NoError(t, err)
+	_, err = lockJob(ctx, locker, jobExpirePlans)
+	assert.ErrorIs(t, err, ErrJobRunning, "a manual run does not overlap a scheduled one")
+
+	unlock()
+	unlock, err = lockJob(ctx, locker, jobExpirePlans)
+	require.NoError(t, err)
+	unlock()
+}
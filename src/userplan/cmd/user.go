@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/app"
+	userD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/user/domain"
+)
+
+const exportPageSize = 500
+
+var (
+	ErrUnknownInput   = errors.New("input must be json or csv")
+	ErrMissingColumn  = errors.New("missing required column")
+	ErrIncompleteUser = errors.New("user must have an email and a name")
+)
+
+func userCommand(e *env) *cli.Command {
+	return &cli.Command{
+		Name:  "user",
+		Usage: "import and export users",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "import",
+				Usage: "create the users of a json or csv file in the user export format, skipping existing emails",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "file", Required: true, Usage: "file to import, - for stdin"},
+					&cli.StringFlag{Name: "format", Usage: "json or csv, defaults to the file extension"},
+					&cli.BoolFlag{Name: "dry-run", Usage: "validate the file without creating users"},
+				},
+				Action: e.withApp(importUsers),
+			},
+			{
+				Name:  "export",
+				Usage: "export users",
+				Flags: append(reportFlags(),
+					&cli.StringFlag{Name: "email", Usage: "only users whose email contains this"},
+					&cli.StringFlag{Name: "name", Usage: "only users whose name contains this"},
+				),
+				Action: e.withApp(exportUsers),
+			},
+		},
+	}
+}
+
+func exportUsers(ctx context.Context, c *cli.Context, a app.App) error {
+	report := reportOf(c)
+	if err := report.Validate(); err != nil {
+		return err
+	}
+
+	filter := &userD.UserFilter{Email: c.String("email"), Name: c.String("name"), Size: exportPageSize}
+	var records []userRecord
+	for filter.Page = 1; ; filter.Page++ {
+		res, err := a.UserService().ListUsers(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, u := range res.Users {
+			records = append(records, newUserRecord(u))
+		}
+		if len(res.Users) < exportPageSize {
+			break
+		}
+	}
+	a.Logger().Info("users exported", zap.Int("count", len(records)))
+	return write(report, userHeader, records)
+}
+
+func importUsers(ctx context.Context, c *cli.Context, a app.App) error {
+	log := a.Logger()
+	file := c.String("file")
+	format := c.String("format")
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(file), ".")
+	}
+
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	records, err := readUsers(r, format)
+	if err != nil {
+		return err
+	}
+
+	dryRun := c.Bool("dry-run")
+	var created, skipped, failed int
+	for i, rec := range records {
+		fields := []zap.Field{zap.Int("record", i+1), zap.String("email", rec.Email)}
+		if rec.Email == "" || rec.Name == "" {
+			log.Error("Failed to import user", append(fields, zap.Error(ErrIncompleteUser))...)
+			failed++
+			continue
+		}
+		_, err := a.UserService().GetByEmail(ctx, rec.Email)
+		if err == nil {
+			log.Info("User exists, skipped", fields...)
+			skipped++
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if dryRun {
+			created++
+			continue
+		}
+		if err := createUser(ctx, a, rec); err != nil {
+			log.Error("Failed to import user", append(fields, zap.Error(err))...)
+			failed++
+			continue
+		}
+		created++
+	}
+
+	log.Info("users imported", zap.Bool("dry_run", dryRun),
+		zap.Int("created", created), zap.Int("skipped", skipped), zap.Int("failed", failed))
+	if failed > 0 {
+		return fmt.Errorf("%d of %d users failed to import", failed, len(records))
+	}
+	return nil
+}
+
+func createUser(ctx context.Context, a app.App, rec userRecord) error {
+	u := &userD.User{
+		Email:                  rec.Email,
+		Name:                   rec.Name,
+		Phone:                  rec.Phone,
+		CompanyName:            rec.CompanyName,
+		JobTitle:               rec.JobTitle,
+		OauthID:                rec.OauthID,
+		Active:                 rec.Active,
+		SubscribeNews:          rec.SubscribeNews,
+		SubscribeNotifications: rec.SubscribeNotifications,
+	}
+	if err := a.UserService().CreateUser(ctx, u); err != nil {
+		return err
+	}
+	//false subscriptions are replaced by the column defaults on create
+	if !rec.SubscribeNews || !rec.SubscribeNotifications {
+		u.SubscribeNews, u.SubscribeNotifications = rec.SubscribeNews, rec.SubscribeNotifications
+		return a.UserService().UpdateUser(ctx, u)
+	}
+	return nil
+}
+
+// readUsers reads users in the user export format, missing subscriptions default to subscribed
+func readUsers(r io.Reader, format string) ([]userRecord, error) {
+	switch format {
+	case OutputJSON:
+		var raw []json.RawMessage
+		if err := json.NewDecoder(r).Decode(&raw); err != nil {
+			return nil, err
+		}
+		records := make([]userRecord, len(raw))
+		for i, m := range raw {
+			records[i] = userRecord{SubscribeNews: true, SubscribeNotifications: true}
+			if err := json.Unmarshal(m, &records[i]); err != nil {
+				return nil, fmt.Errorf("record %d: %w", i+1, err)
+			}
+		}
+		return records, nil
+	case OutputCSV:
+		return readUsersCSV(r)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownInput, format)
+	}
+}
+
+func readUsersCSV(r io.Reader) ([]userRecord, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"email", "name"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrMissingColumn, name)
+		}
+	}
+
+	var records []userRecord
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		get := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		flag := func(name string, def bool) (bool, error) {
+			v := get(name)
+			if v == "" {
+				return def, nil
+			}
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return false, fmt.Errorf("line %d: %s: %w", line, name, err)
+			}
+			return b, nil
+		}
+
+		rec := userRecord{
+			Email:       get("email"),
+			Name:        get("name"),
+			Phone:       get("phone"),
+			CompanyName: get("company_name"),
+			JobTitle:    get("job_title"),
+			OauthID:     get("oauth_id"),
+		}
+		if rec.Active, err = flag("active", false); err != nil {
+			return nil, err
+		}
+		if rec.SubscribeNews, err = flag("subscribe_news", true); err != nil {
+			return nil, err
+		}
+		if rec.SubscribeNotifications, err = flag("subscribe_notifications", true); err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+}
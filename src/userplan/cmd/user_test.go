@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadUsers(t *testing.T) {
+	csvInput := "Email,name,active,subscribe_news\n" +
+		"a@example.com,Ali,true,\n" +
+		"b@example.com,Bita,,false\n"
+	records, err := readUsers(strings.NewReader(csvInput), OutputCSV)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, userRecord{Email: "a@example.com", Name: "Ali", Active: true, SubscribeNews: true, SubscribeNotifications: true}, records[0])
+	assert.Equal(t, userRecord{Email: "b@example.com", Name: "Bita", SubscribeNotifications: true}, records[1])
+
+	_, err = readUsers(strings.NewReader("email,phone\n"), OutputCSV)
+	assert.ErrorIs(t, err, ErrMissingColumn)
+
+	records, err = readUsers(strings.NewReader(`[{"email":"c@example.com","name":"Cyrus","subscribe_notifications":false}]`), OutputJSON)
+	require.NoError(t, err)
+	assert.Equal(t, []userRecord{{Email: "c@example.com", Name: "Cyrus", SubscribeNews: true}}, records)
+
+	_, err = readUsers(strings.NewReader(""), "xml")
+	assert.ErrorIs(t, err, ErrUnknownInput)
+}
@@ -1,10 +1,18 @@
 package config
 
+import "time"
+
 type Config struct {
 	// DevEnv specifies the environment the application runs in.
-	DevEnv bool       `json:"devEnv" env:"DEV_ENV,required,notEmpty"`
-	DB     DBConfig   `json:"db" envPrefix:"DB_"`
-	GRPC   GRPCConfig `json:"grpc" envPrefix:"GRPC_"`
+	DevEnv       bool               `json:"devEnv" env:"DEV_ENV,required,notEmpty"`
+	DB           DBConfig           `json:"db" envPrefix:"DB_"`
+	GRPC         GRPCConfig         `json:"grpc" envPrefix:"GRPC_"`
+	Payment      PaymentConfig      `json:"payment" envPrefix:"PAYMENT_"`
+	Reminder     ReminderConfig     `json:"reminder" envPrefix:"REMINDER_"`
+	Notification NotificationConfig `json:"notification" envPrefix:"NOTIFICATION_"`
+	Scheduler    SchedulerConfig    `json:"scheduler" envPrefix:"SCHEDULER_"`
+	Expiration   ExpirationConfig   `json:"expiration" envPrefix:"EXPIRATION_"`
+	Metrics      MetricsConfig      `json:"metrics" envPrefix:"METRICS_"`
 }
 
 type DBConfig struct {
@@ -15,6 +23,8 @@ type DBConfig struct {
 	User     string `json:"user" env:"USER,required,notEmpty"`
 	Password string `json:"password" env:"PASSWORD,required,notEmpty"`
 	AppName  string `json:"appName" env:"APP_NAME,required,notEmpty"`
+	// apply pending migrations on startup instead of refusing to start
+	Migrate bool `json:"migrate" env:"MIGRATE" envDefault:"false"`
 }
 
 type GRPCConfig struct {
@@ -23,3 +33,58 @@ type GRPCConfig struct {
 	CertFile string `json:"certFile" env:"CERT_FILE,required"`
 	KeyFile  string `json:"keyFile" env:"KEY_FILE,required"`
 }
+
+type PaymentConfig struct {
+	// Gateway charges auto-renewals: none declines every charge, memory keeps them in memory
+	Gateway string `json:"gateway" env:"GATEWAY" envDefault:"none"`
+}
+
+type ReminderConfig struct {
+	// Schedule lists the days before a plan expires on which its user is reminded
+	Schedule []int `json:"schedule" env:"SCHEDULE" envDefault:"7,3,1"`
+}
+
+// SchedulerConfig holds the cron expressions of the jobs run by the server, an empty expression disables a job
+type SchedulerConfig struct {
+	Enabled     bool          `json:"enabled" env:"ENABLED" envDefault:"true"`
+	ExpireSpec  string        `json:"expireSpec" env:"EXPIRE_SPEC" envDefault:"0 0 * * *"`
+	NotifySpec  string        `json:"notifySpec" env:"NOTIFY_SPEC" envDefault:"0 9 * * *"`
+	ChangesSpec string        `json:"changesSpec" env:"CHANGES_SPEC" envDefault:"*/15 * * * *"`
+	JobTimeout  time.Duration `json:"jobTimeout" env:"JOB_TIMEOUT" envDefault:"10m"`
+}
+
+type ExpirationConfig struct {
+	// BatchSize is the number of plans ended per committed transaction
+	BatchSize int `json:"batchSize" env:"BATCH_SIZE" envDefault:"500"`
+}
+
+type MetricsConfig struct {
+	// Addr serves prometheus metrics on /metrics, empty disables them
+	Addr string `json:"addr" env:"ADDR" envDefault:":9090"`
+}
+
+type NotificationConfig struct {
+	// Channels deliver expiration reminders: log, smtp, webhook or file
+	Channels []string `json:"channels" env:"CHANNELS" envDefault:"log"`
+	// TemplateDir overrides the built-in expiring_subject.tmpl and expiring_body.tmpl templates
+	TemplateDir string        `json:"templateDir" env:"TEMPLATE_DIR"`
+	SMTP        SMTPConfig    `json:"smtp" envPrefix:"SMTP_"`
+	Webhook     WebhookConfig `json:"webhook" envPrefix:"WEBHOOK_"`
+	// FilePath is appended one JSON message per line by the file channel
+	FilePath string `json:"filePath" env:"FILE_PATH" envDefault:"notifications.jsonl"`
+}
+
+type SMTPConfig struct {
+	Host     string `json:"host" env:"HOST"`
+	Port     uint   `json:"port" env:"PORT" envDefault:"587"`
+	Username string `json:"username" env:"USERNAME"`
+	Password string `json:"password" env:"PASSWORD"`
+	From     string `json:"from" env:"FROM"`
+	// Timeout bounds a whole delivery, from dialing to the server's answer to the message
+	Timeout time.Duration `json:"timeout" env:"TIMEOUT" envDefault:"30s"`
+}
+
+type WebhookConfig struct {
+	URL     string        `json:"url" env:"URL"`
+	Timeout time.Duration `json:"timeout" env:"TIMEOUT" envDefault:"10s"`
+}
@@ -17,6 +17,11 @@ func validEnvMap() map[string]string {
 		"DB_USER":     "postgres",
 		"DB_PASSWORD": "postgres",
 		"DB_APP_NAME": "userplan-service",
+
+		"GRPC_PORT":      "50051",
+		"GRPC_TLS":       "false",
+		"GRPC_CERT_FILE": "",
+		"GRPC_KEY_FILE":  "",
 	}
 }
 
@@ -0,0 +1,39 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+type fileChannel struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileChannel returns a channel appending messages to path as JSON lines, meant for tests and local runs
+func NewFileChannel(path string) Channel {
+	return &fileChannel{path: path}
+}
+
+func (c *fileChannel) Name() string { return ChannelFile }
+
+func (c *fileChannel) Send(_ context.Context, msg *Message) error {
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f, err := os.OpenFile(c.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,29 @@
+package notification
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type logChannel struct {
+	log *zap.Logger
+}
+
+// NewLogChannel returns a channel that only logs messages
+func NewLogChannel(log *zap.Logger) Channel {
+	return &logChannel{log: log}
+}
+
+func (c *logChannel) Name() string { return ChannelLog }
+
+func (c *logChannel) Send(_ context.Context, msg *Message) error {
+	c.log.Info("Plan expiration reminder",
+		zap.Uint("user_id", msg.UserID),
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.Int("threshold", msg.Threshold),
+		zap.Time("expires_at", msg.ExpiresAt),
+	)
+	return nil
+}
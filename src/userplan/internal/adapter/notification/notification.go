@@ -0,0 +1,168 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"go.uber.org/zap"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/config"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/domain"
+	planP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/port"
+)
+
+const (
+	ChannelLog     = "log"
+	ChannelSMTP    = "smtp"
+	ChannelWebhook = "webhook"
+	ChannelFile    = "file"
+
+	subjectTemplate = "expiring_subject.tmpl"
+	bodyTemplate    = "expiring_body.tmpl"
+)
+
+var (
+	ErrUnknownChannel = errors.New("unknown notification channel")
+	ErrNoChannel      = errors.New("no notification channel is configured")
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+// Message is a rendered notification addressed to a single user
+type Message struct {
+	UserID    uint      `json:"user_id"`
+	To        string    `json:"to"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body"`
+	Threshold int       `json:"threshold"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Channel delivers messages through a single medium
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Dispatcher renders expiration reminders and delivers them through every channel
+type Dispatcher struct {
+	subject  *template.Template
+	body     *template.Template
+	channels []Channel
+	log      *zap.Logger
+}
+
+// New builds the dispatcher of the configured channels and templates
+func New(cfg config.NotificationConfig, log *zap.Logger) (*Dispatcher, error) {
+	channels := make([]Channel, 0, len(cfg.Channels))
+	for _, name := range cfg.Channels {
+		switch strings.TrimSpace(name) {
+		case ChannelLog:
+			channels = append(channels, NewLogChannel(log))
+		case ChannelSMTP:
+			channels = append(channels, NewSMTPChannel(cfg.SMTP))
+		case ChannelWebhook:
+			channels = append(channels, NewWebhookChannel(cfg.Webhook))
+		case ChannelFile:
+			channels = append(channels, NewFileChannel(cfg.FilePath))
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnknownChannel, name)
+		}
+	}
+
+	subject, body, err := loadTemplates(cfg.TemplateDir)
+	if err != nil {
+		return nil, err
+	}
+	return NewDispatcher(subject, body, log, channels...)
+}
+
+// NewDispatcher returns a dispatcher rendering reminders with the subject and body templates
+func NewDispatcher(subject, body string, log *zap.Logger, channels ...Channel) (*Dispatcher, error) {
+	if len(channels) == 0 {
+		return nil, ErrNoChannel
+	}
+	subjectTmpl, err := template.New(subjectTemplate).Parse(subject)
+	if err != nil {
+		return nil, err
+	}
+	bodyTmpl, err := template.New(bodyTemplate).Parse(body)
+	if err != nil {
+		return nil, err
+	}
+	return &Dispatcher{subject: subjectTmpl, body: bodyTmpl, channels: channels, log: log}, nil
+}
+
+var _ planP.ReminderSender = (*Dispatcher)(nil)
+
+// SendReminder delivers the reminder through every channel. it only fails when no channel
+// delivered it, so a channel that is down does not repeat the reminder on the others
+func (d *Dispatcher) SendReminder(ctx context.Context, reminder *domain.Reminder) error {
+	msg, err := d.render(reminder)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, channel := range d.channels {
+		if err := channel.Send(ctx, msg); err != nil {
+			d.log.Warn("Failed to deliver notification",
+				zap.String("channel", channel.Name()), zap.Uint("user_id", msg.UserID), zap.Error(err))
+			errs = append(errs, fmt.Errorf("%s: %w", channel.Name(), err))
+		}
+	}
+	if len(errs) == len(d.channels) {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+func (d *Dispatcher) render(reminder *domain.Reminder) (*Message, error) {
+	var subject, body bytes.Buffer
+	if err := d.subject.Execute(&subject, reminder); err != nil {
+		return nil, err
+	}
+	if err := d.body.Execute(&body, reminder); err != nil {
+		return nil, err
+	}
+	return &Message{
+		UserID:    reminder.UserID,
+		To:        reminder.Email,
+		Subject:   strings.TrimSpace(subject.String()),
+		Body:      body.String(),
+		Threshold: reminder.Threshold,
+		ExpiresAt: reminder.ExpiresAt,
+	}, nil
+}
+
+// loadTemplates reads the templates from dir, falling back to the built-in ones
+func loadTemplates(dir string) (string, string, error) {
+	read := func(name string) (string, error) {
+		if dir != "" {
+			b, err := os.ReadFile(filepath.Join(dir, name))
+			if err == nil {
+				return string(b), nil
+			}
+			if !errors.Is(err, os.ErrNotExist) {
+				return "", err
+			}
+		}
+		b, err := templates.ReadFile("templates/" + name)
+		return string(b), err
+	}
+
+	subject, err := read(subjectTemplate)
+	if err != nil {
+		return "", "", err
+	}
+	body, err := read(bodyTemplate)
+	return subject, body, err
+}
@@ -0,0 +1,73 @@
+package notification
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/config"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/domain"
+)
+
+type failingChannel struct{}
+
+func (failingChannel) Name() string { return "failing" }
+
+func (failingChannel) Send(context.Context, *Message) error { return errors.New("unavailable") }
+
+func readMessages(t *testing.T, path string) []Message {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var messages []Message
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var msg Message
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &msg))
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+func TestDispatcher_RendersToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.jsonl")
+	d, err := New(config.NotificationConfig{Channels: []string{ChannelFile}, FilePath: path}, zap.NewNop())
+	require.NoError(t, err)
+
+	expiresAt := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, d.SendReminder(context.Background(), &domain.Reminder{
+		UserID: 4, Email: "jane@example.com", Name: "Jane", Plan: "Pro",
+		Status: domain.PlanStatusActive, Threshold: 3, Days: 3, ExpiresAt: expiresAt,
+	}))
+
+	messages := readMessages(t, path)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "jane@example.com", messages[0].To)
+	assert.Equal(t, "Your Pro plan expires in 3 days", messages[0].Subject)
+	assert.Contains(t, messages[0].Body, "Hi Jane,")
+	assert.Contains(t, messages[0].Body, "on 2025-03-10")
+	assert.Equal(t, 3, messages[0].Threshold)
+}
+
+func TestDispatcher_FailsOnlyWhenNoChannelDelivers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.jsonl")
+	reminder := &domain.Reminder{UserID: 4, Plan: "Pro", Days: 1}
+
+	d, err := NewDispatcher("{{.Plan}}", "{{.Days}}", zap.NewNop(), failingChannel{}, NewFileChannel(path))
+	require.NoError(t, err)
+	require.NoError(t, d.SendReminder(context.Background(), reminder))
+	assert.Len(t, readMessages(t, path), 1)
+
+	d, err = NewDispatcher("{{.Plan}}", "{{.Days}}", zap.NewNop(), failingChannel{})
+	require.NoError(t, err)
+	assert.Error(t, d.SendReminder(context.Background(), reminder))
+}
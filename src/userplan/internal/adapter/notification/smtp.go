@@ -0,0 +1,107 @@
+package notification
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/config"
+)
+
+var ErrInvalidHeader = errors.New("line breaks are not allowed in mail headers")
+
+type smtpChannel struct {
+	cfg config.SMTPConfig
+}
+
+// NewSMTPChannel returns a channel emailing messages through the configured SMTP server
+func NewSMTPChannel(cfg config.SMTPConfig) Channel {
+	return &smtpChannel{cfg: cfg}
+}
+
+func (c *smtpChannel) Name() string { return ChannelSMTP }
+
+// Send delivers msg like smtp.SendMail, over a connection bounded by the configured timeout and ctx
+func (c *smtpChannel) Send(ctx context.Context, msg *Message) error {
+	mail, err := c.mail(msg)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(c.cfg.Host, strconv.Itoa(int(c.cfg.Port)))
+	dialer := net.Dialer{Timeout: c.cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	deadline, ok := ctx.Deadline()
+	if c.cfg.Timeout > 0 && (!ok || time.Now().Add(c.cfg.Timeout).Before(deadline)) {
+		deadline, ok = time.Now().Add(c.cfg.Timeout), true
+	}
+	if ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+	//canceling ctx unblocks the reads and writes in progress
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, c.cfg.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: c.cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if c.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", c.cfg.Username, c.cfg.Password, c.cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(c.cfg.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(mail); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// mail renders msg, rejecting header values that would start a header or the body of their own
+func (c *smtpChannel) mail(msg *Message) ([]byte, error) {
+	if strings.ContainsAny(msg.To, "\r\n") || strings.ContainsAny(msg.Subject, "\r\n") {
+		return nil, ErrInvalidHeader
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", c.cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String()), nil
+}
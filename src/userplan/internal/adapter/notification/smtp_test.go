@@ -0,0 +1,52 @@
+package notification
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/config"
+)
+
+func TestSMTPChannel_RejectsHeaderInjection(t *testing.T) {
+	c := NewSMTPChannel(config.SMTPConfig{Host: "127.0.0.1", Port: 1, From: "plans@example.com"})
+	err := c.Send(context.Background(), &Message{To: "jane@example.com", Subject: "Pro\r\nBcc: all@example.com"})
+	assert.ErrorIs(t, err, ErrInvalidHeader)
+}
+
+func TestSMTPChannel_SilentServerTimesOut(t *testing.T) {
+	//accepts connections but never greets
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	host, port, err := net.SplitHostPort(ln.Addr().String())
+	require.NoError(t, err)
+	portNum, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	msg := &Message{To: "jane@example.com", Subject: "Pro"}
+
+	c := NewSMTPChannel(config.SMTPConfig{Host: host, Port: uint(portNum), Timeout: 100 * time.Millisecond})
+	start := time.Now()
+	assert.Error(t, c.Send(context.Background(), msg))
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	c = NewSMTPChannel(config.SMTPConfig{Host: host, Port: uint(portNum), Timeout: time.Minute})
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start = time.Now()
+	assert.Error(t, c.Send(ctx, msg), "canceling the context ends the delivery")
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
@@ -0,0 +1,46 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/config"
+)
+
+type webhookChannel struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookChannel returns a channel posting messages as JSON to the configured URL
+func NewWebhookChannel(cfg config.WebhookConfig) Channel {
+	return &webhookChannel{url: cfg.URL, client: &http.Client{Timeout: cfg.Timeout}}
+}
+
+func (c *webhookChannel) Name() string { return ChannelWebhook }
+
+func (c *webhookChannel) Send(ctx context.Context, msg *Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+	return nil
+}
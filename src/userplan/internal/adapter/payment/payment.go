@@ -0,0 +1,88 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/domain"
+	planP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/port"
+)
+
+const (
+	GatewayNone   = "none"
+	GatewayMemory = "memory"
+)
+
+var (
+	ErrUnknownGateway  = errors.New("unknown payment gateway")
+	ErrNoGateway       = errors.New("no payment gateway is configured")
+	ErrPaymentDeclined = errors.New("payment declined")
+)
+
+// New returns the charger of the named gateway
+func New(gateway string) (planP.PaymentCharger, error) {
+	switch gateway {
+	case "", GatewayNone:
+		return disabledCharger{}, nil
+	case GatewayMemory:
+		return NewMemoryCharger(), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownGateway, gateway)
+	}
+}
+
+// disabledCharger declines every charge, auto-renewing plans fall into their grace period
+type disabledCharger struct{}
+
+func (disabledCharger) Charge(context.Context, *domain.Charge) (*domain.Receipt, error) {
+	return nil, ErrNoGateway
+}
+
+// MemoryCharger keeps charges in memory, for tests and local development
+type MemoryCharger struct {
+	mu       sync.Mutex
+	charges  map[string]domain.Charge
+	declined map[uint]bool
+}
+
+func NewMemoryCharger() *MemoryCharger {
+	return &MemoryCharger{
+		charges:  make(map[string]domain.Charge),
+		declined: make(map[uint]bool),
+	}
+}
+
+// Decline makes every following charge of the user fail
+func (c *MemoryCharger) Decline(userID uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.declined[userID] = true
+}
+
+// Charges returns the successful charges by reference
+func (c *MemoryCharger) Charges() map[string]domain.Charge {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	charges := make(map[string]domain.Charge, len(c.charges))
+	for ref, charge := range c.charges {
+		charges[ref] = charge
+	}
+	return charges
+}
+
+// Charge records the charge once per reference
+func (c *MemoryCharger) Charge(_ context.Context, charge *domain.Charge) (*domain.Receipt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	receipt := &domain.Receipt{TransactionID: "mem-" + charge.Reference}
+	if _, ok := c.charges[charge.Reference]; ok {
+		return receipt, nil
+	}
+	if c.declined[charge.UserID] {
+		return nil, ErrPaymentDeclined
+	}
+	c.charges[charge.Reference] = *charge
+	return receipt, nil
+}
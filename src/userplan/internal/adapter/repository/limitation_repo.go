@@ -43,7 +43,14 @@ func (r *limitationRepository) Update(ctx context.Context, limitation *domain.Li
 }
 
 func (r *limitationRepository) Delete(ctx context.Context, id uint) error {
-	return r.db.WithContext(ctx).Delete(&domain.Limitation{}, id).Error
+	res := r.db.WithContext(ctx).Delete(&domain.Limitation{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
 
 func (r *limitationRepository) AssignToPlan(ctx context.Context, planLimitation *domain.PlanLimitation) error {
@@ -60,13 +67,32 @@ func (r *limitationRepository) GetPlanLimitations(ctx context.Context, planID ui
 }
 
 func (r *limitationRepository) UpdatePlanLimitation(ctx context.Context, planLimitation *domain.PlanLimitation) error {
-	return r.db.WithContext(ctx).
+	// update by column so that zero values are written instead of being skipped
+	res := r.db.WithContext(ctx).Model(&domain.PlanLimitation{}).
 		Where("plan_id = ? AND limitation_id = ?", planLimitation.PlanID, planLimitation.LimitationID).
-		Updates(planLimitation).Error
+		Updates(map[string]interface{}{
+			"value":       planLimitation.Value,
+			"unit_price":  planLimitation.UnitPrice,
+			"trial_value": planLimitation.TrialValue,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
 
 func (r *limitationRepository) RemoveFromPlan(ctx context.Context, planID, limitationID uint) error {
-	return r.db.WithContext(ctx).
+	res := r.db.WithContext(ctx).
 		Where("plan_id = ? AND limitation_id = ?", planID, limitationID).
-		Delete(&domain.PlanLimitation{}).Error
+		Delete(&domain.PlanLimitation{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
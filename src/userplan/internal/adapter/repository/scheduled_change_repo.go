@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/domain"
+	planP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/port"
+)
+
+type scheduledChangeRepository struct {
+	db *gorm.DB
+}
+
+func NewScheduledChangeRepository(db *gorm.DB) planP.ScheduledChangeRepository {
+	return &scheduledChangeRepository{db: db}
+}
+
+func (r *scheduledChangeRepository) Create(ctx context.Context, change *domain.ScheduledChange) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(change).Error
+}
+
+func (r *scheduledChangeRepository) GetByID(ctx context.Context, id uint) (*domain.ScheduledChange, error) {
+	var change domain.ScheduledChange
+	err := r.db.WithContext(ctx).Preload("UserPlan").First(&change, id).Error
+	return &change, err
+}
+
+func (r *scheduledChangeRepository) ListPending(ctx context.Context, userPlanID uint) ([]*domain.ScheduledChange, error) {
+	var changes []*domain.ScheduledChange
+	err := r.db.WithContext(ctx).
+		Where("user_plan_id = ? AND status = ?", userPlanID, domain.ScheduledChangePending).
+		Order("effective_at").
+		Find(&changes).Error
+	return changes, err
+}
+
+func (r *scheduledChangeRepository) GetDue(ctx context.Context) ([]*domain.ScheduledChange, error) {
+	var changes []*domain.ScheduledChange
+	err := r.db.WithContext(ctx).
+		Preload("UserPlan").
+		Where("status = ? AND effective_at <= ?", domain.ScheduledChangePending, time.Now()).
+		Order("effective_at").
+		Find(&changes).Error
+	return changes, err
+}
+
+func (r *scheduledChangeRepository) Revoke(ctx context.Context, id uint) error {
+	return r.setStatus(r.db.WithContext(ctx), id, map[string]interface{}{"status": domain.ScheduledChangeRevoked})
+}
+
+func (r *scheduledChangeRepository) Apply(ctx context.Context, change *domain.ScheduledChange, userPlan *domain.UserPlan, status string, history *domain.PlanHistory) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		//marked first so ending the plan does not revoke the change being applied
+		now := time.Now()
+		if err := r.setStatus(tx, change.ID, map[string]interface{}{
+			"status":     domain.ScheduledChangeApplied,
+			"applied_at": now,
+		}); err != nil {
+			return err
+		}
+		change.Status = domain.ScheduledChangeApplied
+		change.AppliedAt = &now
+
+		return transition(tx, userPlan, status, history)
+	})
+}
+
+func (r *scheduledChangeRepository) Fail(ctx context.Context, id uint, reason string) error {
+	return r.setStatus(r.db.WithContext(ctx), id, map[string]interface{}{
+		"status":         domain.ScheduledChangeFailed,
+		"failure_reason": reason,
+	})
+}
+
+// setStatus updates a change that is still pending
+func (r *scheduledChangeRepository) setStatus(db *gorm.DB, id uint, updates map[string]interface{}) error {
+	res := db.Model(&domain.ScheduledChange{}).
+		Where("id = ? AND status = ?", id, domain.ScheduledChangePending).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/usage/domain"
+	usageP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/usage/port"
+)
+
+type usageRepository struct {
+	db *gorm.DB
+}
+
+func NewUsageRepository(db *gorm.DB) usageP.Repo {
+	return &usageRepository{db: db}
+}
+
+func (r *usageRepository) Get(ctx context.Context, userID uint, limitation string, periodStart time.Time) (*domain.Usage, error) {
+	var usage domain.Usage
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND limitation = ? AND period_start = ?", userID, limitation, periodStart).
+		First(&usage).Error
+	return &usage, err
+}
+
+func (r *usageRepository) ListByPeriod(ctx context.Context, userID uint, periodStart time.Time) ([]*domain.Usage, error) {
+	var usages []*domain.Usage
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND period_start = ?", userID, periodStart).
+		Find(&usages).Error
+	return usages, err
+}
+
+// the increment and the limit check happen in one statement, so concurrent
+// consumers of the same counter are serialized by the row lock of the upsert
+const (
+	addUsageSQL = `
+INSERT INTO usages (user_id, limitation, period_start, period_end, used, updated_at)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (user_id, limitation, period_start)
+DO UPDATE SET used = usages.used + EXCLUDED.used, updated_at = EXCLUDED.updated_at`
+
+	consumeUsageSQL = addUsageSQL + `
+WHERE usages.used + EXCLUDED.used <= ?
+RETURNING used`
+)
+
+func (r *usageRepository) Consume(ctx context.Context, usage *domain.Usage, amount, limit int64) (bool, error) {
+	if amount > limit {
+		return false, nil
+	}
+
+	var used []int64
+	err := r.db.WithContext(ctx).
+		Raw(consumeUsageSQL,
+			usage.UserID, usage.Limitation, usage.PeriodStart, usage.PeriodEnd, amount, time.Now(), limit).
+		Scan(&used).Error
+	if err != nil {
+		return false, err
+	}
+	if len(used) == 0 {
+		return false, nil
+	}
+
+	usage.Used = used[0]
+	return true, nil
+}
+
+func (r *usageRepository) Add(ctx context.Context, usage *domain.Usage, amount int64) error {
+	var used int64
+	err := r.db.WithContext(ctx).
+		Raw(addUsageSQL+"\nRETURNING used",
+			usage.UserID, usage.Limitation, usage.PeriodStart, usage.PeriodEnd, amount, time.Now()).
+		Scan(&used).Error
+	if err != nil {
+		return err
+	}
+
+	usage.Used = used
+	return nil
+}
@@ -2,9 +2,12 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/common"
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/domain"
 	planP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/port"
@@ -18,164 +21,345 @@ func NewUserPlanRepository(db *gorm.DB) planP.UserPlanRepository {
 	return &userPlanRepository{db: db}
 }
 
-func (r *userPlanRepository) AssignPlan(ctx context.Context, userPlan *domain.UserPlan) error {
+func (r *userPlanRepository) AssignPlan(ctx context.Context, userPlan *domain.UserPlan, change domain.Change) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err := tx.Model(&domain.UserPlan{}).
-			Where("user_id = ? AND status = ?", userPlan.UserID, domain.PlanStatusActive).
-			Updates(map[string]interface{}{
-				"status":     domain.PlanStatusCanceled,
-				"expires_at": time.Now(),
-			}).Error; err != nil {
-			return err
-		}
-
-		if err := tx.Create(userPlan).Error; err != nil {
-			return err
-		}
-
-		// record history
-		history := &domain.PlanHistory{
-			UserPlanID: userPlan.ID,
-			Action:     domain.PlanActionAssign,
-			NewPlanID:  &userPlan.PlanID,
-			ChangedAt:  time.Now(),
-		}
-		return tx.Create(history).Error
+		return assign(tx, userPlan, domain.PlanActionAssign, change)
 	})
 }
 
-func (r *userPlanRepository) GetByUserID(ctx context.Context, userID uint) (*domain.UserPlan, error) {
-	var userPlan domain.UserPlan
-	err := r.db.WithContext(ctx).
-		Where("user_id = ? AND status = ?", userID, domain.PlanStatusActive).
-		First(&userPlan).Error
-	return &userPlan, err
-}
-
-func (r *userPlanRepository) RenewPlan(ctx context.Context, userID uint, newEndDate time.Time) error {
+// StartTrial records the trial and assigns its plan, failing with ErrTrialUsed
+// when a trial was already started with the same email or oauth ID
+func (r *userPlanRepository) StartTrial(ctx context.Context, trial *domain.Trial, userPlan *domain.UserPlan, change domain.Change) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		var userPlan domain.UserPlan
-		if err := tx.Where("user_id = ? AND status = ?", userID, domain.PlanStatusActive).
-			First(&userPlan).Error; err != nil {
+		query := tx.Model(&domain.Trial{}).Where("user_id = ?", trial.UserID)
+		if trial.Email != "" {
+			query = query.Or("email = ?", trial.Email)
+		}
+		if trial.OauthID != "" {
+			query = query.Or("oauth_id = ?", trial.OauthID)
+		}
+		var used int64
+		if err := query.Count(&used).Error; err != nil {
 			return err
 		}
-
-		//record history before update
-		history := &domain.PlanHistory{
-			UserPlanID: userPlan.ID,
-			Action:     domain.PlanActionRenew,
-			OldPlanID:  &userPlan.PlanID,
-			ChangedAt:  time.Now(),
+		if used > 0 {
+			return domain.ErrTrialUsed
 		}
 
-		//update the plan with new expiration date
-		userPlan.ExTime = domain.CalculateExpirationDate(newEndDate, 0) // set to 00:00 of the specified date
-
-		if err := tx.Save(&userPlan).Error; err != nil {
+		if err := tx.Create(trial).Error; err != nil {
 			return err
 		}
-
-		return tx.Create(history).Error
+		return assign(tx, userPlan, domain.PlanActionTrial, change)
 	})
 }
 
-func (r *userPlanRepository) CancelPlan(ctx context.Context, userID uint) error {
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		var userPlan domain.UserPlan
-		if err := tx.Where("user_id = ? AND status = ?", userID, domain.PlanStatusActive).
-			First(&userPlan).Error; err != nil {
+// assign replaces the live plans of the user with userPlan inside tx
+func assign(tx *gorm.DB, userPlan *domain.UserPlan, action string, change domain.Change) error {
+	var current []domain.UserPlan
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND status IN ?", userPlan.UserID, domain.LivePlanStatuses).
+		Find(&current).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	history := &domain.PlanHistory{
+		Action:    action,
+		ToStatus:  userPlan.Status,
+		NewPlanID: &userPlan.PlanID,
+		ChangedBy: change.By,
+		Reason:    change.Reason,
+		ChangedAt: now,
+	}
+
+	//the previous plan is canceled in favour of the new one
+	for i := range current {
+		old := &current[i]
+		history.OldPlanID = &old.PlanID
+		if err := transition(tx, old, domain.PlanStatusCanceled, &domain.PlanHistory{
+			Action:    domain.PlanActionCancel,
+			OldPlanID: &old.PlanID,
+			NewPlanID: &userPlan.PlanID,
+			ChangedBy: change.By,
+			Reason:    fmt.Sprintf("replaced by plan %d", userPlan.PlanID),
+			ChangedAt: now,
+		}); err != nil {
 			return err
 		}
+	}
 
-		history := &domain.PlanHistory{
-			UserPlanID: userPlan.ID,
-			Action:     domain.PlanActionCancel,
-			OldPlanID:  &userPlan.PlanID,
-			ChangedAt:  time.Now(),
-		}
+	if err := tx.Create(userPlan).Error; err != nil {
+		return err
+	}
 
-		userPlan.ExTime = time.Now()
+	history.UserPlanID = userPlan.ID
+	return tx.Create(history).Error
+}
+
+func (r *userPlanRepository) Transition(ctx context.Context, userPlan *domain.UserPlan, status string, history *domain.PlanHistory) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return transition(tx, userPlan, status, history)
+	})
+}
 
-		if err := tx.Save(&userPlan).Error; err != nil {
+// transition moves userPlan to status and records history inside tx.
+// the update only applies while the stored status still matches userPlan.Status
+func transition(tx *gorm.DB, userPlan *domain.UserPlan, status string, history *domain.PlanHistory) error {
+	if !domain.CanTransition(userPlan.Status, status) {
+		return domain.ErrInvalidTransition
+	}
+
+	res := tx.Model(&domain.UserPlan{}).
+		Where("id = ? AND status = ?", userPlan.ID, userPlan.Status).
+		Updates(map[string]interface{}{
+			"status":          status,
+			"plan_id":         userPlan.PlanID,
+			"ex_time":         userPlan.ExTime,
+			"months":          userPlan.Months,
+			"price":           userPlan.Price,
+			"last_renewal_at": userPlan.LastRenewalAt,
+			"trial":           userPlan.Trial,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrInvalidTransition
+	}
+
+	//changes scheduled for a plan that has ended will never apply
+	if status == domain.PlanStatusExpired || status == domain.PlanStatusCanceled {
+		if err := tx.Model(&domain.ScheduledChange{}).
+			Where("user_plan_id = ? AND status = ?", userPlan.ID, domain.ScheduledChangePending).
+			Update("status", domain.ScheduledChangeRevoked).Error; err != nil {
 			return err
 		}
+	}
 
-		return tx.Create(history).Error
-	})
+	history.UserPlanID = userPlan.ID
+	history.FromStatus = userPlan.Status
+	history.ToStatus = status
+	userPlan.Status = status
+	return tx.Create(history).Error
 }
 
-func (r *userPlanRepository) GetHistory(ctx context.Context, userID uint) ([]*domain.PlanHistory, error) {
-	var history []*domain.PlanHistory
+func (r *userPlanRepository) GetActiveByUserID(ctx context.Context, userID uint) (*domain.UserPlan, error) {
+	var userPlan domain.UserPlan
+	err := r.db.WithContext(ctx).
+		Preload("Plan").
+		Where("user_id = ? AND status IN ?", userID, domain.EntitledPlanStatuses).
+		First(&userPlan).Error
+	return &userPlan, err
+}
+
+func (r *userPlanRepository) GetCurrentByUserID(ctx context.Context, userID uint) (*domain.UserPlan, error) {
+	var userPlan domain.UserPlan
 	err := r.db.WithContext(ctx).
+		Preload("Plan").
+		Where("user_id = ? AND status IN ?", userID, domain.LivePlanStatuses).
+		First(&userPlan).Error
+	return &userPlan, err
+}
+
+func (r *userPlanRepository) GetHistory(ctx context.Context, filter *domain.HistoryFilter) ([]*domain.PlanHistory, error) {
+	var history []*domain.PlanHistory
+	query := r.db.WithContext(ctx).
 		Joins("JOIN user_plans ON user_plans.id = plan_histories.user_plan_id").
-		Where("user_plans.user_id = ?", userID).
-		Order("plan_histories.changed_at DESC").
-		Find(&history).Error
+		Where("user_plans.user_id = ?", filter.UserID)
+	if filter.Action != "" {
+		query = query.Where("plan_histories.action = ?", filter.Action)
+	}
+	if filter.MetadataKey != "" {
+		query = query.Where(common.JSONHasKey("plan_histories.metadata", filter.MetadataKey))
+	}
+	if len(filter.Metadata) > 0 {
+		query = query.Where(common.JSONContains("plan_histories.metadata", filter.Metadata))
+	}
+	err := query.Order("plan_histories.changed_at DESC").Find(&history).Error
 	return history, err
 }
 
-func (r *userPlanRepository) RecordHistory(ctx context.Context, history *domain.PlanHistory) error {
-	return r.db.WithContext(ctx).Create(history).Error
+func (r *userPlanRepository) SetAutoRenew(ctx context.Context, userPlanID uint, enabled bool) error {
+	return r.db.WithContext(ctx).Model(&domain.UserPlan{}).
+		Where("id = ?", userPlanID).
+		Update("auto_renew", enabled).Error
 }
 
-func (r *userPlanRepository) ExpirePlans(ctx context.Context) error {
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		var expiredPlans []domain.UserPlan
-		if err := tx.Where("status = ? AND expires_at <= ?",
-			domain.PlanStatusActive, time.Now()).Find(&expiredPlans).Error; err != nil {
+func (r *userPlanRepository) GetDueRenewals(ctx context.Context) ([]*domain.UserPlan, error) {
+	var plans []*domain.UserPlan
+	err := r.db.WithContext(ctx).
+		Where("auto_renew AND NOT trial AND status IN ? AND ex_time > ? AND ex_time <= ?",
+			domain.EntitledPlanStatuses, time.Time{}, time.Now()).
+		Find(&plans).Error
+	return plans, err
+}
+
+// GetDueTrials returns the trials whose period has ended
+func (r *userPlanRepository) GetDueTrials(ctx context.Context) ([]*domain.UserPlan, error) {
+	var plans []*domain.UserPlan
+	err := r.db.WithContext(ctx).
+		Preload("Plan").
+		Where("trial AND status = ? AND ex_time <= ?", domain.PlanStatusActive, time.Now()).
+		Find(&plans).Error
+	return plans, err
+}
+
+// ExpireBatch ends up to size plans whose term or grace period is over in one transaction.
+// rows locked by a concurrent run are skipped, so every row picked changes state and a
+// run that stopped half way resumes with the remaining rows. a plan failing its update is
+// rolled back alone and reported, exclude keeps it out of the following batches
+func (r *userPlanRepository) ExpireBatch(ctx context.Context, size int, exclude []uint) (*domain.ExpirationResult, error) {
+	result := &domain.ExpirationResult{}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		var ids []uint
+		query := endingPlans(tx, now).
+			Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "user_plans"}, Options: "SKIP LOCKED"}).
+			Limit(size)
+		if len(exclude) > 0 {
+			query = query.Where("user_plans.id NOT IN ?", exclude)
+		}
+		if err := query.Pluck("user_plans.id", &ids).Error; err != nil {
 			return err
 		}
+		if len(ids) == 0 {
+			return nil
+		}
 
-		for _, plan := range expiredPlans {
-			if err := tx.Model(&plan).Update("status", domain.PlanStatusExpired).Error; err != nil {
-				return err
-			}
+		var endedPlans []domain.UserPlan
+		if err := tx.Preload("Plan").Where("id IN ?", ids).Order("id").Find(&endedPlans).Error; err != nil {
+			return err
+		}
 
-			history := &domain.PlanHistory{
-				UserPlanID: plan.ID,
-				Action:     "expired",
-				OldPlanID:  &plan.PlanID,
-				ChangedAt:  time.Now(),
-				Metadata: common.JSON{
-					"expired_at": time.Now(),
-				},
-			}
-			if err := tx.Create(history).Error; err != nil {
+		for i := range endedPlans {
+			plan := &endedPlans[i]
+			before := *plan
+			var history *domain.PlanHistory
+			//savepoint per plan so a failing plan does not roll back the batch
+			err := tx.Transaction(func(tx *gorm.DB) (err error) {
+				history, err = endPlan(tx, plan, now)
 				return err
+			})
+			switch {
+			case err == nil:
+				result.Changes = append(result.Changes, domain.NewStateChange(&before, plan.Status, history))
+				if plan.Status == domain.PlanStatusPastDue {
+					result.PastDue++
+				} else {
+					result.Expired++
+				}
+			case errors.Is(err, domain.ErrInvalidTransition):
+				result.Skipped++
+				result.SkippedIDs = append(result.SkippedIDs, plan.ID)
+			default:
+				result.Failed++
+				result.FailedIDs = append(result.FailedIDs, plan.ID)
 			}
 		}
-
 		return nil
 	})
+	return result, err
 }
 
+// ListEnding returns up to size plans after afterID that an expiration run would end, without locking them
+func (r *userPlanRepository) ListEnding(ctx context.Context, afterID uint, size int, exclude []uint) ([]*domain.UserPlan, error) {
+	var ids []uint
+	query := endingPlans(r.db.WithContext(ctx), time.Now()).
+		Where("user_plans.id > ?", afterID).
+		Limit(size)
+	if len(exclude) > 0 {
+		query = query.Where("user_plans.id NOT IN ?", exclude)
+	}
+	if err := query.Pluck("user_plans.id", &ids).Error; err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	var plans []*domain.UserPlan
+	err := r.db.WithContext(ctx).Preload("Plan").Where("id IN ?", ids).Order("id").Find(&plans).Error
+	return plans, err
+}
+
+// endingPlans selects the plans whose term ended at now, ordered by ID. suspended and past due
+// plans are left until their grace period ends so every selected plan changes state
+func endingPlans(db *gorm.DB, now time.Time) *gorm.DB {
+	graceEnd := "user_plans.ex_time + make_interval(days => plans.grace_period_days)"
+	return db.Model(&domain.UserPlan{}).
+		Joins("JOIN plans ON plans.id = user_plans.plan_id").
+		Where("NOT user_plans.trial AND user_plans.status IN ? AND user_plans.ex_time > ? AND user_plans.ex_time <= ?",
+			[]string{domain.PlanStatusActive, domain.PlanStatusSuspended, domain.PlanStatusPastDue}, time.Time{}, now).
+		Where("user_plans.status = ? OR "+graceEnd+" <= ?", domain.PlanStatusActive, now).
+		Order("user_plans.id")
+}
+
+// endPlan moves plan to the status it ends in and returns the recorded history
+func endPlan(tx *gorm.DB, plan *domain.UserPlan, now time.Time) (*domain.PlanHistory, error) {
+	graceEnd := plan.GraceEnd()
+
+	if plan.EndStatus(now) == domain.PlanStatusPastDue {
+		history := &domain.PlanHistory{
+			Action:    domain.PlanActionPastDue,
+			OldPlanID: &plan.PlanID,
+			ChangedBy: domain.ChangedBySystem,
+			Reason:    "term ended",
+			ChangedAt: now,
+			Metadata:  common.JSON{"grace_ends_at": graceEnd},
+		}
+		return history, transition(tx, plan, domain.PlanStatusPastDue, history)
+	}
+
+	var remindersSent int64
+	if err := tx.Model(&domain.PlanReminder{}).
+		Where("user_plan_id = ? AND sent_at IS NOT NULL", plan.ID).
+		Count(&remindersSent).Error; err != nil {
+		return nil, err
+	}
+
+	reason := "term ended"
+	if plan.Status == domain.PlanStatusPastDue {
+		reason = "grace period ended"
+	}
+	history := &domain.PlanHistory{
+		Action:    domain.PlanActionExpire,
+		OldPlanID: &plan.PlanID,
+		ChangedBy: domain.ChangedBySystem,
+		Reason:    reason,
+		ChangedAt: now,
+		Metadata: common.JSON{
+			"expired_at":     graceEnd,
+			"reminders_sent": remindersSent,
+		},
+	}
+	return history, transition(tx, plan, domain.PlanStatusExpired, history)
+}
+
+// GetExpiringPlans returns plans whose grace period ends within daysThreshold days.
+// active auto-renewing plans are left out as they renew instead
 func (r *userPlanRepository) GetExpiringPlans(ctx context.Context, daysThreshold int) ([]*domain.UserPlan, error) {
 	var plans []*domain.UserPlan
-	thresholdDate := time.Now().AddDate(0, 0, daysThreshold)
+	now := time.Now()
+	thresholdDate := now.AddDate(0, 0, daysThreshold)
+	graceEnd := "user_plans.ex_time + make_interval(days => plans.grace_period_days)"
 
 	err := r.db.WithContext(ctx).
-		Where("status = ? AND expires_at <= ? AND expires_at > ?",
-			domain.PlanStatusActive, thresholdDate, time.Now()).
+		Preload("Plan").
+		Joins("JOIN plans ON plans.id = user_plans.plan_id").
+		Where("user_plans.status IN ? AND user_plans.ex_time > ?", domain.EntitledPlanStatuses, time.Time{}).
+		Where("NOT (user_plans.auto_renew AND user_plans.status = ?)", domain.PlanStatusActive).
+		Where(graceEnd+" > ? AND "+graceEnd+" <= ?", now, thresholdDate).
 		Find(&plans).Error
 
 	return plans, err
 }
 
-func (r *userPlanRepository) Create(ctx context.Context, userPlan *domain.UserPlan) error {
-	return r.db.WithContext(ctx).Create(userPlan).Error
+func (r *userPlanRepository) RecordReminder(ctx context.Context, reminder *domain.PlanReminder) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(reminder)
+	return res.RowsAffected > 0, res.Error
 }
 
-func (r *userPlanRepository) GetActiveByUserID(ctx context.Context, userID uint) (*domain.UserPlan, error) {
-	var userPlan domain.UserPlan
-	err := r.db.WithContext(ctx).
-		Preload("Plan").
-		Where("user_id = ? AND deleted_at IS NULL", userID).
-		First(&userPlan).Error
-	return &userPlan, err
-}
-
-func (r *userPlanRepository) Update(ctx context.Context, userPlan *domain.UserPlan) error {
-	return r.db.WithContext(ctx).Save(userPlan).Error
+func (r *userPlanRepository) DeleteReminder(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&domain.PlanReminder{}, id).Error
 }
 
 func (r *userPlanRepository) GetUserHistory(ctx context.Context, userID uint) ([]*domain.UserPlan, error) {
@@ -187,7 +371,3 @@ func (r *userPlanRepository) GetUserHistory(ctx context.Context, userID uint) ([
 		Find(&userPlans).Error
 	return userPlans, err
 }
-
-func (r *userPlanRepository) SoftDelete(ctx context.Context, id uint) error {
-	return r.db.WithContext(ctx).Delete(&domain.UserPlan{}, id).Error
-}
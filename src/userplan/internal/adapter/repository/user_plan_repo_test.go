@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/domain"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/migrations"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/pkg/migrate"
+)
+
+// testDB connects to TEST_POSTGRES_DSN, a local postgres by default, in a migrated schema of its own
+// that is dropped after the test, skipping the test when postgres is unavailable
+func testDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		dsn = "host=localhost port=5432 user=postgres password=postgres dbname=postgres sslmode=disable connect_timeout=2"
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Skipf("postgres unavailable: %v", err)
+	}
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	//the search path is set per connection
+	sqlDB.SetMaxOpenConns(1)
+
+	schema := fmt.Sprintf("repository_test_%d", time.Now().UnixNano())
+	_, err = sqlDB.Exec(`CREATE SCHEMA ` + schema + `; SET search_path TO ` + schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB.Exec(`DROP SCHEMA ` + schema + ` CASCADE`)
+		sqlDB.Close()
+	})
+
+	all, err := migrate.Load(migrations.FS)
+	require.NoError(t, err)
+	_, err = migrate.New(sqlDB, all, zap.NewNop()).Up(context.Background())
+	require.NoError(t, err)
+	return db
+}
+
+func TestTransition_Postgres(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	require.NoError(t, db.Exec(`INSERT INTO users (id, email, name) VALUES (7, 'a@example.com', 'A')`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO plans (id, title) VALUES (2, 'Pro')`).Error)
+	repo := NewUserPlanRepository(db)
+
+	userPlan := &domain.UserPlan{UserID: 7, PlanID: 2, Status: domain.PlanStatusPending, Months: 1}
+	require.NoError(t, repo.AssignPlan(ctx, userPlan, domain.Change{By: "admin"}))
+	stale := *userPlan
+
+	history := func() []*domain.PlanHistory {
+		entries, err := repo.GetHistory(ctx, &domain.HistoryFilter{UserID: 7})
+		require.NoError(t, err)
+		return entries
+	}
+	require.Len(t, history(), 1)
+
+	err := repo.Transition(ctx, userPlan, domain.PlanStatusSuspended, &domain.PlanHistory{Action: domain.PlanActionSuspend, ChangedAt: time.Now()})
+	assert.ErrorIs(t, err, domain.ErrInvalidTransition, "pending plans can not be suspended")
+	assert.Len(t, history(), 1)
+
+	userPlan.ExTime = domain.ExpirationForTerm(time.Now(), 1)
+	require.NoError(t, repo.Transition(ctx, userPlan, domain.PlanStatusActive,
+		&domain.PlanHistory{Action: domain.PlanActionActivate, ChangedBy: "admin", Reason: "paid", ChangedAt: time.Now()}))
+	current, err := repo.GetCurrentByUserID(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PlanStatusActive, current.Status)
+	assert.False(t, current.ExTime.IsZero())
+	entries := history()
+	require.Len(t, entries, 2)
+	assert.Equal(t, domain.PlanActionActivate, entries[0].Action)
+	assert.Equal(t, domain.PlanStatusPending, entries[0].FromStatus)
+	assert.Equal(t, domain.PlanStatusActive, entries[0].ToStatus)
+	assert.Equal(t, "paid", entries[0].Reason)
+
+	//a copy read before the activation still says pending, its cancel must not apply
+	err = repo.Transition(ctx, &stale, domain.PlanStatusCanceled, &domain.PlanHistory{Action: domain.PlanActionCancel, ChangedAt: time.Now()})
+	assert.ErrorIs(t, err, domain.ErrInvalidTransition)
+	current, err = repo.GetCurrentByUserID(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PlanStatusActive, current.Status)
+	assert.Len(t, history(), 2)
+
+	//ending a plan revokes the changes scheduled for it
+	changes := NewScheduledChangeRepository(db)
+	scheduled := &domain.ScheduledChange{UserPlanID: userPlan.ID, Action: domain.ScheduledActionCancel,
+		EffectiveAt: time.Now().Add(time.Hour), Status: domain.ScheduledChangePending}
+	require.NoError(t, changes.Create(ctx, scheduled))
+	require.NoError(t, repo.Transition(ctx, userPlan, domain.PlanStatusCanceled, &domain.PlanHistory{Action: domain.PlanActionCancel, ChangedAt: time.Now()}))
+	pending, err := changes.ListPending(ctx, userPlan.ID)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+	assert.Len(t, history(), 3)
+}
+
+func TestExpireBatch_RevokesScheduledChanges_Postgres(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	require.NoError(t, db.Exec(`INSERT INTO users (id, email, name) VALUES (7, 'a@example.com', 'A')`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO plans (id, title) VALUES (2, 'Pro')`).Error)
+	repo := NewUserPlanRepository(db)
+
+	userPlan := &domain.UserPlan{UserID: 7, PlanID: 2, Status: domain.PlanStatusActive, Months: 1,
+		ExTime: time.Now().Add(-time.Hour)}
+	require.NoError(t, repo.AssignPlan(ctx, userPlan, domain.Change{By: "admin"}))
+	changes := NewScheduledChangeRepository(db)
+	require.NoError(t, changes.Create(ctx, &domain.ScheduledChange{UserPlanID: userPlan.ID, Action: domain.ScheduledActionCancel,
+		EffectiveAt: time.Now().Add(time.Hour), Status: domain.ScheduledChangePending}))
+
+	result, err := repo.ExpireBatch(ctx, 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Expired)
+	pending, err := changes.ListPending(ctx, userPlan.ID)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
@@ -76,7 +76,7 @@ func (r *userRepository) List(ctx context.Context, filter *domain.UserFilter, li
 	}
 
 	// Get paginated records
-	if err := query.Limit(limit).Offset(offset).Find(&users).Error; err != nil {
+	if err := query.Order("id").Limit(limit).Offset(offset).Find(&users).Error; err != nil {
 		return nil, err
 	}
 
@@ -54,6 +54,8 @@ func (s *server) Start() error {
 	}()
 	pb.RegisterUserServiceServer(s.server, newUserServer(s.app.UserService()))
 	pb.RegisterPlanServiceServer(s.server, newPlanServer(s.app.PlanService()))
+	pb.RegisterLimitationServiceServer(s.server, newLimitationServer(s.app.PlanService()))
+	pb.RegisterUsageServiceServer(s.server, newUsageServer(s.app.UsageService()))
 	return s.server.Serve(lis)
 }
 
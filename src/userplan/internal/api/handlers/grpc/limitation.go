@@ -0,0 +1,128 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/api/pb"
+	planD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/domain"
+	planP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/port"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/pkg/util"
+)
+
+type limitationServiceServer struct {
+	pb.UnimplementedLimitationServiceServer
+	service planP.Service
+}
+
+func newLimitationServer(s planP.Service) pb.LimitationServiceServer {
+	return &limitationServiceServer{service: s}
+}
+
+func (s *limitationServiceServer) ListLimitations(ctx context.Context, _ *pb.Empty) (*pb.ListLimitationsResponse, error) {
+	limitations, err := s.service.ListLimitations(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.ListLimitationsResponse{
+		Limitations: util.Map(limitations, LimitationDomain2Proto),
+	}, nil
+}
+
+func (s *limitationServiceServer) CreateLimitation(ctx context.Context, req *pb.CreateLimitationRequest) (*pb.Limitation, error) {
+	if req.Limitation.GetTitle() == "" {
+		return nil, status.Error(codes.InvalidArgument, "limitation title is required")
+	}
+	limitation := &planD.Limitation{Title: req.Limitation.GetTitle()}
+	if err := s.service.CreateLimitation(ctx, limitation); err != nil {
+		return nil, statusError(err)
+	}
+	return LimitationDomain2Proto(limitation), nil
+}
+
+func (s *limitationServiceServer) UpdateLimitation(ctx context.Context, req *pb.UpdateLimitationRequest) (*pb.Limitation, error) {
+	if req.Limitation.GetTitle() == "" {
+		return nil, status.Error(codes.InvalidArgument, "limitation title is required")
+	}
+	limitation, err := s.service.GetLimitationByID(ctx, uint(req.Limitation.GetId()))
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	limitation.Title = req.Limitation.GetTitle()
+	if err := s.service.UpdateLimitation(ctx, limitation); err != nil {
+		return nil, statusError(err)
+	}
+	return LimitationDomain2Proto(limitation), nil
+}
+
+func (s *limitationServiceServer) DeleteLimitation(ctx context.Context, req *pb.LimitationIDRequest) (*pb.Empty, error) {
+	return &pb.Empty{}, statusError(s.service.DeleteLimitation(ctx, uint(req.Id)))
+}
+
+func (s *limitationServiceServer) ListPlanLimitations(ctx context.Context, req *pb.PlanIDRequest) (*pb.ListPlanLimitationsResponse, error) {
+	planLimitations, err := s.service.GetPlanLimitations(ctx, uint(req.Id))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.ListPlanLimitationsResponse{
+		Limitations: util.Map(planLimitations, PlanLimitationDomain2Proto),
+	}, nil
+}
+
+func (s *limitationServiceServer) AssignLimitationToPlan(ctx context.Context, req *pb.PlanLimitationRequest) (*pb.PlanLimitation, error) {
+	if err := validatePlanLimitation(req); err != nil {
+		return nil, err
+	}
+	err := s.service.AssignLimitationToPlan(ctx, PlanLimitationProto2Domain(req))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return s.planLimitation(ctx, req)
+}
+
+func (s *limitationServiceServer) UpdatePlanLimitation(ctx context.Context, req *pb.PlanLimitationRequest) (*pb.PlanLimitation, error) {
+	if err := validatePlanLimitation(req); err != nil {
+		return nil, err
+	}
+	err := s.service.UpdatePlanLimitation(ctx, PlanLimitationProto2Domain(req))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return s.planLimitation(ctx, req)
+}
+
+func (s *limitationServiceServer) RemoveLimitationFromPlan(ctx context.Context, req *pb.PlanLimitationIDRequest) (*pb.Empty, error) {
+	return &pb.Empty{}, statusError(s.service.RemoveLimitationFromPlan(ctx, uint(req.PlanId), uint(req.LimitationId)))
+}
+
+func validatePlanLimitation(req *pb.PlanLimitationRequest) error {
+	switch {
+	case req.PlanId == 0 || req.LimitationId == 0:
+		return status.Error(codes.InvalidArgument, "plan and limitation ids are required")
+	case req.Value < 0 || req.UnitPrice < 0 || req.GetTrialValue() < 0:
+		return status.Error(codes.InvalidArgument, "quota values and unit price must not be negative")
+	}
+	return nil
+}
+
+// reads back the written plan limitation so the response carries the limitation title
+func (s *limitationServiceServer) planLimitation(ctx context.Context, req *pb.PlanLimitationRequest) (*pb.PlanLimitation, error) {
+	planLimitations, err := s.service.GetPlanLimitations(ctx, uint(req.PlanId))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	for _, pl := range planLimitations {
+		if pl.LimitationID == uint(req.LimitationId) {
+			return PlanLimitationDomain2Proto(pl), nil
+		}
+	}
+	return &pb.PlanLimitation{
+		PlanId:     req.PlanId,
+		Limitation: &pb.Limitation{Id: req.LimitationId},
+		Value:      req.Value,
+		UnitPrice:  req.UnitPrice,
+		TrialValue: req.TrialValue,
+	}, nil
+}
@@ -7,6 +7,7 @@ import (
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/api/pb"
 	planD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/domain"
 	planP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/port"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/pkg/util"
 )
 
 type planServiceServer struct {
@@ -20,12 +21,28 @@ func newPlanServer(s planP.Service) pb.PlanServiceServer {
 
 func (s *planServiceServer) AssignPlan(ctx context.Context, req *pb.PlanAssignmentRequest) (*pb.Empty, error) {
 	reqD := &planD.AssignPlanRequest{
-		UserID: uint(req.UserId),
-		PlanID: uint(req.PlanId),
+		UserID:    uint(req.UserId),
+		PlanID:    uint(req.PlanId),
+		Months:    int(req.Months),
+		AutoRenew: req.AutoRenew,
+		Pending:   req.Pending,
+		Change:    planD.Change{By: req.ChangedBy, Reason: req.Reason},
 	}
 	return &pb.Empty{}, s.service.AssignPlan(ctx, reqD)
 }
 
+func (s *planServiceServer) SetAutoRenew(ctx context.Context, req *pb.AutoRenewRequest) (*pb.Empty, error) {
+	return &pb.Empty{}, s.service.SetAutoRenew(ctx, uint(req.UserId), req.Enabled)
+}
+
+func (s *planServiceServer) StartTrial(ctx context.Context, req *pb.StartTrialRequest) (*pb.Empty, error) {
+	return &pb.Empty{}, s.service.StartTrial(ctx, &planD.StartTrialRequest{
+		UserID: uint(req.UserId),
+		PlanID: uint(req.PlanId),
+		Change: planD.Change{By: req.ChangedBy, Reason: req.Reason},
+	})
+}
+
 func (s *planServiceServer) GetUserPlan(ctx context.Context, req *pb.UserPlanRequest) (*pb.Plan, error) {
 	userPlan, err := s.service.GetUserPlan(ctx, uint(req.UserId))
 	if err != nil {
@@ -37,8 +54,13 @@ func (s *planServiceServer) GetUserPlan(ctx context.Context, req *pb.UserPlanReq
 		Id:          uint64(plan.ID),
 		Name:        plan.Title,
 		Description: "", // add description field to Plan model if needed
-		Price:       0,  // get price from prices array based on subscription period
-		IsActive:    !planD.IsExpired(userPlan.ExTime),
+		IsActive:    userPlan.Entitled(),
+		Payg:        plan.PAYG,
+	}
+
+	//the user's own term and the price paid for it
+	if userPlan.Months > 0 {
+		resPB.Prices = []*pb.PlanPrice{{Months: int32(userPlan.Months), Price: int64(userPlan.Price)}}
 	}
 
 	//duration in days from ExTime
@@ -52,92 +74,162 @@ func (s *planServiceServer) GetUserPlan(ctx context.Context, req *pb.UserPlanReq
 
 func (s *planServiceServer) RenewUserPlan(ctx context.Context, req *pb.RenewPlanRequest) (*pb.Empty, error) {
 	renewReq := &planD.RenewPlanRequest{
-		UserID:  uint(req.UserId),
-		EndDate: time.Unix(req.EndDate, 0),
+		UserID: uint(req.UserId),
+		Change: planD.Change{By: req.ChangedBy, Reason: req.Reason},
+	}
+	if req.EndDate != 0 {
+		renewReq.EndDate = time.Unix(req.EndDate, 0)
 	}
 	return &pb.Empty{}, s.service.RenewUserPlan(ctx, renewReq)
 }
 
-func (s *planServiceServer) CreatePlan(ctx context.Context, req *pb.CreatePlanRequest) (*pb.Plan, error) {
-	plan := &planD.Plan{
-		Title:  req.Plan.Name,
-		Custom: true,
-		PAYG:   false,
-	}
-
-	err := s.service.CreatePlan(ctx, plan)
+func (s *planServiceServer) ChangeUserPlan(ctx context.Context, req *pb.ChangePlanRequest) (*pb.ChangePlanResponse, error) {
+	result, err := s.service.ChangeUserPlan(ctx, &planD.ChangePlanRequest{
+		UserID:         uint(req.UserId),
+		PlanID:         uint(req.PlanId),
+		DeferDowngrade: req.DeferDowngrade,
+		Change:         planD.Change{By: req.ChangedBy, Reason: req.Reason},
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &pb.Plan{
-		Id:          uint64(plan.ID),
-		Name:        plan.Title,
-		Description: "",
-		Price:       0,
-		IsActive:    true,
+	return &pb.ChangePlanResponse{
+		Action:      result.Action,
+		Proration:   int64(result.Proration),
+		Deferred:    result.Deferred,
+		EffectiveAt: result.EffectiveAt.Unix(),
 	}, nil
 }
 
-func (s *planServiceServer) GetPlanByID(ctx context.Context, req *pb.PlanIDRequest) (*pb.Plan, error) {
-	plan, err := s.service.GetPlanByID(ctx, uint(req.Id))
+func (s *planServiceServer) ActivateUserPlan(ctx context.Context, req *pb.PlanTransitionRequest) (*pb.Empty, error) {
+	return &pb.Empty{}, s.service.ActivateUserPlan(ctx, TransitionProto2Domain(req))
+}
+
+func (s *planServiceServer) SuspendUserPlan(ctx context.Context, req *pb.PlanTransitionRequest) (*pb.Empty, error) {
+	return &pb.Empty{}, s.service.SuspendUserPlan(ctx, TransitionProto2Domain(req))
+}
+
+func (s *planServiceServer) ResumeUserPlan(ctx context.Context, req *pb.PlanTransitionRequest) (*pb.Empty, error) {
+	return &pb.Empty{}, s.service.ResumeUserPlan(ctx, TransitionProto2Domain(req))
+}
+
+func (s *planServiceServer) CancelUserPlan(ctx context.Context, req *pb.PlanTransitionRequest) (*pb.Empty, error) {
+	return &pb.Empty{}, s.service.CancelUserPlan(ctx, TransitionProto2Domain(req))
+}
+
+func (s *planServiceServer) GetPlanHistory(ctx context.Context, req *pb.PlanHistoryRequest) (*pb.PlanHistoryResponse, error) {
+	filter := &planD.HistoryFilter{
+		UserID:      uint(req.UserId),
+		Action:      req.Action,
+		MetadataKey: req.MetadataKey,
+	}
+	if req.Metadata != "" {
+		if err := filter.Metadata.Scan(req.Metadata); err != nil {
+			return nil, err
+		}
+	}
+	history, err := s.service.GetPlanHistory(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
+	return &pb.PlanHistoryResponse{Entries: util.Map(history, PlanHistoryDomain2Proto)}, nil
+}
 
-	pbPlan := &pb.Plan{
-		Id:       uint64(plan.ID),
-		Name:     plan.Title,
-		IsActive: plan.Custom || plan.PAYG,
+func (s *planServiceServer) ScheduleUserPlanChange(ctx context.Context, req *pb.ScheduleChangeRequest) (*pb.ScheduledChange, error) {
+	reqD := &planD.ScheduleChangeRequest{
+		UserID: uint(req.UserId),
+		Action: req.Action,
+		PlanID: uint(req.PlanId),
+		Change: planD.Change{By: req.ChangedBy, Reason: req.Reason},
+	}
+	if req.EffectiveAt != 0 {
+		reqD.EffectiveAt = time.Unix(req.EffectiveAt, 0)
 	}
 
-	prices, err := s.service.GetPlanPrices(ctx, plan.ID)
-	if err == nil && len(prices) > 0 {
-		// use the first price as default, or implement logic to select appropriate price
-		pbPlan.Price = float64(prices[0].Price) / 100 // convert from cents to dollars
+	change, err := s.service.ScheduleChange(ctx, reqD)
+	if err != nil {
+		return nil, err
 	}
+	return ScheduledChangeDomain2Proto(change), nil
+}
 
-	return pbPlan, nil
+func (s *planServiceServer) ListScheduledChanges(ctx context.Context, req *pb.UserPlanRequest) (*pb.ListScheduledChangesResponse, error) {
+	changes, err := s.service.ListScheduledChanges(ctx, uint(req.UserId))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ListScheduledChangesResponse{Changes: util.Map(changes, ScheduledChangeDomain2Proto)}, nil
 }
 
-func (s *planServiceServer) GetPlanByName(ctx context.Context, req *pb.PlanNameRequest) (*pb.Plan, error) {
-	plan, err := s.service.GetPlanByTitle(ctx, req.Name)
+func (s *planServiceServer) RevokeScheduledChange(ctx context.Context, req *pb.ScheduledChangeIDRequest) (*pb.Empty, error) {
+	return &pb.Empty{}, s.service.RevokeScheduledChange(ctx, uint(req.UserId), uint(req.Id))
+}
+
+func (s *planServiceServer) CreatePlan(ctx context.Context, req *pb.CreatePlanRequest) (*pb.Plan, error) {
+	plan := &planD.Plan{
+		Title:           req.Plan.Name,
+		Custom:          true,
+		PAYG:            req.Plan.Payg,
+		GracePeriodDays: int(req.Plan.GracePeriodDays),
+	}
+	setPlanTrial(plan, req.Plan)
+
+	err := s.service.CreatePlan(ctx, plan)
 	if err != nil {
 		return nil, err
 	}
 
-	pbPlan := &pb.Plan{
-		Id:       uint64(plan.ID),
-		Name:     plan.Title,
-		IsActive: plan.Custom || plan.PAYG,
+	for _, price := range req.Plan.Prices {
+		if err := s.service.SetPlanPrice(ctx, plan.ID, int(price.Months), int(price.Price)); err != nil {
+			return nil, err
+		}
 	}
 
-	prices, err := s.service.GetPlanPrices(ctx, plan.ID)
-	if err == nil && len(prices) > 0 {
-		pbPlan.Price = float64(prices[0].Price) / 100
+	return s.planProto(ctx, plan)
+}
+
+func (s *planServiceServer) GetPlanByID(ctx context.Context, req *pb.PlanIDRequest) (*pb.Plan, error) {
+	plan, err := s.service.GetPlanByID(ctx, uint(req.Id))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.planProto(ctx, plan)
+}
+
+func (s *planServiceServer) GetPlanByName(ctx context.Context, req *pb.PlanNameRequest) (*pb.Plan, error) {
+	plan, err := s.service.GetPlanByTitle(ctx, req.Name)
+	if err != nil {
+		return nil, err
 	}
 
-	return pbPlan, nil
+	return s.planProto(ctx, plan)
 }
 
 func (s *planServiceServer) UpdatePlan(ctx context.Context, req *pb.UpdatePlanRequest) (*pb.Plan, error) {
 	plan := &planD.Plan{
-		Title:  req.Plan.Name,
-		Custom: req.Plan.IsActive,
-		PAYG:   false,
+		Title:           req.Plan.Name,
+		Custom:          req.Plan.IsActive,
+		PAYG:            req.Plan.Payg,
+		GracePeriodDays: int(req.Plan.GracePeriodDays),
 	}
 	plan.ID = uint(req.Plan.Id)
+	setPlanTrial(plan, req.Plan)
 
 	err := s.service.UpdatePlan(ctx, plan)
 	if err != nil {
 		return nil, err
 	}
 
-	return &pb.Plan{
-		Id:       uint64(plan.ID),
-		Name:     plan.Title,
-		IsActive: plan.Custom || plan.PAYG,
-	}, nil
+	// prices missing from the request are kept, DeletePlanPrice removes a term
+	for _, price := range req.Plan.Prices {
+		if err := s.service.SetPlanPrice(ctx, plan.ID, int(price.Months), int(price.Price)); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.planProto(ctx, plan)
 }
 
 func (s *planServiceServer) DeletePlan(ctx context.Context, req *pb.PlanIDRequest) (*pb.Empty, error) {
@@ -156,17 +248,10 @@ func (s *planServiceServer) ListPlans(ctx context.Context, req *pb.ListPlansRequ
 
 	var pbPlans []*pb.Plan
 	for _, plan := range plans {
-		pbPlan := &pb.Plan{
-			Id:       uint64(plan.ID),
-			Name:     plan.Title,
-			IsActive: plan.Custom || plan.PAYG,
+		pbPlan, err := s.planProto(ctx, plan)
+		if err != nil {
+			return nil, err
 		}
-
-		prices, err := s.service.GetPlanPrices(ctx, plan.ID)
-		if err == nil && len(prices) > 0 {
-			pbPlan.Price = float64(prices[0].Price) / 100
-		}
-
 		pbPlans = append(pbPlans, pbPlan)
 	}
 
@@ -191,3 +276,60 @@ func (s *planServiceServer) TogglePlanActive(ctx context.Context, req *pb.PlanID
 
 	return &pb.Empty{}, nil
 }
+
+func (s *planServiceServer) SetPlanPrice(ctx context.Context, req *pb.PlanPriceRequest) (*pb.PlanPrice, error) {
+	if _, err := s.service.GetPlanByID(ctx, uint(req.PlanId)); err != nil {
+		return nil, err
+	}
+
+	err := s.service.SetPlanPrice(ctx, uint(req.PlanId), int(req.Months), int(req.Price))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PlanPrice{Months: req.Months, Price: req.Price}, nil
+}
+
+func (s *planServiceServer) ListPlanPrices(ctx context.Context, req *pb.PlanIDRequest) (*pb.ListPlanPricesResponse, error) {
+	prices, err := s.service.GetPlanPrices(ctx, uint(req.Id))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ListPlanPricesResponse{Prices: util.Map(prices, PriceDomain2Proto)}, nil
+}
+
+func (s *planServiceServer) DeletePlanPrice(ctx context.Context, req *pb.PlanPriceIDRequest) (*pb.Empty, error) {
+	return &pb.Empty{}, s.service.DeletePlanPrice(ctx, uint(req.PlanId), int(req.Months))
+}
+
+// maps a plan together with its full price table
+func (s *planServiceServer) planProto(ctx context.Context, plan *planD.Plan) (*pb.Plan, error) {
+	prices, err := s.service.GetPlanPrices(ctx, plan.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resPB := &pb.Plan{
+		Id:                 uint64(plan.ID),
+		Name:               plan.Title,
+		IsActive:           plan.Custom || plan.PAYG,
+		Payg:               plan.PAYG,
+		Prices:             util.Map(prices, PriceDomain2Proto),
+		GracePeriodDays:    int32(plan.GracePeriodDays),
+		TrialDays:          int32(plan.TrialDays),
+		TrialConvertMonths: int32(plan.TrialConvertMonths),
+	}
+	if plan.TrialConvertPlanID != nil {
+		resPB.TrialConvertPlanId = uint64(*plan.TrialConvertPlanID)
+	}
+	return resPB, nil
+}
+
+// setPlanTrial copies the trial configuration of req onto plan
+func setPlanTrial(plan *planD.Plan, req *pb.Plan) {
+	plan.TrialDays = int(req.TrialDays)
+	plan.TrialConvertMonths = int(req.TrialConvertMonths)
+	if req.TrialConvertPlanId != 0 {
+		id := uint(req.TrialConvertPlanId)
+		plan.TrialConvertPlanID = &id
+	}
+}
@@ -0,0 +1,65 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/api/pb"
+	usageD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/usage/domain"
+	usageP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/usage/port"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/pkg/util"
+)
+
+type usageServiceServer struct {
+	pb.UnimplementedUsageServiceServer
+	service usageP.Service
+}
+
+func newUsageServer(s usageP.Service) pb.UsageServiceServer {
+	return &usageServiceServer{service: s}
+}
+
+func (s *usageServiceServer) CheckQuota(ctx context.Context, req *pb.QuotaRequest) (*pb.Quota, error) {
+	quota, err := s.service.CheckQuota(ctx, QuotaRequestProto2Domain(req))
+	if err != nil {
+		return nil, err
+	}
+	return QuotaDomain2Proto(quota), nil
+}
+
+func (s *usageServiceServer) ConsumeQuota(ctx context.Context, req *pb.QuotaRequest) (*pb.Quota, error) {
+	quota, err := s.service.ConsumeQuota(ctx, QuotaRequestProto2Domain(req))
+	if err != nil {
+		return nil, err
+	}
+	return QuotaDomain2Proto(quota), nil
+}
+
+func (s *usageServiceServer) GetUsage(ctx context.Context, req *pb.UserPlanRequest) (*pb.UsageResponse, error) {
+	quotas, err := s.service.GetUsage(ctx, uint(req.UserId))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UsageResponse{Quotas: util.Map(quotas, QuotaDomain2Proto)}, nil
+}
+
+func QuotaRequestProto2Domain(req *pb.QuotaRequest) *usageD.QuotaRequest {
+	return &usageD.QuotaRequest{
+		UserID:     uint(req.UserId),
+		Limitation: req.Limitation,
+		Amount:     req.Amount,
+	}
+}
+
+func (s *usageServiceServer) GetUsageStatement(ctx context.Context, req *pb.UsageStatementRequest) (*pb.UsageStatement, error) {
+	statementReq := &usageD.StatementRequest{UserID: uint(req.UserId)}
+	if req.Period != 0 {
+		statementReq.Period = time.Unix(req.Period, 0)
+	}
+
+	statement, err := s.service.GetUsageStatement(ctx, statementReq)
+	if err != nil {
+		return nil, err
+	}
+	return StatementDomain2Proto(statement), nil
+}
@@ -23,6 +23,8 @@ func (s *userServiceServer) ListUsers(ctx context.Context, uf *pb.UserFilter) (*
 		Name:  uf.Name,
 		Email: uf.Email,
 		Phone: uf.Phone,
+		Page:  int(uf.Page),
+		Size:  int(uf.Size),
 	})
 	if err != nil {
 		return nil, err
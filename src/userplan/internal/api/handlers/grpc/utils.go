@@ -1,7 +1,16 @@
 package grpc
 
 import (
+	"encoding/json"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/api/pb"
+	planD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/domain"
+	usageD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/usage/domain"
 	userD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/user/domain"
 )
 
@@ -26,3 +35,139 @@ func UserProto2Domain(u *pb.User) *userD.User {
 		Active: u.Active,
 	}
 }
+
+func LimitationDomain2Proto(l *planD.Limitation) *pb.Limitation {
+	return &pb.Limitation{
+		Id:    uint64(l.ID),
+		Title: l.Title,
+	}
+}
+
+func PlanLimitationDomain2Proto(pl *planD.PlanLimitation) *pb.PlanLimitation {
+	limitation := pl.Limitation
+	limitation.ID = pl.LimitationID
+	resPB := &pb.PlanLimitation{
+		PlanId:     uint64(pl.PlanID),
+		Limitation: LimitationDomain2Proto(&limitation),
+		Value:      int64(pl.Value),
+		UnitPrice:  int64(pl.UnitPrice),
+	}
+	if pl.TrialValue != nil {
+		trialValue := int64(*pl.TrialValue)
+		resPB.TrialValue = &trialValue
+	}
+	return resPB
+}
+
+func PlanLimitationProto2Domain(req *pb.PlanLimitationRequest) *planD.PlanLimitation {
+	pl := &planD.PlanLimitation{
+		PlanID:       uint(req.PlanId),
+		LimitationID: uint(req.LimitationId),
+		Value:        int(req.Value),
+		UnitPrice:    int(req.UnitPrice),
+	}
+	if req.TrialValue != nil {
+		trialValue := int(*req.TrialValue)
+		pl.TrialValue = &trialValue
+	}
+	return pl
+}
+
+func QuotaDomain2Proto(q *usageD.Quota) *pb.Quota {
+	return &pb.Quota{
+		Limitation:  q.Limitation,
+		Limit:       q.Limit,
+		Used:        q.Used,
+		Remaining:   q.Remaining(),
+		Allowed:     q.Allowed,
+		Metered:     q.Metered,
+		PastDue:     q.PastDue,
+		Trial:       q.Trial,
+		PeriodStart: q.PeriodStart.Unix(),
+		PeriodEnd:   q.PeriodEnd.Unix(),
+	}
+}
+
+func StatementDomain2Proto(st *usageD.Statement) *pb.UsageStatement {
+	lines := make([]*pb.UsageStatementLine, 0, len(st.Lines))
+	for _, l := range st.Lines {
+		lines = append(lines, &pb.UsageStatementLine{
+			Limitation: l.Limitation,
+			Units:      l.Units,
+			UnitPrice:  l.UnitPrice,
+			Amount:     l.Amount,
+		})
+	}
+	return &pb.UsageStatement{
+		UserId:      uint64(st.UserID),
+		PlanId:      uint64(st.PlanID),
+		PeriodStart: st.PeriodStart.Unix(),
+		PeriodEnd:   st.PeriodEnd.Unix(),
+		Lines:       lines,
+		Total:       st.Total,
+	}
+}
+
+func PriceDomain2Proto(p *planD.Price) *pb.PlanPrice {
+	return &pb.PlanPrice{
+		Months: int32(p.Month),
+		Price:  int64(p.Price),
+	}
+}
+
+func TransitionProto2Domain(req *pb.PlanTransitionRequest) *planD.Transition {
+	return &planD.Transition{
+		UserID: uint(req.UserId),
+		Change: planD.Change{By: req.ChangedBy, Reason: req.Reason},
+	}
+}
+
+func PlanHistoryDomain2Proto(h *planD.PlanHistory) *pb.PlanHistoryEntry {
+	entry := &pb.PlanHistoryEntry{
+		Id:         uint64(h.ID),
+		UserPlanId: uint64(h.UserPlanID),
+		Action:     h.Action,
+		FromStatus: h.FromStatus,
+		ToStatus:   h.ToStatus,
+		ChangedBy:  h.ChangedBy,
+		Reason:     h.Reason,
+		ChangedAt:  h.ChangedAt.Unix(),
+	}
+	if h.OldPlanID != nil {
+		entry.OldPlanId = uint64(*h.OldPlanID)
+	}
+	if h.NewPlanID != nil {
+		entry.NewPlanId = uint64(*h.NewPlanID)
+	}
+	if len(h.Metadata) > 0 {
+		if b, err := json.Marshal(h.Metadata); err == nil {
+			entry.Metadata = string(b)
+		}
+	}
+	return entry
+}
+
+func ScheduledChangeDomain2Proto(c *planD.ScheduledChange) *pb.ScheduledChange {
+	change := &pb.ScheduledChange{
+		Id:          uint64(c.ID),
+		UserPlanId:  uint64(c.UserPlanID),
+		Action:      c.Action,
+		EffectiveAt: c.EffectiveAt.Unix(),
+		Status:      c.Status,
+		ChangedBy:   c.ChangedBy,
+		Reason:      c.Reason,
+		CreatedAt:   c.CreatedAt.Unix(),
+	}
+	if c.PlanID != nil {
+		change.PlanId = uint64(*c.PlanID)
+	}
+	return change
+}
+
+// statusError gives a missing record the NotFound code so that clients can tell it from a failure
+func statusError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return err
+}
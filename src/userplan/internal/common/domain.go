@@ -22,18 +22,5 @@ type AuditLog struct {
 	RequestID  string `gorm:"size:255" json:"request_id"`
 	IPAddress  string `gorm:"size:45" json:"ip_address"` // IPv4 or IPv6
 	UserAgent  string `gorm:"size:512" json:"user_agent"`
-	Metadata   JSON   `gorm:"type:json" json:"metadata"`
-}
-
-// for handling JSON data in gorm
-type JSON map[string]interface{}
-
-func (j *JSON) Scan(value interface{}) error {
-	// implement scan logic for JSON type
-	return nil
-}
-
-func (j JSON) Value() (interface{}, error) {
-	// implement value logic for JSON type
-	return nil, nil
+	Metadata   JSON   `gorm:"type:jsonb" json:"metadata"`
 }
@@ -0,0 +1,75 @@
+package common
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var ErrJSONSource = errors.New("JSON can only be scanned from bytes or a string")
+
+// JSON is a JSON object stored in a jsonb column, numbers are decoded as json.Number to keep their precision
+type JSON map[string]interface{}
+
+func (j *JSON) Scan(value interface{}) error {
+	var b []byte
+	switch v := value.(type) {
+	case nil:
+		*j = nil
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("%w: %T", ErrJSONSource, value)
+	}
+
+	var m JSON
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&m); err != nil {
+		return err
+	}
+	*j = m
+	return nil
+}
+
+// Value stores a nil JSON as NULL
+func (j JSON) Value() (driver.Value, error) {
+	if j == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(j)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (JSON) GormDataType() string {
+	return "jsonb"
+}
+
+func (JSON) GormDBDataType(db *gorm.DB, _ *schema.Field) string {
+	if db.Dialector.Name() == "postgres" {
+		return "jsonb"
+	}
+	return "json"
+}
+
+// JSONContains matches rows whose JSON column contains every key and value of value, using the GIN index of column
+func JSONContains(column string, value JSON) clause.Expression {
+	return clause.Expr{SQL: "? @> ?::jsonb", Vars: []interface{}{clause.Column{Name: column}, value}}
+}
+
+// JSONHasKey matches rows whose JSON column has the top level key
+func JSONHasKey(column, key string) clause.Expression {
+	return clause.Expr{SQL: "jsonb_exists(?, ?)", Vars: []interface{}{clause.Column{Name: column}, key}}
+}
@@ -0,0 +1,86 @@
+package common
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestJSON_ValueScan(t *testing.T) {
+	j := JSON{"transaction_id": "tx-1", "amount": 9007199254740993, "tags": []string{"a"}}
+	v, err := j.Value()
+	require.NoError(t, err)
+
+	var scanned JSON
+	require.NoError(t, scanned.Scan([]byte(v.(string))))
+	assert.Equal(t, "tx-1", scanned["transaction_id"])
+	assert.Equal(t, json.Number("9007199254740993"), scanned["amount"])
+	assert.Equal(t, []interface{}{"a"}, scanned["tags"])
+
+	require.NoError(t, scanned.Scan(`{"k":"v"}`))
+	assert.Equal(t, JSON{"k": "v"}, scanned)
+
+	require.NoError(t, scanned.Scan(nil))
+	assert.Nil(t, scanned)
+	v, err = JSON(nil).Value()
+	require.NoError(t, err)
+	assert.Nil(t, v)
+
+	assert.ErrorIs(t, scanned.Scan(42), ErrJSONSource)
+	assert.Error(t, scanned.Scan(`[1, 2]`))
+}
+
+// testDB connects to TEST_POSTGRES_DSN, a local postgres by default, skipping the test when it is unavailable
+func testDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		dsn = "host=localhost port=5432 user=postgres password=postgres dbname=postgres sslmode=disable connect_timeout=2"
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Skipf("postgres unavailable: %v", err)
+	}
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	//temporary tables live on a single connection
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+type jsonRow struct {
+	ID   uint
+	Data JSON
+}
+
+func TestJSON_Postgres(t *testing.T) {
+	db := testDB(t)
+	require.NoError(t, db.Exec(`CREATE TEMPORARY TABLE json_rows (id serial PRIMARY KEY, data jsonb)`).Error)
+
+	rows := []*jsonRow{
+		{Data: JSON{"transaction_id": "tx-1", "amount": 100}},
+		{Data: JSON{"error": "card declined"}},
+		{},
+	}
+	require.NoError(t, db.Table("json_rows").Create(&rows).Error)
+
+	var got jsonRow
+	require.NoError(t, db.Table("json_rows").First(&got, rows[0].ID).Error)
+	assert.Equal(t, JSON{"transaction_id": "tx-1", "amount": json.Number("100")}, got.Data)
+	require.NoError(t, db.Table("json_rows").First(&got, rows[2].ID).Error)
+	assert.Nil(t, got.Data)
+
+	var ids []uint
+	require.NoError(t, db.Table("json_rows").Where(JSONHasKey("data", "error")).Pluck("id", &ids).Error)
+	assert.Equal(t, []uint{rows[1].ID}, ids)
+	require.NoError(t, db.Table("json_rows").Where(JSONContains("json_rows.data", JSON{"transaction_id": "tx-1"})).Pluck("id", &ids).Error)
+	assert.Equal(t, []uint{rows[0].ID}, ids)
+	require.NoError(t, db.Table("json_rows").Where(JSONContains("data", JSON{"transaction_id": "tx-2"})).Pluck("id", &ids).Error)
+	assert.Empty(t, ids)
+}
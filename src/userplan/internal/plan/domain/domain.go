@@ -1,6 +1,9 @@
 package domain
 
 import (
+	"errors"
+	"math"
+	"slices"
 	"time"
 
 	"gorm.io/gorm"
@@ -8,20 +11,63 @@ import (
 	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/user/domain"
 )
 
+var ErrTrialUsed = errors.New("trial already used")
+
+var ErrInvalidTransition = errors.New("plan status transition is not allowed")
+
 const (
-	PlanStatusActive   = "active"
-	PlanStatusExpired  = "expired"
-	PlanStatusCanceled = "canceled"
+	PlanStatusPending   = "pending" // assigned but waiting to be activated
+	PlanStatusActive    = "active"
+	PlanStatusSuspended = "suspended"
+	PlanStatusPastDue   = "past_due" // term ended, limits apply until the grace period ends
+	PlanStatusExpired   = "expired"
+	PlanStatusCanceled  = "canceled"
 )
 
 const (
 	PlanActionAssign    = "assign"
+	PlanActionActivate  = "activate"
 	PlanActionRenew     = "renew"
+	PlanActionRenewFail = "renew_failed"
+	PlanActionPastDue   = "past_due"
+	PlanActionTrial     = "trial_start"
+	PlanActionConvert   = "trial_convert"
+	PlanActionTrialEnd  = "trial_expire"
+	PlanActionSuspend   = "suspend"
+	PlanActionResume    = "resume"
+	PlanActionExpire    = "expire"
 	PlanActionCancel    = "cancel"
 	PlanActionUpgrade   = "upgrade"
 	PlanActionDowngrade = "downgrade"
 )
 
+// ChangedBySystem marks lifecycle changes made by background jobs
+const ChangedBySystem = "system"
+
+// allowed status transitions of a user plan, expired and canceled are final
+var planTransitions = map[string][]string{
+	PlanStatusPending:   {PlanStatusActive, PlanStatusCanceled},
+	PlanStatusActive:    {PlanStatusActive, PlanStatusSuspended, PlanStatusPastDue, PlanStatusExpired, PlanStatusCanceled},
+	PlanStatusSuspended: {PlanStatusActive, PlanStatusExpired, PlanStatusCanceled},
+	PlanStatusPastDue:   {PlanStatusActive, PlanStatusExpired, PlanStatusCanceled},
+}
+
+// LivePlanStatuses are the statuses of a user's current plan, a user has at most one plan in them
+var LivePlanStatuses = []string{PlanStatusPending, PlanStatusActive, PlanStatusSuspended, PlanStatusPastDue}
+
+// EntitledPlanStatuses are the statuses in which a plan's limitations apply to its user
+var EntitledPlanStatuses = []string{PlanStatusActive, PlanStatusPastDue}
+
+// CanTransition reports whether a user plan may move from one status to another
+func CanTransition(from, to string) bool {
+	for _, s := range planTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
 type BasicID struct {
 	ID uint `gorm:"primarykey"`
 }
@@ -40,6 +86,13 @@ type Plan struct {
 	Limitations []Limitation `gorm:"many2many:plan_limitations;"`
 	Custom      bool         `gorm:"not null;default:true"`
 	PAYG        bool         `gorm:"not null;default:false"`
+	// days a plan stays past due after its term ends before it expires
+	GracePeriodDays int `gorm:"not null;default:0"`
+	// length of the free trial, zero when the plan has none
+	TrialDays int `gorm:"not null;default:0"`
+	// paid plan and term a trial converts to, trials without one expire
+	TrialConvertPlanID *uint
+	TrialConvertMonths int `gorm:"not null;default:1"`
 }
 
 type Price struct {
@@ -57,16 +110,170 @@ type PlanLimitation struct {
 	PlanID       uint `gorm:"primaryKey"`
 	LimitationID uint `gorm:"primaryKey"`
 	Limitation   Limitation
-	Value        int `gorm:"default:1"`
+	Value        int  `gorm:"default:1"`
+	UnitPrice    int  `gorm:"not null;default:0"` // price per consumed unit on PAYG plans
+	TrialValue   *int // overrides Value during a trial
+}
+
+// Limit returns the quota value of the limitation for a trial or a paid plan
+func (pl *PlanLimitation) Limit(trial bool) int {
+	if trial && pl.TrialValue != nil {
+		return *pl.TrialValue
+	}
+	return pl.Value
 }
 
 type UserPlan struct {
 	BasicWithSoftDelete
-	PlanID uint `gorm:"primaryKey"`
+	PlanID uint `gorm:"not null;index"`
 	Plan   Plan
-	UserID uint `gorm:"primaryKey"`
+	UserID uint `gorm:"not null;index"`
 	User   domain.User
-	ExTime time.Time
+	Status string    `gorm:"size:20;not null;default:active;index"`
+	ExTime time.Time // zero for plans that never expire (PAYG)
+	Months int       // purchased term, reused for invoices and renewals
+	Price  int       // price paid for the term
+	// renew for the same term when it ends instead of expiring
+	AutoRenew     bool `gorm:"not null;default:false"`
+	LastRenewalAt *time.Time
+	Trial         bool `gorm:"not null;default:false"`
+}
+
+// Trial records a started trial, a user gets one trial per email and oauth ID
+type Trial struct {
+	ID        uint   `gorm:"primarykey"`
+	UserID    uint   `gorm:"index"`
+	PlanID    uint   `gorm:"not null"`
+	Email     string `gorm:"uniqueIndex:idx_trials_email,where:email <> ''"` // lowercased
+	OauthID   string `gorm:"uniqueIndex:idx_trials_oauth_id,where:oauth_id <> ''"`
+	StartedAt time.Time
+}
+
+type StartTrialRequest struct {
+	UserID uint
+	PlanID uint
+	Change
+}
+
+// EndStatus is the status an ended plan moves to at now, active plans
+// stay usable as past due while their grace period lasts
+func (up *UserPlan) EndStatus(now time.Time) string {
+	if up.Status == PlanStatusActive && up.GraceEnd().After(now) {
+		return PlanStatusPastDue
+	}
+	return PlanStatusExpired
+}
+
+// Entitled reports whether the limitations of the plan apply to its user, past due plans
+// keep them until the expiration job ends their grace period
+func (up *UserPlan) Entitled() bool {
+	return slices.Contains(EntitledPlanStatuses, up.Status)
+}
+
+// GraceEnd is when the plan expires for good, Plan must be loaded
+func (up *UserPlan) GraceEnd() time.Time {
+	return up.ExTime.AddDate(0, 0, up.Plan.GracePeriodDays)
+}
+
+// PlanReminder records an expiration reminder of a user plan for one threshold of the schedule
+type PlanReminder struct {
+	ID         uint       `gorm:"primarykey"`
+	UserPlanID uint       `gorm:"uniqueIndex:idx_plan_reminder"`
+	Threshold  int        `gorm:"uniqueIndex:idx_plan_reminder"` // days before the grace period ends
+	SentAt     *time.Time // nil when a closer threshold was sent instead
+	CreatedAt  time.Time
+}
+
+// ExpirationResult counts the plans ended by an expiration run
+type ExpirationResult struct {
+	Expired    int
+	PastDue    int // moved into their grace period
+	Skipped    int // changed by someone else while being ended
+	Failed     int
+	SkippedIDs []uint
+	FailedIDs  []uint // user plans that failed, retried on the next run
+	Batches    int
+	Changes    []*StateChange // only collected by runs asked for a report
+}
+
+// ExpirationOptions tunes an expiration run, a dry run only reports the changes it would make
+type ExpirationOptions struct {
+	BatchSize int
+	DryRun    bool
+	Report    bool // collect the changes made in Changes, dry runs always do
+}
+
+// StateChange is a status change of a user plan made by an expiration run
+type StateChange struct {
+	UserPlanID uint
+	UserID     uint
+	OldPlanID  uint
+	NewPlanID  uint
+	FromStatus string
+	ToStatus   string
+	Action     string
+	Reason     string
+	ExTime     time.Time // end of the term after the change
+}
+
+// NewStateChange describes moving userPlan to status as recorded by history, before the transition is made
+func NewStateChange(userPlan *UserPlan, status string, history *PlanHistory) *StateChange {
+	change := &StateChange{
+		UserPlanID: userPlan.ID,
+		UserID:     userPlan.UserID,
+		NewPlanID:  userPlan.PlanID,
+		FromStatus: userPlan.Status,
+		ToStatus:   status,
+		Action:     history.Action,
+		Reason:     history.Reason,
+		ExTime:     userPlan.ExTime,
+	}
+	if history.OldPlanID != nil {
+		change.OldPlanID = *history.OldPlanID
+	}
+	return change
+}
+
+// Add sums the counts of other into r
+func (r *ExpirationResult) Add(other *ExpirationResult) {
+	r.Expired += other.Expired
+	r.PastDue += other.PastDue
+	r.Skipped += other.Skipped
+	r.Failed += other.Failed
+	r.SkippedIDs = append(r.SkippedIDs, other.SkippedIDs...)
+	r.FailedIDs = append(r.FailedIDs, other.FailedIDs...)
+	r.Batches += other.Batches
+	r.Changes = append(r.Changes, other.Changes...)
+}
+
+// Processed is the number of plans picked by the run
+func (r *ExpirationResult) Processed() int {
+	return r.Expired + r.PastDue + r.Skipped + r.Failed
+}
+
+// Reminder tells a user their plan expires in Days days
+type Reminder struct {
+	UserID    uint
+	Email     string
+	Name      string
+	Plan      string
+	Status    string
+	Threshold int
+	Days      int
+	ExpiresAt time.Time
+}
+
+// Charge is a payment collected for a plan term
+type Charge struct {
+	UserID    uint
+	PlanID    uint
+	Months    int
+	Amount    int
+	Reference string // idempotency key of the charge
+}
+
+type Receipt struct {
+	TransactionID string
 }
 
 // tracks changes to user plans
@@ -74,21 +281,99 @@ type PlanHistory struct {
 	common.BaseModel
 	UserPlanID uint        `gorm:"index" json:"user_plan_id"`
 	Action     string      `gorm:"size:50" json:"action"`
+	FromStatus string      `gorm:"size:20" json:"from_status"` // empty for new assignments
+	ToStatus   string      `gorm:"size:20" json:"to_status"`
 	OldPlanID  *uint       `json:"old_plan_id,omitempty"` // nullable for new assignments
 	NewPlanID  *uint       `json:"new_plan_id,omitempty"` // nullable for cancellations
+	ChangedBy  string      `gorm:"size:255" json:"changed_by"`
+	Reason     string      `gorm:"size:500" json:"reason"`
 	ChangedAt  time.Time   `json:"changed_at"`
-	Metadata   common.JSON `gorm:"type:json" json:"metadata"`
+	Metadata   common.JSON `gorm:"type:jsonb" json:"metadata"`
+}
+
+// HistoryFilter selects the plan history of a user, optionally by action and metadata
+type HistoryFilter struct {
+	UserID      uint
+	Action      string
+	MetadataKey string      // entries whose metadata has the key
+	Metadata    common.JSON // entries whose metadata contains these keys and values
+}
+
+const (
+	ScheduledActionChange = "change" // move to another plan
+	ScheduledActionCancel = "cancel"
+)
+
+const (
+	ScheduledChangePending = "pending"
+	ScheduledChangeApplied = "applied"
+	ScheduledChangeRevoked = "revoked"
+	ScheduledChangeFailed  = "failed"
+)
+
+// ScheduledChange is a lifecycle change of a user plan that takes effect at EffectiveAt
+type ScheduledChange struct {
+	common.BaseModel
+	UserPlanID    uint       `gorm:"index;not null" json:"user_plan_id"`
+	UserPlan      UserPlan   `gorm:"foreignKey:UserPlanID;references:ID" json:"-"`
+	Action        string     `gorm:"size:20;not null" json:"action"`
+	PlanID        *uint      `json:"plan_id,omitempty"` // target plan of change actions
+	EffectiveAt   time.Time  `gorm:"index;not null" json:"effective_at"`
+	Status        string     `gorm:"size:20;not null;default:pending;index" json:"status"`
+	ChangedBy     string     `gorm:"size:255" json:"changed_by"`
+	Reason        string     `gorm:"size:500" json:"reason"`
+	AppliedAt     *time.Time `json:"applied_at,omitempty"`
+	FailureReason string     `gorm:"size:500" json:"failure_reason,omitempty"`
+}
+
+type ScheduleChangeRequest struct {
+	UserID      uint
+	Action      string
+	PlanID      uint      // target plan of change actions
+	EffectiveAt time.Time // zero for the end of the current term
+	Change
+}
+
+// Change describes who made a lifecycle change and why
+type Change struct {
+	By     string
+	Reason string
+}
+
+// Transition is a status change of a user plan
+type Transition struct {
+	UserID uint
+	Change
 }
 
 // DTOs
 type AssignPlanRequest struct {
-	UserID uint
-	PlanID uint
+	UserID    uint
+	PlanID    uint
+	Months    int // term to purchase, must match a Price of the plan; ignored for PAYG plans
+	AutoRenew bool
+	Pending   bool // wait for ActivateUserPlan, the term starts on activation
+	Change
+}
+
+type ChangePlanRequest struct {
+	UserID         uint
+	PlanID         uint // plan to move to, must be priced for the user's current term
+	DeferDowngrade bool // apply a downgrade at the end of the current term instead of now
+	Change
+}
+
+type PlanChangeResult struct {
+	Action      string // PlanActionUpgrade or PlanActionDowngrade
+	Proration   int    // amount due for the rest of the term, negative for a credit
+	Deferred    bool
+	EffectiveAt time.Time
 }
 
 type RenewPlanRequest struct {
 	UserID  uint
-	EndDate time.Time
+	EndDate time.Time // explicit end date, zero to renew for the purchased term
+	Change
 }
 
 func CalculateExpirationDate(startDate time.Time, durationDays int) time.Time {
@@ -98,11 +383,46 @@ func CalculateExpirationDate(startDate time.Time, durationDays int) time.Time {
 	return time.Date(expirationDate.Year(), expirationDate.Month(), expirationDate.Day(), 0, 0, 0, 0, expirationDate.Location())
 }
 
+// ExpirationForTerm returns the expiration time of a term of the given months starting at startDate
+func ExpirationForTerm(startDate time.Time, months int) time.Time {
+	return CalculateExpirationDate(startDate.AddDate(0, months, 0), 0)
+}
+
+// TermStart returns the start of a term of the given months ending at exTime
+func TermStart(exTime time.Time, months int) time.Time {
+	return exTime.AddDate(0, -months, 0)
+}
+
+// Prorate returns the difference between newPrice and oldPrice for the days left
+// until exTime of the term starting at termStart, a negative amount is a credit
+func Prorate(oldPrice, newPrice int, termStart, exTime, now time.Time) int {
+	totalDays := daysBetween(termStart, exTime)
+	if totalDays <= 0 {
+		return 0
+	}
+	remainingDays := daysBetween(CalculateExpirationDate(now, 0), exTime)
+	if remainingDays <= 0 {
+		return 0
+	}
+	if remainingDays > totalDays {
+		remainingDays = totalDays
+	}
+	return int(int64(newPrice-oldPrice) * int64(remainingDays) / int64(totalDays))
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
+
+// a zero expiration time means the plan never expires
 func IsExpired(expiresAt time.Time) bool {
-	return time.Now().After(expiresAt)
+	return !expiresAt.IsZero() && time.Now().After(expiresAt)
 }
 
 func IsExpiringSoon(expiresAt time.Time, daysThreshold int) bool {
+	if expiresAt.IsZero() {
+		return false
+	}
 	thresholdDate := time.Now().AddDate(0, 0, daysThreshold)
 	return expiresAt.Before(thresholdDate) && !IsExpired(expiresAt)
 }
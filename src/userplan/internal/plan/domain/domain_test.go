@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{PlanStatusPending, PlanStatusActive, true},
+		{PlanStatusActive, PlanStatusActive, true},
+		{PlanStatusActive, PlanStatusSuspended, true},
+		{PlanStatusSuspended, PlanStatusActive, true},
+		{PlanStatusActive, PlanStatusExpired, true},
+		{PlanStatusSuspended, PlanStatusCanceled, true},
+		{PlanStatusPending, PlanStatusSuspended, false},
+		{PlanStatusExpired, PlanStatusActive, false},
+		{PlanStatusCanceled, PlanStatusActive, false},
+		{PlanStatusCanceled, PlanStatusCanceled, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.allowed, CanTransition(tt.from, tt.to), "%s -> %s", tt.from, tt.to)
+	}
+}
+
+func TestProrate(t *testing.T) {
+	termStart := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	exTime := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC) // 30 day term
+
+	//half the term left
+	now := time.Date(2025, 1, 16, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, 500, Prorate(1000, 2000, termStart, exTime, now))
+	assert.Equal(t, -500, Prorate(2000, 1000, termStart, exTime, now))
+
+	//nothing is due once the term has ended
+	assert.Equal(t, 0, Prorate(1000, 2000, termStart, exTime, exTime.AddDate(0, 0, 1)))
+
+	//a change before the term starts is charged in full
+	assert.Equal(t, 1000, Prorate(1000, 2000, termStart, exTime, termStart.AddDate(0, 0, -5)))
+}
@@ -10,8 +10,19 @@ type Service interface {
 	AssignPlan(ctx context.Context, req *domain.AssignPlanRequest) error
 	GetUserPlan(ctx context.Context, userID uint) (*domain.UserPlan, error)
 	RenewUserPlan(ctx context.Context, req *domain.RenewPlanRequest) error
-	CancelUserPlan(ctx context.Context, userID uint) error
+	SetAutoRenew(ctx context.Context, userID uint, enabled bool) error
+	ChangeUserPlan(ctx context.Context, req *domain.ChangePlanRequest) (*domain.PlanChangeResult, error)
+	ActivateUserPlan(ctx context.Context, req *domain.Transition) error
+	SuspendUserPlan(ctx context.Context, req *domain.Transition) error
+	ResumeUserPlan(ctx context.Context, req *domain.Transition) error
+	CancelUserPlan(ctx context.Context, req *domain.Transition) error
+	ScheduleChange(ctx context.Context, req *domain.ScheduleChangeRequest) (*domain.ScheduledChange, error)
+	ListScheduledChanges(ctx context.Context, userID uint) ([]*domain.ScheduledChange, error)
+	RevokeScheduledChange(ctx context.Context, userID, changeID uint) error
 	GetUserPlanHistory(ctx context.Context, userID uint) ([]*domain.UserPlan, error)
+	GetPlanHistory(ctx context.Context, filter *domain.HistoryFilter) ([]*domain.PlanHistory, error)
+	// StartTrial puts the user on the trial of a plan, once per email and oauth ID
+	StartTrial(ctx context.Context, req *domain.StartTrialRequest) error
 
 	CreatePlan(ctx context.Context, plan *domain.Plan) error
 	GetPlanByID(ctx context.Context, id uint) (*domain.Plan, error)
@@ -22,13 +33,26 @@ type Service interface {
 
 	SetPlanPrice(ctx context.Context, planID uint, months int, price int) error
 	GetPlanPrices(ctx context.Context, planID uint) ([]*domain.Price, error)
+	DeletePlanPrice(ctx context.Context, planID uint, months int) error
 
 	CreateLimitation(ctx context.Context, limitation *domain.Limitation) error
-	AssignLimitationToPlan(ctx context.Context, planID, limitationID uint, value int) error
+	GetLimitationByID(ctx context.Context, id uint) (*domain.Limitation, error)
+	ListLimitations(ctx context.Context) ([]*domain.Limitation, error)
+	UpdateLimitation(ctx context.Context, limitation *domain.Limitation) error
+	DeleteLimitation(ctx context.Context, id uint) error
+	AssignLimitationToPlan(ctx context.Context, planLimitation *domain.PlanLimitation) error
+	UpdatePlanLimitation(ctx context.Context, planLimitation *domain.PlanLimitation) error
+	RemoveLimitationFromPlan(ctx context.Context, planID, limitationID uint) error
 	GetPlanLimitations(ctx context.Context, planID uint) ([]*domain.PlanLimitation, error)
 
-	ExpirePlans(ctx context.Context) error
+	// ExpirePlans applies due scheduled changes, converts trials, renews plans and then ends
+	// the plans whose term is over in batches, each committed on its own
+	ExpirePlans(ctx context.Context, opts domain.ExpirationOptions) (*domain.ExpirationResult, error)
+	// ApplyScheduledChanges applies the scheduled changes that became effective, ExpirePlans applies them as well
+	ApplyScheduledChanges(ctx context.Context) error
 	GetExpiringPlans(ctx context.Context, daysThreshold int) ([]*domain.UserPlan, error)
+	// SendExpirationReminders reminds users of expiring plans once per threshold of schedule (days before expiry)
+	SendExpirationReminders(ctx context.Context, schedule []int) (int, error)
 }
 
 type PlanRepository interface {
@@ -40,15 +64,54 @@ type PlanRepository interface {
 	List(ctx context.Context, includeInactive bool) ([]*domain.Plan, error)
 }
 
+// UserPlanRepository persists user plans, every lifecycle change writes a PlanHistory row in the same transaction
 type UserPlanRepository interface {
-	Create(ctx context.Context, userPlan *domain.UserPlan) error
-	GetByUserID(ctx context.Context, userID uint) (*domain.UserPlan, error)
+	// AssignPlan makes userPlan the user's current plan, canceling any live plan it replaces
+	AssignPlan(ctx context.Context, userPlan *domain.UserPlan, change domain.Change) error
+	// StartTrial records trial and assigns userPlan like AssignPlan
+	StartTrial(ctx context.Context, trial *domain.Trial, userPlan *domain.UserPlan, change domain.Change) error
+	// Transition moves userPlan from its current status to status, persisting its term fields along the way
+	Transition(ctx context.Context, userPlan *domain.UserPlan, status string, history *domain.PlanHistory) error
 	GetActiveByUserID(ctx context.Context, userID uint) (*domain.UserPlan, error)
-	Update(ctx context.Context, userPlan *domain.UserPlan) error
+	GetCurrentByUserID(ctx context.Context, userID uint) (*domain.UserPlan, error)
 	GetUserHistory(ctx context.Context, userID uint) ([]*domain.UserPlan, error)
-	ExpirePlans(ctx context.Context) error
+	GetHistory(ctx context.Context, filter *domain.HistoryFilter) ([]*domain.PlanHistory, error)
+	SetAutoRenew(ctx context.Context, userPlanID uint, enabled bool) error
+	// GetDueRenewals returns auto-renewing plans whose term has ended
+	GetDueRenewals(ctx context.Context) ([]*domain.UserPlan, error)
+	// GetDueTrials returns trials whose period has ended
+	GetDueTrials(ctx context.Context) ([]*domain.UserPlan, error)
+	// ExpireBatch ends up to size plans whose term is over, skipping plans locked by another run and the excluded ones
+	ExpireBatch(ctx context.Context, size int, exclude []uint) (*domain.ExpirationResult, error)
+	// ListEnding returns up to size plans after afterID that ExpireBatch would end, except the excluded ones
+	ListEnding(ctx context.Context, afterID uint, size int, exclude []uint) ([]*domain.UserPlan, error)
 	GetExpiringPlans(ctx context.Context, daysThreshold int) ([]*domain.UserPlan, error)
-	SoftDelete(ctx context.Context, id uint) error
+	// RecordReminder stores reminder unless one exists for its plan and threshold, it reports whether it was stored
+	RecordReminder(ctx context.Context, reminder *domain.PlanReminder) (bool, error)
+	DeleteReminder(ctx context.Context, id uint) error
+}
+
+// ReminderSender tells users their plan is about to expire
+type ReminderSender interface {
+	SendReminder(ctx context.Context, reminder *domain.Reminder) error
+}
+
+// PaymentCharger collects payments for plan terms. charging a reference that was already
+// charged returns the receipt of the first charge instead of charging again
+type PaymentCharger interface {
+	Charge(ctx context.Context, charge *domain.Charge) (*domain.Receipt, error)
+}
+
+type ScheduledChangeRepository interface {
+	Create(ctx context.Context, change *domain.ScheduledChange) error
+	GetByID(ctx context.Context, id uint) (*domain.ScheduledChange, error)
+	ListPending(ctx context.Context, userPlanID uint) ([]*domain.ScheduledChange, error)
+	// GetDue returns pending changes whose effective time has passed, oldest first
+	GetDue(ctx context.Context) ([]*domain.ScheduledChange, error)
+	Revoke(ctx context.Context, id uint) error
+	// Apply marks change applied and transitions userPlan to status in one transaction
+	Apply(ctx context.Context, change *domain.ScheduledChange, userPlan *domain.UserPlan, status string, history *domain.PlanHistory) error
+	Fail(ctx context.Context, id uint, reason string) error
 }
 
 type PriceRepository interface {
@@ -2,49 +2,236 @@ package plan
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
 	"time"
 
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/common"
 	planD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/domain"
 	planP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/port"
+	userP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/user/port"
 )
 
+var (
+	ErrInvalidTerm    = errors.New("plan term must be a positive number of months")
+	ErrTermNotPriced  = errors.New("plan has no price for the requested term")
+	ErrSamePlan       = errors.New("user is already on the requested plan")
+	ErrPAYGPlanChange = errors.New("PAYG plans can not be changed mid-term, assign the plan instead")
+	ErrPAYGAutoRenew  = errors.New("PAYG plans never expire and can not auto-renew")
+	ErrChangeNotPaid  = errors.New("the prorated price of the plan change could not be charged")
+
+	ErrInvalidScheduledAction = errors.New("scheduled action must be change or cancel")
+	ErrEffectiveAtRequired    = errors.New("plan never expires, an effective time is required")
+	ErrEffectiveAtPassed      = errors.New("effective time must be in the future")
+	ErrChangeAlreadyScheduled = errors.New("a change is already scheduled for the user plan")
+	ErrScheduledPlanEnded     = errors.New("the scheduled plan is no longer the user's current plan")
+
+	ErrNoTrial       = errors.New("plan does not offer a trial")
+	ErrHasLivePlan   = errors.New("user already has a plan")
+	ErrTrialConflict = errors.New("trial plans can not be changed, assign a paid plan instead")
+)
+
+const DefaultExpirationBatchSize = 500
+
 type service struct {
-	planRepo       planP.PlanRepository
-	userPlanRepo   planP.UserPlanRepository
-	priceRepo      planP.PriceRepository
-	limitationRepo planP.LimitationRepository
+	log                 *zap.Logger
+	planRepo            planP.PlanRepository
+	userPlanRepo        planP.UserPlanRepository
+	priceRepo           planP.PriceRepository
+	limitationRepo      planP.LimitationRepository
+	scheduledChangeRepo planP.ScheduledChangeRepository
+	charger             planP.PaymentCharger
+	reminder            planP.ReminderSender
+	userService         userP.Service
 }
 
 func New(
+	log *zap.Logger,
 	planRepo planP.PlanRepository,
 	userPlanRepo planP.UserPlanRepository,
 	priceRepo planP.PriceRepository,
 	limitationRepo planP.LimitationRepository,
+	scheduledChangeRepo planP.ScheduledChangeRepository,
+	charger planP.PaymentCharger,
+	reminder planP.ReminderSender,
+	userService userP.Service,
 ) planP.Service {
 	return &service{
-		planRepo:       planRepo,
-		userPlanRepo:   userPlanRepo,
-		priceRepo:      priceRepo,
-		limitationRepo: limitationRepo,
+		log:                 log,
+		planRepo:            planRepo,
+		userPlanRepo:        userPlanRepo,
+		priceRepo:           priceRepo,
+		limitationRepo:      limitationRepo,
+		scheduledChangeRepo: scheduledChangeRepo,
+		charger:             charger,
+		reminder:            reminder,
+		userService:         userService,
 	}
 }
 
 func (s *service) AssignPlan(ctx context.Context, req *planD.AssignPlanRequest) error {
-	//soft delete any existing active plan for the user
-	existingPlan, err := s.userPlanRepo.GetActiveByUserID(ctx, req.UserID)
-	if err == nil && existingPlan != nil {
-		if err := s.userPlanRepo.SoftDelete(ctx, existingPlan.ID); err != nil {
-			return err
-		}
+	plan, err := s.planRepo.GetByID(ctx, req.PlanID)
+	if err != nil {
+		return err
 	}
 
 	userPlan := &planD.UserPlan{
 		UserID: req.UserID,
 		PlanID: req.PlanID,
-		ExTime: time.Now().AddDate(0, 1, 0), //default to 1 month, can be customized
+		Status: planD.PlanStatusActive,
+	}
+	//PAYG plans are billed on metered usage and never expire
+	if !plan.PAYG {
+		userPlan.AutoRenew = req.AutoRenew
+		months := req.Months
+		if months == 0 {
+			months = 1
+		}
+		price, err := s.termPrice(ctx, plan.ID, months)
+		if err != nil {
+			return err
+		}
+		userPlan.Months = months
+		userPlan.Price = price.Price
+		userPlan.ExTime = planD.ExpirationForTerm(time.Now(), months)
+	}
+	if req.Pending {
+		userPlan.Status = planD.PlanStatusPending
+		userPlan.ExTime = time.Time{}
+	}
+
+	return s.userPlanRepo.AssignPlan(ctx, userPlan, req.Change)
+}
+
+// StartTrial assigns the trial of a plan to a user without a live plan.
+// a trial is granted once per email and oauth ID so new accounts can not repeat it
+func (s *service) StartTrial(ctx context.Context, req *planD.StartTrialRequest) error {
+	plan, err := s.planRepo.GetByID(ctx, req.PlanID)
+	if err != nil {
+		return err
+	}
+	if plan.TrialDays <= 0 {
+		return ErrNoTrial
+	}
+
+	user, err := s.userService.GetByID(ctx, req.UserID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.userPlanRepo.GetCurrentByUserID(ctx, req.UserID); err == nil {
+		return ErrHasLivePlan
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	now := time.Now()
+	trial := &planD.Trial{
+		UserID:    user.ID,
+		PlanID:    plan.ID,
+		Email:     strings.ToLower(strings.TrimSpace(user.Email)),
+		OauthID:   user.OauthID,
+		StartedAt: now,
+	}
+	userPlan := &planD.UserPlan{
+		UserID: user.ID,
+		PlanID: plan.ID,
+		Status: planD.PlanStatusActive,
+		ExTime: now.AddDate(0, 0, plan.TrialDays),
+		Trial:  true,
+	}
+	return s.userPlanRepo.StartTrial(ctx, trial, userPlan, req.Change)
+}
+
+// convertTrials moves ended trials to the paid plan configured on their plan.
+// trials without one, or whose first charge fails, expire. a trial failing to end is
+// reported and left for the next run, whose charge reuses the reference of this one
+func (s *service) convertTrials(ctx context.Context, run *expirationRun) error {
+	due, err := s.userPlanRepo.GetDueTrials(ctx)
+	if err != nil {
+		return err
 	}
 
-	return s.userPlanRepo.Create(ctx, userPlan)
+	for _, userPlan := range due {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.convertTrial(ctx, run, userPlan); err != nil {
+			s.log.Error("Failed to end trial",
+				zap.Error(err),
+				zap.Uint("user_plan_id", userPlan.ID),
+				zap.Uint("user_id", userPlan.UserID),
+			)
+			run.fail(userPlan.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *service) convertTrial(ctx context.Context, run *expirationRun, userPlan *planD.UserPlan) error {
+	now := time.Now()
+	oldPlanID := userPlan.PlanID
+	history := &planD.PlanHistory{
+		Action:    planD.PlanActionTrialEnd,
+		OldPlanID: &oldPlanID,
+		ChangedBy: planD.ChangedBySystem,
+		Reason:    "trial ended",
+		ChangedAt: now,
+	}
+	expire := func() error {
+		return run.commit(userPlan, planD.PlanStatusExpired, history, func() error {
+			return s.userPlanRepo.Transition(ctx, userPlan, planD.PlanStatusExpired, history)
+		})
+	}
+
+	convertPlanID := userPlan.Plan.TrialConvertPlanID
+	if convertPlanID == nil {
+		return expire()
+	}
+
+	months := userPlan.Plan.TrialConvertMonths
+	if months <= 0 {
+		months = 1
+	}
+	price, err := s.termPrice(ctx, *convertPlanID, months)
+	if err != nil {
+		history.Metadata = common.JSON{"error": err.Error()}
+		return expire()
+	}
+	history.Metadata = common.JSON{"amount": price.Price}
+	//dry runs assume the charge goes through
+	if !run.dryRun {
+		receipt, err := s.charger.Charge(ctx, &planD.Charge{
+			UserID:    userPlan.UserID,
+			PlanID:    *convertPlanID,
+			Months:    months,
+			Amount:    price.Price,
+			Reference: fmt.Sprintf("trial-%d", userPlan.ID),
+		})
+		if err != nil {
+			history.Metadata = common.JSON{"error": err.Error()}
+			return expire()
+		}
+		history.Metadata["transaction_id"] = receipt.TransactionID
+	}
+
+	history.Action = planD.PlanActionConvert
+	history.NewPlanID = convertPlanID
+	history.Reason = "trial converted"
+
+	userPlan.PlanID = *convertPlanID
+	userPlan.Months = months
+	userPlan.Price = price.Price
+	userPlan.ExTime = planD.ExpirationForTerm(now, months)
+	userPlan.LastRenewalAt = &now
+	userPlan.Trial = false
+	return run.commit(userPlan, planD.PlanStatusActive, history, func() error {
+		return s.userPlanRepo.Transition(ctx, userPlan, planD.PlanStatusActive, history)
+	})
 }
 
 func (s *service) GetUserPlan(ctx context.Context, userID uint) (*planD.UserPlan, error) {
@@ -57,23 +244,455 @@ func (s *service) RenewUserPlan(ctx context.Context, req *planD.RenewPlanRequest
 		return err
 	}
 
-	userPlan.ExTime = req.EndDate
-	return s.userPlanRepo.Update(ctx, userPlan)
+	history := &planD.PlanHistory{
+		Action:    planD.PlanActionRenew,
+		OldPlanID: &userPlan.PlanID,
+		NewPlanID: &userPlan.PlanID,
+		ChangedBy: req.By,
+		Reason:    req.Reason,
+		ChangedAt: time.Now(),
+		Metadata:  common.JSON{"previous_ex_time": userPlan.ExTime},
+	}
+
+	if !req.EndDate.IsZero() {
+		userPlan.ExTime = req.EndDate
+		return s.userPlanRepo.Transition(ctx, userPlan, planD.PlanStatusActive, history)
+	}
+
+	//renew for the purchased term at the plan's current price for that term
+	price, err := s.termPrice(ctx, userPlan.PlanID, userPlan.Months)
+	if err != nil {
+		return err
+	}
+	start := userPlan.ExTime
+	if start.Before(time.Now()) {
+		start = time.Now()
+	}
+	userPlan.ExTime = planD.ExpirationForTerm(start, userPlan.Months)
+	userPlan.Price = price.Price
+	return s.userPlanRepo.Transition(ctx, userPlan, planD.PlanStatusActive, history)
 }
 
-func (s *service) CancelUserPlan(ctx context.Context, userID uint) error {
-	userPlan, err := s.userPlanRepo.GetActiveByUserID(ctx, userID)
+func (s *service) SetAutoRenew(ctx context.Context, userID uint, enabled bool) error {
+	userPlan, err := s.userPlanRepo.GetCurrentByUserID(ctx, userID)
 	if err != nil {
 		return err
 	}
+	if enabled && userPlan.Plan.PAYG {
+		return ErrPAYGAutoRenew
+	}
+	return s.userPlanRepo.SetAutoRenew(ctx, userPlan.ID, enabled)
+}
+
+// renewPlans charges auto-renewing plans whose term ended for another term,
+// plans that can not be charged become past due until the grace period ends.
+// a plan failing to renew is reported and left for the next run
+func (s *service) renewPlans(ctx context.Context, run *expirationRun) error {
+	due, err := s.userPlanRepo.GetDueRenewals(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, userPlan := range due {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.renewPlan(ctx, run, userPlan); err != nil {
+			s.log.Error("Failed to renew plan",
+				zap.Error(err),
+				zap.Uint("user_plan_id", userPlan.ID),
+				zap.Uint("user_id", userPlan.UserID),
+			)
+			run.fail(userPlan.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *service) renewPlan(ctx context.Context, run *expirationRun, userPlan *planD.UserPlan) error {
+	now := time.Now()
+	history := &planD.PlanHistory{
+		Action:    planD.PlanActionRenew,
+		OldPlanID: &userPlan.PlanID,
+		NewPlanID: &userPlan.PlanID,
+		ChangedBy: planD.ChangedBySystem,
+		Reason:    "auto-renewal",
+		ChangedAt: now,
+	}
+
+	receipt, err := s.chargeRenewal(ctx, userPlan, run.dryRun)
+	if err != nil {
+		//past due plans are retried on every run until they expire
+		if userPlan.Status == planD.PlanStatusPastDue {
+			return nil
+		}
+		history.Action = planD.PlanActionRenewFail
+		history.Metadata = common.JSON{"error": err.Error()}
+		return run.commit(userPlan, planD.PlanStatusPastDue, history, func() error {
+			return s.userPlanRepo.Transition(ctx, userPlan, planD.PlanStatusPastDue, history)
+		})
+	}
+
+	history.Metadata = common.JSON{"transaction_id": receipt.TransactionID, "amount": userPlan.Price}
+	userPlan.ExTime = planD.ExpirationForTerm(userPlan.ExTime, userPlan.Months)
+	userPlan.LastRenewalAt = &now
+	err = run.commit(userPlan, planD.PlanStatusActive, history, func() error {
+		return s.userPlanRepo.Transition(ctx, userPlan, planD.PlanStatusActive, history)
+	})
+	if err != nil {
+		//the stored term is unchanged, so the next run charges the same reference again
+		//and gets this transaction back instead of a second charge
+		return fmt.Errorf("renewal charged as %s but not recorded: %w", receipt.TransactionID, err)
+	}
+	return nil
+}
+
+// chargeRenewal collects the current price of the user's term for the term after ExTime,
+// dry runs only look the price up. the reference is the same until ExTime moves, so a renewal
+// that failed after the charge is retried without charging twice
+func (s *service) chargeRenewal(ctx context.Context, userPlan *planD.UserPlan, dryRun bool) (*planD.Receipt, error) {
+	price, err := s.termPrice(ctx, userPlan.PlanID, userPlan.Months)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		userPlan.Price = price.Price
+		return &planD.Receipt{}, nil
+	}
+
+	receipt, err := s.charger.Charge(ctx, &planD.Charge{
+		UserID:    userPlan.UserID,
+		PlanID:    userPlan.PlanID,
+		Months:    userPlan.Months,
+		Amount:    price.Price,
+		Reference: fmt.Sprintf("renew-%d-%d", userPlan.ID, userPlan.ExTime.Unix()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	userPlan.Price = price.Price
+	return receipt, nil
+}
+
+func (s *service) ChangeUserPlan(ctx context.Context, req *planD.ChangePlanRequest) (*planD.PlanChangeResult, error) {
+	userPlan, err := s.userPlanRepo.GetActiveByUserID(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if userPlan.Status != planD.PlanStatusActive {
+		return nil, planD.ErrInvalidTransition
+	}
+	target, price, err := s.changeTarget(ctx, userPlan, req.PlanID)
+	if err != nil {
+		return nil, err
+	}
+
+	action := changeAction(userPlan, price)
+	if action == planD.PlanActionDowngrade && req.DeferDowngrade {
+		change := &planD.ScheduledChange{
+			Action:    planD.ScheduledActionChange,
+			PlanID:    &target.ID,
+			ChangedBy: req.By,
+			Reason:    req.Reason,
+		}
+		if err := s.scheduleChange(ctx, userPlan, change); err != nil {
+			return nil, err
+		}
+		return &planD.PlanChangeResult{Action: action, Deferred: true, EffectiveAt: change.EffectiveAt}, nil
+	}
+
+	now := time.Now()
+	result := &planD.PlanChangeResult{
+		Action:      action,
+		Proration:   planD.Prorate(userPlan.Price, price.Price, planD.TermStart(userPlan.ExTime, userPlan.Months), userPlan.ExTime, now),
+		EffectiveAt: now,
+	}
+	oldPlanID := userPlan.PlanID
+	history := &planD.PlanHistory{
+		Action:    action,
+		OldPlanID: &oldPlanID,
+		NewPlanID: &target.ID,
+		ChangedBy: req.By,
+		Reason:    req.Reason,
+		ChangedAt: now,
+		Metadata:  common.JSON{},
+	}
+	if err := s.settleProration(ctx, userPlan, target.ID, result.Proration, false, history); err != nil {
+		return nil, err
+	}
+
+	userPlan.PlanID = target.ID
+	userPlan.Price = price.Price
+	return result, s.userPlanRepo.Transition(ctx, userPlan, planD.PlanStatusActive, history)
+}
+
+// settleProration charges a positive proration of moving userPlan to planID before the move and records
+// a negative one as a credit in history. the charge reference only changes with the plan, so a change
+// retried after a failed transition is charged once
+func (s *service) settleProration(ctx context.Context, userPlan *planD.UserPlan, planID uint, proration int, dryRun bool, history *planD.PlanHistory) error {
+	history.Metadata["proration"] = proration
+	switch {
+	case proration < 0:
+		history.Metadata["credit"] = -proration
+		return nil
+	case proration == 0 || dryRun:
+		return nil
+	}
+
+	receipt, err := s.charger.Charge(ctx, &planD.Charge{
+		UserID:    userPlan.UserID,
+		PlanID:    planID,
+		Months:    userPlan.Months,
+		Amount:    proration,
+		Reference: fmt.Sprintf("change-%d-%d-%d", userPlan.ID, planID, userPlan.UpdatedAt.Unix()),
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrChangeNotPaid, err)
+	}
+	history.Metadata["transaction_id"] = receipt.TransactionID
+	return nil
+}
 
-	return s.userPlanRepo.SoftDelete(ctx, userPlan.ID)
+// changeTarget validates moving userPlan to planID and returns the plan with its price for the user's term
+func (s *service) changeTarget(ctx context.Context, userPlan *planD.UserPlan, planID uint) (*planD.Plan, *planD.Price, error) {
+	if userPlan.Trial {
+		return nil, nil, ErrTrialConflict
+	}
+	if userPlan.PlanID == planID {
+		return nil, nil, ErrSamePlan
+	}
+	target, err := s.planRepo.GetByID(ctx, planID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if userPlan.Plan.PAYG || target.PAYG {
+		return nil, nil, ErrPAYGPlanChange
+	}
+
+	//the user keeps the purchased term, so the new plan must be priced for it
+	price, err := s.termPrice(ctx, target.ID, userPlan.Months)
+	if err != nil {
+		return nil, nil, err
+	}
+	return target, price, nil
+}
+
+func changeAction(userPlan *planD.UserPlan, price *planD.Price) string {
+	if price.Price < userPlan.Price {
+		return planD.PlanActionDowngrade
+	}
+	return planD.PlanActionUpgrade
+}
+
+func (s *service) ScheduleChange(ctx context.Context, req *planD.ScheduleChangeRequest) (*planD.ScheduledChange, error) {
+	userPlan, err := s.userPlanRepo.GetActiveByUserID(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	change := &planD.ScheduledChange{
+		Action:      req.Action,
+		EffectiveAt: req.EffectiveAt,
+		ChangedBy:   req.By,
+		Reason:      req.Reason,
+	}
+	switch req.Action {
+	case planD.ScheduledActionChange:
+		target, _, err := s.changeTarget(ctx, userPlan, req.PlanID)
+		if err != nil {
+			return nil, err
+		}
+		change.PlanID = &target.ID
+	case planD.ScheduledActionCancel:
+	default:
+		return nil, ErrInvalidScheduledAction
+	}
+
+	if err := s.scheduleChange(ctx, userPlan, change); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// scheduleChange stores change for userPlan, defaulting its effective time to the end of the term
+func (s *service) scheduleChange(ctx context.Context, userPlan *planD.UserPlan, change *planD.ScheduledChange) error {
+	if change.EffectiveAt.IsZero() {
+		if userPlan.ExTime.IsZero() {
+			return ErrEffectiveAtRequired
+		}
+		change.EffectiveAt = userPlan.ExTime
+	}
+	if !change.EffectiveAt.After(time.Now()) {
+		return ErrEffectiveAtPassed
+	}
+
+	pending, err := s.scheduledChangeRepo.ListPending(ctx, userPlan.ID)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return ErrChangeAlreadyScheduled
+	}
+
+	change.UserPlanID = userPlan.ID
+	change.Status = planD.ScheduledChangePending
+	return s.scheduledChangeRepo.Create(ctx, change)
+}
+
+func (s *service) ListScheduledChanges(ctx context.Context, userID uint) ([]*planD.ScheduledChange, error) {
+	userPlan, err := s.userPlanRepo.GetCurrentByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.scheduledChangeRepo.ListPending(ctx, userPlan.ID)
+}
+
+func (s *service) RevokeScheduledChange(ctx context.Context, userID, changeID uint) error {
+	change, err := s.scheduledChangeRepo.GetByID(ctx, changeID)
+	if err != nil {
+		return err
+	}
+	if change.UserPlan.UserID != userID {
+		return gorm.ErrRecordNotFound
+	}
+	return s.scheduledChangeRepo.Revoke(ctx, changeID)
+}
+
+// applyScheduledChanges applies every due change, changes that can not be applied are marked failed
+func (s *service) ApplyScheduledChanges(ctx context.Context) error {
+	return s.applyScheduledChanges(ctx, newExpirationRun(false))
+}
+
+func (s *service) applyScheduledChanges(ctx context.Context, run *expirationRun) error {
+	due, err := s.scheduledChangeRepo.GetDue(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range due {
+		if err := s.applyScheduledChange(ctx, run, change); err != nil && !run.dryRun {
+			if err := s.scheduledChangeRepo.Fail(ctx, change.ID, err.Error()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *service) applyScheduledChange(ctx context.Context, run *expirationRun, change *planD.ScheduledChange) error {
+	userPlan, err := s.userPlanRepo.GetCurrentByUserID(ctx, change.UserPlan.UserID)
+	if err != nil {
+		return err
+	}
+	if userPlan.ID != change.UserPlanID {
+		return ErrScheduledPlanEnded
+	}
+
+	oldPlanID := userPlan.PlanID
+	history := &planD.PlanHistory{
+		Action:    planD.PlanActionCancel,
+		OldPlanID: &oldPlanID,
+		ChangedBy: change.ChangedBy,
+		Reason:    change.Reason,
+		ChangedAt: time.Now(),
+		Metadata:  common.JSON{"scheduled_change_id": change.ID},
+	}
+	if change.Action == planD.ScheduledActionCancel {
+		return run.commit(userPlan, planD.PlanStatusCanceled, history, func() error {
+			return s.scheduledChangeRepo.Apply(ctx, change, userPlan, planD.PlanStatusCanceled, history)
+		})
+	}
+
+	if userPlan.Status != planD.PlanStatusActive {
+		return planD.ErrInvalidTransition
+	}
+	target, price, err := s.changeTarget(ctx, userPlan, *change.PlanID)
+	if err != nil {
+		return err
+	}
+	history.Action = changeAction(userPlan, price)
+	history.NewPlanID = &target.ID
+
+	status := planD.PlanStatusActive
+	if userPlan.ExTime.After(change.EffectiveAt) {
+		proration := planD.Prorate(userPlan.Price, price.Price,
+			planD.TermStart(userPlan.ExTime, userPlan.Months), userPlan.ExTime, change.EffectiveAt)
+		if err := s.settleProration(ctx, userPlan, target.ID, proration, run.dryRun, history); err != nil {
+			return err
+		}
+		userPlan.PlanID = target.ID
+		userPlan.Price = price.Price
+	} else {
+		//a change at the end of the term starts a new term on the target plan, charged like a renewal.
+		//a plan that can not be charged moves to the target plan past due
+		userPlan.PlanID = target.ID
+		userPlan.Price = price.Price
+		receipt, err := s.chargeRenewal(ctx, userPlan, run.dryRun)
+		if err != nil {
+			history.Metadata["error"] = err.Error()
+			status = planD.PlanStatusPastDue
+		} else {
+			now := time.Now()
+			history.Metadata["transaction_id"] = receipt.TransactionID
+			history.Metadata["amount"] = userPlan.Price
+			userPlan.ExTime = planD.ExpirationForTerm(userPlan.ExTime, userPlan.Months)
+			userPlan.LastRenewalAt = &now
+		}
+	}
+	return run.commit(userPlan, status, history, func() error {
+		return s.scheduledChangeRepo.Apply(ctx, change, userPlan, status, history)
+	})
+}
+
+// ActivateUserPlan starts the term of a plan assigned as pending
+func (s *service) ActivateUserPlan(ctx context.Context, req *planD.Transition) error {
+	return s.transition(ctx, req, planD.PlanStatusActive, planD.PlanActionActivate)
+}
+
+func (s *service) SuspendUserPlan(ctx context.Context, req *planD.Transition) error {
+	return s.transition(ctx, req, planD.PlanStatusSuspended, planD.PlanActionSuspend)
+}
+
+func (s *service) ResumeUserPlan(ctx context.Context, req *planD.Transition) error {
+	return s.transition(ctx, req, planD.PlanStatusActive, planD.PlanActionResume)
+}
+
+func (s *service) CancelUserPlan(ctx context.Context, req *planD.Transition) error {
+	return s.transition(ctx, req, planD.PlanStatusCanceled, planD.PlanActionCancel)
+}
+
+// transition moves the user's current plan to status, recording action in its history
+func (s *service) transition(ctx context.Context, req *planD.Transition, status, action string) error {
+	userPlan, err := s.userPlanRepo.GetCurrentByUserID(ctx, req.UserID)
+	if err != nil {
+		return err
+	}
+	//resume and activate are only meaningful from their own source status
+	if (action == planD.PlanActionResume && userPlan.Status != planD.PlanStatusSuspended) ||
+		(action == planD.PlanActionActivate && userPlan.Status != planD.PlanStatusPending) {
+		return planD.ErrInvalidTransition
+	}
+	if action == planD.PlanActionActivate && userPlan.Months > 0 {
+		userPlan.ExTime = planD.ExpirationForTerm(time.Now(), userPlan.Months)
+	}
+
+	return s.userPlanRepo.Transition(ctx, userPlan, status, &planD.PlanHistory{
+		Action:    action,
+		OldPlanID: &userPlan.PlanID,
+		ChangedBy: req.By,
+		Reason:    req.Reason,
+		ChangedAt: time.Now(),
+	})
 }
 
 func (s *service) GetUserPlanHistory(ctx context.Context, userID uint) ([]*planD.UserPlan, error) {
 	return s.userPlanRepo.GetUserHistory(ctx, userID)
 }
 
+func (s *service) GetPlanHistory(ctx context.Context, filter *planD.HistoryFilter) ([]*planD.PlanHistory, error) {
+	return s.userPlanRepo.GetHistory(ctx, filter)
+}
+
 func (s *service) CreatePlan(ctx context.Context, plan *planD.Plan) error {
 	return s.planRepo.Create(ctx, plan)
 }
@@ -99,6 +718,9 @@ func (s *service) ListPlans(ctx context.Context, includeInactive bool) ([]*planD
 }
 
 func (s *service) SetPlanPrice(ctx context.Context, planID uint, months int, price int) error {
+	if months <= 0 {
+		return ErrInvalidTerm
+	}
 	existingPrice, err := s.priceRepo.GetByPlanIDAndMonth(ctx, planID, months)
 	if err != nil {
 		newPrice := &planD.Price{
@@ -117,28 +739,257 @@ func (s *service) GetPlanPrices(ctx context.Context, planID uint) ([]*planD.Pric
 	return s.priceRepo.GetByPlanID(ctx, planID)
 }
 
+func (s *service) DeletePlanPrice(ctx context.Context, planID uint, months int) error {
+	return s.priceRepo.Delete(ctx, planID, months)
+}
+
+func (s *service) termPrice(ctx context.Context, planID uint, months int) (*planD.Price, error) {
+	if months <= 0 {
+		return nil, ErrInvalidTerm
+	}
+	price, err := s.priceRepo.GetByPlanIDAndMonth(ctx, planID, months)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTermNotPriced
+	}
+	return price, err
+}
+
 func (s *service) CreateLimitation(ctx context.Context, limitation *planD.Limitation) error {
 	return s.limitationRepo.Create(ctx, limitation)
 }
 
-func (s *service) AssignLimitationToPlan(ctx context.Context, planID, limitationID uint, value int) error {
-	planLimitation := &planD.PlanLimitation{
-		PlanID:       planID,
-		LimitationID: limitationID,
-		Value:        value,
+func (s *service) GetLimitationByID(ctx context.Context, id uint) (*planD.Limitation, error) {
+	return s.limitationRepo.GetByID(ctx, id)
+}
+
+func (s *service) ListLimitations(ctx context.Context) ([]*planD.Limitation, error) {
+	return s.limitationRepo.List(ctx)
+}
+
+func (s *service) UpdateLimitation(ctx context.Context, limitation *planD.Limitation) error {
+	return s.limitationRepo.Update(ctx, limitation)
+}
+
+func (s *service) DeleteLimitation(ctx context.Context, id uint) error {
+	return s.limitationRepo.Delete(ctx, id)
+}
+
+func (s *service) AssignLimitationToPlan(ctx context.Context, planLimitation *planD.PlanLimitation) error {
+	if _, err := s.planRepo.GetByID(ctx, planLimitation.PlanID); err != nil {
+		return err
+	}
+	if _, err := s.limitationRepo.GetByID(ctx, planLimitation.LimitationID); err != nil {
+		return err
 	}
 	return s.limitationRepo.AssignToPlan(ctx, planLimitation)
 }
 
+func (s *service) UpdatePlanLimitation(ctx context.Context, planLimitation *planD.PlanLimitation) error {
+	return s.limitationRepo.UpdatePlanLimitation(ctx, planLimitation)
+}
+
+func (s *service) RemoveLimitationFromPlan(ctx context.Context, planID, limitationID uint) error {
+	return s.limitationRepo.RemoveFromPlan(ctx, planID, limitationID)
+}
+
 func (s *service) GetPlanLimitations(ctx context.Context, planID uint) ([]*planD.PlanLimitation, error) {
 	return s.limitationRepo.GetPlanLimitations(ctx, planID)
 }
 
 // expiration management
-func (s *service) ExpirePlans(ctx context.Context) error {
-	return s.userPlanRepo.ExpirePlans(ctx)
+func (s *service) ExpirePlans(ctx context.Context, opts planD.ExpirationOptions) (*planD.ExpirationResult, error) {
+	run := newExpirationRun(opts.DryRun)
+	run.report = run.report || opts.Report
+	if err := s.applyScheduledChanges(ctx, run); err != nil {
+		return run.result, err
+	}
+	if err := s.convertTrials(ctx, run); err != nil {
+		return run.result, err
+	}
+	if err := s.renewPlans(ctx, run); err != nil {
+		return run.result, err
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultExpirationBatchSize
+	}
+	if run.dryRun {
+		return run.result, s.previewEndingPlans(ctx, run, batchSize)
+	}
+
+	//every batch is committed, so a canceled run keeps the batches it finished
+	result := run.result
+	for {
+		exclude := append(append([]uint(nil), result.FailedIDs...), result.SkippedIDs...)
+		batch, err := s.userPlanRepo.ExpireBatch(ctx, batchSize, exclude)
+		if err != nil {
+			return result, err
+		}
+		if batch.Processed() == 0 {
+			return result, nil
+		}
+		batch.Batches = 1
+		//a run without a report keeps only the counts, however many batches it takes
+		if !run.report {
+			batch.Changes = nil
+		}
+		result.Add(batch)
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+	}
+}
+
+// previewEndingPlans records the plans the batches of a run would end without changing them,
+// except those the earlier steps of the run already changed
+func (s *service) previewEndingPlans(ctx context.Context, run *expirationRun, batchSize int) error {
+	var changed []uint
+	for _, change := range run.result.Changes {
+		changed = append(changed, change.UserPlanID)
+	}
+
+	now := time.Now()
+	var afterID uint
+	for {
+		plans, err := s.userPlanRepo.ListEnding(ctx, afterID, batchSize, changed)
+		if err != nil || len(plans) == 0 {
+			return err
+		}
+
+		for _, userPlan := range plans {
+			status := userPlan.EndStatus(now)
+			history := &planD.PlanHistory{Action: planD.PlanActionExpire, OldPlanID: &userPlan.PlanID, Reason: "term ended"}
+			if status == planD.PlanStatusPastDue {
+				history.Action = planD.PlanActionPastDue
+				run.result.PastDue++
+			} else {
+				if userPlan.Status == planD.PlanStatusPastDue {
+					history.Reason = "grace period ended"
+				}
+				run.result.Expired++
+			}
+			run.result.Changes = append(run.result.Changes, planD.NewStateChange(userPlan, status, history))
+			afterID = userPlan.ID
+		}
+		run.result.Batches++
+	}
+}
+
+// expirationRun collects the plan changes of an expiration run, dry runs only collect them
+type expirationRun struct {
+	dryRun bool
+	report bool // collect the changes in result
+	result *planD.ExpirationResult
+}
+
+func newExpirationRun(dryRun bool) *expirationRun {
+	return &expirationRun{dryRun: dryRun, report: dryRun, result: &planD.ExpirationResult{}}
+}
+
+// fail counts the user plan as failed, or as skipped when someone else changed it first
+func (r *expirationRun) fail(userPlanID uint, err error) {
+	if errors.Is(err, planD.ErrInvalidTransition) {
+		r.result.Skipped++
+		r.result.SkippedIDs = append(r.result.SkippedIDs, userPlanID)
+		return
+	}
+	r.result.Failed++
+	r.result.FailedIDs = append(r.result.FailedIDs, userPlanID)
+}
+
+// commit records moving userPlan to status and makes the change through apply unless the run is dry
+func (r *expirationRun) commit(userPlan *planD.UserPlan, status string, history *planD.PlanHistory, apply func() error) error {
+	change := planD.NewStateChange(userPlan, status, history)
+	if !r.dryRun {
+		if err := apply(); err != nil {
+			return err
+		}
+	}
+	if r.report {
+		r.result.Changes = append(r.result.Changes, change)
+	}
+	return nil
 }
 
 func (s *service) GetExpiringPlans(ctx context.Context, daysThreshold int) ([]*planD.UserPlan, error) {
 	return s.userPlanRepo.GetExpiringPlans(ctx, daysThreshold)
 }
+
+func (s *service) SendExpirationReminders(ctx context.Context, schedule []int) (int, error) {
+	thresholds := append([]int(nil), schedule...)
+	sort.Ints(thresholds)
+
+	sent := 0
+	//a plan gets the reminder of its closest threshold, the farther ones are recorded as skipped
+	reminded := make(map[uint]bool)
+	//a failed reminder is retried on the next run and must not hold back those of other users
+	var errs []error
+	for _, threshold := range thresholds {
+		plans, err := s.userPlanRepo.GetExpiringPlans(ctx, threshold)
+		if err != nil {
+			return sent, errors.Join(append(errs, err)...)
+		}
+
+		for _, userPlan := range plans {
+			if err := ctx.Err(); err != nil {
+				return sent, errors.Join(append(errs, err)...)
+			}
+			ok, err := s.remind(ctx, userPlan, threshold, !reminded[userPlan.ID])
+			if err != nil {
+				s.log.Error("Failed to send expiration reminder",
+					zap.Error(err),
+					zap.Uint("user_plan_id", userPlan.ID),
+					zap.Uint("user_id", userPlan.UserID),
+					zap.Int("threshold", threshold),
+				)
+				errs = append(errs, fmt.Errorf("user plan %d: %w", userPlan.ID, err))
+			}
+			if ok {
+				sent++
+			}
+			reminded[userPlan.ID] = true
+		}
+	}
+	return sent, errors.Join(errs...)
+}
+
+// remind records the reminder of userPlan for threshold and sends it if send is set
+// and the user subscribed to notifications, it reports whether a reminder was sent
+func (s *service) remind(ctx context.Context, userPlan *planD.UserPlan, threshold int, send bool) (bool, error) {
+	user, err := s.userService.GetByID(ctx, userPlan.UserID)
+	if err != nil {
+		return false, err
+	}
+	send = send && user.SubscribeNotifications
+
+	reminder := &planD.PlanReminder{UserPlanID: userPlan.ID, Threshold: threshold}
+	if send {
+		now := time.Now()
+		reminder.SentAt = &now
+	}
+
+	created, err := s.userPlanRepo.RecordReminder(ctx, reminder)
+	if err != nil || !created || !send {
+		return false, err
+	}
+
+	expiresAt := userPlan.GraceEnd()
+	if err := s.reminder.SendReminder(ctx, &planD.Reminder{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Name:      user.Name,
+		Plan:      userPlan.Plan.Title,
+		Status:    userPlan.Status,
+		Threshold: threshold,
+		Days:      int(math.Ceil(time.Until(expiresAt).Hours() / 24)),
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		//dropped so the reminder is retried on the next run
+		if err := s.userPlanRepo.DeleteReminder(ctx, reminder.ID); err != nil {
+			return false, err
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,784 @@
+package plan
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/adapter/payment"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/common"
+	planD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/domain"
+	planP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/port"
+	userD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/user/domain"
+	userP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/user/port"
+)
+
+type fakeUserPlanRepo struct {
+	planP.UserPlanRepository
+	plans    []*planD.UserPlan
+	history  []*planD.PlanHistory
+	current  *planD.UserPlan // the stored live plan of the user
+	assigned []*planD.UserPlan
+	read     func()        // runs after the current plan is read, to change it concurrently
+	broken   map[uint]bool // user plans whose transitions fail
+}
+
+func (r *fakeUserPlanRepo) GetCurrentByUserID(context.Context, uint) (*planD.UserPlan, error) {
+	if r.current == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	userPlan := *r.current
+	if r.read != nil {
+		r.read()
+	}
+	return &userPlan, nil
+}
+
+func (r *fakeUserPlanRepo) GetActiveByUserID(ctx context.Context, userID uint) (*planD.UserPlan, error) {
+	return r.GetCurrentByUserID(ctx, userID)
+}
+
+func (r *fakeUserPlanRepo) AssignPlan(_ context.Context, userPlan *planD.UserPlan, change planD.Change) error {
+	r.assigned = append(r.assigned, userPlan)
+	r.history = append(r.history, &planD.PlanHistory{Action: planD.PlanActionAssign, ToStatus: userPlan.Status,
+		ChangedBy: change.By, Reason: change.Reason})
+	return nil
+}
+
+func (r *fakeUserPlanRepo) GetDueRenewals(context.Context) ([]*planD.UserPlan, error) {
+	return r.plans, nil
+}
+
+func (r *fakeUserPlanRepo) GetDueTrials(context.Context) ([]*planD.UserPlan, error) {
+	return r.plans, nil
+}
+
+func (r *fakeUserPlanRepo) Transition(_ context.Context, userPlan *planD.UserPlan, status string, history *planD.PlanHistory) error {
+	if !planD.CanTransition(userPlan.Status, status) {
+		return planD.ErrInvalidTransition
+	}
+	if r.broken[userPlan.ID] {
+		return errors.New("connection reset")
+	}
+	//like the repository, only update while the stored status is the one read
+	if r.current != nil && r.current.ID == userPlan.ID {
+		if r.current.Status != userPlan.Status {
+			return planD.ErrInvalidTransition
+		}
+		r.current.Status, r.current.ExTime, r.current.PlanID = status, userPlan.ExTime, userPlan.PlanID
+	}
+	history.FromStatus, history.ToStatus = userPlan.Status, status
+	userPlan.Status = status
+	r.history = append(r.history, history)
+	return nil
+}
+
+type fakePriceRepo struct {
+	planP.PriceRepository
+	price *planD.Price
+}
+
+func (r *fakePriceRepo) GetByPlanIDAndMonth(context.Context, uint, int) (*planD.Price, error) {
+	return r.price, nil
+}
+
+func newRenewalService(userPlan *planD.UserPlan, charger planP.PaymentCharger) (*service, *fakeUserPlanRepo) {
+	userPlans := &fakeUserPlanRepo{plans: []*planD.UserPlan{userPlan}}
+	prices := &fakePriceRepo{price: &planD.Price{PlanID: userPlan.PlanID, Month: userPlan.Months, Price: 1200}}
+	return &service{log: zap.NewNop(), userPlanRepo: userPlans, priceRepo: prices, charger: charger}, userPlans
+}
+
+func TestRenewPlans_ChargesSameTerm(t *testing.T) {
+	exTime := planD.CalculateExpirationDate(time.Now(), 0)
+	userPlan := &planD.UserPlan{PlanID: 2, UserID: 7, Status: planD.PlanStatusActive,
+		ExTime: exTime, Months: 3, Price: 1000, AutoRenew: true}
+	charger := payment.NewMemoryCharger()
+	s, repo := newRenewalService(userPlan, charger)
+
+	require.NoError(t, s.renewPlans(context.Background(), newExpirationRun(false)))
+
+	assert.Equal(t, planD.PlanStatusActive, userPlan.Status)
+	assert.Equal(t, planD.ExpirationForTerm(exTime, 3), userPlan.ExTime)
+	assert.Equal(t, 1200, userPlan.Price)
+	assert.NotNil(t, userPlan.LastRenewalAt)
+	assert.Len(t, charger.Charges(), 1)
+	require.Len(t, repo.history, 1)
+	assert.Equal(t, planD.PlanActionRenew, repo.history[0].Action)
+}
+
+func TestRenewPlans_FailedChargeMovesToPastDue(t *testing.T) {
+	exTime := planD.CalculateExpirationDate(time.Now(), 0)
+	userPlan := &planD.UserPlan{PlanID: 2, UserID: 7, Status: planD.PlanStatusActive,
+		ExTime: exTime, Months: 1, Price: 1000, AutoRenew: true}
+	charger := payment.NewMemoryCharger()
+	charger.Decline(7)
+	s, repo := newRenewalService(userPlan, charger)
+
+	require.NoError(t, s.renewPlans(context.Background(), newExpirationRun(false)))
+	assert.Equal(t, planD.PlanStatusPastDue, userPlan.Status)
+	assert.Equal(t, exTime, userPlan.ExTime)
+	require.Len(t, repo.history, 1)
+	assert.Equal(t, planD.PlanActionRenewFail, repo.history[0].Action)
+
+	//a retry that fails again keeps the plan past due without new history
+	require.NoError(t, s.renewPlans(context.Background(), newExpirationRun(false)))
+	assert.Len(t, repo.history, 1)
+}
+
+func TestConvertTrials(t *testing.T) {
+	convertPlanID := uint(3)
+	newTrial := func() *planD.UserPlan {
+		return &planD.UserPlan{PlanID: 2, UserID: 7, Status: planD.PlanStatusActive, Trial: true,
+			ExTime: time.Now(), Plan: planD.Plan{TrialConvertPlanID: &convertPlanID, TrialConvertMonths: 3}}
+	}
+
+	t.Run("converts to the paid plan", func(t *testing.T) {
+		userPlan := newTrial()
+		charger := payment.NewMemoryCharger()
+		s, repo := newRenewalService(userPlan, charger)
+
+		require.NoError(t, s.convertTrials(context.Background(), newExpirationRun(false)))
+		assert.Equal(t, planD.PlanStatusActive, userPlan.Status)
+		assert.False(t, userPlan.Trial)
+		assert.Equal(t, convertPlanID, userPlan.PlanID)
+		assert.Equal(t, 3, userPlan.Months)
+		assert.Equal(t, 1200, userPlan.Price)
+		assert.Len(t, charger.Charges(), 1)
+		require.Len(t, repo.history, 1)
+		assert.Equal(t, planD.PlanActionConvert, repo.history[0].Action)
+	})
+
+	t.Run("expires when the charge fails", func(t *testing.T) {
+		userPlan := newTrial()
+		charger := payment.NewMemoryCharger()
+		charger.Decline(7)
+		s, repo := newRenewalService(userPlan, charger)
+
+		require.NoError(t, s.convertTrials(context.Background(), newExpirationRun(false)))
+		assert.Equal(t, planD.PlanStatusExpired, userPlan.Status)
+		require.Len(t, repo.history, 1)
+		assert.Equal(t, planD.PlanActionTrialEnd, repo.history[0].Action)
+	})
+
+	t.Run("expires without a paid plan", func(t *testing.T) {
+		userPlan := newTrial()
+		userPlan.Plan.TrialConvertPlanID = nil
+		charger := payment.NewMemoryCharger()
+		s, repo := newRenewalService(userPlan, charger)
+
+		require.NoError(t, s.convertTrials(context.Background(), newExpirationRun(false)))
+		assert.Equal(t, planD.PlanStatusExpired, userPlan.Status)
+		assert.Empty(t, charger.Charges())
+		require.Len(t, repo.history, 1)
+		assert.Equal(t, planD.PlanActionTrialEnd, repo.history[0].Action)
+	})
+
+	t.Run("continues after a failed trial", func(t *testing.T) {
+		failing, converted := newTrial(), newTrial()
+		failing.ID, converted.ID, converted.UserID = 1, 2, 8
+		s, repo := newRenewalService(failing, payment.NewMemoryCharger())
+		repo.plans = []*planD.UserPlan{failing, converted}
+		repo.broken = map[uint]bool{1: true}
+
+		run := newExpirationRun(false)
+		require.NoError(t, s.convertTrials(context.Background(), run))
+		assert.Equal(t, 1, run.result.Failed)
+		assert.Equal(t, []uint{1}, run.result.FailedIDs)
+		assert.False(t, converted.Trial)
+		require.Len(t, repo.history, 1)
+		assert.Equal(t, planD.PlanActionConvert, repo.history[0].Action)
+	})
+}
+
+func TestRenewPlans_ContinuesAfterFailure(t *testing.T) {
+	exTime := planD.CalculateExpirationDate(time.Now(), 0)
+	plans := []*planD.UserPlan{
+		{BasicWithSoftDelete: planD.BasicWithSoftDelete{ID: 1}, PlanID: 2, UserID: 7, Status: planD.PlanStatusActive,
+			ExTime: exTime, Months: 1, AutoRenew: true},
+		{BasicWithSoftDelete: planD.BasicWithSoftDelete{ID: 2}, PlanID: 2, UserID: 8, Status: planD.PlanStatusActive,
+			ExTime: exTime, Months: 1, AutoRenew: true},
+	}
+	charger := payment.NewMemoryCharger()
+	s, repo := newRenewalService(plans[0], charger)
+	repo.plans = plans
+	repo.broken = map[uint]bool{1: true}
+
+	run := newExpirationRun(false)
+	require.NoError(t, s.renewPlans(context.Background(), run))
+	assert.Equal(t, 1, run.result.Failed)
+	assert.Equal(t, []uint{1}, run.result.FailedIDs)
+	assert.Equal(t, planD.PlanStatusActive, plans[1].Status)
+	assert.NotNil(t, plans[1].LastRenewalAt)
+	assert.Len(t, charger.Charges(), 2)
+
+	//the next run renews the plan under the same charge
+	plans[0].ExTime, plans[0].LastRenewalAt = exTime, nil
+	repo.plans, repo.broken = plans[:1], nil
+	require.NoError(t, s.renewPlans(context.Background(), newExpirationRun(false)))
+	assert.NotNil(t, plans[0].LastRenewalAt)
+	assert.Len(t, charger.Charges(), 2)
+}
+
+func TestRenewPlans_DryRunOnlyRecords(t *testing.T) {
+	exTime := planD.CalculateExpirationDate(time.Now(), 0)
+	userPlan := &planD.UserPlan{PlanID: 2, UserID: 7, Status: planD.PlanStatusActive,
+		ExTime: exTime, Months: 1, Price: 1000, AutoRenew: true}
+	charger := payment.NewMemoryCharger()
+	s, repo := newRenewalService(userPlan, charger)
+
+	run := newExpirationRun(true)
+	require.NoError(t, s.renewPlans(context.Background(), run))
+
+	assert.Empty(t, charger.Charges())
+	assert.Empty(t, repo.history)
+	require.Len(t, run.result.Changes, 1)
+	change := run.result.Changes[0]
+	assert.Equal(t, planD.PlanActionRenew, change.Action)
+	assert.Equal(t, planD.PlanStatusActive, change.ToStatus)
+	assert.Equal(t, planD.ExpirationForTerm(exTime, 1), change.ExTime)
+}
+
+type fakeReminderRepo struct {
+	planP.UserPlanRepository
+	expiring  map[int][]*planD.UserPlan // plans by threshold
+	reminders map[[2]int]*planD.PlanReminder
+}
+
+func (r *fakeReminderRepo) GetExpiringPlans(_ context.Context, daysThreshold int) ([]*planD.UserPlan, error) {
+	return r.expiring[daysThreshold], nil
+}
+
+func (r *fakeReminderRepo) RecordReminder(_ context.Context, reminder *planD.PlanReminder) (bool, error) {
+	key := [2]int{int(reminder.UserPlanID), reminder.Threshold}
+	if _, ok := r.reminders[key]; ok {
+		return false, nil
+	}
+	reminder.ID = uint(len(r.reminders) + 1)
+	r.reminders[key] = reminder
+	return true, nil
+}
+
+type countingSender struct {
+	sent int
+	fail map[uint]bool // users whose reminders fail
+}
+
+func (s *countingSender) SendReminder(_ context.Context, reminder *planD.Reminder) error {
+	if s.fail[reminder.UserID] {
+		return errors.New("mailbox unavailable")
+	}
+	s.sent++
+	return nil
+}
+
+func (r *fakeReminderRepo) DeleteReminder(_ context.Context, id uint) error {
+	for key, reminder := range r.reminders {
+		if reminder.ID == id {
+			delete(r.reminders, key)
+		}
+	}
+	return nil
+}
+
+type fakeUserService struct {
+	userP.Service
+	unsubscribed map[uint]bool
+}
+
+func (s *fakeUserService) GetByID(_ context.Context, id uint) (*userD.User, error) {
+	return &userD.User{Basic: userD.Basic{ID: id}, SubscribeNotifications: !s.unsubscribed[id]}, nil
+}
+
+func TestSendExpirationReminders_OncePerThreshold(t *testing.T) {
+	soon := &planD.UserPlan{BasicWithSoftDelete: planD.BasicWithSoftDelete{ID: 1}}
+	later := &planD.UserPlan{BasicWithSoftDelete: planD.BasicWithSoftDelete{ID: 2}}
+	repo := &fakeReminderRepo{
+		expiring: map[int][]*planD.UserPlan{
+			1: {soon},
+			7: {soon, later},
+		},
+		reminders: make(map[[2]int]*planD.PlanReminder),
+	}
+	sender := &countingSender{}
+	s := &service{userPlanRepo: repo, reminder: sender, userService: &fakeUserService{}}
+
+	sent, err := s.SendExpirationReminders(context.Background(), []int{7, 1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	//the plan expiring tomorrow only gets its closest reminder
+	assert.Nil(t, repo.reminders[[2]int{1, 7}].SentAt)
+	assert.NotNil(t, repo.reminders[[2]int{1, 1}].SentAt)
+
+	sent, err = s.SendExpirationReminders(context.Background(), []int{7, 1})
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	assert.Equal(t, 2, sender.sent)
+}
+
+func TestSendExpirationReminders_SkipsUnsubscribed(t *testing.T) {
+	userPlan := &planD.UserPlan{BasicWithSoftDelete: planD.BasicWithSoftDelete{ID: 1}, UserID: 5}
+	repo := &fakeReminderRepo{
+		expiring:  map[int][]*planD.UserPlan{3: {userPlan}},
+		reminders: make(map[[2]int]*planD.PlanReminder),
+	}
+	sender := &countingSender{}
+	s := &service{userPlanRepo: repo, reminder: sender,
+		userService: &fakeUserService{unsubscribed: map[uint]bool{5: true}}}
+
+	sent, err := s.SendExpirationReminders(context.Background(), []int{3})
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	assert.Equal(t, 0, sender.sent)
+	assert.Nil(t, repo.reminders[[2]int{1, 3}].SentAt)
+}
+
+func TestSendExpirationReminders_ContinuesAfterFailure(t *testing.T) {
+	failing := &planD.UserPlan{BasicWithSoftDelete: planD.BasicWithSoftDelete{ID: 1}, UserID: 5}
+	other := &planD.UserPlan{BasicWithSoftDelete: planD.BasicWithSoftDelete{ID: 2}, UserID: 6}
+	repo := &fakeReminderRepo{
+		expiring:  map[int][]*planD.UserPlan{3: {failing, other}},
+		reminders: make(map[[2]int]*planD.PlanReminder),
+	}
+	sender := &countingSender{fail: map[uint]bool{5: true}}
+	s := &service{log: zap.NewNop(), userPlanRepo: repo, reminder: sender, userService: &fakeUserService{}}
+
+	sent, err := s.SendExpirationReminders(context.Background(), []int{3})
+	assert.ErrorContains(t, err, "user plan 1: mailbox unavailable")
+	assert.Equal(t, 1, sent, "the next user is still reminded")
+	assert.Equal(t, 1, sender.sent)
+	assert.Nil(t, repo.reminders[[2]int{1, 3}], "the failed reminder is retried on the next run")
+	assert.NotNil(t, repo.reminders[[2]int{2, 3}].SentAt)
+}
+
+// fakeScheduledChangeRepo keeps changes in memory and applies them through userPlans
+type fakeScheduledChangeRepo struct {
+	planP.ScheduledChangeRepository
+	userPlans *fakeUserPlanRepo
+	changes   []*planD.ScheduledChange
+}
+
+func (r *fakeScheduledChangeRepo) Create(_ context.Context, change *planD.ScheduledChange) error {
+	change.ID = uint(len(r.changes) + 1)
+	r.changes = append(r.changes, change)
+	return nil
+}
+
+func (r *fakeScheduledChangeRepo) GetByID(_ context.Context, id uint) (*planD.ScheduledChange, error) {
+	for _, change := range r.changes {
+		if change.ID == id {
+			found := *change
+			if current := r.userPlans.current; current != nil && current.ID == change.UserPlanID {
+				found.UserPlan = *current
+			}
+			return &found, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeScheduledChangeRepo) ListPending(_ context.Context, userPlanID uint) ([]*planD.ScheduledChange, error) {
+	var pending []*planD.ScheduledChange
+	for _, change := range r.changes {
+		if change.UserPlanID == userPlanID && change.Status == planD.ScheduledChangePending {
+			pending = append(pending, change)
+		}
+	}
+	return pending, nil
+}
+
+func (r *fakeScheduledChangeRepo) GetDue(context.Context) ([]*planD.ScheduledChange, error) {
+	var due []*planD.ScheduledChange
+	for _, change := range r.changes {
+		if change.Status == planD.ScheduledChangePending && !change.EffectiveAt.After(time.Now()) {
+			due = append(due, change)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeScheduledChangeRepo) Revoke(_ context.Context, id uint) error {
+	r.changes[id-1].Status = planD.ScheduledChangeRevoked
+	return nil
+}
+
+func (r *fakeScheduledChangeRepo) Apply(ctx context.Context, change *planD.ScheduledChange, userPlan *planD.UserPlan, status string, history *planD.PlanHistory) error {
+	if err := r.userPlans.Transition(ctx, userPlan, status, history); err != nil {
+		return err
+	}
+	change.Status = planD.ScheduledChangeApplied
+	return nil
+}
+
+func (r *fakeScheduledChangeRepo) Fail(_ context.Context, id uint, reason string) error {
+	r.changes[id-1].Status = planD.ScheduledChangeFailed
+	r.changes[id-1].FailureReason = reason
+	return nil
+}
+
+// fakeBatchRepo ends plans from a queue, failing the ids in fail
+type fakeBatchRepo struct {
+	fakeUserPlanRepo
+	queue    []uint
+	fail     map[uint]bool
+	excludes [][]uint
+}
+
+func (r *fakeBatchRepo) ExpireBatch(_ context.Context, size int, exclude []uint) (*planD.ExpirationResult, error) {
+	r.excludes = append(r.excludes, exclude)
+	excluded := make(map[uint]bool)
+	for _, id := range exclude {
+		excluded[id] = true
+	}
+
+	result := &planD.ExpirationResult{}
+	var rest []uint
+	for _, id := range r.queue {
+		switch {
+		case excluded[id] || result.Processed() == size:
+			rest = append(rest, id)
+		case r.fail[id]:
+			result.Failed++
+			result.FailedIDs = append(result.FailedIDs, id)
+			rest = append(rest, id)
+		default:
+			result.Expired++
+			result.Changes = append(result.Changes, &planD.StateChange{UserPlanID: id, Action: planD.PlanActionExpire})
+		}
+	}
+	r.queue = rest
+	return result, nil
+}
+
+// fakePreviewRepo lists the ending plans of a dry run
+type fakePreviewRepo struct {
+	planP.UserPlanRepository
+	renewals []*planD.UserPlan
+	ending   []*planD.UserPlan
+}
+
+func (r *fakePreviewRepo) GetDueRenewals(context.Context) ([]*planD.UserPlan, error) {
+	return r.renewals, nil
+}
+
+func (r *fakePreviewRepo) GetDueTrials(context.Context) ([]*planD.UserPlan, error) {
+	return nil, nil
+}
+
+func (r *fakePreviewRepo) ListEnding(_ context.Context, afterID uint, size int, exclude []uint) ([]*planD.UserPlan, error) {
+	var plans []*planD.UserPlan
+	for _, userPlan := range r.ending {
+		if userPlan.ID > afterID && !slices.Contains(exclude, userPlan.ID) && len(plans) < size {
+			plans = append(plans, userPlan)
+		}
+	}
+	return plans, nil
+}
+
+func TestExpirePlans_DryRunListsEachPlanOnce(t *testing.T) {
+	ended := planD.CalculateExpirationDate(time.Now(), -1)
+	renewing := &planD.UserPlan{BasicWithSoftDelete: planD.BasicWithSoftDelete{ID: 1}, PlanID: 2, UserID: 7,
+		Status: planD.PlanStatusActive, ExTime: ended, Months: 1, AutoRenew: true}
+	expiring := &planD.UserPlan{BasicWithSoftDelete: planD.BasicWithSoftDelete{ID: 2}, PlanID: 2, UserID: 8,
+		Status: planD.PlanStatusActive, ExTime: ended, Months: 1}
+	graceEnded := &planD.UserPlan{BasicWithSoftDelete: planD.BasicWithSoftDelete{ID: 3}, PlanID: 2, UserID: 9,
+		Status: planD.PlanStatusPastDue, ExTime: ended, Months: 1}
+	repo := &fakePreviewRepo{
+		renewals: []*planD.UserPlan{renewing},
+		ending:   []*planD.UserPlan{renewing, expiring, graceEnded},
+	}
+	prices := &fakePriceRepo{price: &planD.Price{PlanID: 2, Month: 1, Price: 1200}}
+	s := &service{userPlanRepo: repo, priceRepo: prices, scheduledChangeRepo: &fakeScheduledChangeRepo{}}
+
+	result, err := s.ExpirePlans(context.Background(), planD.ExpirationOptions{BatchSize: 2, DryRun: true})
+	require.NoError(t, err)
+	actions := make(map[uint]string)
+	for _, change := range result.Changes {
+		assert.NotContains(t, actions, change.UserPlanID, "plan %d is listed twice", change.UserPlanID)
+		actions[change.UserPlanID] = change.Action
+	}
+	assert.Equal(t, map[uint]string{
+		1: planD.PlanActionRenew,
+		2: planD.PlanActionExpire,
+		3: planD.PlanActionExpire,
+	}, actions)
+	assert.Equal(t, 2, result.Expired)
+}
+
+func TestExpirePlans_ProcessesInBatches(t *testing.T) {
+	repo := &fakeBatchRepo{queue: []uint{1, 2, 3, 4, 5}, fail: map[uint]bool{2: true}}
+	s := &service{userPlanRepo: repo, scheduledChangeRepo: &fakeScheduledChangeRepo{}}
+
+	result, err := s.ExpirePlans(context.Background(), planD.ExpirationOptions{BatchSize: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.Expired)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, []uint{2}, result.FailedIDs)
+	assert.Equal(t, 3, result.Batches)
+	//the failed plan stays queued for the next run but is not retried in this one
+	assert.Equal(t, []uint{2}, repo.queue)
+	assert.Equal(t, []uint{2}, repo.excludes[len(repo.excludes)-1])
+	assert.Empty(t, result.Changes, "runs without a report keep only the counts")
+
+	repo.queue, repo.fail = []uint{6, 7, 8}, nil
+	result, err = s.ExpirePlans(context.Background(), planD.ExpirationOptions{BatchSize: 2, Report: true})
+	require.NoError(t, err)
+	assert.Len(t, result.Changes, 3)
+}
+
+type fakePlanRepo struct {
+	planP.PlanRepository
+	plan *planD.Plan
+}
+
+func (r *fakePlanRepo) GetByID(context.Context, uint) (*planD.Plan, error) {
+	return r.plan, nil
+}
+
+func TestAssignPlan_Pending(t *testing.T) {
+	repo := &fakeUserPlanRepo{}
+	prices := &fakePriceRepo{price: &planD.Price{PlanID: 2, Month: 3, Price: 1200}}
+	s := &service{userPlanRepo: repo, priceRepo: prices, planRepo: &fakePlanRepo{plan: &planD.Plan{BasicID: planD.BasicID{ID: 2}}}}
+
+	require.NoError(t, s.AssignPlan(context.Background(), &planD.AssignPlanRequest{UserID: 7, PlanID: 2, Months: 3, Pending: true}))
+	require.Len(t, repo.assigned, 1)
+	userPlan := repo.assigned[0]
+	assert.Equal(t, planD.PlanStatusPending, userPlan.Status)
+	assert.True(t, userPlan.ExTime.IsZero(), "the term starts on activation")
+	assert.Equal(t, 3, userPlan.Months)
+	assert.Equal(t, 1200, userPlan.Price)
+
+	repo.current = userPlan
+	before := time.Now()
+	require.NoError(t, s.ActivateUserPlan(context.Background(), &planD.Transition{UserID: 7, Change: planD.Change{By: "admin"}}))
+	assert.Equal(t, planD.PlanStatusActive, repo.current.Status)
+	assert.False(t, repo.current.ExTime.Before(planD.ExpirationForTerm(before, 3)))
+	require.Len(t, repo.history, 2)
+	activated := repo.history[1]
+	assert.Equal(t, planD.PlanActionActivate, activated.Action)
+	assert.Equal(t, planD.PlanStatusPending, activated.FromStatus)
+	assert.Equal(t, planD.PlanStatusActive, activated.ToStatus)
+	assert.Equal(t, "admin", activated.ChangedBy)
+}
+
+func TestTransition(t *testing.T) {
+	tests := []struct {
+		from    string
+		do      func(s *service, ctx context.Context, req *planD.Transition) error
+		action  string
+		to      string
+		allowed bool
+	}{
+		{planD.PlanStatusActive, (*service).SuspendUserPlan, planD.PlanActionSuspend, planD.PlanStatusSuspended, true},
+		{planD.PlanStatusSuspended, (*service).ResumeUserPlan, planD.PlanActionResume, planD.PlanStatusActive, true},
+		{planD.PlanStatusPastDue, (*service).CancelUserPlan, planD.PlanActionCancel, planD.PlanStatusCanceled, true},
+		{planD.PlanStatusPending, (*service).CancelUserPlan, planD.PlanActionCancel, planD.PlanStatusCanceled, true},
+		{planD.PlanStatusActive, (*service).ResumeUserPlan, planD.PlanActionResume, planD.PlanStatusActive, false},
+		{planD.PlanStatusActive, (*service).ActivateUserPlan, planD.PlanActionActivate, planD.PlanStatusActive, false},
+		{planD.PlanStatusPending, (*service).SuspendUserPlan, planD.PlanActionSuspend, planD.PlanStatusSuspended, false},
+		{planD.PlanStatusPastDue, (*service).SuspendUserPlan, planD.PlanActionSuspend, planD.PlanStatusSuspended, false},
+	}
+	for _, tt := range tests {
+		name := tt.from + " " + tt.action
+		repo := &fakeUserPlanRepo{current: &planD.UserPlan{BasicWithSoftDelete: planD.BasicWithSoftDelete{ID: 1},
+			PlanID: 2, UserID: 7, Status: tt.from}}
+		s := &service{userPlanRepo: repo}
+
+		err := tt.do(s, context.Background(), &planD.Transition{UserID: 7, Change: planD.Change{By: "admin", Reason: "test"}})
+		if !tt.allowed {
+			assert.ErrorIs(t, err, planD.ErrInvalidTransition, name)
+			assert.Equal(t, tt.from, repo.current.Status, name)
+			assert.Empty(t, repo.history, name)
+			continue
+		}
+		require.NoError(t, err, name)
+		assert.Equal(t, tt.to, repo.current.Status, name)
+		require.Len(t, repo.history, 1, name)
+		assert.Equal(t, tt.action, repo.history[0].Action, name)
+		assert.Equal(t, tt.from, repo.history[0].FromStatus, name)
+		assert.Equal(t, tt.to, repo.history[0].ToStatus, name)
+		assert.Equal(t, "test", repo.history[0].Reason, name)
+	}
+}
+
+func TestTransition_ConcurrentChangeRejected(t *testing.T) {
+	repo := &fakeUserPlanRepo{current: &planD.UserPlan{BasicWithSoftDelete: planD.BasicWithSoftDelete{ID: 1},
+		PlanID: 2, UserID: 7, Status: planD.PlanStatusActive}}
+	repo.read = func() { repo.current.Status = planD.PlanStatusCanceled }
+	s := &service{userPlanRepo: repo}
+
+	err := s.SuspendUserPlan(context.Background(), &planD.Transition{UserID: 7})
+	assert.ErrorIs(t, err, planD.ErrInvalidTransition)
+	assert.Equal(t, planD.PlanStatusCanceled, repo.current.Status, "the concurrent cancel is kept")
+	assert.Empty(t, repo.history)
+}
+
+func TestChangeUserPlan_Proration(t *testing.T) {
+	exTime := planD.CalculateExpirationDate(time.Now(), 15)
+	tests := []struct {
+		name    string
+		price   int
+		decline bool
+		action  string
+		charged bool
+	}{
+		{"upgrade", 3000, false, planD.PlanActionUpgrade, true},
+		{"declined upgrade", 3000, true, planD.PlanActionUpgrade, false},
+		{"downgrade", 500, false, planD.PlanActionDowngrade, false},
+	}
+	for _, tt := range tests {
+		repo := &fakeUserPlanRepo{current: &planD.UserPlan{BasicWithSoftDelete: planD.BasicWithSoftDelete{ID: 1},
+			PlanID: 2, UserID: 7, Status: planD.PlanStatusActive, ExTime: exTime, Months: 1, Price: 1000}}
+		prices := &fakePriceRepo{price: &planD.Price{PlanID: 3, Month: 1, Price: tt.price}}
+		charger := payment.NewMemoryCharger()
+		if tt.decline {
+			charger.Decline(7)
+		}
+		s := &service{userPlanRepo: repo, priceRepo: prices, charger: charger,
+			planRepo: &fakePlanRepo{plan: &planD.Plan{BasicID: planD.BasicID{ID: 3}}}}
+
+		result, err := s.ChangeUserPlan(context.Background(), &planD.ChangePlanRequest{UserID: 7, PlanID: 3, Change: planD.Change{By: "admin"}})
+		if tt.decline {
+			assert.ErrorIs(t, err, ErrChangeNotPaid, tt.name)
+			assert.Equal(t, uint(2), repo.current.PlanID, "%s: the plan is not switched", tt.name)
+			assert.Empty(t, repo.history, tt.name)
+			continue
+		}
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.action, result.Action, tt.name)
+		require.Len(t, repo.history, 1, tt.name)
+		metadata := repo.history[0].Metadata
+		assert.Equal(t, result.Proration, metadata["proration"], tt.name)
+		if tt.charged {
+			assert.Positive(t, result.Proration, tt.name)
+			require.Len(t, charger.Charges(), 1, tt.name)
+			for _, charge := range charger.Charges() {
+				assert.Equal(t, result.Proration, charge.Amount, tt.name)
+			}
+			assert.NotEmpty(t, metadata["transaction_id"], tt.name)
+			assert.Equal(t, uint(3), repo.current.PlanID, tt.name)
+			continue
+		}
+		assert.Negative(t, result.Proration, tt.name)
+		assert.Empty(t, charger.Charges(), tt.name)
+		assert.Equal(t, -result.Proration, metadata["credit"], tt.name)
+		assert.Equal(t, uint(3), repo.current.PlanID, tt.name)
+	}
+}
+
+// newChangeService serves the active plan 2 of user 7 ending at exTime, with plan 3 priced at price
+func newChangeService(exTime time.Time, price int) (*service, *fakeUserPlanRepo, *fakeScheduledChangeRepo) {
+	userPlans := &fakeUserPlanRepo{current: &planD.UserPlan{BasicWithSoftDelete: planD.BasicWithSoftDelete{ID: 1},
+		PlanID: 2, UserID: 7, Status: planD.PlanStatusActive, ExTime: exTime, Months: 1, Price: 1000}}
+	changes := &fakeScheduledChangeRepo{userPlans: userPlans}
+	prices := &fakePriceRepo{price: &planD.Price{PlanID: 3, Month: 1, Price: price}}
+	s := &service{log: zap.NewNop(), userPlanRepo: userPlans, priceRepo: prices, scheduledChangeRepo: changes,
+		charger: payment.NewMemoryCharger(), planRepo: &fakePlanRepo{plan: &planD.Plan{BasicID: planD.BasicID{ID: 3}}}}
+	return s, userPlans, changes
+}
+
+func TestScheduleChange(t *testing.T) {
+	exTime := planD.CalculateExpirationDate(time.Now(), 10)
+	s, _, changes := newChangeService(exTime, 500)
+	ctx := context.Background()
+
+	_, err := s.ScheduleChange(ctx, &planD.ScheduleChangeRequest{UserID: 7, Action: planD.ScheduledActionCancel,
+		EffectiveAt: time.Now().Add(-time.Hour)})
+	assert.ErrorIs(t, err, ErrEffectiveAtPassed)
+
+	change, err := s.ScheduleChange(ctx, &planD.ScheduleChangeRequest{UserID: 7, Action: planD.ScheduledActionChange, PlanID: 3})
+	require.NoError(t, err)
+	assert.Equal(t, exTime, change.EffectiveAt, "changes default to the end of the term")
+	assert.Equal(t, planD.ScheduledChangePending, change.Status)
+	assert.Equal(t, uint(1), change.UserPlanID)
+
+	_, err = s.ScheduleChange(ctx, &planD.ScheduleChangeRequest{UserID: 7, Action: planD.ScheduledActionCancel})
+	assert.ErrorIs(t, err, ErrChangeAlreadyScheduled)
+
+	assert.ErrorIs(t, s.RevokeScheduledChange(ctx, 8, change.ID), gorm.ErrRecordNotFound, "only the plan's user can revoke it")
+	require.NoError(t, s.RevokeScheduledChange(ctx, 7, change.ID))
+	pending, err := s.ListScheduledChanges(ctx, 7)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+	assert.Equal(t, planD.ScheduledChangeRevoked, changes.changes[0].Status)
+}
+
+func TestApplyScheduledChanges_TermEnd(t *testing.T) {
+	exTime := time.Now().Add(-time.Minute).Truncate(time.Second)
+	s, userPlans, changes := newChangeService(exTime, 500)
+	target := uint(3)
+	changes.changes = []*planD.ScheduledChange{{BaseModel: common.BaseModel{ID: 1}, UserPlanID: 1,
+		UserPlan: *userPlans.current, Action: planD.ScheduledActionChange, PlanID: &target,
+		EffectiveAt: exTime, Status: planD.ScheduledChangePending}}
+
+	require.NoError(t, s.ApplyScheduledChanges(context.Background()))
+	assert.Equal(t, planD.ScheduledChangeApplied, changes.changes[0].Status)
+	assert.Equal(t, uint(3), userPlans.current.PlanID)
+	assert.Equal(t, planD.PlanStatusActive, userPlans.current.Status)
+	assert.Equal(t, planD.ExpirationForTerm(exTime, 1), userPlans.current.ExTime, "a new term starts on the target plan")
+	require.Len(t, userPlans.history, 1)
+	assert.Equal(t, planD.PlanActionDowngrade, userPlans.history[0].Action)
+	assert.NotContains(t, userPlans.history[0].Metadata, "proration")
+	charges := s.charger.(*payment.MemoryCharger).Charges()
+	require.Len(t, charges, 1, "the new term is charged")
+	for _, charge := range charges {
+		assert.Equal(t, 500, charge.Amount)
+		assert.Equal(t, uint(3), charge.PlanID)
+	}
+}
+
+func TestApplyScheduledChanges_TermEndDeclined(t *testing.T) {
+	exTime := time.Now().Add(-time.Minute).Truncate(time.Second)
+	s, userPlans, changes := newChangeService(exTime, 500)
+	s.charger.(*payment.MemoryCharger).Decline(7)
+	target := uint(3)
+	changes.changes = []*planD.ScheduledChange{{BaseModel: common.BaseModel{ID: 1}, UserPlanID: 1,
+		UserPlan: *userPlans.current, Action: planD.ScheduledActionChange, PlanID: &target,
+		EffectiveAt: exTime, Status: planD.ScheduledChangePending}}
+
+	require.NoError(t, s.ApplyScheduledChanges(context.Background()))
+	assert.Equal(t, planD.ScheduledChangeApplied, changes.changes[0].Status)
+	assert.Equal(t, uint(3), userPlans.current.PlanID)
+	assert.Equal(t, planD.PlanStatusPastDue, userPlans.current.Status)
+	assert.Equal(t, exTime, userPlans.current.ExTime, "no term is given without a charge")
+	require.Len(t, userPlans.history, 1)
+	assert.Contains(t, userPlans.history[0].Metadata, "error")
+}
+
+func TestApplyScheduledChanges_MidTerm(t *testing.T) {
+	exTime := planD.CalculateExpirationDate(time.Now(), 15)
+	s, userPlans, changes := newChangeService(exTime, 500)
+	target := uint(3)
+	changes.changes = []*planD.ScheduledChange{{BaseModel: common.BaseModel{ID: 1}, UserPlanID: 1,
+		UserPlan: *userPlans.current, Action: planD.ScheduledActionChange, PlanID: &target,
+		EffectiveAt: time.Now().Add(-time.Minute), Status: planD.ScheduledChangePending}}
+
+	require.NoError(t, s.ApplyScheduledChanges(context.Background()))
+	assert.Equal(t, planD.ScheduledChangeApplied, changes.changes[0].Status)
+	assert.Equal(t, uint(3), userPlans.current.PlanID)
+	assert.Equal(t, exTime, userPlans.current.ExTime, "the term is kept")
+	require.Len(t, userPlans.history, 1)
+	assert.Negative(t, userPlans.history[0].Metadata["proration"])
+	assert.Contains(t, userPlans.history[0].Metadata, "credit")
+	assert.Empty(t, s.charger.(*payment.MemoryCharger).Charges())
+}
+
+func TestApplyScheduledChanges_FailureMarked(t *testing.T) {
+	s, userPlans, changes := newChangeService(planD.CalculateExpirationDate(time.Now(), 15), 500)
+	ended := *userPlans.current
+	ended.ID = 9
+	changes.changes = []*planD.ScheduledChange{{BaseModel: common.BaseModel{ID: 1}, UserPlanID: ended.ID,
+		UserPlan: ended, Action: planD.ScheduledActionCancel,
+		EffectiveAt: time.Now().Add(-time.Minute), Status: planD.ScheduledChangePending}}
+
+	require.NoError(t, s.ApplyScheduledChanges(context.Background()))
+	assert.Equal(t, planD.ScheduledChangeFailed, changes.changes[0].Status)
+	assert.Equal(t, ErrScheduledPlanEnded.Error(), changes.changes[0].FailureReason)
+	assert.Equal(t, planD.PlanStatusActive, userPlans.current.Status, "the user's current plan is left alone")
+	assert.Empty(t, userPlans.history)
+}
@@ -0,0 +1,89 @@
+package domain
+
+import "time"
+
+// Usage is the consumption counter of a single limitation for a user within one billing period.
+// Counters are never reset in place; a new period simply starts a new row.
+type Usage struct {
+	ID          uint      `gorm:"primarykey"`
+	UserID      uint      `gorm:"not null;uniqueIndex:idx_usage_user_limitation_period"`
+	Limitation  string    `gorm:"not null;uniqueIndex:idx_usage_user_limitation_period"` // Limitation.Title
+	PeriodStart time.Time `gorm:"not null;uniqueIndex:idx_usage_user_limitation_period"`
+	PeriodEnd   time.Time `gorm:"not null"`
+	Used        int64     `gorm:"not null;default:0"`
+	UpdatedAt   time.Time
+}
+
+// Quota is the state of a user's limitation for the current billing period
+type Quota struct {
+	Limitation  string
+	Limit       int64
+	Used        int64
+	Allowed     bool
+	Metered     bool // PAYG usage is billed instead of capped
+	PastDue     bool // the plan's term ended and it is in its grace period
+	Trial       bool // limits are the trial overrides of the plan
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+}
+
+func (q *Quota) Remaining() int64 {
+	if q.Used >= q.Limit {
+		return 0
+	}
+	return q.Limit - q.Used
+}
+
+// DTOs
+type QuotaRequest struct {
+	UserID     uint
+	Limitation string
+	Amount     int64
+}
+
+// Statement is the bill of a PAYG user for one billing period
+type Statement struct {
+	UserID      uint
+	PlanID      uint
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Lines       []StatementLine
+	Total       int64
+}
+
+type StatementLine struct {
+	Limitation string
+	Units      int64
+	UnitPrice  int64
+	Amount     int64
+}
+
+type StatementRequest struct {
+	UserID uint
+	Period time.Time // any time within the requested period, zero for the current one
+}
+
+// BillingPeriod returns the monthly period containing now, anchored on the given time.
+// Fixed plans anchor on their expiration time and PAYG plans on their start time,
+// so a plan anchored on the 15th bills from the 15th of one month to the 15th of the next.
+func BillingPeriod(anchor, now time.Time) (start, end time.Time) {
+	if anchor.After(now) {
+		end = anchor
+		for {
+			start = end.AddDate(0, -1, 0)
+			if !start.After(now) {
+				return start, end
+			}
+			end = start
+		}
+	}
+
+	start = anchor
+	for {
+		end = start.AddDate(0, 1, 0)
+		if end.After(now) {
+			return start, end
+		}
+		start = end
+	}
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBillingPeriod_CurrentMonth(t *testing.T) {
+	exTime := time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	start, end := BillingPeriod(exTime, now)
+	assert.Equal(t, time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, exTime, end)
+}
+
+func TestBillingPeriod_EarlierMonth(t *testing.T) {
+	exTime := time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC)
+
+	start, end := BillingPeriod(exTime, now)
+	assert.Equal(t, time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2025, 4, 15, 0, 0, 0, 0, time.UTC), end)
+}
+
+func TestBillingPeriod_PeriodBoundary(t *testing.T) {
+	exTime := time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	start, _ := BillingPeriod(exTime, now)
+	assert.Equal(t, now, start)
+}
+
+func TestQuotaRemaining(t *testing.T) {
+	assert.Equal(t, int64(3), (&Quota{Limit: 10, Used: 7}).Remaining())
+	assert.Equal(t, int64(0), (&Quota{Limit: 10, Used: 12}).Remaining())
+}
+
+func TestBillingPeriod_AnchorInPast(t *testing.T) {
+	startedAt := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 3, 25, 0, 0, 0, 0, time.UTC)
+
+	start, end := BillingPeriod(startedAt, now)
+	assert.Equal(t, time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2025, 4, 10, 9, 0, 0, 0, time.UTC), end)
+}
@@ -0,0 +1,26 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/usage/domain"
+)
+
+type Service interface {
+	CheckQuota(ctx context.Context, req *domain.QuotaRequest) (*domain.Quota, error)
+	ConsumeQuota(ctx context.Context, req *domain.QuotaRequest) (*domain.Quota, error)
+	GetUsage(ctx context.Context, userID uint) ([]*domain.Quota, error)
+	GetUsageStatement(ctx context.Context, req *domain.StatementRequest) (*domain.Statement, error)
+}
+
+type Repo interface {
+	Get(ctx context.Context, userID uint, limitation string, periodStart time.Time) (*domain.Usage, error)
+	ListByPeriod(ctx context.Context, userID uint, periodStart time.Time) ([]*domain.Usage, error)
+	// Consume atomically adds amount to the counter as long as the total stays within limit.
+	// It reports false and leaves the counter untouched when the limit would be exceeded,
+	// otherwise usage.Used is set to the new total.
+	Consume(ctx context.Context, usage *domain.Usage, amount, limit int64) (bool, error)
+	// Add atomically adds amount to the counter without any limit and sets usage.Used to the new total.
+	Add(ctx context.Context, usage *domain.Usage, amount int64) error
+}
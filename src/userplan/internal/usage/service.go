@@ -0,0 +1,239 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	planD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/domain"
+	planP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/port"
+	usageD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/usage/domain"
+	usageP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/usage/port"
+)
+
+var (
+	ErrNoActivePlan         = errors.New("user has no active plan")
+	ErrLimitationNotInPlan  = errors.New("limitation is not part of the user's plan")
+	ErrInvalidConsumeAmount = errors.New("consume amount must be positive")
+	ErrPlanNotPAYG          = errors.New("usage statements are only available for PAYG plans")
+)
+
+type service struct {
+	repo        usageP.Repo
+	planService planP.Service
+}
+
+func New(r usageP.Repo, planService planP.Service) usageP.Service {
+	return &service{repo: r, planService: planService}
+}
+
+func (s *service) CheckQuota(ctx context.Context, req *usageD.QuotaRequest) (*usageD.Quota, error) {
+	userPlan, err := s.activePlan(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	quota, err := s.quota(ctx, userPlan, req.Limitation)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := req.Amount
+	if amount <= 0 {
+		amount = 1
+	}
+	quota.Allowed = quota.Metered || quota.Used+amount <= quota.Limit
+	return quota, nil
+}
+
+func (s *service) ConsumeQuota(ctx context.Context, req *usageD.QuotaRequest) (*usageD.Quota, error) {
+	if req.Amount <= 0 {
+		return nil, ErrInvalidConsumeAmount
+	}
+	userPlan, err := s.activePlan(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	quota, err := s.quota(ctx, userPlan, req.Limitation)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &usageD.Usage{
+		UserID:      req.UserID,
+		Limitation:  req.Limitation,
+		PeriodStart: quota.PeriodStart,
+		PeriodEnd:   quota.PeriodEnd,
+	}
+
+	//metered usage is accumulated for billing instead of being capped
+	if quota.Metered {
+		if err := s.repo.Add(ctx, usage, req.Amount); err != nil {
+			return nil, err
+		}
+		quota.Allowed = true
+		quota.Used = usage.Used
+		return quota, nil
+	}
+
+	ok, err := s.repo.Consume(ctx, usage, req.Amount, quota.Limit)
+	if err != nil {
+		return nil, err
+	}
+	quota.Allowed = ok
+	if ok {
+		quota.Used = usage.Used
+	}
+	return quota, nil
+}
+
+func (s *service) GetUsage(ctx context.Context, userID uint) ([]*usageD.Quota, error) {
+	userPlan, err := s.activePlan(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	planLimitations, err := s.planService.GetPlanLimitations(ctx, userPlan.PlanID)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := billingPeriod(userPlan, time.Now())
+	used, err := s.usedByLimitation(ctx, userID, start)
+	if err != nil {
+		return nil, err
+	}
+
+	quotas := make([]*usageD.Quota, 0, len(planLimitations))
+	for _, pl := range planLimitations {
+		q := &usageD.Quota{
+			Limitation:  pl.Limitation.Title,
+			Limit:       int64(pl.Limit(userPlan.Trial)),
+			Used:        used[pl.Limitation.Title],
+			Metered:     userPlan.Plan.PAYG,
+			PastDue:     userPlan.Status == planD.PlanStatusPastDue,
+			Trial:       userPlan.Trial,
+			PeriodStart: start,
+			PeriodEnd:   end,
+		}
+		q.Allowed = q.Metered || q.Used < q.Limit
+		quotas = append(quotas, q)
+	}
+	return quotas, nil
+}
+
+func (s *service) GetUsageStatement(ctx context.Context, req *usageD.StatementRequest) (*usageD.Statement, error) {
+	userPlan, err := s.activePlan(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !userPlan.Plan.PAYG {
+		return nil, ErrPlanNotPAYG
+	}
+	planLimitations, err := s.planService.GetPlanLimitations(ctx, userPlan.PlanID)
+	if err != nil {
+		return nil, err
+	}
+
+	period := req.Period
+	if period.IsZero() {
+		period = time.Now()
+	}
+	start, end := billingPeriod(userPlan, period)
+	used, err := s.usedByLimitation(ctx, req.UserID, start)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &usageD.Statement{
+		UserID:      req.UserID,
+		PlanID:      userPlan.PlanID,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Lines:       make([]usageD.StatementLine, 0, len(planLimitations)),
+	}
+	for _, pl := range planLimitations {
+		line := usageD.StatementLine{
+			Limitation: pl.Limitation.Title,
+			Units:      used[pl.Limitation.Title],
+			UnitPrice:  int64(pl.UnitPrice),
+		}
+		line.Amount = line.Units * line.UnitPrice
+		statement.Lines = append(statement.Lines, line)
+		statement.Total += line.Amount
+	}
+	return statement, nil
+}
+
+func (s *service) activePlan(ctx context.Context, userID uint) (*planD.UserPlan, error) {
+	userPlan, err := s.planService.GetUserPlan(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNoActivePlan
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !userPlan.Entitled() {
+		return nil, ErrNoActivePlan
+	}
+	return userPlan, nil
+}
+
+// builds the quota of a limitation for the current billing period without checking it
+func (s *service) quota(ctx context.Context, userPlan *planD.UserPlan, limitation string) (*usageD.Quota, error) {
+	planLimitations, err := s.planService.GetPlanLimitations(ctx, userPlan.PlanID)
+	if err != nil {
+		return nil, err
+	}
+
+	var planLimitation *planD.PlanLimitation
+	for _, pl := range planLimitations {
+		if pl.Limitation.Title == limitation {
+			planLimitation = pl
+			break
+		}
+	}
+	if planLimitation == nil {
+		return nil, ErrLimitationNotInPlan
+	}
+
+	start, end := billingPeriod(userPlan, time.Now())
+	quota := &usageD.Quota{
+		Limitation:  limitation,
+		Limit:       int64(planLimitation.Limit(userPlan.Trial)),
+		Metered:     userPlan.Plan.PAYG,
+		PastDue:     userPlan.Status == planD.PlanStatusPastDue,
+		Trial:       userPlan.Trial,
+		PeriodStart: start,
+		PeriodEnd:   end,
+	}
+
+	usage, err := s.repo.Get(ctx, userPlan.UserID, limitation, start)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+	case err != nil:
+		return nil, err
+	default:
+		quota.Used = usage.Used
+	}
+	return quota, nil
+}
+
+func (s *service) usedByLimitation(ctx context.Context, userID uint, periodStart time.Time) (map[string]int64, error) {
+	usages, err := s.repo.ListByPeriod(ctx, userID, periodStart)
+	if err != nil {
+		return nil, err
+	}
+	used := make(map[string]int64, len(usages))
+	for _, u := range usages {
+		used[u.Limitation] = u.Used
+	}
+	return used, nil
+}
+
+// PAYG plans have no expiration, so their periods are anchored on the day they started
+func billingPeriod(userPlan *planD.UserPlan, now time.Time) (time.Time, time.Time) {
+	if userPlan.Plan.PAYG || userPlan.ExTime.IsZero() {
+		return usageD.BillingPeriod(userPlan.CreatedAt, now)
+	}
+	return usageD.BillingPeriod(userPlan.ExTime, now)
+}
@@ -0,0 +1,86 @@
+package usage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	planD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/domain"
+	planP "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/plan/port"
+	usageD "hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/internal/usage/domain"
+)
+
+type fakePlanService struct {
+	planP.Service
+	userPlan *planD.UserPlan
+}
+
+func (s *fakePlanService) GetUserPlan(context.Context, uint) (*planD.UserPlan, error) {
+	return s.userPlan, nil
+}
+
+func (s *fakePlanService) GetPlanLimitations(context.Context, uint) ([]*planD.PlanLimitation, error) {
+	return []*planD.PlanLimitation{{Limitation: planD.Limitation{Title: "requests"}, Value: 10}}, nil
+}
+
+type fakeRepo struct {
+	used int64
+}
+
+func (r *fakeRepo) Get(context.Context, uint, string, time.Time) (*usageD.Usage, error) {
+	if r.used == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &usageD.Usage{Used: r.used}, nil
+}
+
+func (r *fakeRepo) ListByPeriod(context.Context, uint, time.Time) ([]*usageD.Usage, error) {
+	return []*usageD.Usage{{Limitation: "requests", Used: r.used}}, nil
+}
+
+func (r *fakeRepo) Consume(_ context.Context, usage *usageD.Usage, amount, limit int64) (bool, error) {
+	if r.used+amount > limit {
+		return false, nil
+	}
+	r.used += amount
+	usage.Used = r.used
+	return true, nil
+}
+
+func (r *fakeRepo) Add(_ context.Context, usage *usageD.Usage, amount int64) error {
+	r.used += amount
+	usage.Used = r.used
+	return nil
+}
+
+func TestQuota_PastDue(t *testing.T) {
+	ctx := context.Background()
+	userPlan := &planD.UserPlan{PlanID: 1, UserID: 7, Status: planD.PlanStatusPastDue, ExTime: time.Now().AddDate(0, 0, -2)}
+	repo := &fakeRepo{used: 9}
+	s := New(repo, &fakePlanService{userPlan: userPlan})
+	req := &usageD.QuotaRequest{UserID: 7, Limitation: "requests", Amount: 1}
+
+	quota, err := s.CheckQuota(ctx, req)
+	require.NoError(t, err, "past due plans keep their limitations during the grace period")
+	assert.True(t, quota.PastDue)
+	assert.True(t, quota.Allowed)
+
+	quota, err = s.ConsumeQuota(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, quota.Allowed)
+	quota, err = s.ConsumeQuota(ctx, req)
+	require.NoError(t, err)
+	assert.False(t, quota.Allowed, "limits are still enforced")
+
+	quotas, err := s.GetUsage(ctx, 7)
+	require.NoError(t, err)
+	require.Len(t, quotas, 1)
+	assert.True(t, quotas[0].PastDue)
+
+	userPlan.Status = planD.PlanStatusExpired
+	_, err = s.CheckQuota(ctx, req)
+	assert.ErrorIs(t, err, ErrNoActivePlan)
+}
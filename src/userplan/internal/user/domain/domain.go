@@ -29,8 +29,12 @@ type UserFilter struct {
 	Name   string
 	Email  string
 	Phone  string
+	Page   int // 1-based, defaults to the first page
+	Size   int // defaults to DefaultPageSize
 }
 
+const DefaultPageSize = 20
+
 type PaginatedUsers struct {
 	Users             []*User
 	Page, Size, Total int64
@@ -16,8 +16,17 @@ func New(r userP.Repo) userP.Service {
 }
 
 func (s *service) ListUsers(ctx context.Context, uf *userD.UserFilter) (*userD.PaginatedUsers, error) {
-	const defaultLimit = 20
-	return s.repo.List(ctx, uf, defaultLimit, 0)
+	if uf == nil {
+		uf = &userD.UserFilter{}
+	}
+	size, page := uf.Size, uf.Page
+	if size <= 0 {
+		size = userD.DefaultPageSize
+	}
+	if page <= 0 {
+		page = 1
+	}
+	return s.repo.List(ctx, uf, size, (page-1)*size)
 }
 
 func (s *service) CreateUser(ctx context.Context, u *userD.User) error {
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS usages;
+DROP TABLE IF EXISTS plan_reminders;
+DROP TABLE IF EXISTS trials;
+DROP TABLE IF EXISTS scheduled_changes;
+DROP TABLE IF EXISTS plan_histories;
+DROP TABLE IF EXISTS user_plans;
+DROP TABLE IF EXISTS plan_limitations;
+DROP TABLE IF EXISTS limitations;
+DROP TABLE IF EXISTS prices;
+DROP TABLE IF EXISTS plans;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,173 @@
+-- baseline schema, tables and indexes are created only when missing so databases
+-- created by the former AutoMigrate start from this version. their tables lack the
+-- columns added since, which are added and backfilled after each table.
+
+CREATE TABLE IF NOT EXISTS users (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    oauth_id text,
+    email text NOT NULL,
+    name text NOT NULL,
+    password text,
+    phone text,
+    company_name text,
+    job_title text,
+    active boolean NOT NULL DEFAULT false,
+    subscribe_news boolean NOT NULL DEFAULT true,
+    subscribe_notifications boolean NOT NULL DEFAULT true
+);
+CREATE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_oauth_id ON users (oauth_id);
+
+CREATE TABLE IF NOT EXISTS plans (
+    id bigserial PRIMARY KEY,
+    title text NOT NULL,
+    custom boolean NOT NULL DEFAULT true,
+    payg boolean NOT NULL DEFAULT false,
+    grace_period_days bigint NOT NULL DEFAULT 0,
+    trial_days bigint NOT NULL DEFAULT 0,
+    trial_convert_plan_id bigint,
+    trial_convert_months bigint NOT NULL DEFAULT 1
+);
+ALTER TABLE plans ADD COLUMN IF NOT EXISTS grace_period_days bigint NOT NULL DEFAULT 0;
+ALTER TABLE plans ADD COLUMN IF NOT EXISTS trial_days bigint NOT NULL DEFAULT 0;
+ALTER TABLE plans ADD COLUMN IF NOT EXISTS trial_convert_plan_id bigint;
+ALTER TABLE plans ADD COLUMN IF NOT EXISTS trial_convert_months bigint NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS prices (
+    plan_id bigint NOT NULL REFERENCES plans (id) ON DELETE CASCADE,
+    month bigint NOT NULL,
+    price bigint NOT NULL DEFAULT 1000000,
+    PRIMARY KEY (plan_id, month)
+);
+
+CREATE TABLE IF NOT EXISTS limitations (
+    id bigserial PRIMARY KEY,
+    title text NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS plan_limitations (
+    plan_id bigint NOT NULL REFERENCES plans (id) ON DELETE CASCADE,
+    limitation_id bigint NOT NULL REFERENCES limitations (id) ON DELETE CASCADE,
+    value bigint DEFAULT 1,
+    unit_price bigint NOT NULL DEFAULT 0,
+    trial_value bigint,
+    PRIMARY KEY (plan_id, limitation_id)
+);
+ALTER TABLE plan_limitations ADD COLUMN IF NOT EXISTS unit_price bigint NOT NULL DEFAULT 0;
+ALTER TABLE plan_limitations ADD COLUMN IF NOT EXISTS trial_value bigint;
+
+CREATE TABLE IF NOT EXISTS user_plans (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    plan_id bigint NOT NULL REFERENCES plans (id),
+    user_id bigint NOT NULL REFERENCES users (id),
+    status varchar(20) NOT NULL DEFAULT 'active',
+    ex_time timestamptz,
+    months bigint,
+    price bigint,
+    auto_renew boolean NOT NULL DEFAULT false,
+    last_renewal_at timestamptz,
+    trial boolean NOT NULL DEFAULT false
+);
+ALTER TABLE user_plans ADD COLUMN IF NOT EXISTS status varchar(20) NOT NULL DEFAULT 'active';
+ALTER TABLE user_plans ADD COLUMN IF NOT EXISTS months bigint;
+ALTER TABLE user_plans ADD COLUMN IF NOT EXISTS price bigint;
+ALTER TABLE user_plans ADD COLUMN IF NOT EXISTS auto_renew boolean NOT NULL DEFAULT false;
+ALTER TABLE user_plans ADD COLUMN IF NOT EXISTS last_renewal_at timestamptz;
+ALTER TABLE user_plans ADD COLUMN IF NOT EXISTS trial boolean NOT NULL DEFAULT false;
+-- AutoMigrate assigned every plan for one month, renewals and plan changes need the term and its price
+UPDATE user_plans SET months = 1 WHERE months IS NULL OR months <= 0;
+UPDATE user_plans SET price = COALESCE(
+    (SELECT prices.price FROM prices WHERE prices.plan_id = user_plans.plan_id AND prices.month = user_plans.months), 0)
+WHERE price IS NULL;
+-- AutoMigrate ended plans by soft deleting them, when they expired or were replaced
+UPDATE user_plans SET status = CASE WHEN ex_time <= deleted_at THEN 'expired' ELSE 'canceled' END
+WHERE deleted_at IS NOT NULL AND status = 'active';
+-- AutoMigrate keyed user_plans on (id, plan_id, user_id), which scheduled_changes can not reference
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_index WHERE indrelid = 'user_plans'::regclass AND indisprimary AND indnatts > 1) THEN
+        ALTER TABLE user_plans DROP CONSTRAINT user_plans_pkey;
+        ALTER TABLE user_plans ADD PRIMARY KEY (id);
+    END IF;
+END $$;
+CREATE INDEX IF NOT EXISTS idx_user_plans_status ON user_plans (status);
+CREATE INDEX IF NOT EXISTS idx_user_plans_user_id ON user_plans (user_id);
+CREATE INDEX IF NOT EXISTS idx_user_plans_plan_id ON user_plans (plan_id);
+CREATE INDEX IF NOT EXISTS idx_user_plans_ex_time ON user_plans (ex_time);
+CREATE INDEX IF NOT EXISTS idx_user_plans_deleted_at ON user_plans (deleted_at);
+
+CREATE TABLE IF NOT EXISTS plan_histories (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_plan_id bigint,
+    action varchar(50),
+    from_status varchar(20),
+    to_status varchar(20),
+    old_plan_id bigint,
+    new_plan_id bigint,
+    changed_by varchar(255),
+    reason varchar(500),
+    changed_at timestamptz,
+    metadata json
+);
+CREATE INDEX IF NOT EXISTS idx_plan_histories_user_plan_id ON plan_histories (user_plan_id);
+CREATE INDEX IF NOT EXISTS idx_plan_histories_deleted_at ON plan_histories (deleted_at);
+
+CREATE TABLE IF NOT EXISTS scheduled_changes (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_plan_id bigint NOT NULL REFERENCES user_plans (id),
+    action varchar(20) NOT NULL,
+    plan_id bigint,
+    effective_at timestamptz NOT NULL,
+    status varchar(20) NOT NULL DEFAULT 'pending',
+    changed_by varchar(255),
+    reason varchar(500),
+    applied_at timestamptz,
+    failure_reason varchar(500)
+);
+CREATE INDEX IF NOT EXISTS idx_scheduled_changes_status ON scheduled_changes (status);
+CREATE INDEX IF NOT EXISTS idx_scheduled_changes_effective_at ON scheduled_changes (effective_at);
+CREATE INDEX IF NOT EXISTS idx_scheduled_changes_user_plan_id ON scheduled_changes (user_plan_id);
+CREATE INDEX IF NOT EXISTS idx_scheduled_changes_deleted_at ON scheduled_changes (deleted_at);
+
+CREATE TABLE IF NOT EXISTS trials (
+    id bigserial PRIMARY KEY,
+    user_id bigint,
+    plan_id bigint NOT NULL,
+    email text,
+    oauth_id text,
+    started_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_trials_user_id ON trials (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_trials_email ON trials (email) WHERE email <> '';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_trials_oauth_id ON trials (oauth_id) WHERE oauth_id <> '';
+
+CREATE TABLE IF NOT EXISTS plan_reminders (
+    id bigserial PRIMARY KEY,
+    user_plan_id bigint,
+    threshold bigint,
+    sent_at timestamptz,
+    created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_plan_reminder ON plan_reminders (user_plan_id, threshold);
+
+CREATE TABLE IF NOT EXISTS usages (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    limitation text NOT NULL,
+    period_start timestamptz NOT NULL,
+    period_end timestamptz NOT NULL,
+    used bigint NOT NULL DEFAULT 0,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_usage_user_limitation_period ON usages (user_id, limitation, period_start);
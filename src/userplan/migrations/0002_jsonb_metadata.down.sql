@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_plan_histories_metadata;
+ALTER TABLE plan_histories ALTER COLUMN metadata TYPE json USING metadata::json;
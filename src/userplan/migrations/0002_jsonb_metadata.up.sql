@@ -0,0 +1,2 @@
+ALTER TABLE plan_histories ALTER COLUMN metadata TYPE jsonb USING metadata::jsonb;
+CREATE INDEX IF NOT EXISTS idx_plan_histories_metadata ON plan_histories USING gin (metadata);
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"hash/fnv"
+
+	"gorm.io/gorm"
+)
+
+// AdvisoryLocker elects a single holder of a named lock across replicas
+// sharing a database through postgres session advisory locks
+type AdvisoryLocker struct {
+	db *gorm.DB
+}
+
+func NewAdvisoryLocker(db *gorm.DB) *AdvisoryLocker {
+	return &AdvisoryLocker{db: db}
+}
+
+// TryLock takes the lock of name without waiting. when acquired the returned unlock
+// releases it, the lock is also released if the connection holding it is lost
+func (l *AdvisoryLocker) TryLock(ctx context.Context, name string) (acquired bool, unlock func(), err error) {
+	sqlDB, err := l.db.DB()
+	if err != nil {
+		return false, nil, err
+	}
+	//session locks belong to a connection, so the lock is held on a dedicated one
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return false, nil, err
+	}
+
+	key := lockKey(name)
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&acquired); err != nil {
+		conn.Close()
+		return false, nil, err
+	}
+	if !acquired {
+		conn.Close()
+		return false, nil, nil
+	}
+
+	return true, func() {
+		conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key)
+		conn.Close()
+	}, nil
+}
+
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	expirationPlans = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "userplan",
+		Name:      "expiration_plans_total",
+		Help:      "User plans processed by expiration runs by result.",
+	}, []string{"result"})
+
+	expirationDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "userplan",
+		Name:      "expiration_run_duration_seconds",
+		Help:      "Duration of expiration runs.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 4, 8),
+	})
+
+	expirationLastRun = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "userplan",
+		Name:      "expiration_last_run_timestamp_seconds",
+		Help:      "Unix time the last expiration run finished.",
+	})
+)
+
+// ObserveExpiration records the counts of an expiration run
+func ObserveExpiration(expired, pastDue, skipped, failed int, duration time.Duration) {
+	expirationPlans.WithLabelValues("expired").Add(float64(expired))
+	expirationPlans.WithLabelValues("past_due").Add(float64(pastDue))
+	expirationPlans.WithLabelValues("skipped").Add(float64(skipped))
+	expirationPlans.WithLabelValues("failed").Add(float64(failed))
+	expirationDuration.Observe(duration.Seconds())
+	expirationLastRun.SetToCurrentTime()
+}
+
+// NewServer returns a server exposing the metrics on /metrics at addr
+func NewServer(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	return &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+}
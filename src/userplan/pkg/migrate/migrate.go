@@ -0,0 +1,318 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Table records the applied migrations
+const Table = "schema_migrations"
+
+var (
+	ErrInvalidName = errors.New("migration file name must be <version>_<name>.up.sql or <version>_<name>.down.sql")
+	ErrDuplicate   = errors.New("duplicate migration")
+	ErrMissingUp   = errors.New("migration has no up file")
+	ErrNoDown      = errors.New("migration can not be rolled back")
+	ErrSchemaDrift = errors.New("database schema does not match the migrations")
+)
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is one version of the schema, Down undoes Up
+type Migration struct {
+	Version  uint64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // of Up, an applied migration must not change
+}
+
+// Status is a migration known to the binary, the database or both
+type Status struct {
+	Version   uint64
+	Name      string
+	AppliedAt *time.Time
+	Known     bool // the binary has the migration
+	Modified  bool // applied with another checksum
+}
+
+// Load reads the migrations of fsys ordered by version, files that are not .sql are ignored
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[uint64]*Migration{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sql") {
+			continue
+		}
+		match := fileName.FindStringSubmatch(e.Name())
+		if match == nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidName, e.Name())
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidName, e.Name())
+		}
+		b, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("%w: version %d is named %s and %s", ErrDuplicate, version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(b)
+		} else {
+			m.Down = string(b)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" {
+			return nil, fmt.Errorf("%w: %d_%s", ErrMissingUp, m.Version, m.Name)
+		}
+		sum := sha256.Sum256([]byte(m.Up))
+		m.Checksum = hex.EncodeToString(sum[:])
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator applies migrations to a postgres database, runs of several instances are serialized by an advisory lock
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	log        *zap.Logger
+}
+
+func New(db *sql.DB, migrations []Migration, log *zap.Logger) *Migrator {
+	return &Migrator{db: db, migrations: migrations, log: log}
+}
+
+type applied struct {
+	checksum  string
+	name      string
+	appliedAt time.Time
+}
+
+// Up applies the pending migrations in order, each in its own transaction
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	count := 0
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		done, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(done, false); err != nil {
+			return err
+		}
+		for _, mg := range m.migrations {
+			if _, ok := done[mg.Version]; ok {
+				continue
+			}
+			err := m.run(ctx, conn, mg.Up,
+				`INSERT INTO `+Table+` (version, name, checksum, applied_at) VALUES ($1, $2, $3, $4)`,
+				mg.Version, mg.Name, mg.Checksum, time.Now())
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", mg.Version, mg.Name, err)
+			}
+			m.log.Info("migration applied", zap.Uint64("version", mg.Version), zap.String("name", mg.Name))
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Down rolls back the last steps applied migrations
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	count := 0
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		done, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(done, false); err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+			mg := m.migrations[i]
+			if _, ok := done[mg.Version]; !ok {
+				continue
+			}
+			if strings.TrimSpace(mg.Down) == "" {
+				return fmt.Errorf("%w: %d_%s", ErrNoDown, mg.Version, mg.Name)
+			}
+			err := m.run(ctx, conn, mg.Down, `DELETE FROM `+Table+` WHERE version = $1`, mg.Version)
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", mg.Version, mg.Name, err)
+			}
+			m.log.Info("migration rolled back", zap.Uint64("version", mg.Version), zap.String("name", mg.Name))
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Status lists the migrations of the binary and the database by version
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	done, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []Status
+	for _, mg := range m.migrations {
+		s := Status{Version: mg.Version, Name: mg.Name, Known: true}
+		if a, ok := done[mg.Version]; ok {
+			s.AppliedAt = &a.appliedAt
+			s.Modified = a.checksum != mg.Checksum
+			delete(done, mg.Version)
+		}
+		statuses = append(statuses, s)
+	}
+	for version, a := range done {
+		statuses = append(statuses, Status{Version: version, Name: a.name, AppliedAt: &a.appliedAt})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// Check returns ErrSchemaDrift unless exactly the migrations of the binary are applied
+func (m *Migrator) Check(ctx context.Context) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	done, err := m.applied(ctx, conn)
+	if err != nil {
+		return err
+	}
+	return m.verify(done, true)
+}
+
+// verify reports applied migrations the binary does not have or that changed since,
+// and the pending ones when pending is set
+func (m *Migrator) verify(done map[uint64]applied, pending bool) error {
+	known := make(map[uint64]bool, len(m.migrations))
+	var problems []string
+	for _, mg := range m.migrations {
+		known[mg.Version] = true
+		a, ok := done[mg.Version]
+		switch {
+		case !ok && pending:
+			problems = append(problems, fmt.Sprintf("%d_%s is not applied", mg.Version, mg.Name))
+		case ok && a.checksum != mg.Checksum:
+			problems = append(problems, fmt.Sprintf("%d_%s changed after it was applied", mg.Version, mg.Name))
+		}
+	}
+	for version, a := range done {
+		if !known[version] {
+			problems = append(problems, fmt.Sprintf("%d_%s is applied but unknown to this version", version, a.name))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrSchemaDrift, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// locked runs fn on a connection holding the migration lock, creating the migrations table first
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock(hashtext($1))`, Table); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock(hashtext($1))`, Table)
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+Table+` (
+		version bigint PRIMARY KEY,
+		name text NOT NULL,
+		checksum text NOT NULL,
+		applied_at timestamptz NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// applied returns the applied migrations by version, none when the migrations table is missing
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[uint64]applied, error) {
+	done := map[uint64]applied{}
+	var exists bool
+	if err := conn.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, Table).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return done, nil
+	}
+
+	rows, err := conn.QueryContext(ctx, `SELECT version, name, checksum, applied_at FROM `+Table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var version uint64
+		var a applied
+		if err := rows.Scan(&version, &a.name, &a.checksum, &a.appliedAt); err != nil {
+			return nil, err
+		}
+		done[version] = a
+	}
+	return done, rows.Err()
+}
+
+// run executes script and records it with the record statement in one transaction
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, script, record string, args ...any) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
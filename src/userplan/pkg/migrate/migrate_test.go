@@ -0,0 +1,167 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"hamgit.ir/arcaptcha/arcaptcha-dumbledore/userplan/migrations"
+)
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load(fstest.MapFS{
+		"0002_add_index.up.sql": {Data: []byte("CREATE INDEX i ON t (c);")},
+		"0001_init.up.sql":      {Data: []byte("CREATE TABLE t (c int);")},
+		"0001_init.down.sql":    {Data: []byte("DROP TABLE t;")},
+		"migrations.go":         {Data: []byte("package migrations")},
+	})
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+	assert.Equal(t, uint64(1), migrations[0].Version)
+	assert.Equal(t, "init", migrations[0].Name)
+	assert.Equal(t, "DROP TABLE t;", migrations[0].Down)
+	assert.NotEmpty(t, migrations[0].Checksum)
+	assert.Equal(t, uint64(2), migrations[1].Version)
+	assert.Empty(t, migrations[1].Down)
+
+	_, err = Load(fstest.MapFS{"init.up.sql": {}})
+	assert.ErrorIs(t, err, ErrInvalidName)
+	_, err = Load(fstest.MapFS{"0001_init.down.sql": {Data: []byte("DROP TABLE t;")}})
+	assert.ErrorIs(t, err, ErrMissingUp)
+	_, err = Load(fstest.MapFS{"0001_a.up.sql": {Data: []byte("SELECT 1;")}, "0001_b.up.sql": {Data: []byte("SELECT 1;")}})
+	assert.ErrorIs(t, err, ErrDuplicate)
+}
+
+func TestLoad_Embedded(t *testing.T) {
+	all, err := Load(migrations.FS)
+	require.NoError(t, err)
+	require.NotEmpty(t, all)
+	for _, m := range all {
+		assert.NotEmpty(t, m.Down, "%d_%s has no down migration", m.Version, m.Name)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	all, err := Load(fstest.MapFS{
+		"0001_init.up.sql": {Data: []byte("CREATE TABLE t (c int);")},
+		"0002_more.up.sql": {Data: []byte("CREATE TABLE u (c int);")},
+	})
+	require.NoError(t, err)
+	m := New(nil, all, zap.NewNop())
+
+	done := map[uint64]applied{1: {checksum: all[0].Checksum, name: "init"}}
+	assert.NoError(t, m.verify(done, false))
+	assert.ErrorIs(t, m.verify(done, true), ErrSchemaDrift)
+
+	done[2] = applied{checksum: all[1].Checksum, name: "more"}
+	assert.NoError(t, m.verify(done, true))
+
+	done[1] = applied{checksum: "edited", name: "init"}
+	assert.ErrorIs(t, m.verify(done, false), ErrSchemaDrift)
+
+	done[1] = applied{checksum: all[0].Checksum, name: "init"}
+	done[3] = applied{checksum: "x", name: "newer"}
+	assert.ErrorIs(t, m.verify(done, false), ErrSchemaDrift)
+}
+
+// testDB connects to TEST_POSTGRES_DSN, a local postgres by default, in a schema of its own
+// that is dropped after the test, skipping the test when postgres is unavailable
+func testDB(t *testing.T) *sql.DB {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		dsn = "host=localhost port=5432 user=postgres password=postgres dbname=postgres sslmode=disable connect_timeout=2"
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Skipf("postgres unavailable: %v", err)
+	}
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	//the search path is set per connection
+	sqlDB.SetMaxOpenConns(1)
+
+	schema := fmt.Sprintf("migrate_test_%d", time.Now().UnixNano())
+	_, err = sqlDB.Exec(`CREATE SCHEMA ` + schema + `; SET search_path TO ` + schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB.Exec(`DROP SCHEMA ` + schema + ` CASCADE`)
+		sqlDB.Close()
+	})
+	return sqlDB
+}
+
+// legacySchema is the schema AutoMigrate created before the migrations, with a plan
+// assigned, one expired and one replaced
+const legacySchema = `
+CREATE TABLE users (id bigserial PRIMARY KEY, created_at timestamptz, updated_at timestamptz, oauth_id text,
+    email text NOT NULL, name text NOT NULL, password text, phone text, company_name text, job_title text,
+    active boolean NOT NULL DEFAULT false, subscribe_news boolean NOT NULL DEFAULT true,
+    subscribe_notifications boolean NOT NULL DEFAULT true);
+CREATE TABLE plans (id bigserial PRIMARY KEY, title text NOT NULL, custom boolean NOT NULL DEFAULT true,
+    payg boolean NOT NULL DEFAULT false);
+CREATE TABLE prices (plan_id bigint, month bigint, price bigint NOT NULL DEFAULT 1000000, PRIMARY KEY (plan_id, month));
+CREATE TABLE limitations (id bigserial PRIMARY KEY, title text NOT NULL UNIQUE);
+CREATE TABLE plan_limitations (plan_id bigint, limitation_id bigint, value bigint DEFAULT 1,
+    PRIMARY KEY (plan_id, limitation_id));
+CREATE TABLE user_plans (id bigserial, created_at timestamptz, updated_at timestamptz, deleted_at timestamptz,
+    plan_id bigint, user_id bigint, ex_time timestamptz, PRIMARY KEY (id, plan_id, user_id));
+
+INSERT INTO users (id, email, name) VALUES (1, 'a@example.com', 'A');
+INSERT INTO plans (id, title) VALUES (1, 'Pro'), (2, 'Custom');
+INSERT INTO prices (plan_id, month, price) VALUES (1, 1, 5000);
+INSERT INTO plan_limitations (plan_id, limitation_id) VALUES (1, 1);
+INSERT INTO user_plans (id, plan_id, user_id, ex_time, deleted_at) VALUES
+    (1, 1, 1, now() - interval '40 days', now() - interval '10 days'),
+    (2, 2, 1, now() + interval '20 days', now() - interval '5 days'),
+    (3, 1, 1, now() + interval '25 days', NULL);
+`
+
+func TestUp_LegacySchema(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	_, err := db.ExecContext(ctx, legacySchema)
+	require.NoError(t, err)
+
+	all, err := Load(migrations.FS)
+	require.NoError(t, err)
+	m := New(db, all, zap.NewNop())
+	_, err = m.Up(ctx)
+	require.NoError(t, err, "databases created by AutoMigrate are upgraded")
+	require.NoError(t, m.Check(ctx))
+
+	type row struct {
+		status        string
+		months, price int
+	}
+	rows, err := db.QueryContext(ctx, `SELECT status, months, price FROM user_plans ORDER BY id`)
+	require.NoError(t, err)
+	defer rows.Close()
+	var got []row
+	for rows.Next() {
+		var r row
+		require.NoError(t, rows.Scan(&r.status, &r.months, &r.price))
+		got = append(got, r)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []row{
+		{status: "expired", months: 1, price: 5000},
+		{status: "canceled", months: 1, price: 0},
+		{status: "active", months: 1, price: 5000},
+	}, got, "terms are backfilled so plans can be renewed and changed")
+
+	var grace, unitPrice int
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT grace_period_days FROM plans WHERE id = 1`).Scan(&grace))
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT unit_price FROM plan_limitations WHERE plan_id = 1`).Scan(&unitPrice))
+	assert.Zero(t, grace)
+	assert.Zero(t, unitPrice)
+}
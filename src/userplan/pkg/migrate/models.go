@@ -0,0 +1,49 @@
+package migrate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// CheckModels returns ErrSchemaDrift when a table or column of the models is missing from the database,
+// catching schemas changed by hand or models changed without a migration
+func CheckModels(db *gorm.DB, models ...any) error {
+	migrator := db.Migrator()
+	var problems []string
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		table := stmt.Schema.Table
+		if !migrator.HasTable(model) {
+			problems = append(problems, fmt.Sprintf("table %s is missing", table))
+			continue
+		}
+
+		columns, err := migrator.ColumnTypes(model)
+		if err != nil {
+			return err
+		}
+		existing := make(map[string]bool, len(columns))
+		for _, c := range columns {
+			existing[c.Name()] = true
+		}
+		for _, f := range stmt.Schema.Fields {
+			if f.DBName == "" || f.IgnoreMigration {
+				continue
+			}
+			if !existing[f.DBName] {
+				problems = append(problems, fmt.Sprintf("column %s.%s is missing", table, f.DBName))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrSchemaDrift, strings.Join(problems, "; "))
+	}
+	return nil
+}
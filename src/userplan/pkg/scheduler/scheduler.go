@@ -0,0 +1,97 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+// Locker elects the replica that runs a job, TryLock reports false while another one holds the lock
+type Locker interface {
+	TryLock(ctx context.Context, name string) (bool, func(), error)
+}
+
+// Job is a named task run on a cron schedule
+type Job struct {
+	Name    string
+	Spec    string // standard 5 field cron expression, or a descriptor such as @hourly
+	Lock    string // advisory lock the job runs under, Name by default. jobs sharing a lock never overlap
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+// Scheduler runs jobs on their schedule, each run only on the replica holding the job's lock
+type Scheduler struct {
+	cron   *cron.Cron
+	locker Locker
+	log    *zap.Logger
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func New(locker Locker, log *zap.Logger) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		cron:   cron.New(cron.WithChain(cron.SkipIfStillRunning(cron.DiscardLogger))),
+		locker: locker,
+		log:    log,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Add registers job, an empty spec disables it
+func (s *Scheduler) Add(job Job) error {
+	if job.Spec == "" {
+		s.log.Info("Scheduled job disabled", zap.String("job", job.Name))
+		return nil
+	}
+	_, err := s.cron.AddFunc(job.Spec, func() { s.run(job) })
+	return err
+}
+
+func (s *Scheduler) Start() {
+	s.cron.Start()
+}
+
+// Stop stops scheduling jobs, cancels the running ones and waits for them to return
+func (s *Scheduler) Stop() {
+	done := s.cron.Stop()
+	s.cancel()
+	<-done.Done()
+}
+
+func (s *Scheduler) run(job Job) {
+	ctx := s.ctx
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.Timeout)
+		defer cancel()
+	}
+	log := s.log.With(zap.String("job", job.Name))
+
+	lock := job.Lock
+	if lock == "" {
+		lock = job.Name
+	}
+	acquired, unlock, err := s.locker.TryLock(ctx, lock)
+	if err != nil {
+		log.Error("Failed to take job lock", zap.Error(err))
+		return
+	}
+	if !acquired {
+		log.Debug("Job is run by another replica")
+		return
+	}
+	defer unlock()
+
+	start := time.Now()
+	log.Info("Job started")
+	if err := job.Run(ctx); err != nil {
+		log.Error("Job failed", zap.Error(err), zap.Duration("duration", time.Since(start)))
+		return
+	}
+	log.Info("Job completed", zap.Duration("duration", time.Since(start)))
+}
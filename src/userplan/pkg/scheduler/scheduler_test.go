@@ -0,0 +1,36 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type fakeLocker struct {
+	held     map[string]bool
+	unlocked int
+}
+
+func (l *fakeLocker) TryLock(_ context.Context, name string) (bool, func(), error) {
+	if l.held[name] {
+		return false, nil, nil
+	}
+	return true, func() { l.unlocked++ }, nil
+}
+
+func TestRun_OnlyLockHolderRuns(t *testing.T) {
+	locker := &fakeLocker{held: map[string]bool{"taken": true}}
+	s := New(locker, zap.NewNop())
+
+	runs := 0
+	job := func(context.Context) error { runs++; return nil }
+
+	s.run(Job{Name: "free", Run: job})
+	s.run(Job{Name: "taken", Run: job})
+	s.run(Job{Name: "shared", Lock: "taken", Run: job})
+
+	assert.Equal(t, 1, runs)
+	assert.Equal(t, 1, locker.unlocked)
+}